@@ -0,0 +1,353 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/rennerdo30/webencode/pkg/client"
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// globalOptions carries the persistent flags and loaded config.
+type globalOptions struct {
+	config    cliConfig
+	kernelURL string
+	apiKey    string
+	token     string
+	jsonOut   bool
+}
+
+// pick returns the first non-empty value.
+func pick(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// client builds the SDK client from flags, environment, and config, in
+// that precedence order.
+func (o *globalOptions) client() (*client.Client, error) {
+	url := pick(o.kernelURL, os.Getenv("WEBENCODE_KERNEL_URL"), o.config.KernelURL, "http://localhost:8686")
+	var opts []client.Option
+	if key := pick(o.apiKey, os.Getenv("WEBENCODE_API_KEY"), o.config.APIKey); key != "" {
+		opts = append(opts, client.WithAPIKey(key))
+	} else if token := pick(o.token, os.Getenv("WEBENCODE_TOKEN"), o.config.Token); token != "" {
+		opts = append(opts, client.WithToken(token))
+	}
+	return client.New(url, opts...), nil
+}
+
+// print renders v as JSON when --json is set, otherwise through table.
+func (o *globalOptions) print(v interface{}, table func(w *tabwriter.Writer)) error {
+	if o.jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	table(w)
+	return w.Flush()
+}
+
+func newJobCmd(opts *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{Use: "job", Short: "Manage transcode jobs"}
+
+	var source, title, outputPrefix string
+	var profiles []string
+	var watch bool
+	submit := &cobra.Command{
+		Use:   "submit",
+		Short: "Submit a transcode job",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if source == "" {
+				return errors.New("--source is required")
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+			job, err := c.SubmitJob(cmd.Context(), client.JobRequest{
+				Title:        title,
+				SourceURL:    source,
+				OutputPrefix: outputPrefix,
+				ProfileIDs:   profiles,
+			})
+			if err != nil {
+				return err
+			}
+			if watch {
+				return watchJob(cmd.Context(), c, opts, job.ID)
+			}
+			return opts.print(job, func(w *tabwriter.Writer) {
+				fmt.Fprintf(w, "ID\tSTATUS\n%s\t%s\n", job.ID, job.Status)
+			})
+		},
+	}
+	submit.Flags().StringVar(&source, "source", "", "source URL or storage path")
+	submit.Flags().StringVar(&title, "title", "", "job title")
+	submit.Flags().StringVar(&outputPrefix, "output-prefix", "", "output key prefix")
+	submit.Flags().StringSliceVar(&profiles, "profile", nil, "profile ID (repeatable)")
+	submit.Flags().BoolVarP(&watch, "watch", "w", false, "wait for the job to finish")
+	cmd.AddCommand(submit)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List jobs",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+			jobs, err := c.ListJobs(cmd.Context())
+			if err != nil {
+				return err
+			}
+			return opts.print(jobs, func(w *tabwriter.Writer) {
+				fmt.Fprintln(w, "ID\tSTATUS\tTITLE\tSOURCE")
+				for _, j := range jobs {
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", j.ID, j.Status, j.Title, j.SourceURL)
+				}
+			})
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "get <job-id>",
+		Short: "Show one job",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+			job, err := c.GetJob(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return opts.print(job, func(w *tabwriter.Writer) {
+				fmt.Fprintf(w, "ID\t%s\nSTATUS\t%s\nTITLE\t%s\nSOURCE\t%s\nERROR\t%s\n",
+					job.ID, job.Status, job.Title, job.SourceURL, job.Error)
+			})
+		},
+	})
+
+	var follow bool
+	logs := &cobra.Command{
+		Use:   "logs <job-id>",
+		Short: "Show a job's events",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+			if !follow {
+				tasks, err := c.ListJobTasks(cmd.Context(), args[0])
+				if err != nil {
+					return err
+				}
+				return opts.print(tasks, func(w *tabwriter.Writer) {
+					fmt.Fprintln(w, "TASK\tPROFILE\tSTATUS\tWORKER\tERROR")
+					for _, t := range tasks {
+						profile := ""
+						if t.Profile != nil {
+							profile = t.Profile.Name
+						}
+						fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", t.ID, profile, t.Status, t.WorkerID, t.Error)
+					}
+				})
+			}
+			events, err := c.Watch(cmd.Context(), nil, args[0])
+			if err != nil {
+				return err
+			}
+			for e := range events {
+				fmt.Printf("%s %s %s\n", e.At.Format("15:04:05"), e.Type, e.Message)
+			}
+			return nil
+		},
+	}
+	logs.Flags().BoolVarP(&follow, "follow", "f", false, "stream live events")
+	cmd.AddCommand(logs)
+
+	return cmd
+}
+
+// watchJob follows a job to completion, printing status changes.
+func watchJob(ctx context.Context, c *client.Client, opts *globalOptions, jobID string) error {
+	job, err := c.WatchJob(ctx, jobID, func(j *types.Job) {
+		fmt.Printf("%s: %s\n", j.ID, j.Status)
+	})
+	if err != nil {
+		return err
+	}
+	if job.Status == types.JobStatusFailed {
+		return fmt.Errorf("job failed: %s", job.Error)
+	}
+	return nil
+}
+
+func newWorkersCmd(opts *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{Use: "workers", Short: "Inspect worker nodes"}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List workers",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+			workers, err := c.ListWorkers(cmd.Context())
+			if err != nil {
+				return err
+			}
+			return opts.print(workers, func(w *tabwriter.Writer) {
+				fmt.Fprintln(w, "ID\tHOSTNAME\tSTATUS\tLAST HEARTBEAT")
+				for _, wk := range workers {
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", wk.ID, wk.Hostname, wk.Status, wk.LastHeartbeat.Format("15:04:05"))
+				}
+			})
+		},
+	})
+	return cmd
+}
+
+func newStreamCmd(opts *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{Use: "stream", Short: "Manage live streams"}
+
+	var title, source string
+	var archive bool
+	create := &cobra.Command{
+		Use:   "create",
+		Short: "Create a stream",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if title == "" {
+				return errors.New("--title is required")
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+			stream, err := c.CreateStream(cmd.Context(), client.StreamRequest{
+				Title:     title,
+				SourceURL: source,
+				Archive:   archive,
+			})
+			if err != nil {
+				return err
+			}
+			return opts.print(stream, func(w *tabwriter.Writer) {
+				fmt.Fprintf(w, "ID\tSTATUS\n%s\t%s\n", stream.ID, stream.Status)
+			})
+		},
+	}
+	create.Flags().StringVar(&title, "title", "", "stream title")
+	create.Flags().StringVar(&source, "source", "", "ingest source URL")
+	create.Flags().BoolVar(&archive, "archive", false, "record and transcode when the stream ends")
+	cmd.AddCommand(create)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List streams",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+			streams, err := c.ListStreams(cmd.Context())
+			if err != nil {
+				return err
+			}
+			return opts.print(streams, func(w *tabwriter.Writer) {
+				fmt.Fprintln(w, "ID\tSTATUS\tTITLE")
+				for _, s := range streams {
+					fmt.Fprintf(w, "%s\t%s\t%s\n", s.ID, s.Status, s.Title)
+				}
+			})
+		},
+	})
+	return cmd
+}
+
+func newProfilesCmd(opts *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{Use: "profiles", Short: "Inspect encoding profiles"}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List profiles",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+			profiles, err := c.ListProfiles(cmd.Context())
+			if err != nil {
+				return err
+			}
+			return opts.print(profiles, func(w *tabwriter.Writer) {
+				fmt.Fprintln(w, "ID\tNAME\tVIDEO\tRESOLUTION")
+				for _, p := range profiles {
+					fmt.Fprintf(w, "%s\t%s\t%s\t%dx%d\n", p.ID, p.Name, p.VideoCodec, p.Width, p.Height)
+				}
+			})
+		},
+	})
+	return cmd
+}
+
+func newPublishCmd(opts *globalOptions) *cobra.Command {
+	var source, plugin, title, description, visibility string
+	var profiles, tags []string
+	var watch bool
+	cmd := &cobra.Command{
+		Use:   "publish",
+		Short: "Encode a source and publish the result to a platform",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if source == "" || plugin == "" {
+				return errors.New("--source and --plugin are required")
+			}
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+			job, err := c.SubmitJob(cmd.Context(), client.JobRequest{
+				Title:      title,
+				SourceURL:  source,
+				ProfileIDs: profiles,
+				Publish: &types.PublishSpec{
+					Plugin:      plugin,
+					Title:       title,
+					Description: description,
+					Tags:        tags,
+					Visibility:  visibility,
+				},
+			})
+			if err != nil {
+				return err
+			}
+			if watch {
+				return watchJob(cmd.Context(), c, opts, job.ID)
+			}
+			return opts.print(job, func(w *tabwriter.Writer) {
+				fmt.Fprintf(w, "ID\tSTATUS\n%s\t%s\n", job.ID, job.Status)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&source, "source", "", "source URL or storage path")
+	cmd.Flags().StringVar(&plugin, "plugin", "", "publisher plugin, e.g. publisher-youtube")
+	cmd.Flags().StringVar(&title, "title", "", "published title")
+	cmd.Flags().StringVar(&description, "description", "", "published description")
+	cmd.Flags().StringVar(&visibility, "visibility", "", "public, unlisted, or private")
+	cmd.Flags().StringSliceVar(&profiles, "profile", nil, "profile ID (repeatable)")
+	cmd.Flags().StringSliceVar(&tags, "tag", nil, "tag (repeatable)")
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "wait for the job to finish")
+	return cmd
+}
@@ -0,0 +1,69 @@
+// Command cli is the webencode command-line interface for headless
+// users and CI systems. It talks to the kernel through pkg/client.
+//
+// The kernel URL and credentials come from flags, the environment
+// (WEBENCODE_KERNEL_URL, WEBENCODE_API_KEY, WEBENCODE_TOKEN), or the
+// config file at ~/.config/webencode/config.json:
+//
+//	{"kernel_url": "http://localhost:8686", "api_key": "wek_..."}
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// cliConfig is the persisted CLI configuration.
+type cliConfig struct {
+	KernelURL string `json:"kernel_url,omitempty"`
+	APIKey    string `json:"api_key,omitempty"`
+	Token     string `json:"token,omitempty"`
+}
+
+// loadConfig reads the config file, tolerating its absence.
+func loadConfig() cliConfig {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return cliConfig{}
+	}
+	data, err := os.ReadFile(filepath.Join(home, ".config", "webencode", "config.json"))
+	if err != nil {
+		return cliConfig{}
+	}
+	var cfg cliConfig
+	_ = json.Unmarshal(data, &cfg)
+	return cfg
+}
+
+func main() {
+	root := newRootCmd()
+	if err := root.Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, "webencode:", err)
+		os.Exit(1)
+	}
+}
+
+func newRootCmd() *cobra.Command {
+	opts := &globalOptions{config: loadConfig()}
+	root := &cobra.Command{
+		Use:           "webencode",
+		Short:         "Control a WebEncode kernel",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	root.PersistentFlags().StringVar(&opts.kernelURL, "kernel-url", "", "kernel base URL (default from config/env)")
+	root.PersistentFlags().StringVar(&opts.apiKey, "api-key", "", "API key (wek_...)")
+	root.PersistentFlags().StringVar(&opts.token, "token", "", "bearer token")
+	root.PersistentFlags().BoolVar(&opts.jsonOut, "json", false, "print raw JSON instead of tables")
+
+	root.AddCommand(newJobCmd(opts))
+	root.AddCommand(newWorkersCmd(opts))
+	root.AddCommand(newStreamCmd(opts))
+	root.AddCommand(newProfilesCmd(opts))
+	root.AddCommand(newPublishCmd(opts))
+	return root
+}
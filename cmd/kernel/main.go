@@ -0,0 +1,683 @@
+// Command kernel runs the WebEncode controller: HTTP API, live stream
+// management and plugin orchestration.
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+
+	"github.com/rennerdo30/webencode/internal/bootstrap"
+	"github.com/rennerdo30/webencode/internal/bus"
+	"github.com/rennerdo30/webencode/internal/encode"
+
+	"github.com/rennerdo30/webencode/internal/kernel/api"
+	"github.com/rennerdo30/webencode/internal/kernel/assets"
+	"github.com/rennerdo30/webencode/internal/kernel/audit"
+	"github.com/rennerdo30/webencode/internal/kernel/auth"
+	"github.com/rennerdo30/webencode/internal/kernel/config"
+	"github.com/rennerdo30/webencode/internal/kernel/events"
+	"github.com/rennerdo30/webencode/internal/kernel/feeds"
+	"github.com/rennerdo30/webencode/internal/kernel/grpcsrv"
+	"github.com/rennerdo30/webencode/internal/kernel/jobs"
+	"github.com/rennerdo30/webencode/internal/kernel/keys"
+	"github.com/rennerdo30/webencode/internal/kernel/leader"
+	"github.com/rennerdo30/webencode/internal/kernel/live"
+	"github.com/rennerdo30/webencode/internal/kernel/orchestrator"
+	"github.com/rennerdo30/webencode/internal/kernel/probe"
+	"github.com/rennerdo30/webencode/internal/kernel/profiles"
+	"github.com/rennerdo30/webencode/internal/kernel/ratelimit"
+	"github.com/rennerdo30/webencode/internal/kernel/restream"
+	"github.com/rennerdo30/webencode/internal/kernel/s3ingest"
+	"github.com/rennerdo30/webencode/internal/kernel/templates"
+	"github.com/rennerdo30/webencode/internal/kernel/urlpolicy"
+	"github.com/rennerdo30/webencode/internal/kernel/usage"
+	"github.com/rennerdo30/webencode/internal/kernel/webhooks"
+	"github.com/rennerdo30/webencode/internal/kernel/workers"
+	"github.com/rennerdo30/webencode/internal/metrics"
+	"github.com/rennerdo30/webencode/internal/pluginmgr"
+	"github.com/rennerdo30/webencode/internal/trace"
+	"github.com/rennerdo30/webencode/internal/worker"
+	"github.com/rennerdo30/webencode/pkg/plugin"
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+func main() {
+	if path := config.FilePath(); path != "" {
+		if err := config.LoadFile(path); err != nil {
+			log.Fatalf("kernel: load config file: %v", err)
+		}
+		log.Printf("kernel: loaded config file %s", path)
+	}
+	cfg := config.Load()
+	trace.Init(cfg.OTLPEndpoint, "webencode-kernel")
+
+	restreams := restream.NewManager()
+	defer restreams.Shutdown()
+
+	pluginManager := pluginmgr.NewManager(cfg.PluginDir)
+	defer pluginManager.Shutdown()
+
+	streams := live.NewRegistry()
+	jobManager := jobs.NewManager()
+	eventLog := events.NewLog(0)
+	profileRegistry := profiles.NewRegistry()
+	templateRegistry := templates.NewRegistry()
+
+	// Every user-supplied URL — job sources, edit bumpers, feeds,
+	// webhooks — is checked against one anti-SSRF policy before anything
+	// fetches it.
+	sourcePolicy := urlpolicy.New()
+	if len(cfg.SourceSchemes) > 0 {
+		sourcePolicy.Schemes = cfg.SourceSchemes
+	}
+	sourcePolicy.AllowPrivate = cfg.SourceAllowPrivate
+	sourcePolicy.FetchTimeout = cfg.SourceFetchTimeout
+
+	feedWatcher := feeds.NewWatcher()
+	feedWatcher.Client = sourcePolicy.Client()
+
+	webhookManager := webhooks.NewManager(eventLog)
+
+	probeStore := probe.NewStore()
+	assetCatalog := assets.NewCatalog()
+	hlsKeyStore := keys.NewStore()
+	assetCatalog.Probes = probeStore
+
+	accountant := usage.NewAccountant(usage.Quota{
+		MaxEncodeMinutes:  float64(cfg.QuotaEncodeMinutes),
+		MaxStorageBytes:   int64(cfg.QuotaStorageBytes),
+		MaxEgressBytes:    int64(cfg.QuotaEgressBytes),
+		MaxConcurrentJobs: cfg.QuotaConcurrentJobs,
+	})
+
+	workerManager := workers.NewManager(eventLog, cfg.HeartbeatTimeout)
+	workerManager.RequireAuth = cfg.RequireWorkerAuth
+	stop := make(chan struct{})
+	defer close(stop)
+	// singleton launches services that must run on exactly one kernel
+	// replica (worker monitor, webhook dispatch, feed polling). With
+	// leader election enabled it is swapped for a lease-gated launcher
+	// below, so the HTTP API can scale out without duplicating them.
+	singleton := func(run func(stop <-chan struct{})) { go run(stop) }
+
+	if cfg.NATSURL == "" && cfg.EmbeddedNATS {
+		url, stopBus, err := bus.StartEmbedded(cfg.EmbeddedNATSPort)
+		if err != nil {
+			log.Fatalf("kernel: %v", err)
+		}
+		defer stopBus()
+		cfg.NATSURL = url
+		log.Printf("kernel: embedded NATS server on %s", url)
+	}
+
+	// replicaID distinguishes this kernel instance on the bus when
+	// several run against one NATS cluster.
+	replicaID := uuid.NewString()
+	// broadcastConfig announces plugin config changes and reloads to
+	// the other kernel replicas; nil without a bus.
+	var broadcastConfig func(change types.PluginConfigChange)
+	// drainWorker asks one worker to stop accepting tasks ahead of
+	// scale-down; nil without a bus.
+	var drainWorker func(workerID string) error
+
+	var orch *orchestrator.Orchestrator
+	if cfg.NATSURL != "" {
+		nc, err := bus.Connect(cfg.NATSURL)
+		if err != nil {
+			log.Fatalf("kernel: connect NATS: %v", err)
+		}
+		defer nc.Close()
+		if cfg.JetStream {
+			classes := make(map[string]bus.StreamSettings)
+			for _, class := range []types.TaskType{types.TaskTypeProbe, types.TaskTypeTranscode, types.TaskTypeIngest} {
+				s := bus.StreamSettings{
+					MaxAge:        cfg.StreamMaxAge,
+					Replicas:      cfg.StreamReplicas,
+					MaxAckPending: cfg.StreamMaxAckPending,
+				}
+				if raw, ok := cfg.StreamMaxAgeOverrides[string(class)]; ok {
+					d, err := time.ParseDuration(raw)
+					if err != nil {
+						log.Fatalf("kernel: WEBENCODE_STREAM_MAX_AGE_OVERRIDES: %q is not a duration", raw)
+					}
+					s.MaxAge = d
+				}
+				if raw, ok := cfg.StreamMaxAckPendingOverrides[string(class)]; ok {
+					n, err := strconv.Atoi(raw)
+					if err != nil {
+						log.Fatalf("kernel: WEBENCODE_STREAM_MAX_ACK_PENDING_OVERRIDES: %q is not an integer", raw)
+					}
+					s.MaxAckPending = n
+				}
+				classes[string(class)] = s
+			}
+			if err := bus.InitStreams(nc, classes); err != nil {
+				log.Fatalf("kernel: init task streams: %v", err)
+			}
+		}
+		if _, err := nc.Subscribe(bus.SubjectHeartbeat, func(msg *nats.Msg) {
+			var hb types.Heartbeat
+			if err := json.Unmarshal(msg.Data, &hb); err != nil {
+				log.Printf("kernel: malformed heartbeat: %v", err)
+				return
+			}
+			if !workerManager.Authenticate(hb.WorkerID, hb.Token) {
+				log.Printf("kernel: rejected heartbeat from unauthenticated worker %s", hb.WorkerID)
+				return
+			}
+			workerManager.Observe(hb)
+		}); err != nil {
+			log.Fatalf("kernel: subscribe heartbeats: %v", err)
+		}
+		if _, err := nc.Subscribe(bus.SubjectPluginConfig, func(msg *nats.Msg) {
+			var change types.PluginConfigChange
+			if err := json.Unmarshal(msg.Data, &change); err != nil {
+				log.Printf("kernel: malformed config change: %v", err)
+				return
+			}
+			if change.Origin == replicaID {
+				return
+			}
+			if change.Reload {
+				if err := pluginManager.Reload(change.Plugin); err != nil {
+					log.Printf("kernel: reload %s after broadcast: %v", change.Plugin, err)
+				}
+				return
+			}
+			if err := pluginManager.SetConfig(change.Plugin, change.Values); err != nil {
+				log.Printf("kernel: apply broadcast config for %s: %v", change.Plugin, err)
+			}
+		}); err != nil {
+			log.Fatalf("kernel: subscribe config changes: %v", err)
+		}
+		broadcastConfig = func(change types.PluginConfigChange) {
+			change.Origin = replicaID
+			change.At = time.Now().UTC()
+			data, err := json.Marshal(change)
+			if err != nil {
+				return
+			}
+			if err := nc.Publish(bus.SubjectPluginConfig, data); err != nil {
+				log.Printf("kernel: publish config change: %v", err)
+			}
+		}
+		drainWorker = func(workerID string) error {
+			data, err := json.Marshal(types.WorkerDrain{WorkerID: workerID, At: time.Now().UTC()})
+			if err != nil {
+				return err
+			}
+			return nc.Publish(bus.SubjectWorkerDrain, data)
+		}
+		if cfg.LeaderElection {
+			elector, err := leader.New(nc, replicaID)
+			if err != nil {
+				log.Printf("kernel: leader election unavailable, running singletons locally: %v", err)
+			} else {
+				go elector.Run(stop)
+				singleton = func(run func(stop <-chan struct{})) { go elector.RunWhenLeader(run, stop) }
+			}
+		}
+		orch = orchestrator.New(nc, jobManager, workerManager, eventLog)
+		orch.Profiles = profileRegistry
+		orch.Publisher = pluginManager.Publisher
+		orch.Assets = assetCatalog
+		orch.Usage = accountant
+		orch.Scheduler = orchestrator.PolicyByName(cfg.SchedulerPolicy)
+		orch.MaxInflight = cfg.SchedulerMaxInflight
+		orch.Keys = hlsKeyStore
+		orch.KeyURLBase = cfg.PublicURL
+		orch.BackendRegions = cfg.StorageBackendRegions
+		if err := orch.Start(); err != nil {
+			log.Fatalf("kernel: start orchestrator: %v", err)
+		}
+		if len(cfg.TaskMaxRuntime) > 0 {
+			orch.MaxRuntime = make(map[types.TaskType]time.Duration, len(cfg.TaskMaxRuntime))
+			for taskType, raw := range cfg.TaskMaxRuntime {
+				d, err := time.ParseDuration(raw)
+				if err != nil {
+					log.Fatalf("kernel: WEBENCODE_TASK_MAX_RUNTIME: %q is not a duration", raw)
+				}
+				orch.MaxRuntime[types.TaskType(taskType)] = d
+			}
+		}
+		// Tasks stranded on a dead worker requeue as soon as its
+		// incident opens, instead of waiting for the reconciler sweep.
+		workerManager.OnUnhealthy = orch.RequeueWorkerTasks
+		// Reconciliation repairs task state that drifted across worker
+		// deaths and kernel restarts; the watchdog fails tasks that
+		// overran their runtime cap. Exactly one replica runs each.
+		singleton(func(st <-chan struct{}) { orch.Reconcile(30*time.Second, st) })
+		singleton(func(st <-chan struct{}) { orch.Watchdog(30*time.Second, st) })
+		// Watch relay health and tell workers to pause batch encodes
+		// while any relay is falling behind realtime.
+		go func() {
+			ticker := time.NewTicker(5 * time.Second)
+			defer ticker.Stop()
+			var active bool
+			for {
+				select {
+				case <-stop:
+					return
+				case <-ticker.C:
+				}
+				degraded := restreams.Degraded()
+				if degraded == active {
+					continue
+				}
+				active = degraded
+				t := types.Throttle{Active: active, At: time.Now().UTC()}
+				if active {
+					t.Reason = "restream relay degraded"
+				}
+				data, err := json.Marshal(t)
+				if err != nil {
+					continue
+				}
+				if err := nc.Publish(bus.SubjectThrottle, data); err != nil {
+					log.Printf("kernel: publish throttle: %v", err)
+				}
+			}
+		}()
+	}
+
+	singleton(func(st <-chan struct{}) { workerManager.Monitor(5*time.Second, st) })
+	singleton(webhookManager.Run)
+
+	if cfg.AllInOne {
+		// Run a worker inside the kernel process, sharing its plugin
+		// manager, so dev setups and small installs need one binary.
+		if cfg.NATSURL == "" {
+			log.Fatal("kernel: all-in-one mode needs a bus (set NATS_URL or WEBENCODE_EMBEDDED_NATS=true)")
+		}
+		w, err := worker.NewInProcess(worker.Config{
+			NATSURL:         cfg.NATSURL,
+			PluginDir:       cfg.PluginDir,
+			StoragePlugin:   cfg.StoragePlugin,
+			StorageBackends: cfg.StorageBackends,
+		}, pluginManager)
+		if err != nil {
+			log.Fatalf("kernel: start in-process worker: %v", err)
+		}
+		if err := w.SubscribeTasks(); err != nil {
+			log.Fatalf("kernel: in-process worker subscribe tasks: %v", err)
+		}
+		if err := w.SubscribeThrottle(); err != nil {
+			log.Fatalf("kernel: in-process worker subscribe throttle: %v", err)
+		}
+		if err := w.SubscribeDrain(); err != nil {
+			log.Fatalf("kernel: in-process worker subscribe drain: %v", err)
+		}
+		go w.Run(stop)
+		log.Printf("kernel: in-process worker %s joined the bus", w.ID)
+	}
+
+	// launchTemplateJob submits and dispatches one job through a
+	// template, mirroring the template-launch endpoint; the automated
+	// ingest paths (feeds, bucket notifications) go through it.
+	launchTemplateJob := func(templateID, ownerID, title, sourceURL string, ingest bool, metadata map[string]interface{}) error {
+		t, err := templateRegistry.Get(templateID)
+		if err != nil {
+			return err
+		}
+		if title == "" {
+			title = t.Name
+		}
+		metadata["template_id"] = t.ID
+		spec := &types.Job{
+			UserID:     ownerID,
+			Title:      title,
+			SourceURL:  sourceURL,
+			ProfileIDs: t.ProfileIDs,
+			FanOut:     t.FanOut,
+			Publish:    t.Publish,
+			Metadata:   metadata,
+		}
+		if ingest {
+			spec.Ingest = &types.IngestSpec{}
+		}
+		job := jobManager.Submit(spec)
+		job.OutputPrefix = templates.ExpandOutput(t, job)
+		if err := jobManager.SetOutputPrefix(job.ID, job.OutputPrefix); err != nil {
+			return err
+		}
+		if orch == nil {
+			return nil
+		}
+		jobProfiles := make([]*types.Profile, 0, len(job.ProfileIDs))
+		for _, id := range job.ProfileIDs {
+			p, err := profileRegistry.Get(id)
+			if err != nil {
+				return err
+			}
+			jobProfiles = append(jobProfiles, p)
+		}
+		return orch.SubmitJob(job, jobProfiles)
+	}
+	feedWatcher.Submit = func(f *feeds.Feed, item feeds.Item) error {
+		return launchTemplateJob(f.TemplateID, f.OwnerID, item.Title, item.EnclosureURL, f.Ingest, map[string]interface{}{
+			"feed_id":   f.ID,
+			"feed_item": item.GUID,
+		})
+	}
+	singleton(feedWatcher.Run)
+
+	// Priority chains: the plural config lists take precedence over the
+	// single-plugin fields, which remain the common case.
+	storagePlugins := cfg.StoragePlugins
+	if len(storagePlugins) == 0 && cfg.StoragePlugin != "" {
+		storagePlugins = []string{cfg.StoragePlugin}
+	}
+	authPlugins := cfg.AuthPlugins
+	if len(authPlugins) == 0 && cfg.AuthPlugin != "" {
+		authPlugins = []string{cfg.AuthPlugin}
+	}
+
+	storageFor := func(alias string) (plugin.StorageService, error) {
+		name, ok := cfg.StorageBackends[alias]
+		if !ok {
+			return nil, fmt.Errorf("unknown storage backend %q", alias)
+		}
+		return pluginManager.Storage(name)
+	}
+
+	var storage func() (plugin.StorageService, error)
+	if len(storagePlugins) > 0 {
+		chain := pluginManager.StorageChain(storagePlugins, cfg.StorageFailoverOps)
+		storage = func() (plugin.StorageService, error) {
+			return chain, nil
+		}
+	}
+
+	objectIngest := s3ingest.NewListener()
+	objectIngest.Submit = func(rule *s3ingest.Rule, bucket, key string) error {
+		// Presign the landed object through the storage plugin when one
+		// is configured, so workers can fetch it directly.
+		source := "s3://" + bucket + "/" + key
+		if storage != nil {
+			if st, err := storage(); err == nil {
+				if url, err := st.PresignGet(key, 24*time.Hour); err == nil {
+					source = url
+				}
+			}
+		}
+		return launchTemplateJob(rule.TemplateID, rule.OwnerID, key, source, false, map[string]interface{}{
+			"ingest_rule": rule.ID,
+			"bucket":      bucket,
+			"object_key":  key,
+		})
+	}
+
+	archiver := live.NewArchiver(streams, jobManager, cfg.RecordDir)
+	archiver.Storage = storage
+	if orch != nil {
+		orch.Storage = storage
+	}
+
+	var livePlugin func() (plugin.LiveService, error)
+	if cfg.LivePlugin != "" {
+		livePlugin = func() (plugin.LiveService, error) {
+			return pluginManager.Live(cfg.LivePlugin)
+		}
+	}
+
+	authorizer := auth.FromConfig(cfg)
+	server := &api.Server{
+		Authorizer:   authorizer,
+		Streams:      streams,
+		Profiles:     profileRegistry,
+		Restreams:    restreams,
+		Archiver:     archiver,
+		Events:       eventLog,
+		Emergency:    live.NewEmergencyMonitor(streams, eventLog, live.DefaultEmergencyConfig),
+		Jobs:         jobManager,
+		Batches:      jobs.NewBatches(jobManager),
+		Workers:      workerManager,
+		Keys:         auth.NewKeyStore(),
+		Sessions:     auth.NewSessionStore(),
+		Webhooks:     webhookManager,
+		Audit:        audit.NewLog(cfg.AuditMax, cfg.AuditRetention),
+		Templates:    templateRegistry,
+		Feeds:        feedWatcher,
+		ObjectIngest: objectIngest,
+		Probes:       probeStore,
+		Assets:       assetCatalog,
+		Usage:        accountant,
+		HLSKeys:      hlsKeyStore,
+		Pricing: api.Pricing{
+			PerEncodeMinute: cfg.PricePerEncodeMinute,
+			PerGBStored:     cfg.PricePerGBStored,
+			Currency:        cfg.PriceCurrency,
+		},
+		BootstrapNATSURL:   cfg.NATSURL,
+		BootstrapPluginDir: cfg.PluginDir,
+		Storage:            storage,
+		StorageBackends:    cfg.StorageBackends,
+		StorageFor:         storageFor,
+		Live:               livePlugin,
+		Publisher:          pluginManager.Publisher,
+	}
+	if policy, ok := authorizer.(*auth.PolicyAuthorizer); ok {
+		server.Policy = policy
+	}
+	server.Orchestrator = orch
+	server.DrainWorker = drainWorker
+	server.SourceURLs = sourcePolicy
+	buildRateLimits := func(c config.Config) (api.RateLimits, error) {
+		overrides := make(map[string]int, len(c.RateLimitOverrides))
+		for caller, v := range c.RateLimitOverrides {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return api.RateLimits{}, fmt.Errorf("invalid rate limit override for %s: %q", caller, v)
+			}
+			overrides[caller] = n
+		}
+		return api.RateLimits{
+			Default:   c.RateLimit,
+			Expensive: c.RateLimitExpensive,
+			Window:    c.RateLimitWindow,
+			Overrides: overrides,
+		}, nil
+	}
+	if cfg.RateLimit > 0 {
+		if cfg.RedisAddr != "" {
+			server.RateLimiter = ratelimit.NewRedisLimiter(cfg.RedisAddr, cfg.RedisPassword)
+		} else {
+			server.RateLimiter = ratelimit.NewMemoryLimiter()
+		}
+		limits, err := buildRateLimits(cfg)
+		if err != nil {
+			log.Fatalf("kernel: %v", err)
+		}
+		server.RateLimits = limits
+	}
+	// Config reload re-reads the file layer and applies the settings
+	// that are safe to change on a running kernel (rate limits);
+	// everything else still needs a restart. Triggered by SIGHUP or
+	// POST /v1/system/config/reload.
+	server.ReloadConfig = func() {
+		if path := config.FilePath(); path != "" {
+			if err := config.LoadFile(path); err != nil {
+				log.Printf("kernel: reload config: %v", err)
+				return
+			}
+		}
+		fresh := config.Load()
+		limits, err := buildRateLimits(fresh)
+		if err != nil {
+			log.Printf("kernel: reload config: %v", err)
+			return
+		}
+		server.SetRateLimits(limits)
+		log.Printf("kernel: config reloaded, rate limits applied")
+	}
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	go func() {
+		for range sighup {
+			server.ReloadConfig()
+		}
+	}()
+	if len(authPlugins) > 0 {
+		chain := pluginManager.AuthChain(authPlugins)
+		server.Auth = func() (plugin.AuthService, error) {
+			return chain, nil
+		}
+	}
+	configuredPlugins := append(append([]string{cfg.LivePlugin}, storagePlugins...), authPlugins...)
+	server.Plugins = func() []pluginmgr.Status {
+		return pluginManager.Scan(configuredPlugins)
+	}
+	server.ReloadPlugin = func(name string) error {
+		if err := pluginManager.Reload(name); err != nil {
+			return err
+		}
+		if broadcastConfig != nil {
+			broadcastConfig(types.PluginConfigChange{Plugin: name, Reload: true})
+		}
+		return nil
+	}
+	server.CodecSupport = func() map[string]bool {
+		return encode.DetectEncoders("libsvtav1", "libaom-av1", "libvvenc", "libx265")
+	}
+	server.PluginSchema = pluginManager.ConfigSchema
+	server.PluginConfig = pluginManager.Config
+	server.SetPluginConfig = func(name string, values map[string]string) error {
+		if err := pluginManager.SetConfig(name, values); err != nil {
+			return err
+		}
+		if broadcastConfig != nil {
+			broadcastConfig(types.PluginConfigChange{Plugin: name, Values: values})
+		}
+		return nil
+	}
+	if cfg.PluginRegistryURL != "" {
+		installer := &pluginmgr.Installer{
+			IndexURL:  cfg.PluginRegistryURL,
+			PluginDir: cfg.PluginDir,
+		}
+		if cfg.PluginRegistryKey != "" {
+			key, err := hex.DecodeString(cfg.PluginRegistryKey)
+			if err != nil || len(key) != ed25519.PublicKeySize {
+				log.Fatalf("kernel: WEBENCODE_PLUGIN_REGISTRY_KEY is not a hex ed25519 public key")
+			}
+			installer.PublicKey = ed25519.PublicKey(key)
+		}
+		server.PluginCatalog = installer.Available
+		server.InstallPlugin = func(name string) error {
+			if err := installer.Install(name); err != nil {
+				return err
+			}
+			return pluginManager.Reload(name)
+		}
+	}
+	go pluginManager.Watch(10*time.Second, stop)
+	go pluginManager.Supervise(10*time.Second, stop)
+	metrics.NewGaugeFunc("webencode_jobs", "Jobs by status.",
+		[]string{"status"}, func() map[string]float64 {
+			out := make(map[string]float64)
+			for _, j := range jobManager.List("") {
+				out[metrics.Key(string(j.Status))]++
+			}
+			return out
+		})
+	metrics.NewGaugeFunc("webencode_workers", "Worker nodes by status.",
+		[]string{"status"}, func() map[string]float64 {
+			out := make(map[string]float64)
+			for _, w := range workerManager.List() {
+				out[metrics.Key(string(w.Status))]++
+			}
+			return out
+		})
+	metrics.NewGaugeFunc("webencode_live_streams", "Live streams by status.",
+		[]string{"status"}, func() map[string]float64 {
+			out := make(map[string]float64)
+			for _, st := range streams.List("") {
+				out[metrics.Key(string(st.Status))]++
+			}
+			return out
+		})
+	metrics.NewGaugeFunc("webencode_plugin_up", "Whether the plugin's last health check passed.",
+		[]string{"plugin"}, func() map[string]float64 {
+			out := make(map[string]float64)
+			for _, st := range pluginManager.Scan(configuredPlugins) {
+				up := 0.0
+				if st.State == "ok" && (st.Health == "" || st.Health == "healthy") {
+					up = 1
+				}
+				out[metrics.Key(st.Name)] = up
+			}
+			return out
+		})
+	for _, st := range pluginManager.Scan(configuredPlugins) {
+		if st.State == "ok" {
+			continue
+		}
+		var msg string
+		switch st.State {
+		case "missing":
+			msg = "configured plugin has no binary in the plugin dir"
+		case "unused":
+			msg = "plugin binary present but not configured"
+		}
+		log.Printf("kernel: plugin %s: %s", st.Name, msg)
+		eventLog.Append(events.Event{
+			Type:     "plugin.drift",
+			Resource: st.Name,
+			Message:  msg,
+			Data:     map[string]interface{}{"state": st.State, "kind": st.Kind},
+		})
+	}
+
+	if cfg.GRPCAddr != "" {
+		grpcServer := &grpcsrv.Server{
+			Jobs:           jobManager,
+			Streams:        streams,
+			Workers:        workerManager,
+			Profiles:       profileRegistry,
+			Orchestrator:   orch,
+			Authorizer:     authorizer,
+			Keys:           server.Keys,
+			SourceURLs:     sourcePolicy,
+			AuthConfigured: len(authPlugins) > 0,
+		}
+		go func() {
+			if err := grpcServer.Serve(cfg.GRPCAddr); err != nil {
+				log.Fatalf("kernel: gRPC API: %v", err)
+			}
+		}()
+	}
+
+	if cfg.SIEMEndpoint != "" {
+		exporter := events.NewExporter(eventLog, events.ExportConfig{
+			Endpoint:      cfg.SIEMEndpoint,
+			BatchSize:     cfg.SIEMBatchSize,
+			FlushInterval: cfg.SIEMFlushInterval,
+		})
+		go exporter.Run(nil)
+	}
+
+	if cfg.BootstrapKey != "" {
+		key, err := bootstrap.ParseKey(cfg.BootstrapKey)
+		if err != nil {
+			log.Fatalf("kernel: %v", err)
+		}
+		server.BootstrapKey = key
+	}
+
+	log.Printf("kernel: listening on %s", cfg.ListenAddr)
+	if err := http.ListenAndServe(cfg.ListenAddr, server.Routes()); err != nil {
+		log.Fatalf("kernel: %v", err)
+	}
+}
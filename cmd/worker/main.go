@@ -0,0 +1,165 @@
+// Command worker runs a WebEncode worker node.
+//
+// The quickest way to bring a node up is a bootstrap token issued by the
+// kernel (POST /v1/workers/bootstrap-token):
+//
+//	WEBENCODE_BOOTSTRAP_KEY=<hex> worker --join <token>
+//
+// Without --join the worker reads NATS_URL, WEBENCODE_PLUGIN_DIR and
+// friends from the environment.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/rennerdo30/webencode/internal/bootstrap"
+	"github.com/rennerdo30/webencode/internal/trace"
+	"github.com/rennerdo30/webencode/internal/worker"
+)
+
+// parseBackends parses "alias=plugin,alias2=plugin2" pairs.
+func parseBackends(v string) map[string]string {
+	out := make(map[string]string)
+	for _, part := range strings.Split(v, ",") {
+		alias, name, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found || alias == "" || name == "" {
+			continue
+		}
+		out[alias] = name
+	}
+	return out
+}
+
+func main() {
+	join := flag.String("join", "", "bootstrap token issued by the kernel")
+	flag.Parse()
+	trace.Init(os.Getenv("WEBENCODE_OTLP_ENDPOINT"), "webencode-worker")
+
+	var cfg worker.Config
+	if *join != "" {
+		key, err := bootstrap.ParseKey(os.Getenv("WEBENCODE_BOOTSTRAP_KEY"))
+		if err != nil {
+			log.Fatalf("worker: %v", err)
+		}
+		token, err := bootstrap.Open(*join, key)
+		if err != nil {
+			log.Fatalf("worker: invalid bootstrap token: %v", err)
+		}
+		cfg = worker.Config{
+			NATSURL:         token.NATSURL,
+			NATSCreds:       token.NATSCreds,
+			WorkerID:        token.WorkerID,
+			WorkerToken:     token.WorkerToken,
+			PluginDir:       token.PluginDir,
+			StoragePlugin:   os.Getenv("WEBENCODE_STORAGE_PLUGIN"),
+			StorageBackends: parseBackends(os.Getenv("WEBENCODE_STORAGE_BACKENDS")),
+			EncoderPlugin:   os.Getenv("WEBENCODE_ENCODER_PLUGIN"),
+			Labels:          token.Labels,
+		}
+	} else {
+		cfg = worker.Config{
+			NATSURL:         os.Getenv("NATS_URL"),
+			NATSCreds:       os.Getenv("NATS_CREDS"),
+			WorkerID:        os.Getenv("WEBENCODE_WORKER_ID"),
+			WorkerToken:     os.Getenv("WEBENCODE_WORKER_TOKEN"),
+			PluginDir:       os.Getenv("WEBENCODE_PLUGIN_DIR"),
+			StoragePlugin:   os.Getenv("WEBENCODE_STORAGE_PLUGIN"),
+			StorageBackends: parseBackends(os.Getenv("WEBENCODE_STORAGE_BACKENDS")),
+			EncoderPlugin:   os.Getenv("WEBENCODE_ENCODER_PLUGIN"),
+			Labels:          parseBackends(os.Getenv("WEBENCODE_WORKER_LABELS")),
+		}
+	}
+	if v := os.Getenv("WEBENCODE_MIN_FREE_BYTES"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			log.Fatalf("worker: invalid WEBENCODE_MIN_FREE_BYTES: %v", err)
+		}
+		cfg.MinFreeBytes = n
+	}
+	if v := os.Getenv("WEBENCODE_TRANSFER_CONCURRENCY"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			log.Fatalf("worker: invalid WEBENCODE_TRANSFER_CONCURRENCY: %v", err)
+		}
+		cfg.TransferConcurrency = n
+	}
+	if v := os.Getenv("WEBENCODE_TRANSFER_BANDWIDTH_KBPS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			log.Fatalf("worker: invalid WEBENCODE_TRANSFER_BANDWIDTH_KBPS: %v", err)
+		}
+		cfg.TransferBandwidthKbps = n
+	}
+	if v := os.Getenv("WEBENCODE_CHECKPOINT_CHUNK"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			log.Fatalf("worker: invalid WEBENCODE_CHECKPOINT_CHUNK: %v", err)
+		}
+		cfg.CheckpointChunk = d
+	}
+	cfg.SandboxImage = os.Getenv("WEBENCODE_SANDBOX_IMAGE")
+	if v := os.Getenv("WEBENCODE_SANDBOX_CPUS"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			log.Fatalf("worker: invalid WEBENCODE_SANDBOX_CPUS: %v", err)
+		}
+		cfg.SandboxCPUs = f
+	}
+	if v := os.Getenv("WEBENCODE_SANDBOX_MEMORY_MB"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			log.Fatalf("worker: invalid WEBENCODE_SANDBOX_MEMORY_MB: %v", err)
+		}
+		cfg.SandboxMemoryMB = n
+	}
+	cfg.PreemptionURL = os.Getenv("WEBENCODE_PREEMPTION_URL")
+	if v := os.Getenv("WEBENCODE_PREEMPTION_GRACE"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			log.Fatalf("worker: invalid WEBENCODE_PREEMPTION_GRACE: %v", err)
+		}
+		cfg.PreemptionGrace = d
+	}
+	if cfg.NATSURL == "" {
+		log.Fatal("worker: no NATS URL configured (use --join or NATS_URL)")
+	}
+
+	w, err := worker.New(cfg)
+	if err != nil {
+		log.Fatalf("worker: %v", err)
+	}
+	if err := w.SubscribeTasks(); err != nil {
+		log.Fatalf("worker: subscribe tasks: %v", err)
+	}
+	if err := w.SubscribeThrottle(); err != nil {
+		log.Fatalf("worker: subscribe throttle: %v", err)
+	}
+	if err := w.SubscribeDrain(); err != nil {
+		log.Fatalf("worker: subscribe drain: %v", err)
+	}
+	log.Printf("worker: %s joined %s", w.ID, cfg.NATSURL)
+
+	stop := make(chan struct{})
+	if cfg.PreemptionURL != "" {
+		go w.WatchPreemption(stop)
+	}
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+		select {
+		case <-sig:
+		case <-w.Preempted():
+			// The node evacuated after a preemption notice; shut down
+			// without waiting for the platform's SIGTERM.
+		}
+		close(stop)
+	}()
+	w.Run(stop)
+}
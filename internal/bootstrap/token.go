@@ -0,0 +1,98 @@
+// Package bootstrap issues and opens worker bootstrap tokens: one
+// copy-pasteable string that carries everything a fresh node needs to
+// join the cluster. Tokens are AES-GCM encrypted with a shared cluster
+// key, which both hides credentials and authenticates the issuer.
+package bootstrap
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Token is the payload embedded in a bootstrap token.
+type Token struct {
+	// NATSURL and NATSCreds connect the worker to the bus.
+	NATSURL   string `json:"nats_url"`
+	NATSCreds string `json:"nats_creds,omitempty"`
+	// PluginDir is where the worker looks for plugin executables.
+	PluginDir string `json:"plugin_dir,omitempty"`
+	// WorkerID and WorkerToken are the enrollment issued alongside the
+	// token; the worker uses them to authenticate its bus messages.
+	WorkerID    string `json:"worker_id,omitempty"`
+	WorkerToken string `json:"worker_token,omitempty"`
+	// Labels are attached to the worker's heartbeats (region, gpu, ...).
+	Labels    map[string]string `json:"labels,omitempty"`
+	IssuedAt  time.Time         `json:"issued_at"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// ParseKey decodes the shared cluster key from its hex form.
+func ParseKey(hexKey string) ([]byte, error) {
+	key, err := hex.DecodeString(hexKey)
+	if err != nil {
+		return nil, fmt.Errorf("bootstrap key is not valid hex: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("bootstrap key must be 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+// Seal encrypts a token with the cluster key.
+func Seal(t Token, key []byte) (string, error) {
+	plaintext, err := json.Marshal(t)
+	if err != nil {
+		return "", err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, plaintext, nil)
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts and validates a token with the cluster key.
+func Open(encoded string, key []byte) (*Token, error) {
+	sealed, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("malformed token: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("malformed token")
+	}
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("token authentication failed: %w", err)
+	}
+	var t Token
+	if err := json.Unmarshal(plaintext, &t); err != nil {
+		return nil, err
+	}
+	if time.Now().After(t.ExpiresAt) {
+		return nil, fmt.Errorf("token expired at %s", t.ExpiresAt.Format(time.RFC3339))
+	}
+	return &t, nil
+}
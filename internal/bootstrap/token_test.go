@@ -0,0 +1,98 @@
+package bootstrap
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testKey() []byte {
+	return bytes.Repeat([]byte{0x42}, 32)
+}
+
+func TestSealOpenRoundTrip(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	in := Token{
+		NATSURL:     "nats://kernel:4222",
+		PluginDir:   "/opt/webencode/plugins",
+		WorkerID:    "w-1",
+		WorkerToken: "secret",
+		Labels:      map[string]string{"region": "eu-west"},
+		IssuedAt:    now,
+		ExpiresAt:   now.Add(time.Hour),
+	}
+	sealed, err := Seal(in, testKey())
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	out, err := Open(sealed, testKey())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if out.NATSURL != in.NATSURL || out.WorkerID != in.WorkerID ||
+		out.WorkerToken != in.WorkerToken || out.Labels["region"] != "eu-west" {
+		t.Errorf("round trip lost fields: got %+v", out)
+	}
+}
+
+func TestOpenRejectsWrongKey(t *testing.T) {
+	sealed, err := Seal(Token{ExpiresAt: time.Now().Add(time.Hour)}, testKey())
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	other := bytes.Repeat([]byte{0x17}, 32)
+	if _, err := Open(sealed, other); err == nil {
+		t.Error("Open accepted a token sealed with a different key")
+	}
+}
+
+func TestOpenRejectsTampering(t *testing.T) {
+	sealed, err := Seal(Token{ExpiresAt: time.Now().Add(time.Hour)}, testKey())
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(sealed)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	raw[len(raw)-1] ^= 0x01
+	if _, err := Open(base64.RawURLEncoding.EncodeToString(raw), testKey()); err == nil {
+		t.Error("Open accepted a tampered token")
+	}
+	if _, err := Open("not base64!!", testKey()); err == nil {
+		t.Error("Open accepted garbage input")
+	}
+	if _, err := Open("", testKey()); err == nil {
+		t.Error("Open accepted an empty token")
+	}
+}
+
+func TestOpenRejectsExpired(t *testing.T) {
+	sealed, err := Seal(Token{ExpiresAt: time.Now().Add(-time.Minute)}, testKey())
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if _, err := Open(sealed, testKey()); err == nil {
+		t.Error("Open accepted an expired token")
+	} else if !strings.Contains(err.Error(), "expired") {
+		t.Errorf("expected expiry error, got %v", err)
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	if _, err := ParseKey("zz"); err == nil {
+		t.Error("ParseKey accepted non-hex input")
+	}
+	if _, err := ParseKey("abcd"); err == nil {
+		t.Error("ParseKey accepted a short key")
+	}
+	key, err := ParseKey(strings.Repeat("ab", 32))
+	if err != nil {
+		t.Fatalf("ParseKey: %v", err)
+	}
+	if len(key) != 32 {
+		t.Errorf("ParseKey returned %d bytes, want 32", len(key))
+	}
+}
@@ -0,0 +1,62 @@
+// Package bus wraps the NATS connection shared by the kernel and
+// workers and names the subjects they talk over.
+package bus
+
+import (
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// Subjects used on the bus.
+const (
+	// SubjectHeartbeat carries types.Heartbeat messages from workers.
+	SubjectHeartbeat = "workers.heartbeat"
+	// SubjectTasksPrefix roots the per-class task dispatch subjects: a
+	// task of type t travels on TaskSubject(t), e.g.
+	// "tasks.dispatch.transcode". Per-class subjects let stream
+	// retention, age and ack limits differ between quick probes and
+	// hours-long transcodes.
+	SubjectTasksPrefix = "tasks.dispatch"
+	// SubjectTasksAll matches every task class; workers consume it in
+	// the TaskQueueGroup so each task lands on one worker.
+	SubjectTasksAll = "tasks.dispatch.*"
+	// SubjectTaskEvents carries types.TaskEvent lifecycle messages from
+	// workers back to the kernel.
+	SubjectTaskEvents = "tasks.events"
+	// SubjectTaskLogs carries types.TaskLog lines (ffmpeg stderr) from
+	// workers for live job log tailing.
+	SubjectTaskLogs = "tasks.logs"
+	// SubjectDeadLetter carries tasks that exhausted their retries, for
+	// external consumers archiving or alerting on them.
+	SubjectDeadLetter = "tasks.dead"
+	// SubjectThrottle carries types.Throttle messages telling workers to
+	// pause or resume batch encodes while live relays are degraded.
+	SubjectThrottle = "workers.throttle"
+	// SubjectWorkerDrain carries types.WorkerDrain requests telling one
+	// worker to stop accepting tasks and finish what it has, so
+	// autoscalers can take a node out of rotation before deleting it.
+	SubjectWorkerDrain = "workers.drain"
+	// SubjectPluginConfig carries types.PluginConfigChange broadcasts so
+	// every kernel replica applies plugin config changes and reloads,
+	// not just the one the API call landed on.
+	SubjectPluginConfig = "plugins.config"
+)
+
+// TaskQueueGroup is the queue group workers share on the dispatch
+// subjects.
+const TaskQueueGroup = "workers"
+
+// TaskSubject returns the dispatch subject for one task class.
+func TaskSubject(class string) string {
+	return SubjectTasksPrefix + "." + class
+}
+
+// Connect dials NATS with sane retry defaults.
+func Connect(url string) (*nats.Conn, error) {
+	return nats.Connect(url,
+		nats.MaxReconnects(-1),
+		nats.ReconnectWait(2*time.Second),
+		nats.Name("webencode"),
+	)
+}
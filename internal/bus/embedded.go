@@ -0,0 +1,42 @@
+package bus
+
+import (
+	"fmt"
+	"time"
+
+	server "github.com/nats-io/nats-server/v2/server"
+)
+
+// embeddedStartTimeout is how long StartEmbedded waits for the embedded
+// server to accept connections.
+const embeddedStartTimeout = 5 * time.Second
+
+// StartEmbedded runs an in-process loopback NATS server for
+// single-machine deployments with no external infrastructure. The
+// server is imported as a library so the kernel stays a genuinely
+// single binary — nothing to install on the host. The returned stop
+// function shuts the server down; callers defer it next to the
+// connection close.
+func StartEmbedded(port int) (url string, stop func(), err error) {
+	if port <= 0 {
+		port = 4222
+	}
+	srv, err := server.NewServer(&server.Options{
+		Host:   "127.0.0.1",
+		Port:   port,
+		NoSigs: true,
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("embedded bus: %w", err)
+	}
+	go srv.Start()
+	if !srv.ReadyForConnections(embeddedStartTimeout) {
+		srv.Shutdown()
+		return "", nil, fmt.Errorf("embedded bus: server did not become ready on 127.0.0.1:%d", port)
+	}
+	stop = func() {
+		srv.Shutdown()
+		srv.WaitForShutdown()
+	}
+	return srv.ClientURL(), stop, nil
+}
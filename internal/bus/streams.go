@@ -0,0 +1,70 @@
+package bus
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// StreamSettings configures one per-class JetStream task stream.
+type StreamSettings struct {
+	// MaxAge drops captured dispatches older than this; zero keeps
+	// them until other limits apply.
+	MaxAge time.Duration
+	// Replicas is the stream replication factor on a clustered bus;
+	// zero means 1.
+	Replicas int
+	// MaxAckPending caps unacknowledged deliveries on the class's
+	// durable consumer; zero uses the server default.
+	MaxAckPending int
+}
+
+// InitStreams provisions one JetStream stream per task class over its
+// dispatch subject, each with its own retention, age, replication and
+// ack-pending settings — hours-long transcodes and sub-second probes
+// should not share limits. Each stream gets a durable pull consumer
+// named after the worker queue group, so dispatches published while no
+// worker was up can be replayed. Workers keep consuming over core
+// queue subscriptions; the streams add durability and replay on top
+// without changing the dispatch path.
+func InitStreams(nc *nats.Conn, classes map[string]StreamSettings) error {
+	js, err := nc.JetStream()
+	if err != nil {
+		return fmt.Errorf("bus: jetstream: %w", err)
+	}
+	for class, s := range classes {
+		replicas := s.Replicas
+		if replicas <= 0 {
+			replicas = 1
+		}
+		cfg := &nats.StreamConfig{
+			Name:      streamName(class),
+			Subjects:  []string{TaskSubject(class)},
+			Retention: nats.LimitsPolicy,
+			MaxAge:    s.MaxAge,
+			Replicas:  replicas,
+		}
+		if _, err := js.AddStream(cfg); err != nil {
+			// The stream may exist with older settings; converge it.
+			if _, err := js.UpdateStream(cfg); err != nil {
+				return fmt.Errorf("bus: stream %s: %w", cfg.Name, err)
+			}
+		}
+		if _, err := js.AddConsumer(cfg.Name, &nats.ConsumerConfig{
+			Durable:       TaskQueueGroup,
+			AckPolicy:     nats.AckExplicitPolicy,
+			MaxAckPending: s.MaxAckPending,
+		}); err != nil {
+			return fmt.Errorf("bus: consumer on %s: %w", cfg.Name, err)
+		}
+	}
+	return nil
+}
+
+// streamName maps a task class to its stream name, e.g. "transcode"
+// to "WEBENCODE_TASKS_TRANSCODE".
+func streamName(class string) string {
+	return "WEBENCODE_TASKS_" + strings.ToUpper(class)
+}
@@ -0,0 +1,200 @@
+// Package encode maps encoding profiles to ffmpeg arguments. It is
+// shared by the worker and the ffmpeg-based encoder plugin so both
+// produce identical command lines for the same profile.
+package encode
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// OutputArgs builds the ffmpeg output arguments (everything after the
+// input flags) for one profile rendering to outputPath.
+func OutputArgs(p *types.Profile, outputPath string) []string {
+	return OutputArgsWithCrop(p, outputPath, "")
+}
+
+// OutputArgsWithCrop is OutputArgs with a cropdetect window ("w:h:x:y")
+// from a pre-pass applied before the profile's aspect conversion; smart
+// aspect mode callers run the pre-pass and feed its result here.
+func OutputArgsWithCrop(p *types.Profile, outputPath, detectedCrop string) []string {
+	var args []string
+
+	if p.AudioOnly {
+		args = append(args, "-vn")
+	} else {
+		if p.VideoCodec != "" {
+			args = append(args, "-c:v", p.VideoCodec)
+		}
+		var filters []string
+		if p.Width > 0 || p.Height > 0 || detectedCrop != "" {
+			filters = append(filters, aspectFilter(p.Width, p.Height, string(p.AspectMode), detectedCrop))
+		}
+		filters = append(filters, filterChain(&p.Filters)...)
+		if f := hdrFilter(p); f != "" {
+			filters = append(filters, f)
+		}
+		if vf := strings.Join(filters, ","); vf != "" || p.Filters.Watermark != nil {
+			if wm := p.Filters.Watermark; wm != nil {
+				vf = watermarkGraph(vf, wm)
+			}
+			args = append(args, "-vf", vf)
+		}
+		args = append(args, hdrArgs(p)...)
+		switch {
+		case p.CRF > 0:
+			args = append(args, "-crf", strconv.Itoa(p.CRF))
+		case p.VideoBitrateKbps > 0:
+			args = append(args, "-b:v", fmt.Sprintf("%dk", p.VideoBitrateKbps))
+		}
+		if p.Preset != "" {
+			args = append(args, "-preset", p.Preset)
+		}
+		args = append(args, av1Args(p)...)
+		if p.Framerate > 0 {
+			args = append(args, "-r", strconv.FormatFloat(p.Framerate, 'f', -1, 64))
+		}
+	}
+	if p.AudioCodec != "" {
+		args = append(args, "-c:a", p.AudioCodec)
+	}
+	if p.AudioBitrateKbps > 0 {
+		args = append(args, "-b:a", fmt.Sprintf("%dk", p.AudioBitrateKbps))
+	}
+
+	args = append(args, containerArgs(&p.Container, outputPath)...)
+	args = append(args, outputPath)
+	return args
+}
+
+// OutputExt picks the file extension for a profile's container.
+func OutputExt(p *types.Profile) string {
+	switch p.Container.Format {
+	case "hls":
+		return ".m3u8"
+	case "mkv", "matroska":
+		return ".mkv"
+	case "webm":
+		return ".webm"
+	case "":
+		if p.AudioOnly {
+			return audioExt(p.AudioCodec)
+		}
+		return ".mp4"
+	default:
+		return "." + p.Container.Format
+	}
+}
+
+// audioExt infers the container extension for audio-only outputs from
+// the codec when the profile does not name a format.
+func audioExt(codec string) string {
+	switch codec {
+	case "mp3", "libmp3lame":
+		return ".mp3"
+	case "flac":
+		return ".flac"
+	case "opus", "libopus":
+		return ".opus"
+	case "vorbis", "libvorbis":
+		return ".ogg"
+	case "aac":
+		return ".m4a"
+	default:
+		// Stream copies and exotic codecs go into Matroska audio, which
+		// accepts anything.
+		return ".mka"
+	}
+}
+
+// containerArgs translates the container options to muxer flags.
+func containerArgs(c *types.ContainerOptions, outputPath string) []string {
+	var args []string
+	if c.Format != "" {
+		args = append(args, "-f", c.Format)
+	}
+
+	var movflags []string
+	if c.Faststart {
+		movflags = append(movflags, "+faststart")
+	}
+	if c.Fragmented {
+		// empty_moov+default_base_moof is the CMAF-friendly fMP4 layout.
+		movflags = append(movflags, "+frag_keyframe", "+empty_moov", "+default_base_moof")
+		if c.FragmentDurationMs > 0 {
+			args = append(args, "-frag_duration", strconv.Itoa(c.FragmentDurationMs*1000))
+		}
+	}
+	if len(movflags) > 0 {
+		args = append(args, "-movflags", strings.Join(movflags, ""))
+	}
+
+	switch c.Format {
+	case "hls":
+		if c.SegmentDurationSec > 0 {
+			args = append(args, "-hls_time", strconv.Itoa(c.SegmentDurationSec))
+		}
+		if c.SegmentPattern != "" {
+			args = append(args, "-hls_segment_filename", siblingPath(outputPath, c.SegmentPattern))
+		}
+	case "segment":
+		if c.SegmentDurationSec > 0 {
+			args = append(args, "-segment_time", strconv.Itoa(c.SegmentDurationSec))
+		}
+	}
+	return args
+}
+
+// scaleFilter builds a scale filter that keeps aspect ratio when only
+// one dimension is given.
+func scaleFilter(width, height int) string {
+	w, h := "-2", "-2"
+	if width > 0 {
+		w = strconv.Itoa(width)
+	}
+	if height > 0 {
+		h = strconv.Itoa(height)
+	}
+	return fmt.Sprintf("scale=%s:%s", w, h)
+}
+
+// siblingPath places pattern next to the main output file.
+func siblingPath(outputPath, pattern string) string {
+	if i := strings.LastIndexByte(outputPath, '/'); i >= 0 {
+		return outputPath[:i+1] + pattern
+	}
+	return pattern
+}
+
+// ValidateContainer rejects option combinations the muxers can't honour,
+// so bad profiles fail at creation time rather than mid-encode.
+func ValidateContainer(c *types.ContainerOptions) error {
+	if c.Faststart && c.Fragmented {
+		return fmt.Errorf("faststart and fragmented output are mutually exclusive")
+	}
+	if c.SegmentPattern != "" && c.Format != "hls" && c.Format != "segment" {
+		return fmt.Errorf("segment_pattern requires format hls or segment, got %q", c.Format)
+	}
+	if c.FragmentDurationMs > 0 && !c.Fragmented {
+		return fmt.Errorf("fragment_duration_ms requires fragmented output")
+	}
+	if enc := c.Encryption; enc != nil {
+		if c.Format != "hls" {
+			return fmt.Errorf("encryption requires format hls, got %q", c.Format)
+		}
+		switch enc.Method {
+		case "", "aes-128":
+		case "sample-aes":
+			return fmt.Errorf("sample-aes requires an external DRM packager; the built-in ffmpeg path supports aes-128 only")
+		default:
+			return fmt.Errorf("unknown encryption method %q", enc.Method)
+		}
+		if enc.KeyRotationSegments < 0 {
+			return fmt.Errorf("key_rotation_segments must be non-negative")
+		}
+	}
+	return nil
+}
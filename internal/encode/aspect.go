@@ -0,0 +1,56 @@
+package encode
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// aspectFilter builds the -vf chain converting the source to the
+// profile's frame. detectedCrop, when non-empty, is a "w:h:x:y" window
+// from a cropdetect pre-pass applied before the aspect conversion.
+func aspectFilter(width, height int, mode string, detectedCrop string) string {
+	var chain string
+	if detectedCrop != "" {
+		chain = "crop=" + detectedCrop + ","
+	}
+	switch mode {
+	case "pad":
+		return chain + fmt.Sprintf(
+			"scale=%d:%d:force_original_aspect_ratio=decrease,pad=%d:%d:(ow-iw)/2:(oh-ih)/2",
+			width, height, width, height)
+	case "crop", "smart":
+		return chain + fmt.Sprintf(
+			"scale=%d:%d:force_original_aspect_ratio=increase,crop=%d:%d",
+			width, height, width, height)
+	default:
+		return chain + scaleFilter(width, height)
+	}
+}
+
+// CropDetectArgs builds the arguments for a cropdetect pre-pass: a short
+// null-muxed sample whose stderr carries the detected crop windows.
+func CropDetectArgs(sourceURL string, sampleSec int) []string {
+	if sampleSec <= 0 {
+		sampleSec = 10
+	}
+	return []string{
+		"-hide_banner",
+		"-i", sourceURL,
+		"-t", strconv.Itoa(sampleSec),
+		"-vf", "cropdetect=round=2",
+		"-f", "null", "-",
+	}
+}
+
+var cropDetectRe = regexp.MustCompile(`crop=(\d+:\d+:\d+:\d+)`)
+
+// ParseCropDetect extracts the last crop window ("w:h:x:y") cropdetect
+// printed; empty when the pass found none.
+func ParseCropDetect(stderr string) string {
+	matches := cropDetectRe.FindAllStringSubmatch(stderr, -1)
+	if len(matches) == 0 {
+		return ""
+	}
+	return matches[len(matches)-1][1]
+}
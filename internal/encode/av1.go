@@ -0,0 +1,88 @@
+package encode
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// av1Args translates the SVT-AV1 options into a -svtav1-params string.
+func av1Args(p *types.Profile) []string {
+	a := p.AV1
+	if a == nil {
+		return nil
+	}
+	var params []string
+	if a.FilmGrain > 0 {
+		params = append(params, "film-grain="+strconv.Itoa(a.FilmGrain))
+		if a.FilmGrainDenoise {
+			params = append(params, "film-grain-denoise=1")
+		}
+	}
+	if a.TileRows > 0 {
+		params = append(params, "tile-rows="+strconv.Itoa(a.TileRows))
+	}
+	if a.TileColumns > 0 {
+		params = append(params, "tile-columns="+strconv.Itoa(a.TileColumns))
+	}
+	if len(params) == 0 {
+		return nil
+	}
+	return []string{"-svtav1-params", strings.Join(params, ":")}
+}
+
+// ValidateCodecOptions rejects codec-specific option combinations the
+// encoders can't honour, so bad profiles fail at creation time rather
+// than mid-encode.
+func ValidateCodecOptions(p *types.Profile) error {
+	switch p.VideoCodec {
+	case "libsvtav1":
+		// SVT-AV1 presets are numeric, 0 (slowest) .. 13 (fastest).
+		if p.Preset != "" {
+			n, err := strconv.Atoi(p.Preset)
+			if err != nil || n < 0 || n > 13 {
+				return fmt.Errorf("libsvtav1 preset must be 0..13, got %q", p.Preset)
+			}
+		}
+	case "libvvenc":
+		// vvenc presets are named speed tiers.
+		switch p.Preset {
+		case "", "faster", "fast", "medium", "slow", "slower":
+		default:
+			return fmt.Errorf("libvvenc preset must be faster..slower, got %q", p.Preset)
+		}
+	}
+	if a := p.AV1; a != nil {
+		if p.VideoCodec != "libsvtav1" {
+			return fmt.Errorf("av1 options require video_codec libsvtav1, got %q", p.VideoCodec)
+		}
+		if a.FilmGrain < 0 || a.FilmGrain > 50 {
+			return fmt.Errorf("av1 film_grain must be 0..50, got %d", a.FilmGrain)
+		}
+		if a.FilmGrainDenoise && a.FilmGrain == 0 {
+			return fmt.Errorf("av1 film_grain_denoise requires film_grain")
+		}
+		if a.TileRows < 0 || a.TileRows > 6 || a.TileColumns < 0 || a.TileColumns > 6 {
+			return fmt.Errorf("av1 tile_rows and tile_columns must be 0..6")
+		}
+	}
+	return nil
+}
+
+// DetectEncoders reports which of the named encoders the local ffmpeg
+// build supports, by scanning `ffmpeg -encoders`. VVC/x266 in
+// particular is still missing from many distro builds.
+func DetectEncoders(names ...string) map[string]bool {
+	out := make(map[string]bool, len(names))
+	listing, err := exec.Command("ffmpeg", "-hide_banner", "-encoders").Output()
+	if err != nil {
+		return out
+	}
+	for _, name := range names {
+		out[name] = strings.Contains(string(listing), " "+name+" ")
+	}
+	return out
+}
@@ -0,0 +1,132 @@
+package encode
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// filterChain maps the profile's filter options to ffmpeg filter
+// stages, in the documented order. The watermark overlay is not part of
+// the chain; it needs a second source and is grafted on by
+// watermarkGraph.
+func filterChain(f *types.FilterOptions) []string {
+	var filters []string
+	if f.Deinterlace != "" {
+		filters = append(filters, f.Deinterlace)
+	}
+	if f.Crop != "" {
+		filters = append(filters, "crop="+f.Crop)
+	}
+	if f.Pad != "" {
+		filters = append(filters, "pad="+f.Pad)
+	}
+	switch f.Rotate {
+	case 90:
+		filters = append(filters, "transpose=1")
+	case 180:
+		filters = append(filters, "hflip", "vflip")
+	case 270:
+		filters = append(filters, "transpose=2")
+	}
+	if f.Denoise != "" {
+		filters = append(filters, denoiseFilter(f.Denoise))
+	}
+	if f.FadeInSec > 0 {
+		filters = append(filters, fmt.Sprintf("fade=t=in:st=0:d=%g", f.FadeInSec))
+	}
+	if f.FadeOutSec > 0 {
+		filters = append(filters, fmt.Sprintf("fade=t=out:st=%g:d=%g", f.FadeOutStartSec, f.FadeOutSec))
+	}
+	return filters
+}
+
+// denoiseFilter maps the named strength to hqdn3d parameters.
+func denoiseFilter(strength string) string {
+	switch strength {
+	case "light":
+		return "hqdn3d=2:1.5:3:2.25"
+	case "heavy":
+		return "hqdn3d=8:6:12:9"
+	}
+	return "hqdn3d"
+}
+
+// watermarkGraph wraps the linear filter chain into a labeled graph that
+// pulls the overlay image in with the movie source filter, so a plain
+// -vf argument still works and no extra -i input is needed.
+func watermarkGraph(chain string, wm *types.WatermarkOptions) string {
+	overlay := "movie=" + wm.Image + ",format=rgba"
+	if wm.Opacity > 0 && wm.Opacity < 1 {
+		overlay += fmt.Sprintf(",colorchannelmixer=aa=%g", wm.Opacity)
+	}
+	pos := overlayPosition(wm.Position)
+	if chain == "" {
+		return fmt.Sprintf("%s[wm];[in][wm]overlay=%s", overlay, pos)
+	}
+	return fmt.Sprintf("%s[wm];[in]%s[base];[base][wm]overlay=%s", overlay, chain, pos)
+}
+
+// overlayPosition translates the anchor name to overlay coordinates,
+// with a 10-pixel margin off the edges.
+func overlayPosition(position string) string {
+	switch position {
+	case "top-left":
+		return "10:10"
+	case "top-right":
+		return "W-w-10:10"
+	case "bottom-left":
+		return "10:H-h-10"
+	case "center":
+		return "(W-w)/2:(H-h)/2"
+	}
+	return "W-w-10:H-h-10"
+}
+
+// ValidateFilters rejects filter settings the graph builder can't map.
+func ValidateFilters(f *types.FilterOptions) error {
+	switch f.Deinterlace {
+	case "", "yadif", "bwdif":
+	default:
+		return fmt.Errorf("unknown deinterlace filter %q", f.Deinterlace)
+	}
+	switch f.Rotate {
+	case 0, 90, 180, 270:
+	default:
+		return fmt.Errorf("rotate must be 90, 180 or 270, got %d", f.Rotate)
+	}
+	switch f.Denoise {
+	case "", "light", "medium", "heavy":
+	default:
+		return fmt.Errorf("unknown denoise strength %q", f.Denoise)
+	}
+	if f.Crop != "" && strings.Count(f.Crop, ":") != 3 {
+		return fmt.Errorf("crop must be \"w:h:x:y\", got %q", f.Crop)
+	}
+	if f.Pad != "" {
+		if n := strings.Count(f.Pad, ":"); n < 3 || n > 4 {
+			return fmt.Errorf("pad must be \"w:h:x:y[:color]\", got %q", f.Pad)
+		}
+	}
+	if f.FadeInSec < 0 || f.FadeOutSec < 0 || f.FadeOutStartSec < 0 {
+		return fmt.Errorf("fade durations must be non-negative")
+	}
+	if f.FadeOutSec > 0 && f.FadeOutStartSec == 0 {
+		return fmt.Errorf("fade_out_sec requires fade_out_start_sec")
+	}
+	if wm := f.Watermark; wm != nil {
+		if wm.Image == "" {
+			return fmt.Errorf("watermark requires an image")
+		}
+		switch wm.Position {
+		case "", "top-left", "top-right", "bottom-left", "bottom-right", "center":
+		default:
+			return fmt.Errorf("unknown watermark position %q", wm.Position)
+		}
+		if wm.Opacity < 0 || wm.Opacity > 1 {
+			return fmt.Errorf("watermark opacity must be between 0 and 1")
+		}
+	}
+	return nil
+}
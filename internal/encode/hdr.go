@@ -0,0 +1,76 @@
+package encode
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// tonemapFilter converts HDR input to SDR BT.709: linearize, tone-map
+// with hable, and re-quantize to TV-range 8-bit.
+const tonemapFilter = "zscale=t=linear:npl=100,format=gbrpf32le,zscale=p=bt709,tonemap=tonemap=hable:desat=0,zscale=t=bt709:m=bt709:r=tv,format=yuv420p"
+
+// hdrFilter returns the filter stage a profile's HDR mode needs, or "".
+func hdrFilter(p *types.Profile) string {
+	if p.HDRMode == types.HDRModeTonemap {
+		return tonemapFilter
+	}
+	return ""
+}
+
+// hdrArgs returns the codec and color-signaling flags for the profile's
+// HDR mode. The explicit color properties matter beyond the bitstream:
+// the HLS/DASH muxers derive manifest transfer tags (VIDEO-RANGE=PQ/
+// HLG) from them.
+func hdrArgs(p *types.Profile) []string {
+	switch p.HDRMode {
+	case types.HDRModePassthrough:
+		trc := "smpte2084"
+		if strings.EqualFold(p.HDRTransfer, "hlg") {
+			trc = "arib-std-b67"
+		}
+		args := []string{
+			"-pix_fmt", "yuv420p10le",
+			"-color_primaries", "bt2020",
+			"-color_trc", trc,
+			"-colorspace", "bt2020nc",
+		}
+		if strings.Contains(p.VideoCodec, "x265") {
+			// hdr-opt keeps mastering-display and CLL SEI from the
+			// source; repeat-headers re-emits them on every keyframe so
+			// segmented outputs stay self-describing.
+			args = append(args, "-x265-params", "hdr-opt=1:repeat-headers=1")
+		}
+		return args
+	case types.HDRModeTonemap:
+		return []string{
+			"-color_primaries", "bt709",
+			"-color_trc", "bt709",
+			"-colorspace", "bt709",
+		}
+	}
+	return nil
+}
+
+// ValidateHDR rejects HDR settings the pipeline can't honour.
+func ValidateHDR(p *types.Profile) error {
+	switch p.HDRMode {
+	case types.HDRModeNone, types.HDRModeTonemap:
+	case types.HDRModePassthrough:
+		if p.VideoCodec != "" && !strings.Contains(p.VideoCodec, "x265") &&
+			!strings.Contains(p.VideoCodec, "hevc") &&
+			!strings.Contains(p.VideoCodec, "av1") &&
+			!strings.Contains(p.VideoCodec, "vp9") {
+			return fmt.Errorf("hdr_mode passthrough requires a 10-bit capable codec, got %q", p.VideoCodec)
+		}
+	default:
+		return fmt.Errorf("unknown hdr_mode %q", p.HDRMode)
+	}
+	switch strings.ToLower(p.HDRTransfer) {
+	case "", "pq", "hlg":
+	default:
+		return fmt.Errorf("unknown hdr_transfer %q", p.HDRTransfer)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package encode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// Loudnorm defaults beyond the integrated target: the true-peak ceiling
+// and loudness range EBU R128 streaming delivery commonly uses.
+const (
+	loudnormTruePeak = -1.5
+	loudnormLRA      = 11
+)
+
+// LoudnormMeasureArgs builds the first loudnorm pass: measure only,
+// decode to null output. The filter prints its measurement as JSON on
+// stderr, which ParseLoudnormMeasurement reads back.
+func LoudnormMeasureArgs(input string, targetLUFS float64) []string {
+	return []string{
+		"-hide_banner", "-nostats",
+		"-i", input,
+		"-af", fmt.Sprintf("loudnorm=I=%g:TP=%g:LRA=%d:print_format=json", targetLUFS, loudnormTruePeak, loudnormLRA),
+		"-f", "null", "-",
+	}
+}
+
+// LoudnormMeasurement is the JSON block the loudnorm filter prints at
+// the end of the measurement pass. ffmpeg emits the numbers as strings.
+type LoudnormMeasurement struct {
+	InputI       string `json:"input_i"`
+	InputTP      string `json:"input_tp"`
+	InputLRA     string `json:"input_lra"`
+	InputThresh  string `json:"input_thresh"`
+	TargetOffset string `json:"target_offset"`
+}
+
+// ParseLoudnormMeasurement extracts the loudnorm JSON from the
+// measurement pass's stderr.
+func ParseLoudnormMeasurement(stderr string) (*LoudnormMeasurement, error) {
+	start := strings.LastIndex(stderr, "{")
+	end := strings.LastIndex(stderr, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no loudnorm measurement in ffmpeg output")
+	}
+	var m LoudnormMeasurement
+	if err := json.Unmarshal([]byte(stderr[start:end+1]), &m); err != nil {
+		return nil, fmt.Errorf("parse loudnorm measurement: %w", err)
+	}
+	return &m, nil
+}
+
+// LoudnormFilter builds the second-pass correction filter from the
+// measured values; linear mode keeps the gain constant across the file.
+func LoudnormFilter(targetLUFS float64, m *LoudnormMeasurement) string {
+	return fmt.Sprintf(
+		"loudnorm=I=%g:TP=%g:LRA=%d:measured_I=%s:measured_TP=%s:measured_LRA=%s:measured_thresh=%s:offset=%s:linear=true",
+		targetLUFS, loudnormTruePeak, loudnormLRA,
+		m.InputI, m.InputTP, m.InputLRA, m.InputThresh, m.TargetOffset,
+	)
+}
+
+// Report converts the measurement to the loudness metadata attached to
+// normalized renditions.
+func (m *LoudnormMeasurement) Report(targetLUFS float64) *types.LoudnessReport {
+	return &types.LoudnessReport{
+		TargetLUFS:      targetLUFS,
+		InputLUFS:       parseLoudnormNumber(m.InputI),
+		InputTruePeakDB: parseLoudnormNumber(m.InputTP),
+		InputLRA:        parseLoudnormNumber(m.InputLRA),
+	}
+}
+
+func parseLoudnormNumber(s string) float64 {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return f
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type startLadderRequest struct {
+	ProfileIDs []string `json:"profile_ids"`
+}
+
+func (s *Server) startLadder(w http.ResponseWriter, r *http.Request) {
+	if s.streamForRequest(w, r, "streams", "update") == nil {
+		return
+	}
+	var req startLadderRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	renditions, err := s.ABR.StartLadder(chi.URLParam(r, "streamID"), req.ProfileIDs)
+	if err != nil {
+		respondError(w, http.StatusConflict, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, renditions)
+}
+
+func (s *Server) stopLadder(w http.ResponseWriter, r *http.Request) {
+	if s.streamForRequest(w, r, "streams", "update") == nil {
+		return
+	}
+	if err := s.ABR.StopLadder(chi.URLParam(r, "streamID")); err != nil {
+		respondError(w, http.StatusConflict, err)
+		return
+	}
+	respondJSON(w, http.StatusNoContent, nil)
+}
+
+func (s *Server) getLadder(w http.ResponseWriter, r *http.Request) {
+	if s.streamForRequest(w, r, "streams", "read") == nil {
+		return
+	}
+	respondJSON(w, http.StatusOK, s.ABR.Ladder(chi.URLParam(r, "streamID")))
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/rennerdo30/webencode/internal/kernel/auth"
+)
+
+const apiKeyContextKey contextKey = "webencode.apikey"
+
+// apiKeyMiddleware authenticates requests presenting an API key
+// (Authorization: Bearer wek_... or X-API-Key), independently of the
+// auth plugin. The key's user and scopes govern the request.
+func (s *Server) apiKeyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.Keys == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		secret := r.Header.Get("X-API-Key")
+		if secret == "" {
+			if bearer := r.Header.Get("Authorization"); strings.HasPrefix(bearer, "Bearer wek_") {
+				secret = strings.TrimPrefix(bearer, "Bearer ")
+			}
+		}
+		if secret == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		key, err := s.Keys.Validate(secret)
+		if err != nil {
+			respondError(w, http.StatusUnauthorized, err)
+			return
+		}
+		ctx := ContextWithUser(r.Context(), key.User())
+		ctx = context.WithValue(ctx, apiKeyContextKey, key)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// apiKeyFromContext returns the API key a request authenticated with,
+// or nil.
+func apiKeyFromContext(ctx context.Context) *auth.APIKey {
+	k, _ := ctx.Value(apiKeyContextKey).(*auth.APIKey)
+	return k
+}
+
+type createAPIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes,omitempty"`
+	// TTL like "720h"; empty keys never expire.
+	TTL string `json:"ttl,omitempty"`
+}
+
+type createAPIKeyResponse struct {
+	*auth.APIKey
+	// Secret is shown exactly once.
+	Secret string `json:"secret"`
+}
+
+func (s *Server) createAPIKey(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "apikeys", "create", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	var req createAPIKeyRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Name == "" {
+		respondError(w, http.StatusBadRequest, errors.New("name is required"))
+		return
+	}
+	var ttl time.Duration
+	if req.TTL != "" {
+		parsed, err := time.ParseDuration(req.TTL)
+		if err != nil || parsed <= 0 {
+			respondError(w, http.StatusBadRequest, errors.New("bad ttl: want a positive duration like \"720h\""))
+			return
+		}
+		ttl = parsed
+	}
+	secret, key, err := s.Keys.Issue(s.user(r), req.Name, req.Scopes, ttl)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, createAPIKeyResponse{APIKey: key, Secret: secret})
+}
+
+func (s *Server) listAPIKeys(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "apikeys", "list", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, s.Keys.ListForUser(s.scope(r)))
+}
+
+func (s *Server) deleteAPIKey(w http.ResponseWriter, r *http.Request) {
+	key, err := s.Keys.Get(chi.URLParam(r, "keyID"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	if err := s.authorize(r, "apikeys", "delete", ownerLabels(key.UserID)); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if err := s.Keys.Revoke(key.ID); err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	respondJSON(w, http.StatusNoContent, nil)
+}
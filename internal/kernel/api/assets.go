@@ -0,0 +1,96 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/rennerdo30/webencode/internal/kernel/assets"
+)
+
+// assetsCatalog guards handlers that need the catalog configured.
+func (s *Server) assetsCatalog(w http.ResponseWriter) (ok bool) {
+	if s.Assets == nil {
+		respondError(w, http.StatusConflict, errors.New("asset catalog is not configured"))
+		return false
+	}
+	return true
+}
+
+// listAssets searches the catalog. All filters are optional and
+// combine; non-admins only see their own assets.
+func (s *Server) listAssets(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "assets", "list", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if !s.assetsCatalog(w) {
+		return
+	}
+	q := r.URL.Query()
+	filter := assets.Filter{
+		OwnerID: s.scope(r),
+		Query:   q.Get("query"),
+		Kind:    assets.Kind(q.Get("kind")),
+		Codec:   q.Get("codec"),
+		Tag:     q.Get("tag"),
+	}
+	if v := q.Get("min_duration"); v != "" {
+		d, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, errors.New("min_duration must be a number of seconds"))
+			return
+		}
+		filter.MinDurationSec = d
+	}
+	respondJSON(w, http.StatusOK, s.Assets.Search(filter))
+}
+
+func (s *Server) getAsset(w http.ResponseWriter, r *http.Request) {
+	if !s.assetsCatalog(w) {
+		return
+	}
+	a, err := s.Assets.Get(chi.URLParam(r, "assetID"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	if err := s.authorize(r, "assets", "read", ownerLabels(a.OwnerID)); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, a)
+}
+
+type setAssetTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+// setAssetTags replaces an asset's tag list.
+func (s *Server) setAssetTags(w http.ResponseWriter, r *http.Request) {
+	if !s.assetsCatalog(w) {
+		return
+	}
+	a, err := s.Assets.Get(chi.URLParam(r, "assetID"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	if err := s.authorize(r, "assets", "update", ownerLabels(a.OwnerID)); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	var req setAssetTagsRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	updated, err := s.Assets.SetTags(a.ID, req.Tags)
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, updated)
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/rennerdo30/webencode/internal/kernel/audit"
+)
+
+// auditBodyLimit caps how much of a request body lands in the audit
+// log.
+const auditBodyLimit = 64 << 10
+
+// auditMiddleware records every mutating call: actor, resource, source
+// IP, outcome and the (redacted) request body.
+func (s *Server) auditMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.Audit == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+		var body map[string]interface{}
+		if r.Body != nil {
+			data, err := io.ReadAll(io.LimitReader(r.Body, auditBodyLimit))
+			if err == nil {
+				r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(data), r.Body))
+				if json.Unmarshal(data, &body) != nil {
+					body = nil
+				}
+			}
+		}
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+		s.Audit.Append(audit.Entry{
+			Actor:    s.user(r).ID,
+			Method:   r.Method,
+			Path:     r.URL.Path,
+			Resource: auditResource(r.URL.Path),
+			RemoteIP: r.RemoteAddr,
+			Status:   ww.Status(),
+			Request:  audit.Redact(body),
+		})
+	})
+}
+
+// auditResource extracts the API resource segment from a request path.
+func auditResource(path string) string {
+	rest := strings.TrimPrefix(path, "/v1/")
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		rest = rest[:i]
+	}
+	return rest
+}
+
+// auditFilter parses the shared time/user/resource query filters.
+func auditFilter(r *http.Request) (audit.Filter, error) {
+	var f audit.Filter
+	if v := r.URL.Query().Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return f, errors.New("since must be RFC 3339")
+		}
+		f.Since = t
+	}
+	if v := r.URL.Query().Get("until"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return f, errors.New("until must be RFC 3339")
+		}
+		f.Until = t
+	}
+	f.Actor = r.URL.Query().Get("actor")
+	f.Resource = r.URL.Query().Get("resource")
+	return f, nil
+}
+
+func (s *Server) listAuditEntries(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "audit", "list", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if s.Audit == nil {
+		respondError(w, http.StatusConflict, errors.New("audit logging is not configured"))
+		return
+	}
+	f, err := auditFilter(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	// Non-admin callers only see their own activity, whatever actor
+	// filter they asked for.
+	if scope := s.scope(r); scope != "" {
+		f.Actor = scope
+	}
+	q, err := parseListQuery(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	entries := s.Audit.List(f)
+	if !q.paged() {
+		respondJSON(w, http.StatusOK, entries)
+		return
+	}
+	respondJSON(w, http.StatusOK, paginate(entries, func(e audit.Entry) string { return e.ID }, q))
+}
+
+// exportAudit streams matching audit entries as JSONL (default) or CSV
+// for compliance archives.
+func (s *Server) exportAudit(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "audit", "list", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if s.Audit == nil {
+		respondError(w, http.StatusConflict, errors.New("audit logging is not configured"))
+		return
+	}
+	f, err := auditFilter(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	if scope := s.scope(r); scope != "" {
+		f.Actor = scope
+	}
+	entries := s.Audit.List(f)
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "jsonl":
+		w.Header().Set("Content-Type", "application/jsonl")
+		enc := json.NewEncoder(w)
+		for _, e := range entries {
+			_ = enc.Encode(e)
+		}
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		cw := csv.NewWriter(w)
+		_ = cw.Write([]string{"at", "actor", "method", "path", "resource", "remote_ip", "status"})
+		for _, e := range entries {
+			_ = cw.Write([]string{
+				e.At.Format(time.RFC3339),
+				e.Actor,
+				e.Method,
+				e.Path,
+				e.Resource,
+				e.RemoteIP,
+				strconv.Itoa(e.Status),
+			})
+		}
+		cw.Flush()
+	default:
+		respondError(w, http.StatusBadRequest, errors.New("format must be jsonl or csv"))
+	}
+}
@@ -0,0 +1,19 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/rennerdo30/webencode/internal/kernel/orchestrator"
+)
+
+// getAutoscale serves the queue snapshot external autoscalers size the
+// worker deployment on. KEDA's metrics-api scaler reads a value out of
+// the JSON body by path (e.g. "pending_encode_minutes"); the same
+// figures are exposed as gauges on /metrics for the Prometheus scaler.
+func (s *Server) getAutoscale(w http.ResponseWriter, r *http.Request) {
+	if s.Orchestrator == nil {
+		respondJSON(w, http.StatusOK, orchestrator.AutoscaleStatus{})
+		return
+	}
+	respondJSON(w, http.StatusOK, s.Orchestrator.Autoscale())
+}
@@ -0,0 +1,259 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/rennerdo30/webencode/internal/kernel/jobs"
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+type createJobBatchRequest struct {
+	// Sources lists explicit source URLs, one job each.
+	Sources []string `json:"sources,omitempty"`
+	// Prefix selects storage objects to encode instead of explicit
+	// sources; Glob optionally filters them by base name (e.g. "*.mov").
+	Prefix       string   `json:"prefix,omitempty"`
+	Glob         string   `json:"glob,omitempty"`
+	ProfileIDs   []string `json:"profile_ids"`
+	OutputPrefix string   `json:"output_prefix,omitempty"`
+	// TitlePrefix is prepended to each job's derived title.
+	TitlePrefix string `json:"title_prefix,omitempty"`
+}
+
+// batchSource is one resolved input: the URL workers fetch plus the
+// title and metadata it was derived from.
+type batchSource struct {
+	url   string
+	title string
+	key   string
+}
+
+// createJobBatch submits one job per source as a single batch. All
+// sources and profiles are resolved and validated before the first job
+// is created, so a bad entry rejects the whole request instead of
+// leaving a partial batch behind.
+func (s *Server) createJobBatch(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "jobs", "create", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	var req createJobBatchRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	if len(req.Sources) == 0 && req.Prefix == "" {
+		respondError(w, http.StatusBadRequest, errors.New("sources or prefix is required"))
+		return
+	}
+	if len(req.Sources) > 0 && req.Prefix != "" {
+		respondError(w, http.StatusBadRequest, errors.New("sources and prefix are mutually exclusive"))
+		return
+	}
+	for _, id := range req.ProfileIDs {
+		if _, err := s.Profiles.Get(id); err != nil {
+			respondError(w, http.StatusBadRequest, errors.New("unknown profile "+id))
+			return
+		}
+	}
+
+	var sources []batchSource
+	if len(req.Sources) > 0 {
+		for _, url := range req.Sources {
+			if url == "" {
+				respondError(w, http.StatusBadRequest, errors.New("sources entries must be non-empty"))
+				return
+			}
+			if err := s.checkSourceURLs(s.user(r).ID, url); err != nil {
+				respondError(w, http.StatusBadRequest, err)
+				return
+			}
+			sources = append(sources, batchSource{url: url, title: path.Base(url)})
+		}
+	} else {
+		resolved, err := s.resolveBatchPrefix(req.Prefix, req.Glob)
+		if err != nil {
+			respondError(w, http.StatusBadGateway, err)
+			return
+		}
+		sources = resolved
+	}
+	if len(sources) == 0 {
+		respondError(w, http.StatusBadRequest, errors.New("no sources matched"))
+		return
+	}
+
+	jobIDs := make([]string, 0, len(sources))
+	submitted := make([]*types.Job, 0, len(sources))
+	for _, src := range sources {
+		job := &types.Job{
+			UserID:       s.user(r).ID,
+			Title:        req.TitlePrefix + src.title,
+			SourceURL:    src.url,
+			OutputPrefix: req.OutputPrefix,
+			ProfileIDs:   req.ProfileIDs,
+		}
+		if src.key != "" {
+			job.Metadata = map[string]interface{}{"source_key": src.key}
+		}
+		s.Jobs.Submit(job)
+		jobIDs = append(jobIDs, job.ID)
+		submitted = append(submitted, job)
+	}
+	batch := s.Batches.Create(s.user(r).ID, jobIDs)
+	for _, job := range submitted {
+		if err := s.dispatchBatchJob(job); err != nil {
+			s.Jobs.SetStatus(job.ID, types.JobStatusFailed, err.Error())
+		}
+	}
+
+	progress, err := s.Batches.Progress(batch.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, progress)
+}
+
+// resolveBatchPrefix expands a storage prefix (and optional glob on the
+// object base name) into presigned batch sources.
+func (s *Server) resolveBatchPrefix(prefix, glob string) ([]batchSource, error) {
+	if s.Storage == nil {
+		return nil, errors.New("no storage plugin configured")
+	}
+	storage, err := s.Storage()
+	if err != nil {
+		return nil, err
+	}
+	objects, err := storage.List(strings.TrimPrefix(prefix, "/"))
+	if err != nil {
+		return nil, err
+	}
+	var sources []batchSource
+	for _, obj := range objects {
+		base := path.Base(obj.Key)
+		if glob != "" {
+			ok, err := path.Match(glob, base)
+			if err != nil {
+				return nil, errors.New("invalid glob: " + err.Error())
+			}
+			if !ok {
+				continue
+			}
+		}
+		// Presign so workers fetch sources without storage credentials.
+		url, err := storage.PresignGet(obj.Key, 24*time.Hour)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, batchSource{url: url, title: base, key: obj.Key})
+	}
+	return sources, nil
+}
+
+// dispatchBatchJob hands one batch member to the orchestrator without
+// writing a response; batch handlers respond with the whole batch.
+func (s *Server) dispatchBatchJob(job *types.Job) error {
+	if s.Orchestrator == nil {
+		return nil
+	}
+	jobProfiles := make([]*types.Profile, 0, len(job.ProfileIDs))
+	for _, id := range job.ProfileIDs {
+		p, err := s.Profiles.Get(id)
+		if err != nil {
+			return err
+		}
+		jobProfiles = append(jobProfiles, p)
+	}
+	return s.Orchestrator.SubmitJob(job, jobProfiles)
+}
+
+// batchForRequest loads the batch addressed by the URL and authorizes
+// the action against its owner.
+func (s *Server) batchForRequest(w http.ResponseWriter, r *http.Request, action string) *jobs.Batch {
+	batch, err := s.Batches.Get(chi.URLParam(r, "batchID"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return nil
+	}
+	if err := s.authorize(r, "jobs", action, ownerLabels(batch.UserID)); err != nil {
+		respondAuthError(w, err)
+		return nil
+	}
+	return batch
+}
+
+func (s *Server) getJobBatch(w http.ResponseWriter, r *http.Request) {
+	batch := s.batchForRequest(w, r, "read")
+	if batch == nil {
+		return
+	}
+	progress, err := s.Batches.Progress(batch.ID)
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, progress)
+}
+
+// cancelJobBatch cancels every member job that has not yet reached a
+// terminal state.
+func (s *Server) cancelJobBatch(w http.ResponseWriter, r *http.Request) {
+	batch := s.batchForRequest(w, r, "update")
+	if batch == nil {
+		return
+	}
+	for _, jobID := range batch.JobIDs {
+		job, err := s.Jobs.Get(jobID)
+		if err != nil {
+			continue
+		}
+		if job.Status == types.JobStatusQueued || job.Status == types.JobStatusRunning {
+			s.Jobs.SetStatus(jobID, types.JobStatusCancelled, "")
+		}
+	}
+	progress, err := s.Batches.Progress(batch.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, progress)
+}
+
+// retryJobBatch requeues and re-dispatches every failed or cancelled
+// member job.
+func (s *Server) retryJobBatch(w http.ResponseWriter, r *http.Request) {
+	batch := s.batchForRequest(w, r, "update")
+	if batch == nil {
+		return
+	}
+	for _, jobID := range batch.JobIDs {
+		job, err := s.Jobs.Get(jobID)
+		if err != nil {
+			continue
+		}
+		if job.Status != types.JobStatusFailed && job.Status != types.JobStatusCancelled {
+			continue
+		}
+		if err := s.Jobs.SetStatus(jobID, types.JobStatusQueued, ""); err != nil {
+			continue
+		}
+		job.Status = types.JobStatusQueued
+		job.Error = ""
+		if err := s.dispatchBatchJob(job); err != nil {
+			s.Jobs.SetStatus(jobID, types.JobStatusFailed, err.Error())
+		}
+	}
+	progress, err := s.Batches.Progress(batch.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, progress)
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// bulkJobFilter selects the jobs a bulk action applies to. All set
+// fields must match.
+type bulkJobFilter struct {
+	Status string `json:"status,omitempty"`
+	User   string `json:"user,omitempty"`
+	// OlderThan is a Go duration (e.g. "720h"); only jobs created
+	// before now-OlderThan match.
+	OlderThan string `json:"older_than,omitempty"`
+}
+
+type bulkJobRequest struct {
+	// Action is "cancel", "retry" or "delete".
+	Action string        `json:"action"`
+	Filter bulkJobFilter `json:"filter"`
+}
+
+// bulkOperation is the progress-reporting resource behind one bulk
+// action; POST /v1/jobs/bulk returns it and GET /v1/jobs/bulk/{opID}
+// polls it while the action runs in the background.
+type bulkOperation struct {
+	ID     string `json:"id"`
+	Action string `json:"action"`
+	// Status is "running" until every matched job has been processed.
+	Status  string `json:"status"`
+	Matched int    `json:"matched"`
+	// Processed counts jobs the action succeeded on; Skipped counts
+	// matches the action did not apply to (e.g. deleting a running job).
+	Processed  int       `json:"processed"`
+	Skipped    int       `json:"skipped"`
+	CreatedAt  time.Time `json:"created_at"`
+	FinishedAt time.Time `json:"finished_at,omitempty"`
+}
+
+// bulkJobs matches jobs by filter and applies the action to each in the
+// background, responding immediately with the operation resource.
+func (s *Server) bulkJobs(w http.ResponseWriter, r *http.Request) {
+	var req bulkJobRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	action := "update"
+	if req.Action == "delete" {
+		action = "delete"
+	}
+	// The selection below is scoped to the caller's own jobs, so the
+	// request authorizes against the caller as owner.
+	if err := s.authorize(r, "jobs", action, ownerLabels(s.user(r).ID)); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	switch req.Action {
+	case "cancel", "retry", "delete":
+	default:
+		respondError(w, http.StatusBadRequest, fmt.Errorf("unknown action %q (want cancel, retry or delete)", req.Action))
+		return
+	}
+	var cutoff time.Time
+	if req.Filter.OlderThan != "" {
+		d, err := time.ParseDuration(req.Filter.OlderThan)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, fmt.Errorf("invalid older_than: %w", err))
+			return
+		}
+		cutoff = time.Now().UTC().Add(-d)
+	}
+	// Non-admin callers only ever operate on their own jobs.
+	owner := s.scope(r)
+	if owner == "" {
+		owner = req.Filter.User
+	}
+
+	var matched []string
+	for _, j := range s.Jobs.List(owner) {
+		if req.Filter.Status != "" && string(j.Status) != req.Filter.Status {
+			continue
+		}
+		if !cutoff.IsZero() && !j.CreatedAt.Before(cutoff) {
+			continue
+		}
+		matched = append(matched, j.ID)
+	}
+
+	op := &bulkOperation{
+		ID:        uuid.NewString(),
+		Action:    req.Action,
+		Status:    "running",
+		Matched:   len(matched),
+		CreatedAt: time.Now().UTC(),
+	}
+	s.bulkMu.Lock()
+	if s.bulkOps == nil {
+		s.bulkOps = make(map[string]*bulkOperation)
+	}
+	s.bulkOps[op.ID] = op
+	s.bulkMu.Unlock()
+
+	go s.runBulkOperation(op, req.Action, matched)
+	respondJSON(w, http.StatusAccepted, op)
+}
+
+// runBulkOperation applies one action to the matched jobs, updating
+// the operation's counters as it goes.
+func (s *Server) runBulkOperation(op *bulkOperation, action string, jobIDs []string) {
+	for _, id := range jobIDs {
+		ok := s.applyBulkAction(action, id)
+		s.bulkMu.Lock()
+		if ok {
+			op.Processed++
+		} else {
+			op.Skipped++
+		}
+		s.bulkMu.Unlock()
+	}
+	s.bulkMu.Lock()
+	op.Status = "completed"
+	op.FinishedAt = time.Now().UTC()
+	s.bulkMu.Unlock()
+}
+
+// applyBulkAction runs one action against one job, reporting whether it
+// applied.
+func (s *Server) applyBulkAction(action, jobID string) bool {
+	job, err := s.Jobs.Get(jobID)
+	if err != nil {
+		return false
+	}
+	switch action {
+	case "cancel":
+		if job.Status != types.JobStatusQueued && job.Status != types.JobStatusRunning {
+			return false
+		}
+		return s.Jobs.SetStatus(jobID, types.JobStatusCancelled, "") == nil
+	case "retry":
+		if job.Status != types.JobStatusFailed && job.Status != types.JobStatusCancelled {
+			return false
+		}
+		if err := s.Jobs.SetStatus(jobID, types.JobStatusQueued, ""); err != nil {
+			return false
+		}
+		job.Status = types.JobStatusQueued
+		job.Error = ""
+		if err := s.dispatchBatchJob(job); err != nil {
+			s.Jobs.SetStatus(jobID, types.JobStatusFailed, err.Error())
+			return false
+		}
+		return true
+	case "delete":
+		return s.Jobs.Delete(jobID) == nil
+	}
+	return false
+}
+
+// getBulkOperation reports the progress of one bulk operation.
+func (s *Server) getBulkOperation(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "jobs", "read", ownerLabels(s.user(r).ID)); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	s.bulkMu.Lock()
+	op, ok := s.bulkOps[chi.URLParam(r, "opID")]
+	var copied bulkOperation
+	if ok {
+		copied = *op
+	}
+	s.bulkMu.Unlock()
+	if !ok {
+		respondError(w, http.StatusNotFound, errors.New("bulk operation not found"))
+		return
+	}
+	respondJSON(w, http.StatusOK, &copied)
+}
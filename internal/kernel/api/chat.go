@@ -0,0 +1,92 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/rennerdo30/webencode/pkg/plugin"
+)
+
+type chatSettingsRequest struct {
+	SlowModeSec     *int  `json:"slow_mode_sec,omitempty"`
+	SubscribersOnly *bool `json:"subscribers_only,omitempty"`
+	EmoteOnly       *bool `json:"emote_only,omitempty"`
+	// Channels are the platform chats to push the settings to.
+	Channels []chatChannel `json:"channels,omitempty"`
+}
+
+type chatChannel struct {
+	// Plugin is the publisher plugin name, e.g. "twitch".
+	Plugin string `json:"plugin"`
+	// ChannelID is the platform channel/broadcaster identifier.
+	ChannelID string `json:"channel_id"`
+}
+
+type chatSettingsResponse struct {
+	Settings interface{} `json:"settings"`
+	// Results reports the outcome per pushed channel: "ok",
+	// "unsupported", or the error message.
+	Results map[string]string `json:"results,omitempty"`
+}
+
+// patchChatSettings merges chat controls into the stream's state and
+// pushes them to the named platform chats — one panel for slow mode and
+// friends during incidents.
+func (s *Server) patchChatSettings(w http.ResponseWriter, r *http.Request) {
+	stream := s.streamForRequest(w, r, "streams", "update")
+	if stream == nil {
+		return
+	}
+	var req chatSettingsRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.SlowModeSec != nil && *req.SlowModeSec < 0 {
+		respondError(w, http.StatusBadRequest, errors.New("slow_mode_sec must be >= 0"))
+		return
+	}
+	settings := plugin.ChatSettings{
+		SlowModeSec:     req.SlowModeSec,
+		SubscribersOnly: req.SubscribersOnly,
+		EmoteOnly:       req.EmoteOnly,
+	}
+	state, err := s.Streams.ApplyChatSettings(stream.ID, settings)
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+
+	results := make(map[string]string)
+	for _, ch := range req.Channels {
+		results[ch.Plugin] = s.pushChatSettings(ch, settings)
+	}
+	respondJSON(w, http.StatusOK, chatSettingsResponse{Settings: state, Results: results})
+}
+
+// pushChatSettings applies the settings to one platform chat and
+// reports the outcome.
+func (s *Server) pushChatSettings(ch chatChannel, settings plugin.ChatSettings) string {
+	if s.Publisher == nil {
+		return "no publisher plugins configured"
+	}
+	publisher, err := s.Publisher(ch.Plugin)
+	if err != nil {
+		return err.Error()
+	}
+	if err := publisher.SetChatSettings(ch.ChannelID, settings); err != nil {
+		if plugin.IsChatControlsUnsupported(err) {
+			return "unsupported"
+		}
+		return err.Error()
+	}
+	return "ok"
+}
+
+func (s *Server) getChatSettings(w http.ResponseWriter, r *http.Request) {
+	stream := s.streamForRequest(w, r, "streams", "read")
+	if stream == nil {
+		return
+	}
+	respondJSON(w, http.StatusOK, s.Streams.ChatSettings(stream.ID))
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/rennerdo30/webencode/internal/kernel/live"
+	"github.com/rennerdo30/webencode/pkg/plugin"
+)
+
+type createClipRequest struct {
+	Title    string  `json:"title,omitempty"`
+	StartSec float64 `json:"start_sec"`
+	EndSec   float64 `json:"end_sec"`
+	// ProfileIDs for the clip's transcode job.
+	ProfileIDs []string `json:"profile_ids,omitempty"`
+	// Publish, when set, pushes the raw cut to a platform right away —
+	// "clip that" workflows care about speed more than the final encode.
+	Publish *clipPublishRequest `json:"publish,omitempty"`
+}
+
+type clipPublishRequest struct {
+	// Plugin is the publisher plugin name, e.g. "youtube".
+	Plugin      string            `json:"plugin"`
+	Description string            `json:"description,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Visibility  plugin.Visibility `json:"visibility,omitempty"`
+}
+
+type clipResponse struct {
+	*live.Clip
+	// Published reports where the raw cut ended up when a publish target
+	// was requested.
+	Published *plugin.PublishResult `json:"published,omitempty"`
+}
+
+// createClip cuts a clip from the stream's live recording buffer and
+// submits it as a transcode job.
+func (s *Server) createClip(w http.ResponseWriter, r *http.Request) {
+	if s.streamForRequest(w, r, "streams", "update") == nil {
+		return
+	}
+	if s.Archiver == nil {
+		respondError(w, http.StatusConflict, errors.New("archiving is not configured"))
+		return
+	}
+	var req createClipRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	for _, id := range req.ProfileIDs {
+		if _, err := s.Profiles.Get(id); err != nil {
+			respondError(w, http.StatusBadRequest, errors.New("unknown profile "+id))
+			return
+		}
+	}
+	if req.Publish != nil && req.Publish.Plugin == "" {
+		respondError(w, http.StatusBadRequest, errors.New("publish.plugin is required"))
+		return
+	}
+
+	clip, err := s.Archiver.CutClip(chi.URLParam(r, "streamID"), req.Title, req.StartSec, req.EndSec, req.ProfileIDs)
+	if err != nil {
+		respondError(w, http.StatusConflict, err)
+		return
+	}
+
+	resp := clipResponse{Clip: clip}
+	if req.Publish != nil {
+		result, err := s.publishClip(clip, req.Publish)
+		if err != nil {
+			respondError(w, http.StatusBadGateway, err)
+			return
+		}
+		resp.Published = result
+	}
+	respondJSON(w, http.StatusCreated, resp)
+}
+
+// publishClip pushes the raw cut to the requested platform.
+func (s *Server) publishClip(clip *live.Clip, req *clipPublishRequest) (*plugin.PublishResult, error) {
+	if s.Publisher == nil {
+		return nil, errors.New("no publisher plugins configured")
+	}
+	publisher, err := s.Publisher(req.Plugin)
+	if err != nil {
+		return nil, err
+	}
+	fileURL := clip.Key
+	if s.Storage != nil {
+		if storage, err := s.Storage(); err == nil {
+			if url, err := storage.PresignGet(clip.Key, 24*time.Hour); err == nil {
+				fileURL = url
+			}
+		}
+	}
+	return publisher.Publish(plugin.PublishRequest{
+		FileURL:     fileURL,
+		Title:       clip.Title,
+		Description: req.Description,
+		Tags:        req.Tags,
+		Visibility:  req.Visibility,
+	})
+}
+
+func (s *Server) listClips(w http.ResponseWriter, r *http.Request) {
+	if s.streamForRequest(w, r, "streams", "read") == nil {
+		return
+	}
+	if s.Archiver == nil {
+		respondError(w, http.StatusConflict, errors.New("archiving is not configured"))
+		return
+	}
+	respondJSON(w, http.StatusOK, s.Archiver.Clips(chi.URLParam(r, "streamID")))
+}
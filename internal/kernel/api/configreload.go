@@ -0,0 +1,22 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+)
+
+// reloadConfig re-reads the config file and applies the safe-to-change
+// settings (rate limits) without a restart; the SIGHUP handler runs
+// the same path.
+func (s *Server) reloadConfig(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "system", "write", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if s.ReloadConfig == nil {
+		respondError(w, http.StatusConflict, errors.New("config reload is not configured"))
+		return
+	}
+	s.ReloadConfig()
+	respondJSON(w, http.StatusOK, map[string]string{"status": "reloaded"})
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/rennerdo30/webencode/internal/kernel/orchestrator"
+)
+
+// orchestratorRequired guards handlers that need the bus configured.
+func (s *Server) orchestratorRequired(w http.ResponseWriter) (ok bool) {
+	if s.Orchestrator == nil {
+		respondError(w, http.StatusConflict, errors.New("no bus configured"))
+		return false
+	}
+	return true
+}
+
+// listDeadTasks returns all dead-lettered tasks, newest first,
+// scope-filtered to the caller's own jobs for non-admins.
+func (s *Server) listDeadTasks(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "jobs", "list", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if !s.orchestratorRequired(w) {
+		return
+	}
+	all := s.Orchestrator.DeadLetters()
+	if scope := s.scope(r); scope != "" {
+		scoped := all[:0]
+		for _, dl := range all {
+			if dl.Task.UserID == scope {
+				scoped = append(scoped, dl)
+			}
+		}
+		all = scoped
+	}
+	respondJSON(w, http.StatusOK, all)
+}
+
+// getDeadTask returns one dead-lettered task with its exact payload and
+// final error.
+func (s *Server) getDeadTask(w http.ResponseWriter, r *http.Request) {
+	if !s.orchestratorRequired(w) {
+		return
+	}
+	dl, err := s.Orchestrator.DeadLetter(chi.URLParam(r, "taskID"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	if err := s.authorize(r, "jobs", "read", ownerLabels(dl.Task.UserID)); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, dl)
+}
+
+type replayTaskRequest struct {
+	// WorkerID pins the replay to one worker.
+	WorkerID string `json:"worker_id,omitempty"`
+	// ProfileID swaps the encode parameters before replaying.
+	ProfileID string `json:"profile_id,omitempty"`
+	// SourceURL replaces the input URL, e.g. a refreshed presigned link.
+	SourceURL string `json:"source_url,omitempty"`
+}
+
+// replayDeadTask puts a dead-lettered task back on the bus with a fresh
+// attempt budget, optionally pinned or with modified parameters.
+func (s *Server) replayDeadTask(w http.ResponseWriter, r *http.Request) {
+	if !s.orchestratorRequired(w) {
+		return
+	}
+	dl, err := s.Orchestrator.DeadLetter(chi.URLParam(r, "taskID"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	if err := s.authorize(r, "jobs", "update", ownerLabels(dl.Task.UserID)); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	var req replayTaskRequest
+	if r.ContentLength > 0 {
+		if err := decodeJSON(r, &req); err != nil {
+			respondError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	// A refreshed source link is still a user-supplied URL; it goes
+	// through the same policy as the original submission.
+	if err := s.checkSourceURLs(s.user(r).ID, req.SourceURL); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	over := orchestrator.ReplayOverrides{
+		TargetWorker: req.WorkerID,
+		SourceURL:    req.SourceURL,
+	}
+	if req.ProfileID != "" {
+		p, err := s.Profiles.Get(req.ProfileID)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, errors.New("unknown profile "+req.ProfileID))
+			return
+		}
+		over.Profile = p
+	}
+	if req.WorkerID != "" {
+		if _, err := s.Workers.Get(req.WorkerID); err != nil {
+			respondError(w, http.StatusBadRequest, errors.New("unknown worker "+req.WorkerID))
+			return
+		}
+	}
+	task, err := s.Orchestrator.Replay(dl.Task.ID, over)
+	if err != nil {
+		respondError(w, http.StatusBadGateway, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, task)
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// demoProfileName identifies the profile the demo endpoint creates.
+const demoProfileName = "demo-480p"
+
+// createDemo seeds a working end-to-end setup on a fresh install: a
+// synthetic license-free sample clip (generated with ffmpeg's test
+// sources, so no media ships in the repo), a small shared profile, a
+// transcode job over the sample, and a demo stream. New users see the
+// full pipeline run within minutes without hunting for content.
+func (s *Server) createDemo(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "system", "create", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+
+	samplePath, err := demoSample()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, fmt.Errorf("generate sample media: %w", err))
+		return
+	}
+
+	profile := s.demoProfile()
+	job := s.Jobs.Submit(&types.Job{
+		UserID:       s.user(r).ID,
+		Title:        "Demo encode",
+		SourceURL:    samplePath,
+		OutputPrefix: "demo/outputs",
+		ProfileIDs:   []string{profile.ID},
+		Metadata:     map[string]interface{}{"demo": true},
+	})
+	stream := s.Streams.Create(s.user(r).ID, "Demo stream", samplePath, false)
+
+	if s.Orchestrator != nil {
+		if err := s.Orchestrator.SubmitJob(job, []*types.Profile{profile}); err != nil {
+			respondError(w, http.StatusBadGateway, err)
+			return
+		}
+	}
+	respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"sample":  samplePath,
+		"profile": profile,
+		"job":     job,
+		"stream":  stream,
+	})
+}
+
+// demoProfile returns the shared demo profile, creating it on first
+// use.
+func (s *Server) demoProfile() *types.Profile {
+	for _, p := range s.Profiles.List("") {
+		if p.Name == demoProfileName {
+			return p
+		}
+	}
+	p, _ := s.Profiles.Create(&types.Profile{
+		Name:             demoProfileName,
+		VideoCodec:       "libx264",
+		Width:            854,
+		Height:           480,
+		CRF:              28,
+		Preset:           "veryfast",
+		AudioCodec:       "aac",
+		AudioBitrateKbps: 96,
+	})
+	return p
+}
+
+// demoSample generates (once) a ten-second test clip from ffmpeg's
+// built-in sources and returns its local path.
+func demoSample() (string, error) {
+	path := filepath.Join(os.TempDir(), "webencode-demo", "sample.mp4")
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	cmd := exec.Command("ffmpeg",
+		"-hide_banner", "-loglevel", "error", "-y",
+		"-f", "lavfi", "-i", "testsrc2=size=1280x720:rate=30:duration=10",
+		"-f", "lavfi", "-i", "sine=frequency=440:duration=10",
+		"-pix_fmt", "yuv420p",
+		"-c:v", "libx264", "-preset", "veryfast",
+		"-c:a", "aac",
+		path,
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("ffmpeg: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return path, nil
+}
@@ -0,0 +1,17 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/rennerdo30/webencode/pkg/errcode"
+)
+
+// listErrorCodes returns the error code catalog, so UIs and webhook
+// consumers can resolve codes to descriptions.
+func (s *Server) listErrorCodes(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "system", "read", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, errcode.Catalog())
+}
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// Pricing is the per-unit rate card cost estimates are computed from.
+type Pricing struct {
+	PerEncodeMinute float64 `json:"per_encode_minute"`
+	PerGBStored     float64 `json:"per_gb_stored"`
+	Currency        string  `json:"currency"`
+}
+
+type estimateJobRequest struct {
+	SourceURL       string   `json:"source_url"`
+	ProfileIDs      []string `json:"profile_ids"`
+	TrimStartSec    float64  `json:"trim_start_sec,omitempty"`
+	TrimDurationSec float64  `json:"trim_duration_sec,omitempty"`
+}
+
+// profileEstimate is the prediction for one rendition.
+type profileEstimate struct {
+	ProfileID     string  `json:"profile_id"`
+	ProfileName   string  `json:"profile_name"`
+	EncodeMinutes float64 `json:"encode_minutes"`
+	OutputBytes   int64   `json:"output_bytes"`
+	Cost          float64 `json:"cost,omitempty"`
+}
+
+type estimateJobResponse struct {
+	SourceDurationSec float64           `json:"source_duration_sec"`
+	Profiles          []profileEstimate `json:"profiles"`
+	TotalMinutes      float64           `json:"total_encode_minutes"`
+	TotalBytes        int64             `json:"total_output_bytes"`
+	TotalCost         float64           `json:"total_cost,omitempty"`
+	Currency          string            `json:"currency,omitempty"`
+}
+
+// estimateJob probes the source and predicts encode time, output size
+// and cost for the selected profiles without submitting anything. The
+// numbers are heuristic — codec speed classes and bits-per-pixel rules
+// of thumb — and meant to flag expensive ladders, not to bill from.
+func (s *Server) estimateJob(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "jobs", "read", ownerLabels(s.user(r).ID)); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	var req estimateJobRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.SourceURL == "" {
+		respondError(w, http.StatusBadRequest, errors.New("source_url is required"))
+		return
+	}
+	// The probe runs ffprobe in the kernel process; an unvalidated URL
+	// here would reach internal services no worker ever touches.
+	if err := s.checkSourceURLs(s.user(r).ID, req.SourceURL); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	if len(req.ProfileIDs) == 0 {
+		respondError(w, http.StatusBadRequest, errors.New("profile_ids is required"))
+		return
+	}
+	jobProfiles := make([]*types.Profile, 0, len(req.ProfileIDs))
+	for _, id := range req.ProfileIDs {
+		p, err := s.Profiles.Get(id)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, errors.New("unknown profile "+id))
+			return
+		}
+		jobProfiles = append(jobProfiles, p)
+	}
+	if s.Probes == nil {
+		respondError(w, http.StatusConflict, errors.New("probing is not configured"))
+		return
+	}
+	result, err := s.Probes.Probe("estimate:"+req.SourceURL, req.SourceURL)
+	if err != nil {
+		respondError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	duration := result.Report.Container.DurationSec
+	if req.TrimStartSec > 0 && req.TrimStartSec < duration {
+		duration -= req.TrimStartSec
+	}
+	if req.TrimDurationSec > 0 && req.TrimDurationSec < duration {
+		duration = req.TrimDurationSec
+	}
+	var srcWidth, srcHeight int
+	var srcFPS float64
+	for _, t := range result.Report.Tracks {
+		if t.Type == "video" {
+			srcWidth, srcHeight = t.Width, t.Height
+			srcFPS = parseFramerate(t.Framerate)
+			break
+		}
+	}
+
+	resp := estimateJobResponse{SourceDurationSec: duration, Currency: s.Pricing.Currency}
+	for _, p := range jobProfiles {
+		est := estimateProfile(p, duration, srcWidth, srcHeight, srcFPS)
+		est.Cost = est.EncodeMinutes*s.Pricing.PerEncodeMinute +
+			float64(est.OutputBytes)/1e9*s.Pricing.PerGBStored
+		resp.Profiles = append(resp.Profiles, est)
+		resp.TotalMinutes += est.EncodeMinutes
+		resp.TotalBytes += est.OutputBytes
+		resp.TotalCost += est.Cost
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// estimateProfile predicts one rendition from the profile parameters
+// and the probed source.
+func estimateProfile(p *types.Profile, durationSec float64, srcW, srcH int, srcFPS float64) profileEstimate {
+	w, h := p.Width, p.Height
+	if w == 0 || h == 0 {
+		w, h = srcW, srcH
+	}
+	if w == 0 || h == 0 {
+		w, h = 1920, 1080
+	}
+	fps := p.Framerate
+	if fps == 0 {
+		fps = srcFPS
+	}
+	if fps == 0 {
+		fps = 30
+	}
+
+	videoKbps := float64(p.VideoBitrateKbps)
+	if videoKbps == 0 {
+		// CRF output has no declared bitrate; 0.07 bits per pixel is a
+		// serviceable rule of thumb for mid-range CRF values.
+		videoKbps = 0.07 * float64(w) * float64(h) * fps / 1000
+	}
+	audioKbps := float64(p.AudioBitrateKbps)
+	if audioKbps == 0 && p.AudioCodec != "" {
+		audioKbps = 128
+	}
+	outputBytes := int64((videoKbps + audioKbps) * 1000 / 8 * durationSec)
+
+	// Encode speed relative to realtime for a 1080p input, scaled by
+	// output pixel count. Software encoders only; hardware encoders are
+	// effectively realtime-plus.
+	speed := codecSpeed(p.VideoCodec)
+	speed *= float64(1920*1080) / float64(w*h)
+	if speed < 0.05 {
+		speed = 0.05
+	}
+	encodeMinutes := durationSec / 60 / speed
+
+	return profileEstimate{
+		ProfileID:     p.ID,
+		ProfileName:   p.Name,
+		EncodeMinutes: encodeMinutes,
+		OutputBytes:   outputBytes,
+	}
+}
+
+// codecSpeed is the assumed realtime multiple encoding 1080p with the
+// given codec.
+func codecSpeed(codec string) float64 {
+	c := strings.ToLower(codec)
+	switch {
+	case strings.Contains(c, "nvenc") || strings.Contains(c, "vaapi") || strings.Contains(c, "qsv"):
+		return 8
+	case strings.Contains(c, "x264") || c == "h264":
+		return 4
+	case strings.Contains(c, "x265") || c == "hevc":
+		return 1.5
+	case strings.Contains(c, "svtav1"):
+		return 1
+	case strings.Contains(c, "aom") || c == "av1":
+		return 0.3
+	case strings.Contains(c, "vp9"):
+		return 0.8
+	default:
+		return 2
+	}
+}
+
+// parseFramerate turns ffprobe's "num/den" rate into a float.
+func parseFramerate(rate string) float64 {
+	num, den, ok := strings.Cut(rate, "/")
+	if !ok {
+		f, _ := strconv.ParseFloat(rate, 64)
+		return f
+	}
+	n, err1 := strconv.ParseFloat(num, 64)
+	d, err2 := strconv.ParseFloat(den, 64)
+	if err1 != nil || err2 != nil || d == 0 {
+		return 0
+	}
+	return n / d
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/rennerdo30/webencode/internal/kernel/events"
+)
+
+// listEvents serves the kernel event log with the shared collection
+// filters; type matches as a dotted-name prefix (e.g. "task." or
+// "worker.unhealthy"). Pagination cursors are event sequence numbers,
+// the same cursor space the replay API uses. Non-admin callers only see
+// events about jobs and streams they own.
+func (s *Server) listEvents(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "events", "list", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	q, err := parseListQuery(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	scope := s.scope(r)
+	all := s.Events.List(q.Resource, 0)
+	filtered := all[:0]
+	for _, ev := range all {
+		if !s.eventVisible(scope, ev) {
+			continue
+		}
+		if q.Type != "" && !strings.HasPrefix(ev.Type, q.Type) {
+			continue
+		}
+		if !q.inRange(ev.At) {
+			continue
+		}
+		filtered = append(filtered, ev)
+	}
+	if !q.paged() {
+		respondJSON(w, http.StatusOK, filtered)
+		return
+	}
+	var afterSeq uint64
+	if q.Cursor != "" {
+		afterSeq, err = strconv.ParseUint(q.Cursor, 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, fmt.Errorf("invalid cursor %q", q.Cursor))
+			return
+		}
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	page := listPage[events.Event]{Items: []events.Event{}}
+	for _, ev := range filtered {
+		if ev.Seq <= afterSeq {
+			continue
+		}
+		if len(page.Items) == limit {
+			page.NextCursor = strconv.FormatUint(page.Items[limit-1].Seq, 10)
+			break
+		}
+		page.Items = append(page.Items, ev)
+	}
+	respondJSON(w, http.StatusOK, page)
+}
+
+// eventVisible reports whether an event may be shown to a scoped
+// (non-admin) caller. Events carry no owner of their own; one is
+// visible when its resource resolves to a job or stream the caller
+// owns. Everything else — worker health, plugin drift, task-level
+// internals — is operator telemetry and stays admin-only, the same
+// line search draws.
+func (s *Server) eventVisible(scope string, ev events.Event) bool {
+	if scope == "" {
+		return true
+	}
+	if ev.Resource == "" {
+		return false
+	}
+	if job, err := s.Jobs.Get(ev.Resource); err == nil {
+		return job.UserID == scope
+	}
+	if stream, err := s.Streams.Get(ev.Resource); err == nil {
+		return stream.UserID == scope
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/rennerdo30/webencode/internal/kernel/feeds"
+)
+
+type createFeedRequest struct {
+	URL string `json:"url"`
+	// TemplateID names the job template each new enclosure launches.
+	TemplateID string `json:"template_id"`
+	// IntervalSec between polls; zero uses the watcher default.
+	IntervalSec int `json:"interval_sec,omitempty"`
+	// Ingest fetches enclosures into storage before transcoding.
+	Ingest bool `json:"ingest,omitempty"`
+}
+
+// createFeed registers an RSS/Atom feed whose new enclosures are
+// submitted as jobs through the named template.
+func (s *Server) createFeed(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "feeds", "create", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	var req createFeedRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.TemplateID != "" {
+		if _, err := s.Templates.Get(req.TemplateID); err != nil {
+			respondError(w, http.StatusBadRequest, errors.New("unknown template "+req.TemplateID))
+			return
+		}
+	}
+	if err := s.checkSourceURLs(s.user(r).ID, req.URL); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	feed, err := s.Feeds.Create(&feeds.Feed{
+		OwnerID:     s.user(r).ID,
+		URL:         req.URL,
+		TemplateID:  req.TemplateID,
+		IntervalSec: req.IntervalSec,
+		Ingest:      req.Ingest,
+	})
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, feed)
+}
+
+func (s *Server) listFeeds(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "feeds", "list", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, s.Feeds.List(s.scope(r)))
+}
+
+func (s *Server) getFeed(w http.ResponseWriter, r *http.Request) {
+	feed, err := s.Feeds.Get(chi.URLParam(r, "feedID"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	if err := s.authorize(r, "feeds", "read", ownerLabels(feed.OwnerID)); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, feed)
+}
+
+func (s *Server) deleteFeed(w http.ResponseWriter, r *http.Request) {
+	feed, err := s.Feeds.Get(chi.URLParam(r, "feedID"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	if err := s.authorize(r, "feeds", "delete", ownerLabels(feed.OwnerID)); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if err := s.Feeds.Delete(feed.ID); err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/rennerdo30/webencode/pkg/plugin"
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// storage resolves the configured storage plugin or reports a conflict.
+func (s *Server) storage(w http.ResponseWriter) (ok bool) {
+	if s.Storage == nil {
+		respondError(w, http.StatusConflict, errors.New("no storage plugin configured"))
+		return false
+	}
+	return true
+}
+
+// browseFiles lists objects under ?path= on the storage backend.
+// ?backend= browses one of the aliased backends instead of the default.
+func (s *Server) browseFiles(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "files", "list", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	var (
+		storage plugin.StorageService
+		err     error
+	)
+	if backend := r.URL.Query().Get("backend"); backend != "" {
+		if s.StorageFor == nil {
+			respondError(w, http.StatusBadRequest, errors.New("no storage backends configured"))
+			return
+		}
+		storage, err = s.StorageFor(backend)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, err)
+			return
+		}
+	} else {
+		if !s.storage(w) {
+			return
+		}
+		storage, err = s.Storage()
+		if err != nil {
+			respondError(w, http.StatusBadGateway, err)
+			return
+		}
+	}
+	objects, err := storage.List(strings.TrimPrefix(r.URL.Query().Get("path"), "/"))
+	if err != nil {
+		respondError(w, http.StatusBadGateway, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, objects)
+}
+
+// listStorageBackends returns the alias-to-plugin mapping table so UIs
+// can offer each backend for browsing and job output selection.
+func (s *Server) listStorageBackends(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "files", "list", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	backends := s.StorageBackends
+	if backends == nil {
+		backends = map[string]string{}
+	}
+	respondJSON(w, http.StatusOK, backends)
+}
+
+type filesEncodeRequest struct {
+	// Path is the browsed object key to encode.
+	Path       string   `json:"path"`
+	ProfileIDs []string `json:"profile_ids"`
+	Title      string   `json:"title,omitempty"`
+	// OutputPrefix overrides the default sibling "encoded/" prefix.
+	OutputPrefix string `json:"output_prefix,omitempty"`
+}
+
+// encodeFile creates a job straight from a browsed path, resolving the
+// storage backend and defaulting the output next to the source — no
+// copy-pasting of URLs between the browser and the job form.
+func (s *Server) encodeFile(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "jobs", "create", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if !s.storage(w) {
+		return
+	}
+	var req filesEncodeRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Path == "" {
+		respondError(w, http.StatusBadRequest, errors.New("path is required"))
+		return
+	}
+	for _, id := range req.ProfileIDs {
+		if _, err := s.Profiles.Get(id); err != nil {
+			respondError(w, http.StatusBadRequest, errors.New("unknown profile "+id))
+			return
+		}
+	}
+
+	storage, err := s.Storage()
+	if err != nil {
+		respondError(w, http.StatusBadGateway, err)
+		return
+	}
+	key := strings.TrimPrefix(req.Path, "/")
+	// Presign so workers fetch the source without storage credentials.
+	sourceURL, err := storage.PresignGet(key, 24*time.Hour)
+	if err != nil {
+		respondError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	outputPrefix := req.OutputPrefix
+	if outputPrefix == "" {
+		outputPrefix = path.Join(path.Dir(key), "encoded")
+	}
+	title := req.Title
+	if title == "" {
+		title = path.Base(key)
+	}
+	job := s.Jobs.Submit(&types.Job{
+		UserID:       s.user(r).ID,
+		Title:        title,
+		SourceURL:    sourceURL,
+		OutputPrefix: outputPrefix,
+		ProfileIDs:   req.ProfileIDs,
+		Metadata: map[string]interface{}{
+			"source_key": key,
+		},
+	})
+	s.dispatchJob(w, job)
+}
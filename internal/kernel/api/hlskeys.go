@@ -0,0 +1,35 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// getHLSKey serves the raw AES-128 content key players fetch through
+// the manifest's #EXT-X-KEY URI. Access is authorized against the
+// owning job, so a leaked manifest alone does not unlock the content.
+func (s *Server) getHLSKey(w http.ResponseWriter, r *http.Request) {
+	if s.HLSKeys == nil {
+		respondError(w, http.StatusConflict, errors.New("key delivery is not configured"))
+		return
+	}
+	key, err := s.HLSKeys.Get(chi.URLParam(r, "keyID"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	job, err := s.Jobs.Get(key.JobID)
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	if err := s.authorize(r, "jobs", "read", ownerLabels(job.UserID)); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = w.Write(key.Value)
+}
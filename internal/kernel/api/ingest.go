@@ -0,0 +1,63 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+)
+
+// live resolves the configured live plugin or reports a conflict.
+func (s *Server) live(w http.ResponseWriter) (ok bool) {
+	if s.Live == nil {
+		respondError(w, http.StatusConflict, errors.New("no live plugin configured"))
+		return false
+	}
+	return true
+}
+
+// startIngest provisions ingest endpoints (RTMP/SRT/WHIP) for a stream
+// via the live plugin and remembers the playback URL as the stream
+// source for relays and recording.
+func (s *Server) startIngest(w http.ResponseWriter, r *http.Request) {
+	stream := s.streamForRequest(w, r, "streams", "update")
+	if stream == nil {
+		return
+	}
+	if !s.live(w) {
+		return
+	}
+	liveSvc, err := s.Live()
+	if err != nil {
+		respondError(w, http.StatusBadGateway, err)
+		return
+	}
+	endpoints, err := liveSvc.StartIngest(stream.ID)
+	if err != nil {
+		respondError(w, http.StatusBadGateway, err)
+		return
+	}
+	if stream.SourceURL == "" && endpoints.PlaybackURL != "" {
+		_ = s.Streams.SetSourceURL(stream.ID, endpoints.PlaybackURL)
+	}
+	respondJSON(w, http.StatusOK, endpoints)
+}
+
+// stopIngest tears the ingest down.
+func (s *Server) stopIngest(w http.ResponseWriter, r *http.Request) {
+	stream := s.streamForRequest(w, r, "streams", "update")
+	if stream == nil {
+		return
+	}
+	if !s.live(w) {
+		return
+	}
+	liveSvc, err := s.Live()
+	if err != nil {
+		respondError(w, http.StatusBadGateway, err)
+		return
+	}
+	if err := liveSvc.StopIngest(stream.ID); err != nil {
+		respondError(w, http.StatusBadGateway, err)
+		return
+	}
+	respondJSON(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,405 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/rennerdo30/webencode/internal/kernel/usage"
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+type createJobRequest struct {
+	Title        string `json:"title,omitempty"`
+	SourceURL    string `json:"source_url"`
+	OutputPrefix string `json:"output_prefix,omitempty"`
+	// StorageBackend sends outputs to one of the configured storage
+	// backend aliases; empty uses the default backend.
+	StorageBackend string   `json:"storage_backend,omitempty"`
+	ProfileIDs     []string `json:"profile_ids"`
+	// FanOut declares derivative jobs (social cuts, teasers) spawned
+	// when the primary encode completes.
+	FanOut  []types.FanOutSpec `json:"fan_out,omitempty"`
+	Publish *types.PublishSpec `json:"publish,omitempty"`
+	// ExtractAudio adds a stream-copy task that pulls the audio track
+	// out of the source without transcoding.
+	ExtractAudio bool `json:"extract_audio,omitempty"`
+	// Edit concatenates bumper clips around the source and/or cuts it
+	// down to an EDL of keep-ranges before transcoding.
+	Edit *types.EditSpec `json:"edit,omitempty"`
+	// Ingest fetches the source with yt-dlp before transcoding;
+	// source_url may then be any page URL the tool understands.
+	Ingest *types.IngestSpec `json:"ingest,omitempty"`
+	// Deadline feeds deadline-aware scheduling when it is enabled.
+	Deadline time.Time `json:"deadline,omitempty"`
+}
+
+func (s *Server) createJob(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "jobs", "create", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	var req createJobRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.SourceURL == "" {
+		respondError(w, http.StatusBadRequest, errors.New("source_url is required"))
+		return
+	}
+	urls := []string{req.SourceURL}
+	if req.Edit != nil {
+		urls = append(append(urls, req.Edit.PreRollURLs...), req.Edit.PostRollURLs...)
+	}
+	if err := s.checkSourceURLs(s.user(r).ID, urls...); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	for _, id := range req.ProfileIDs {
+		if _, err := s.Profiles.Get(id); err != nil {
+			respondError(w, http.StatusBadRequest, errors.New("unknown profile "+id))
+			return
+		}
+	}
+	if req.StorageBackend != "" {
+		if _, ok := s.StorageBackends[req.StorageBackend]; !ok {
+			respondError(w, http.StatusBadRequest, errors.New("unknown storage backend "+req.StorageBackend))
+			return
+		}
+	}
+	if req.Edit != nil {
+		for _, rg := range req.Edit.Ranges {
+			if rg.StartSec < 0 || rg.EndSec <= rg.StartSec {
+				respondError(w, http.StatusBadRequest, errors.New("edit ranges need 0 <= start_sec < end_sec"))
+				return
+			}
+		}
+	}
+	for _, spec := range req.FanOut {
+		if spec.Name == "" {
+			respondError(w, http.StatusBadRequest, errors.New("fan_out entries need a name"))
+			return
+		}
+		for _, id := range append([]string{}, spec.ProfileIDs...) {
+			if _, err := s.Profiles.Get(id); err != nil {
+				respondError(w, http.StatusBadRequest, errors.New("unknown profile "+id+" in fan_out "+spec.Name))
+				return
+			}
+		}
+	}
+	job := s.Jobs.Submit(&types.Job{
+		UserID:         s.user(r).ID,
+		Title:          req.Title,
+		SourceURL:      req.SourceURL,
+		OutputPrefix:   req.OutputPrefix,
+		StorageBackend: req.StorageBackend,
+		ProfileIDs:     req.ProfileIDs,
+		FanOut:         req.FanOut,
+		Publish:        req.Publish,
+		ExtractAudio:   req.ExtractAudio,
+		Edit:           req.Edit,
+		Ingest:         req.Ingest,
+		Deadline:       req.Deadline,
+	})
+	s.dispatchJob(w, job)
+}
+
+// checkSourceURLs validates user-supplied URLs against the source URL
+// policy; a nil policy allows everything.
+func (s *Server) checkSourceURLs(userID string, urls ...string) error {
+	if s.SourceURLs == nil {
+		return nil
+	}
+	for _, u := range urls {
+		if err := s.SourceURLs.Validate(userID, u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkPushURL validates a user-supplied push destination (restream
+// target) against the URL policy; a nil policy allows everything.
+func (s *Server) checkPushURL(userID, raw string) error {
+	if s.SourceURLs == nil {
+		return nil
+	}
+	return s.SourceURLs.ValidatePush(userID, raw)
+}
+
+// dispatchJob hands a freshly submitted job to the orchestrator and
+// writes the creation response.
+func (s *Server) dispatchJob(w http.ResponseWriter, job *types.Job) {
+	if s.Orchestrator != nil {
+		jobProfiles := make([]*types.Profile, 0, len(job.ProfileIDs))
+		for _, id := range job.ProfileIDs {
+			p, err := s.Profiles.Get(id)
+			if err != nil {
+				respondError(w, http.StatusInternalServerError, err)
+				return
+			}
+			jobProfiles = append(jobProfiles, p)
+		}
+		if err := s.Orchestrator.SubmitJob(job, jobProfiles); err != nil {
+			if errors.Is(err, usage.ErrQuotaExceeded) {
+				respondError(w, http.StatusTooManyRequests, err)
+				return
+			}
+			respondError(w, http.StatusBadGateway, err)
+			return
+		}
+	}
+	respondJSON(w, http.StatusCreated, job)
+}
+
+type cloneJobRequest struct {
+	// All fields are optional; unset fields keep the original job's
+	// values.
+	Title        *string   `json:"title,omitempty"`
+	SourceURL    *string   `json:"source_url,omitempty"`
+	OutputPrefix *string   `json:"output_prefix,omitempty"`
+	ProfileIDs   *[]string `json:"profile_ids,omitempty"`
+}
+
+// cloneJob pre-fills a new submission from an existing job, recording
+// the lineage and a human-readable diff of what changed — "same as last
+// week, higher bitrate" stays reproducible and auditable.
+func (s *Server) cloneJob(w http.ResponseWriter, r *http.Request) {
+	parent, err := s.Jobs.Get(chi.URLParam(r, "jobID"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	if err := s.authorize(r, "jobs", "create", ownerLabels(parent.UserID)); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	var req cloneJobRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	clone := &types.Job{
+		UserID:          s.user(r).ID,
+		Title:           parent.Title,
+		SourceURL:       parent.SourceURL,
+		OutputPrefix:    parent.OutputPrefix,
+		StorageBackend:  parent.StorageBackend,
+		ProfileIDs:      append([]string(nil), parent.ProfileIDs...),
+		TrimStartSec:    parent.TrimStartSec,
+		TrimDurationSec: parent.TrimDurationSec,
+		FanOut:          parent.FanOut,
+		Publish:         parent.Publish,
+		ExtractAudio:    parent.ExtractAudio,
+		Edit:            parent.Edit,
+		Ingest:          parent.Ingest,
+	}
+	var diff []string
+	if req.Title != nil && *req.Title != clone.Title {
+		diff = append(diff, fmt.Sprintf("title: %q -> %q", clone.Title, *req.Title))
+		clone.Title = *req.Title
+	}
+	if req.SourceURL != nil && *req.SourceURL != clone.SourceURL {
+		if err := s.checkSourceURLs(s.user(r).ID, *req.SourceURL); err != nil {
+			respondError(w, http.StatusBadRequest, err)
+			return
+		}
+		diff = append(diff, fmt.Sprintf("source_url: %q -> %q", clone.SourceURL, *req.SourceURL))
+		clone.SourceURL = *req.SourceURL
+	}
+	if req.OutputPrefix != nil && *req.OutputPrefix != clone.OutputPrefix {
+		diff = append(diff, fmt.Sprintf("output_prefix: %q -> %q", clone.OutputPrefix, *req.OutputPrefix))
+		clone.OutputPrefix = *req.OutputPrefix
+	}
+	if req.ProfileIDs != nil && !equalStrings(*req.ProfileIDs, clone.ProfileIDs) {
+		diff = append(diff, fmt.Sprintf("profile_ids: %v -> %v", clone.ProfileIDs, *req.ProfileIDs))
+		clone.ProfileIDs = *req.ProfileIDs
+	}
+	for _, id := range clone.ProfileIDs {
+		if _, err := s.Profiles.Get(id); err != nil {
+			respondError(w, http.StatusBadRequest, errors.New("unknown profile "+id))
+			return
+		}
+	}
+
+	clone.Metadata = map[string]interface{}{
+		"cloned_from": parent.ID,
+	}
+	if len(diff) > 0 {
+		clone.Metadata["diff"] = diff
+	}
+	job := s.Jobs.Submit(clone)
+	s.dispatchJob(w, job)
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) listJobTasks(w http.ResponseWriter, r *http.Request) {
+	job, err := s.Jobs.Get(chi.URLParam(r, "jobID"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	if err := s.authorize(r, "jobs", "read", ownerLabels(job.UserID)); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if s.Orchestrator == nil {
+		respondJSON(w, http.StatusOK, []struct{}{})
+		return
+	}
+	respondJSON(w, http.StatusOK, s.Orchestrator.Tasks(chi.URLParam(r, "jobID")))
+}
+
+// listJobAnalytics returns the per-task encode progress series (frame,
+// fps, bitrate, q, speed over time) for charting encode performance.
+func (s *Server) listJobAnalytics(w http.ResponseWriter, r *http.Request) {
+	job, err := s.Jobs.Get(chi.URLParam(r, "jobID"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	if err := s.authorize(r, "jobs", "read", ownerLabels(job.UserID)); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if s.Orchestrator == nil {
+		respondJSON(w, http.StatusOK, []struct{}{})
+		return
+	}
+	respondJSON(w, http.StatusOK, s.Orchestrator.JobAnalytics(job.ID))
+}
+
+// listJobOutputs returns the structured artifacts a job's tasks have
+// produced so far, with presigned download links when a storage plugin
+// is configured.
+func (s *Server) listJobOutputs(w http.ResponseWriter, r *http.Request) {
+	job, err := s.Jobs.Get(chi.URLParam(r, "jobID"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	if err := s.authorize(r, "jobs", "read", ownerLabels(job.UserID)); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if s.Orchestrator == nil {
+		respondJSON(w, http.StatusOK, []types.Artifact{})
+		return
+	}
+	artifacts := s.Orchestrator.Outputs(job.ID)
+	if s.Storage != nil {
+		if storage, err := s.Storage(); err == nil {
+			for i, a := range artifacts {
+				// Worker-local paths (no storage plugin at encode time)
+				// cannot be presigned.
+				if strings.HasPrefix(a.Key, "/") {
+					continue
+				}
+				if url, err := storage.PresignGet(a.Key, 24*time.Hour); err == nil {
+					artifacts[i].URL = url
+					if s.Usage != nil {
+						s.Usage.RecordEgress(job.UserID, a.SizeBytes)
+					}
+				}
+			}
+		}
+	}
+	respondJSON(w, http.StatusOK, artifacts)
+}
+
+func (s *Server) listJobs(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "jobs", "list", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	q, err := parseListQuery(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	jobs := filterJobs(s.Jobs.List(s.scope(r)), q)
+	if err := sortJobs(jobs, q); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	if !q.paged() {
+		respondJSON(w, http.StatusOK, jobs)
+		return
+	}
+	respondJSON(w, http.StatusOK, paginate(jobs, func(j *types.Job) string { return j.ID }, q))
+}
+
+// filterJobs applies the shared collection filters to a job list.
+func filterJobs(jobs []*types.Job, q listQuery) []*types.Job {
+	out := jobs[:0]
+	for _, j := range jobs {
+		if q.Status != "" && string(j.Status) != q.Status {
+			continue
+		}
+		if q.User != "" && j.UserID != q.User {
+			continue
+		}
+		if q.Profile != "" && !containsProfile(j.ProfileIDs, q.Profile) {
+			continue
+		}
+		if !q.inRange(j.CreatedAt) || !matchSub(j.SourceURL, q.Source) {
+			continue
+		}
+		out = append(out, j)
+	}
+	return out
+}
+
+func containsProfile(ids []string, id string) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// sortJobs orders a job list by the requested field.
+func sortJobs(jobs []*types.Job, q listQuery) error {
+	switch q.Sort {
+	case "", "created_at":
+		sortSlice(jobs, func(a, b *types.Job) bool { return a.CreatedAt.Before(b.CreatedAt) }, q.Desc)
+	case "updated_at":
+		sortSlice(jobs, func(a, b *types.Job) bool { return a.UpdatedAt.Before(b.UpdatedAt) }, q.Desc)
+	case "status":
+		sortSlice(jobs, func(a, b *types.Job) bool { return a.Status < b.Status }, q.Desc)
+	default:
+		return fmt.Errorf("invalid sort %q for jobs", q.Sort)
+	}
+	return nil
+}
+
+func (s *Server) getJob(w http.ResponseWriter, r *http.Request) {
+	job, err := s.Jobs.Get(chi.URLParam(r, "jobID"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	if err := s.authorize(r, "jobs", "read", ownerLabels(job.UserID)); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, job)
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/rennerdo30/webencode/internal/kernel/events"
+)
+
+// logBackfill is how many historical events a log stream replays before
+// going live.
+const logBackfill = 200
+
+// streamJobLogs tails one job's worker log lines and task lifecycle
+// transitions as server-sent events. ?level=error drops info-level
+// lines; the stream starts with a backfill of recent history.
+func (s *Server) streamJobLogs(w http.ResponseWriter, r *http.Request) {
+	job, err := s.Jobs.Get(chi.URLParam(r, "jobID"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	if err := s.authorize(r, "jobs", "read", ownerLabels(job.UserID)); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		respondError(w, http.StatusInternalServerError, errors.New("streaming unsupported"))
+		return
+	}
+	level := r.URL.Query().Get("level")
+
+	// Subscribe before backfilling so no event falls in the gap; the
+	// sequence number dedupes the overlap.
+	sub, cancel := s.Events.Subscribe()
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	var lastSeq uint64
+	for _, ev := range s.Events.List(job.ID, logBackfill) {
+		if writeLogEvent(w, ev, level) {
+			lastSeq = ev.Seq
+		}
+	}
+	flusher.Flush()
+
+	ping := time.NewTicker(15 * time.Second)
+	defer ping.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ping.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		case ev, ok := <-sub:
+			if !ok {
+				return
+			}
+			if ev.Resource != job.ID || ev.Seq <= lastSeq {
+				continue
+			}
+			if writeLogEvent(w, ev, level) {
+				lastSeq = ev.Seq
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// writeLogEvent emits one event in SSE framing if it belongs in a log
+// stream at the given level. Reports whether anything was written.
+func writeLogEvent(w http.ResponseWriter, ev events.Event, level string) bool {
+	if ev.Type != "job.log" && !strings.HasPrefix(ev.Type, "task.") {
+		return false
+	}
+	if level == "error" {
+		if ev.Type == "job.log" {
+			if l, _ := ev.Data["level"].(string); l != "error" {
+				return false
+			}
+		} else if ev.Type != "task.failed" {
+			return false
+		}
+	}
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return false
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, data)
+	return true
+}
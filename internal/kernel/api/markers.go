@@ -0,0 +1,47 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type createMarkerRequest struct {
+	Label string `json:"label"`
+	// At is optional; omitted means "now".
+	At *time.Time `json:"at,omitempty"`
+}
+
+func (s *Server) createMarker(w http.ResponseWriter, r *http.Request) {
+	if s.streamForRequest(w, r, "streams", "update") == nil {
+		return
+	}
+	var req createMarkerRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Label == "" {
+		respondError(w, http.StatusBadRequest, errors.New("label is required"))
+		return
+	}
+	at := time.Time{}
+	if req.At != nil {
+		at = *req.At
+	}
+	marker, err := s.Streams.AddMarker(chi.URLParam(r, "streamID"), req.Label, at)
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, marker)
+}
+
+func (s *Server) listMarkers(w http.ResponseWriter, r *http.Request) {
+	if s.streamForRequest(w, r, "streams", "read") == nil {
+		return
+	}
+	respondJSON(w, http.StatusOK, s.Streams.Markers(chi.URLParam(r, "streamID")))
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// getMediaInfo returns the deep media info report for an asset. The ID
+// resolves against the asset catalog first, and falls back to a job ID
+// (probing that job's source) for callers predating the catalog. The
+// first request runs ffprobe and caches the result.
+func (s *Server) getMediaInfo(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "assetID")
+	var ownerID, location string
+	if s.Assets != nil {
+		if a, err := s.Assets.Get(id); err == nil {
+			ownerID, location = a.OwnerID, a.Location
+		}
+	}
+	if location == "" {
+		job, err := s.Jobs.Get(id)
+		if err != nil {
+			respondError(w, http.StatusNotFound, err)
+			return
+		}
+		ownerID, location = job.UserID, job.SourceURL
+	}
+	if err := s.authorize(r, "assets", "read", ownerLabels(ownerID)); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if s.Probes == nil {
+		respondError(w, http.StatusConflict, errors.New("probing is not configured"))
+		return
+	}
+	result, err := s.Probes.Probe(id, location)
+	if err != nil {
+		respondError(w, http.StatusBadGateway, err)
+		return
+	}
+	if r.URL.Query().Get("raw") == "true" {
+		respondJSON(w, http.StatusOK, result.Raw)
+		return
+	}
+	respondJSON(w, http.StatusOK, result.Report)
+}
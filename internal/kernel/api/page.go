@@ -0,0 +1,155 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultPageSize and maxPageSize bound paginated responses.
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// listQuery is the shared pagination/filter/sort vocabulary of the
+// collection endpoints: cursor/limit page through results, sort/order
+// pick the ordering, and the remaining fields narrow the set. Endpoints
+// apply the filters that make sense for their resource and ignore the
+// rest.
+type listQuery struct {
+	Cursor string
+	Limit  int
+	Sort   string
+	Desc   bool
+
+	Status   string
+	User     string
+	Profile  string
+	Source   string
+	Resource string
+	Type     string
+	Since    time.Time
+	Until    time.Time
+}
+
+// parseListQuery reads the shared collection parameters off a request.
+func parseListQuery(r *http.Request) (listQuery, error) {
+	q := listQuery{
+		Cursor:   r.URL.Query().Get("cursor"),
+		Sort:     r.URL.Query().Get("sort"),
+		Status:   r.URL.Query().Get("status"),
+		User:     r.URL.Query().Get("user"),
+		Profile:  r.URL.Query().Get("profile"),
+		Source:   r.URL.Query().Get("source"),
+		Resource: r.URL.Query().Get("resource"),
+		Type:     r.URL.Query().Get("type"),
+	}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return q, fmt.Errorf("invalid limit %q", v)
+		}
+		if n > maxPageSize {
+			n = maxPageSize
+		}
+		q.Limit = n
+	}
+	switch order := r.URL.Query().Get("order"); order {
+	case "", "asc":
+	case "desc":
+		q.Desc = true
+	default:
+		return q, fmt.Errorf("invalid order %q (want asc or desc)", order)
+	}
+	var err error
+	if v := r.URL.Query().Get("since"); v != "" {
+		if q.Since, err = time.Parse(time.RFC3339, v); err != nil {
+			return q, fmt.Errorf("invalid since: %w", err)
+		}
+	}
+	if v := r.URL.Query().Get("until"); v != "" {
+		if q.Until, err = time.Parse(time.RFC3339, v); err != nil {
+			return q, fmt.Errorf("invalid until: %w", err)
+		}
+	}
+	return q, nil
+}
+
+// paged reports whether the client asked for a paginated envelope;
+// without cursor or limit, endpoints keep their plain-array responses.
+func (q listQuery) paged() bool {
+	return q.Cursor != "" || q.Limit > 0
+}
+
+// inRange applies the since/until window to a timestamp.
+func (q listQuery) inRange(t time.Time) bool {
+	if !q.Since.IsZero() && t.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && t.After(q.Until) {
+		return false
+	}
+	return true
+}
+
+// matchSub is the case-insensitive substring filter used for source
+// URLs and similar free-text fields; an empty pattern matches all.
+func matchSub(value, pattern string) bool {
+	if pattern == "" {
+		return true
+	}
+	return strings.Contains(strings.ToLower(value), strings.ToLower(pattern))
+}
+
+// listPage is the paginated response envelope: the requested slice and
+// the cursor of the page after it (empty on the last page).
+type listPage[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// paginate slices one page out of the already filtered and sorted
+// items. The cursor is the ID of the last item of the previous page;
+// an unknown cursor starts from the beginning.
+func paginate[T any](items []T, id func(T) string, q listQuery) listPage[T] {
+	start := 0
+	if q.Cursor != "" {
+		for i, item := range items {
+			if id(item) == q.Cursor {
+				start = i + 1
+				break
+			}
+		}
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	end := start + limit
+	if end > len(items) {
+		end = len(items)
+	}
+	page := listPage[T]{Items: items[start:end]}
+	if end < len(items) && end > start {
+		page.NextCursor = id(items[end-1])
+	}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	return page
+}
+
+// sortSlice orders items by the given less function, reversed for
+// descending queries.
+func sortSlice[T any](items []T, less func(a, b T) bool, desc bool) {
+	sort.SliceStable(items, func(i, j int) bool {
+		if desc {
+			return less(items[j], items[i])
+		}
+		return less(items[i], items[j])
+	})
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/rennerdo30/webencode/internal/pluginmgr"
+)
+
+// listPlugins reports every plugin's deployment state so operators spot
+// config drift: configured names without a binary, and binaries nothing
+// references.
+func (s *Server) listPlugins(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "plugins", "read", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	var statuses []pluginmgr.Status
+	if s.Plugins != nil {
+		statuses = s.Plugins()
+	}
+	respondJSON(w, http.StatusOK, statuses)
+}
+
+// reloadPlugin restarts one plugin subprocess against the current
+// binary, draining in-flight calls first, so a redeployed plugin takes
+// effect without a kernel restart.
+func (s *Server) reloadPlugin(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "plugins", "update", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if s.ReloadPlugin == nil {
+		respondError(w, http.StatusConflict, errors.New("plugin management is not configured"))
+		return
+	}
+	name := chi.URLParam(r, "pluginID")
+	if err := s.ReloadPlugin(name); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]string{"name": name, "state": "reloaded"})
+}
+
+// getPluginSchema returns the config fields a plugin declares, for
+// validation and UI form generation.
+func (s *Server) getPluginSchema(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "plugins", "read", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if s.PluginSchema == nil {
+		respondError(w, http.StatusConflict, errors.New("plugin management is not configured"))
+		return
+	}
+	fields, err := s.PluginSchema(chi.URLParam(r, "pluginID"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, fields)
+}
+
+// getPluginConfig returns the stored plugin config with secrets
+// redacted.
+func (s *Server) getPluginConfig(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "plugins", "read", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if s.PluginConfig == nil {
+		respondError(w, http.StatusConflict, errors.New("plugin management is not configured"))
+		return
+	}
+	values, err := s.PluginConfig(chi.URLParam(r, "pluginID"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, values)
+}
+
+// setPluginConfig validates submitted values against the plugin's
+// schema and pushes them to the running plugin.
+func (s *Server) setPluginConfig(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "plugins", "update", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if s.SetPluginConfig == nil {
+		respondError(w, http.StatusConflict, errors.New("plugin management is not configured"))
+		return
+	}
+	var values map[string]string
+	if err := decodeJSON(r, &values); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	name := chi.URLParam(r, "pluginID")
+	if err := s.SetPluginConfig(name, values); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]string{"name": name, "state": "configured"})
+}
+
+// listAvailablePlugins returns the configured registry's catalog.
+func (s *Server) listAvailablePlugins(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "plugins", "read", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if s.PluginCatalog == nil {
+		respondError(w, http.StatusConflict, errors.New("no plugin registry is configured"))
+		return
+	}
+	plugins, err := s.PluginCatalog()
+	if err != nil {
+		respondError(w, http.StatusBadGateway, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, plugins)
+}
+
+type installPluginRequest struct {
+	// Name is the registry entry to install (<kind>-<impl>).
+	Name string `json:"name"`
+}
+
+// installPlugin downloads, verifies and installs a plugin binary from
+// the configured registry, then launches it.
+func (s *Server) installPlugin(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "plugins", "create", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if s.InstallPlugin == nil {
+		respondError(w, http.StatusConflict, errors.New("no plugin registry is configured"))
+		return
+	}
+	var req installPluginRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Name == "" {
+		respondError(w, http.StatusBadRequest, errors.New("name is required"))
+		return
+	}
+	if err := s.InstallPlugin(req.Name); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, map[string]string{"name": req.Name, "state": "installed"})
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/rennerdo30/webencode/internal/kernel/auth"
+)
+
+// policy resolves the editable policy engine or reports a conflict (the
+// rule API is unavailable when authorization is delegated to OPA).
+func (s *Server) policy(w http.ResponseWriter) (ok bool) {
+	if s.Policy == nil {
+		respondError(w, http.StatusConflict, errors.New("authorization is delegated; policy rules are not editable here"))
+		return false
+	}
+	return true
+}
+
+func (s *Server) listPolicyRules(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "policy", "list", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if !s.policy(w) {
+		return
+	}
+	respondJSON(w, http.StatusOK, s.Policy.Rules())
+}
+
+func (s *Server) upsertPolicyRule(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "policy", "update", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if !s.policy(w) {
+		return
+	}
+	var rule auth.Rule
+	if err := decodeJSON(r, &rule); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := rule.Validate(); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, s.Policy.Upsert(rule))
+}
+
+func (s *Server) deletePolicyRule(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "policy", "delete", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if !s.policy(w) {
+		return
+	}
+	if err := s.Policy.Delete(chi.URLParam(r, "ruleID")); err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	respondJSON(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/rennerdo30/webencode/internal/kernel/profiles"
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+func (s *Server) createProfile(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "profiles", "create", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	var p types.Profile
+	if err := decodeJSON(r, &p); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	// Admin-created profiles are shared; user-created ones are scoped to
+	// their owner.
+	if u := s.user(r); !u.IsAdmin() {
+		p.OwnerID = u.ID
+	}
+	created, err := s.Profiles.Create(&p)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, created)
+}
+
+func (s *Server) updateProfile(w http.ResponseWriter, r *http.Request) {
+	existing, err := s.Profiles.Get(chi.URLParam(r, "profileID"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	if err := s.authorize(r, "profiles", "update", ownerLabels(existing.OwnerID)); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	var p types.Profile
+	if err := decodeJSON(r, &p); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	p.OwnerID = existing.OwnerID
+	updated, err := s.Profiles.Update(chi.URLParam(r, "profileID"), &p)
+	if err != nil {
+		if errors.Is(err, profiles.ErrNotFound) {
+			respondError(w, http.StatusNotFound, err)
+			return
+		}
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, updated)
+}
+
+func (s *Server) listProfiles(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "profiles", "list", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, s.Profiles.List(s.scope(r)))
+}
+
+func (s *Server) getProfile(w http.ResponseWriter, r *http.Request) {
+	p, err := s.Profiles.Get(chi.URLParam(r, "profileID"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	if err := s.authorize(r, "profiles", "read", ownerLabels(p.OwnerID)); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, p)
+}
+
+// createProfileLadder generates an ABR ladder from a source probe and
+// stores every rung as a profile in one call.
+func (s *Server) createProfileLadder(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "profiles", "create", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	var req profiles.LadderRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	ladder, err := profiles.GenerateLadder(&req)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	ownerID := ""
+	if u := s.user(r); !u.IsAdmin() {
+		ownerID = u.ID
+	}
+	created := make([]*types.Profile, 0, len(ladder))
+	for _, p := range ladder {
+		p.OwnerID = ownerID
+		stored, err := s.Profiles.Create(p)
+		if err != nil {
+			// Roll back the rungs already stored so a half-created ladder
+			// never lingers.
+			for _, c := range created {
+				_ = s.Profiles.Delete(c.ID)
+			}
+			respondError(w, http.StatusBadRequest, err)
+			return
+		}
+		created = append(created, stored)
+	}
+	respondJSON(w, http.StatusCreated, created)
+}
+
+// listCodecSupport reports which optional encoders (SVT-AV1, VVC, ...)
+// the local ffmpeg build provides, so clients can gate profile options
+// on what will actually encode.
+func (s *Server) listCodecSupport(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "profiles", "list", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if s.CodecSupport == nil {
+		respondError(w, http.StatusConflict, errors.New("codec detection is not configured"))
+		return
+	}
+	respondJSON(w, http.StatusOK, s.CodecSupport())
+}
+
+func (s *Server) deleteProfile(w http.ResponseWriter, r *http.Request) {
+	p, err := s.Profiles.Get(chi.URLParam(r, "profileID"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	if err := s.authorize(r, "profiles", "delete", ownerLabels(p.OwnerID)); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if err := s.Profiles.Delete(chi.URLParam(r, "profileID")); err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	respondJSON(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RateLimits configures the API rate limiter: requests per window per
+// caller, with a separate (tighter) bucket for expensive endpoints
+// like job submission. Zero limits disable the respective bucket.
+type RateLimits struct {
+	Default   int
+	Expensive int
+	Window    time.Duration
+	// Overrides raises or lowers the default limit for specific
+	// callers (user IDs or API key IDs).
+	Overrides map[string]int
+}
+
+// expensiveEndpoint classifies requests that kick off real work —
+// job/batch submission and media uploads — which get their own,
+// tighter bucket.
+func expensiveEndpoint(r *http.Request) bool {
+	if r.Method != http.MethodPost {
+		return false
+	}
+	switch r.URL.Path {
+	case "/v1/jobs", "/v1/jobs/batch", "/v1/jobs/bulk", "/v1/uploads":
+		return true
+	}
+	return false
+}
+
+// rateLimits snapshots the limiter config.
+func (s *Server) rateLimits() RateLimits {
+	s.limitsMu.Lock()
+	defer s.limitsMu.Unlock()
+	return s.RateLimits
+}
+
+// SetRateLimits swaps the limiter config; config reload applies
+// changed limits through it without a restart.
+func (s *Server) SetRateLimits(rl RateLimits) {
+	s.limitsMu.Lock()
+	s.RateLimits = rl
+	s.limitsMu.Unlock()
+}
+
+// rateLimitCaller identifies who a request is charged to: the
+// authenticated user, falling back to the client address for anonymous
+// traffic.
+func (s *Server) rateLimitCaller(r *http.Request) string {
+	if u := s.user(r); u != nil && u.ID != "" {
+		return u.ID
+	}
+	return r.RemoteAddr
+}
+
+// rateLimitMiddleware charges each request against the caller's bucket
+// and reflects the outcome in RateLimit-* headers. Limiter backend
+// errors fail open: degraded Redis slows nobody down, it just stops
+// limiting.
+func (s *Server) rateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rl := s.rateLimits()
+		if s.RateLimiter == nil || rl.Default <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		bucket, limit := "default", rl.Default
+		if rl.Expensive > 0 && expensiveEndpoint(r) {
+			bucket, limit = "expensive", rl.Expensive
+		}
+		caller := s.rateLimitCaller(r)
+		if override, ok := rl.Overrides[caller]; ok && bucket == "default" {
+			limit = override
+		}
+		window := rl.Window
+		if window <= 0 {
+			window = time.Minute
+		}
+		d, err := s.RateLimiter.Allow(bucket, caller, limit, window)
+		if err != nil {
+			log.Printf("api: rate limiter unavailable, failing open: %v", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(d.Limit))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(d.Remaining))
+		if secs := int(time.Until(d.Reset).Seconds()); secs > 0 {
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(secs))
+		}
+		if !d.Allowed {
+			respondError(w, http.StatusTooManyRequests, errors.New("rate limit exceeded"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
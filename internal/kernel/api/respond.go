@@ -0,0 +1,58 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/rennerdo30/webencode/internal/kernel/auth"
+	"github.com/rennerdo30/webencode/pkg/errcode"
+)
+
+// respondJSON writes a JSON response body with the given status.
+func respondJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if v != nil {
+		_ = json.NewEncoder(w).Encode(v)
+	}
+}
+
+// errorBody is the uniform error envelope of the API. Code is the
+// machine-readable catalog code (pkg/errcode); consumers branch on it
+// instead of parsing the message.
+type errorBody struct {
+	Error string       `json:"error"`
+	Code  errcode.Code `json:"code"`
+}
+
+// respondError maps an error to a status code and the error envelope.
+// Errors carrying a catalog code keep it; everything else gets the
+// general code for the status.
+func respondError(w http.ResponseWriter, status int, err error) {
+	body := errorBody{Error: err.Error()}
+	var coded *errcode.Error
+	if errors.As(err, &coded) {
+		body.Code = coded.Code
+	} else {
+		body.Code = errcode.FromStatus(status)
+	}
+	respondJSON(w, status, body)
+}
+
+// respondAuthError writes 403 for authorization failures and 500 for
+// anything else the authorizer reported.
+func respondAuthError(w http.ResponseWriter, err error) {
+	if errors.Is(err, auth.ErrForbidden) {
+		respondError(w, http.StatusForbidden, err)
+		return
+	}
+	respondError(w, http.StatusInternalServerError, err)
+}
+
+// decodeJSON parses a request body into v.
+func decodeJSON(r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/rennerdo30/webencode/internal/kernel/restream"
+)
+
+type createRestreamTargetRequest struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+func (s *Server) createRestreamTarget(w http.ResponseWriter, r *http.Request) {
+	stream := s.streamForRequest(w, r, "restreams", "create")
+	if stream == nil {
+		return
+	}
+	var req createRestreamTargetRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.URL == "" {
+		respondError(w, http.StatusBadRequest, errors.New("url is required"))
+		return
+	}
+	if err := s.checkPushURL(s.user(r).ID, req.URL); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	target := s.Restreams.AddTarget(stream.ID, req.Name, req.URL)
+	respondJSON(w, http.StatusCreated, target)
+}
+
+func (s *Server) listRestreamTargets(w http.ResponseWriter, r *http.Request) {
+	stream := s.streamForRequest(w, r, "restreams", "list")
+	if stream == nil {
+		return
+	}
+	q, err := parseListQuery(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	targets := s.Restreams.ListForStream(stream.ID)
+	filtered := targets[:0]
+	for _, t := range targets {
+		if q.Status != "" && string(t.Status) != q.Status {
+			continue
+		}
+		if !q.inRange(t.CreatedAt) || !matchSub(t.Name, q.Source) {
+			continue
+		}
+		filtered = append(filtered, t)
+	}
+	sortSlice(filtered, func(a, b *restream.Target) bool { return a.CreatedAt.Before(b.CreatedAt) }, q.Desc)
+	if !q.paged() {
+		respondJSON(w, http.StatusOK, filtered)
+		return
+	}
+	respondJSON(w, http.StatusOK, paginate(filtered, func(t *restream.Target) string { return t.ID }, q))
+}
+
+func (s *Server) getRestreamTarget(w http.ResponseWriter, r *http.Request) {
+	if s.streamForRequest(w, r, "restreams", "read") == nil {
+		return
+	}
+	target, err := s.Restreams.Get(chi.URLParam(r, "targetID"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, target)
+}
+
+func (s *Server) deleteRestreamTarget(w http.ResponseWriter, r *http.Request) {
+	if s.streamForRequest(w, r, "restreams", "delete") == nil {
+		return
+	}
+	if err := s.Restreams.RemoveTarget(chi.URLParam(r, "targetID")); err != nil {
+		if errors.Is(err, restream.ErrNotFound) {
+			respondError(w, http.StatusNotFound, err)
+			return
+		}
+		respondError(w, http.StatusInternalServerError, err)
+		return
+	}
+	respondJSON(w, http.StatusNoContent, nil)
+}
+
+func (s *Server) startRestreamTarget(w http.ResponseWriter, r *http.Request) {
+	stream := s.streamForRequest(w, r, "restreams", "update")
+	if stream == nil {
+		return
+	}
+	if stream.SourceURL == "" {
+		respondError(w, http.StatusConflict, errors.New("stream has no source URL yet"))
+		return
+	}
+	if err := s.Restreams.StartTarget(chi.URLParam(r, "targetID"), stream.SourceURL); err != nil {
+		if errors.Is(err, restream.ErrNotFound) {
+			respondError(w, http.StatusNotFound, err)
+			return
+		}
+		respondError(w, http.StatusConflict, err)
+		return
+	}
+	target, _ := s.Restreams.Get(chi.URLParam(r, "targetID"))
+	respondJSON(w, http.StatusOK, target)
+}
+
+func (s *Server) stopRestreamTarget(w http.ResponseWriter, r *http.Request) {
+	if s.streamForRequest(w, r, "restreams", "update") == nil {
+		return
+	}
+	if err := s.Restreams.StopTarget(chi.URLParam(r, "targetID")); err != nil {
+		if errors.Is(err, restream.ErrNotFound) {
+			respondError(w, http.StatusNotFound, err)
+			return
+		}
+		respondError(w, http.StatusConflict, err)
+		return
+	}
+	target, _ := s.Restreams.Get(chi.URLParam(r, "targetID"))
+	respondJSON(w, http.StatusOK, target)
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/rennerdo30/webencode/internal/kernel/orchestrator"
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// listRetryPolicies returns the effective retry policy per task type,
+// defaults included.
+func (s *Server) listRetryPolicies(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "system", "read", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if s.Orchestrator == nil {
+		respondError(w, http.StatusConflict, errors.New("orchestrator is not configured"))
+		return
+	}
+	respondJSON(w, http.StatusOK, s.Orchestrator.RetryPolicies())
+}
+
+// setRetryPolicy installs the retry policy for one task type.
+func (s *Server) setRetryPolicy(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "system", "write", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if s.Orchestrator == nil {
+		respondError(w, http.StatusConflict, errors.New("orchestrator is not configured"))
+		return
+	}
+	var policy orchestrator.RetryPolicy
+	if err := decodeJSON(r, &policy); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	taskType := types.TaskType(chi.URLParam(r, "taskType"))
+	if err := s.Orchestrator.SetRetryPolicy(taskType, policy); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]orchestrator.RetryPolicy{string(taskType): policy})
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/rennerdo30/webencode/internal/kernel/s3ingest"
+)
+
+// handleS3Events receives S3 bucket notifications (MinIO/SeaweedFS
+// webhook targets post here) and submits jobs for objects that match an
+// ingest rule.
+func (s *Server) handleS3Events(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "ingest", "create", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	var n s3ingest.Notification
+	if err := decodeJSON(r, &n); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]int{"submitted": s.ObjectIngest.Handle(n)})
+}
+
+type createIngestRuleRequest struct {
+	// Bucket limits the rule to one bucket; empty matches any.
+	Bucket string `json:"bucket,omitempty"`
+	// Prefix and Suffix filter object keys ("incoming/", ".mp4").
+	Prefix string `json:"prefix,omitempty"`
+	Suffix string `json:"suffix,omitempty"`
+	// TemplateID names the job template each matching object launches.
+	TemplateID string `json:"template_id"`
+}
+
+func (s *Server) createIngestRule(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "ingest", "create", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	var req createIngestRuleRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.TemplateID != "" {
+		if _, err := s.Templates.Get(req.TemplateID); err != nil {
+			respondError(w, http.StatusBadRequest, errors.New("unknown template "+req.TemplateID))
+			return
+		}
+	}
+	rule, err := s.ObjectIngest.CreateRule(&s3ingest.Rule{
+		OwnerID:    s.user(r).ID,
+		Bucket:     req.Bucket,
+		Prefix:     req.Prefix,
+		Suffix:     req.Suffix,
+		TemplateID: req.TemplateID,
+	})
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, rule)
+}
+
+func (s *Server) listIngestRules(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "ingest", "list", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, s.ObjectIngest.ListRules(s.scope(r)))
+}
+
+func (s *Server) deleteIngestRule(w http.ResponseWriter, r *http.Request) {
+	rule, err := s.ObjectIngest.GetRule(chi.URLParam(r, "ruleID"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	if err := s.authorize(r, "ingest", "delete", ownerLabels(rule.OwnerID)); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if err := s.ObjectIngest.DeleteRule(rule.ID); err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
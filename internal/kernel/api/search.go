@@ -0,0 +1,149 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rennerdo30/webencode/internal/kernel/assets"
+)
+
+// searchHit is one match across the searchable subsystems.
+type searchHit struct {
+	// Type is the hit's resource kind: job, stream, asset or event.
+	Type   string `json:"type"`
+	ID     string `json:"id"`
+	Title  string `json:"title,omitempty"`
+	Status string `json:"status,omitempty"`
+	UserID string `json:"user_id,omitempty"`
+	Codec  string `json:"codec,omitempty"`
+	// Snippet is the field the query matched against.
+	Snippet   string    `json:"snippet,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// searchResponse carries the hits plus facet counts over the full
+// (pre-query) candidate set, so a UI can offer drill-down filters.
+type searchResponse struct {
+	Total  int                       `json:"total"`
+	Hits   []searchHit               `json:"hits"`
+	Facets map[string]map[string]int `json:"facets"`
+}
+
+// search runs a query across jobs, streams, assets and the event log.
+// It scans the kernel's in-memory state directly; an external index
+// (Postgres FTS, OpenSearch) would slot in behind this one handler.
+// Filters: ?query= substring, ?type=, ?status=, ?user_id=, ?codec=,
+// ?from=/?to= RFC 3339 bounds on creation time.
+func (s *Server) search(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "search", "read", ownerLabels(s.user(r).ID)); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	q := r.URL.Query()
+	query := strings.ToLower(q.Get("query"))
+	typeFilter := q.Get("type")
+	statusFilter := q.Get("status")
+	userFilter := q.Get("user_id")
+	codecFilter := q.Get("codec")
+	var from, to time.Time
+	var err error
+	if v := q.Get("from"); v != "" {
+		if from, err = time.Parse(time.RFC3339, v); err != nil {
+			respondError(w, http.StatusBadRequest, errors.New("from must be RFC 3339"))
+			return
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		if to, err = time.Parse(time.RFC3339, v); err != nil {
+			respondError(w, http.StatusBadRequest, errors.New("to must be RFC 3339"))
+			return
+		}
+	}
+	if scope := s.scope(r); scope != "" {
+		userFilter = scope
+	}
+
+	var candidates []searchHit
+	for _, j := range s.Jobs.List("") {
+		candidates = append(candidates, searchHit{
+			Type: "job", ID: j.ID, Title: j.Title, Status: string(j.Status),
+			UserID: j.UserID, Snippet: j.SourceURL, CreatedAt: j.CreatedAt,
+		})
+	}
+	for _, st := range s.Streams.List("") {
+		candidates = append(candidates, searchHit{
+			Type: "stream", ID: st.ID, Title: st.Title, Status: string(st.Status),
+			UserID: st.UserID, CreatedAt: st.CreatedAt,
+		})
+	}
+	if s.Assets != nil {
+		for _, a := range s.Assets.Search(assets.Filter{}) {
+			candidates = append(candidates, searchHit{
+				Type: "asset", ID: a.ID, Title: a.Title, Status: string(a.Kind),
+				UserID: a.OwnerID, Codec: a.VideoCodec,
+				Snippet: a.Location, CreatedAt: a.CreatedAt,
+			})
+		}
+	}
+	for _, ev := range s.Events.List("", 1000) {
+		candidates = append(candidates, searchHit{
+			Type: "event", ID: ev.ID, Title: ev.Type,
+			Snippet: ev.Message, CreatedAt: ev.At,
+		})
+	}
+
+	resp := searchResponse{
+		Hits: []searchHit{},
+		Facets: map[string]map[string]int{
+			"type":   {},
+			"status": {},
+			"user":   {},
+			"codec":  {},
+		},
+	}
+	for _, h := range candidates {
+		// Events carry no owner; everyone scoped to an owner skips them.
+		if userFilter != "" && h.UserID != userFilter {
+			continue
+		}
+		if !from.IsZero() && h.CreatedAt.Before(from) {
+			continue
+		}
+		if !to.IsZero() && h.CreatedAt.After(to) {
+			continue
+		}
+		// Facets count everything surviving scope and date filters, so
+		// the UI can show what the other facet values would yield.
+		bumpFacet(resp.Facets["type"], h.Type)
+		bumpFacet(resp.Facets["status"], h.Status)
+		bumpFacet(resp.Facets["user"], h.UserID)
+		bumpFacet(resp.Facets["codec"], h.Codec)
+		if typeFilter != "" && h.Type != typeFilter {
+			continue
+		}
+		if statusFilter != "" && h.Status != statusFilter {
+			continue
+		}
+		if codecFilter != "" && !strings.EqualFold(h.Codec, codecFilter) {
+			continue
+		}
+		if query != "" &&
+			!strings.Contains(strings.ToLower(h.Title), query) &&
+			!strings.Contains(strings.ToLower(h.Snippet), query) &&
+			!strings.Contains(h.ID, query) {
+			continue
+		}
+		resp.Hits = append(resp.Hits, h)
+	}
+	resp.Total = len(resp.Hits)
+	respondJSON(w, http.StatusOK, resp)
+}
+
+func bumpFacet(m map[string]int, key string) {
+	if key == "" {
+		return
+	}
+	m[key]++
+}
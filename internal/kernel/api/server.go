@@ -0,0 +1,423 @@
+// Package api is the kernel's HTTP API.
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/rennerdo30/webencode/internal/kernel/assets"
+	"github.com/rennerdo30/webencode/internal/kernel/audit"
+	"github.com/rennerdo30/webencode/internal/kernel/auth"
+	"github.com/rennerdo30/webencode/internal/kernel/events"
+	"github.com/rennerdo30/webencode/internal/kernel/feeds"
+	"github.com/rennerdo30/webencode/internal/kernel/jobs"
+	"github.com/rennerdo30/webencode/internal/kernel/keys"
+	"github.com/rennerdo30/webencode/internal/kernel/live"
+	"github.com/rennerdo30/webencode/internal/kernel/orchestrator"
+	"github.com/rennerdo30/webencode/internal/kernel/probe"
+	"github.com/rennerdo30/webencode/internal/kernel/profiles"
+	"github.com/rennerdo30/webencode/internal/kernel/ratelimit"
+	"github.com/rennerdo30/webencode/internal/kernel/restream"
+	"github.com/rennerdo30/webencode/internal/kernel/s3ingest"
+	"github.com/rennerdo30/webencode/internal/kernel/templates"
+	"github.com/rennerdo30/webencode/internal/kernel/urlpolicy"
+	"github.com/rennerdo30/webencode/internal/kernel/usage"
+	"github.com/rennerdo30/webencode/internal/kernel/webhooks"
+	"github.com/rennerdo30/webencode/internal/kernel/workers"
+	"github.com/rennerdo30/webencode/internal/metrics"
+	"github.com/rennerdo30/webencode/internal/pluginmgr"
+	"github.com/rennerdo30/webencode/internal/trace"
+	"github.com/rennerdo30/webencode/pkg/plugin"
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// Server bundles the kernel subsystems the HTTP handlers talk to.
+type Server struct {
+	Authorizer auth.Authorizer
+	// Policy is the editable rule engine backing Authorizer when
+	// authorization is not delegated to an external engine.
+	Policy    *auth.PolicyAuthorizer
+	Streams   *live.Registry
+	Profiles  *profiles.Registry
+	Restreams *restream.Manager
+	Archiver  *live.Archiver
+	Events    *events.Log
+	Emergency *live.EmergencyMonitor
+	ABR       *live.ABRManager
+	Jobs      *jobs.Manager
+	// Batches groups jobs submitted via POST /v1/jobs/batch.
+	Batches *jobs.Batches
+	// Probes runs and caches deep media info probes.
+	Probes *probe.Store
+	// Assets catalogs ingested sources and produced outputs.
+	Assets *assets.Catalog
+	// Usage meters per-user consumption and holds quotas.
+	Usage *usage.Accountant
+	// Pricing feeds pre-submission cost estimates.
+	Pricing Pricing
+	// HLSKeys serves segment-encryption keys to players; nil disables
+	// key delivery.
+	HLSKeys keys.Provider
+	Workers *workers.Manager
+	// Keys issues and validates API keys for machine clients.
+	Keys *auth.KeyStore
+	// Sessions tracks bearer-token sessions so logout and admin
+	// termination actually revoke them.
+	Sessions *auth.SessionStore
+	// Webhooks delivers events to registered HTTP endpoints.
+	Webhooks *webhooks.Manager
+	// Audit records mutating API calls; nil disables audit logging.
+	Audit *audit.Log
+	// RateLimiter charges requests against per-caller buckets; nil
+	// disables rate limiting.
+	RateLimiter ratelimit.Limiter
+	// RateLimits configures the limiter. Read it through rateLimits();
+	// config reload swaps it at runtime via SetRateLimits.
+	RateLimits RateLimits
+	// ReloadConfig re-reads the config file and applies the
+	// safe-to-change settings; nil disables the reload endpoint.
+	ReloadConfig func()
+	// DrainWorker asks one worker to stop accepting tasks ahead of
+	// scale-down; nil disables the drain endpoint.
+	DrainWorker func(workerID string) error
+	// SourceURLs validates user-supplied source, feed and webhook URLs
+	// against the anti-SSRF policy; nil skips validation.
+	SourceURLs *urlpolicy.Policy
+	// Templates stores reusable job templates.
+	Templates *templates.Registry
+	// Feeds watches RSS/Atom feeds for automated republish pipelines.
+	Feeds *feeds.Watcher
+	// ObjectIngest turns S3 bucket notifications into job submissions.
+	ObjectIngest *s3ingest.Listener
+	// Orchestrator dispatches tasks for submitted jobs; nil when no bus
+	// is configured (jobs then stay queued).
+	Orchestrator *orchestrator.Orchestrator
+	// Storage resolves the configured storage plugin; nil when none is
+	// configured.
+	Storage func() (plugin.StorageService, error)
+	// StorageBackends maps backend aliases to plugin names when several
+	// storage backends are active at once.
+	StorageBackends map[string]string
+	// StorageFor resolves a storage backend alias; nil when no aliases
+	// are configured.
+	StorageFor func(alias string) (plugin.StorageService, error)
+	// Live resolves the configured live plugin; nil when none is
+	// configured.
+	Live func() (plugin.LiveService, error)
+	// Publisher resolves a publisher plugin by name; nil when none are
+	// configured.
+	Publisher func(name string) (plugin.PublisherService, error)
+	// Auth resolves the configured auth plugin; nil leaves requests
+	// anonymous.
+	Auth func() (plugin.AuthService, error)
+	// Plugins reports plugin deployment state for drift inspection.
+	Plugins func() []pluginmgr.Status
+	// ReloadPlugin restarts one plugin subprocess; nil disables the
+	// reload endpoint.
+	ReloadPlugin func(name string) error
+	// PluginSchema, PluginConfig and SetPluginConfig manage kernel-held
+	// plugin configuration; nil disables the config endpoints.
+	PluginSchema    func(name string) ([]plugin.ConfigField, error)
+	PluginConfig    func(name string) (map[string]string, error)
+	SetPluginConfig func(name string, values map[string]string) error
+	// PluginCatalog and InstallPlugin expose the remote plugin
+	// registry; nil when no registry is configured.
+	PluginCatalog func() ([]pluginmgr.RegistryPlugin, error)
+	InstallPlugin func(name string) error
+	// CodecSupport reports which optional encoders (SVT-AV1, VVC, ...)
+	// the encode host supports; nil disables the codecs endpoint.
+	CodecSupport func() map[string]bool
+	identityMu   sync.Mutex
+	identities   map[string]cachedIdentity
+	// statsMu guards the cached dashboard stats snapshot.
+	statsMu    sync.Mutex
+	statsCache *systemStats
+	// bulkMu guards the in-flight bulk job operations.
+	bulkMu  sync.Mutex
+	bulkOps map[string]*bulkOperation
+	// limitsMu guards RateLimits against concurrent reload.
+	limitsMu sync.Mutex
+	// router backs route-pattern matching in the request validator.
+	router chi.Router
+
+	// BootstrapKey and friends feed worker bootstrap tokens; an empty
+	// key disables token issuance.
+	BootstrapKey       []byte
+	BootstrapNATSURL   string
+	BootstrapPluginDir string
+}
+
+type contextKey string
+
+const userContextKey contextKey = "webencode.user"
+
+// UserFromContext returns the authenticated user attached to a request,
+// or nil.
+func UserFromContext(ctx context.Context) *types.User {
+	u, _ := ctx.Value(userContextKey).(*types.User)
+	return u
+}
+
+// ContextWithUser attaches an authenticated user to a request context.
+func ContextWithUser(ctx context.Context, u *types.User) context.Context {
+	return context.WithValue(ctx, userContextKey, u)
+}
+
+// anonymousAdmin stands in for the request user while no auth plugin is
+// configured, so a fresh install is usable before identity is wired up.
+var anonymousAdmin = &types.User{ID: "anonymous", Username: "anonymous", Role: types.RoleAdmin}
+
+// anonymousUser is the identity of unauthenticated requests once an
+// auth plugin IS configured. No built-in policy rule matches its role,
+// so it can do nothing unless an operator explicitly grants it
+// something.
+var anonymousUser = &types.User{ID: "anonymous", Username: "anonymous", Role: "anonymous"}
+
+// user resolves the request identity. Requests that presented no
+// credentials run as the admin stand-in only while no auth plugin is
+// configured; with one configured they hold the unprivileged anonymous
+// identity instead.
+func (s *Server) user(r *http.Request) *types.User {
+	if u := UserFromContext(r.Context()); u != nil {
+		return u
+	}
+	if s.Auth != nil {
+		return anonymousUser
+	}
+	return anonymousAdmin
+}
+
+// ownerLabels builds the request labels for a resource owned by
+// ownerID; empty means unowned (shared).
+func ownerLabels(ownerID string) map[string]string {
+	if ownerID == "" {
+		return nil
+	}
+	return map[string]string{"owner": ownerID}
+}
+
+// scope returns the owner ID list endpoints filter by: empty for admins
+// (everything), otherwise the requesting user's ID.
+func (s *Server) scope(r *http.Request) string {
+	if u := s.user(r); !u.IsAdmin() {
+		return u.ID
+	}
+	return ""
+}
+
+// streamForRequest loads the stream addressed by the URL and authorizes
+// the action on the given resource against the stream's owner (stream
+// sub-resources inherit it). It writes the error response and returns
+// nil when either step fails.
+func (s *Server) streamForRequest(w http.ResponseWriter, r *http.Request, resource, action string) *types.Stream {
+	stream, err := s.Streams.Get(chi.URLParam(r, "streamID"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return nil
+	}
+	if err := s.authorize(r, resource, action, ownerLabels(stream.UserID)); err != nil {
+		respondAuthError(w, err)
+		return nil
+	}
+	return stream
+}
+
+// authorize runs the configured authorizer for one handler invocation.
+// Requests authenticated with an API key are additionally bounded by
+// the key's scopes.
+func (s *Server) authorize(r *http.Request, resource, action string, labels map[string]string) error {
+	if key := apiKeyFromContext(r.Context()); key != nil && !key.Allows(resource, action) {
+		return fmt.Errorf("%w: API key lacks scope %s:%s", auth.ErrForbidden, resource, action)
+	}
+	return s.Authorizer.Authorize(r.Context(), auth.Request{
+		User:     s.user(r),
+		Resource: resource,
+		Action:   action,
+		Labels:   labels,
+	})
+}
+
+// Routes builds the kernel's HTTP handler.
+func (s *Server) Routes() http.Handler {
+	r := chi.NewRouter()
+	s.router = r
+	r.Use(middleware.Recoverer)
+	r.Use(middleware.RealIP)
+	r.Use(trace.Middleware)
+	r.Use(s.apiKeyMiddleware)
+	r.Use(s.authMiddleware)
+	r.Use(s.rateLimitMiddleware)
+	r.Use(s.auditMiddleware)
+	r.Use(s.validateRequest)
+
+	// Prometheus scrape target; intentionally outside /v1 and its
+	// request validation.
+	r.Method(http.MethodGet, "/metrics", metrics.Handler())
+	// Autoscaler scrape target (KEDA metrics-api); same trust boundary
+	// as /metrics.
+	r.Get("/autoscale", s.getAutoscale)
+
+	r.Route("/v1", func(r chi.Router) {
+		r.Route("/apikeys", func(r chi.Router) {
+			r.Get("/", s.listAPIKeys)
+			r.Post("/", s.createAPIKey)
+			r.Delete("/{keyID}", s.deleteAPIKey)
+		})
+		r.Route("/policy/rules", func(r chi.Router) {
+			r.Get("/", s.listPolicyRules)
+			r.Post("/", s.upsertPolicyRule)
+			r.Delete("/{ruleID}", s.deletePolicyRule)
+		})
+		r.Route("/policy/source-domains", func(r chi.Router) {
+			r.Get("/", s.listSourceDomains)
+			r.Put("/{userID}", s.setSourceDomains)
+		})
+		r.Route("/profiles", func(r chi.Router) {
+			r.Get("/", s.listProfiles)
+			r.Get("/codecs", s.listCodecSupport)
+			r.Post("/ladder", s.createProfileLadder)
+			r.Post("/", s.createProfile)
+			r.Get("/{profileID}", s.getProfile)
+			r.Put("/{profileID}", s.updateProfile)
+			r.Delete("/{profileID}", s.deleteProfile)
+		})
+		r.Route("/jobs", func(r chi.Router) {
+			r.Get("/", s.listJobs)
+			r.Post("/", s.createJob)
+			r.Post("/estimate", s.estimateJob)
+			r.Post("/bulk", s.bulkJobs)
+			r.Get("/bulk/{opID}", s.getBulkOperation)
+			r.Post("/batch", s.createJobBatch)
+			r.Get("/batch/{batchID}", s.getJobBatch)
+			r.Post("/batch/{batchID}/cancel", s.cancelJobBatch)
+			r.Post("/batch/{batchID}/retry", s.retryJobBatch)
+			r.Get("/{jobID}", s.getJob)
+			r.Get("/{jobID}/tasks", s.listJobTasks)
+			r.Get("/{jobID}/analytics", s.listJobAnalytics)
+			r.Get("/{jobID}/outputs", s.listJobOutputs)
+			r.Get("/{jobID}/logs/stream", s.streamJobLogs)
+			r.Post("/{jobID}/clone", s.cloneJob)
+		})
+		r.Get("/search", s.search)
+		r.Get("/hls/keys/{keyID}", s.getHLSKey)
+		r.Route("/usage", func(r chi.Router) {
+			r.Get("/", s.listUsage)
+			r.Get("/{userID}", s.getUsage)
+			r.Put("/{userID}/quota", s.setQuota)
+		})
+		r.Route("/audit", func(r chi.Router) {
+			r.Get("/", s.listAuditEntries)
+			r.Get("/export", s.exportAudit)
+		})
+		r.Route("/tasks/dead", func(r chi.Router) {
+			r.Get("/", s.listDeadTasks)
+			r.Get("/{taskID}", s.getDeadTask)
+			r.Post("/{taskID}/replay", s.replayDeadTask)
+		})
+		r.Get("/plugins", s.listPlugins)
+		r.Post("/plugins/{pluginID}/reload", s.reloadPlugin)
+		r.Get("/plugins/{pluginID}/schema", s.getPluginSchema)
+		r.Get("/plugins/{pluginID}/config", s.getPluginConfig)
+		r.Put("/plugins/{pluginID}/config", s.setPluginConfig)
+		r.Get("/plugins/available", s.listAvailablePlugins)
+		r.Post("/plugins/install", s.installPlugin)
+		r.Get("/openapi.json", s.getOpenAPI)
+		r.Post("/system/demo", s.createDemo)
+		r.Get("/system/stats", s.getSystemStats)
+		r.Get("/events", s.listEvents)
+		r.Post("/system/config/reload", s.reloadConfig)
+		r.Get("/system/error-codes", s.listErrorCodes)
+		r.Get("/system/retry-policies", s.listRetryPolicies)
+		r.Put("/system/retry-policies/{taskType}", s.setRetryPolicy)
+		r.Post("/auth/logout", s.logout)
+		r.Get("/ws", s.handleWS)
+		r.Route("/templates", func(r chi.Router) {
+			r.Get("/", s.listTemplates)
+			r.Post("/", s.createTemplate)
+			r.Get("/{templateID}", s.getTemplate)
+			r.Delete("/{templateID}", s.deleteTemplate)
+			r.Post("/{templateID}/launch", s.launchTemplate)
+		})
+		r.Route("/ingest/s3", func(r chi.Router) {
+			r.Post("/", s.handleS3Events)
+			r.Get("/rules", s.listIngestRules)
+			r.Post("/rules", s.createIngestRule)
+			r.Delete("/rules/{ruleID}", s.deleteIngestRule)
+		})
+		r.Route("/feeds", func(r chi.Router) {
+			r.Get("/", s.listFeeds)
+			r.Post("/", s.createFeed)
+			r.Get("/{feedID}", s.getFeed)
+			r.Delete("/{feedID}", s.deleteFeed)
+		})
+		r.Route("/webhooks", func(r chi.Router) {
+			r.Get("/", s.listWebhooks)
+			r.Post("/", s.createWebhook)
+			r.Delete("/{webhookID}", s.deleteWebhook)
+			r.Get("/{webhookID}/deliveries", s.listWebhookDeliveries)
+		})
+		r.Route("/sessions", func(r chi.Router) {
+			r.Get("/", s.listSessions)
+			r.Delete("/{sessionID}", s.deleteSession)
+		})
+		r.Route("/workers", func(r chi.Router) {
+			r.Get("/", s.listWorkers)
+			r.Post("/enroll", s.enrollWorker)
+			r.Post("/bootstrap-token", s.createBootstrapToken)
+			r.Get("/{workerID}", s.getWorker)
+			r.Get("/{workerID}/incidents", s.listWorkerIncidents)
+			r.Post("/{workerID}/benchmark", s.benchmarkWorker)
+			r.Post("/{workerID}/drain", s.drainWorker)
+		})
+		r.Route("/assets", func(r chi.Router) {
+			r.Get("/", s.listAssets)
+			r.Get("/{assetID}", s.getAsset)
+			r.Put("/{assetID}/tags", s.setAssetTags)
+			r.Get("/{assetID}/mediainfo", s.getMediaInfo)
+		})
+		r.Route("/files", func(r chi.Router) {
+			r.Get("/", s.browseFiles)
+			r.Post("/encode", s.encodeFile)
+		})
+		r.Get("/storage/backends", s.listStorageBackends)
+		r.Route("/streams", func(r chi.Router) {
+			r.Get("/", s.listStreams)
+			r.Post("/", s.createStream)
+			r.Route("/{streamID}", func(r chi.Router) {
+				r.Get("/", s.getStream)
+				r.Delete("/", s.deleteStream)
+				r.Post("/start", s.startStream)
+				r.Post("/stop", s.stopStream)
+				r.Post("/ingest/start", s.startIngest)
+				r.Post("/ingest/stop", s.stopIngest)
+				r.Get("/abr", s.getLadder)
+				r.Post("/abr/start", s.startLadder)
+				r.Post("/abr/stop", s.stopLadder)
+				r.Get("/markers", s.listMarkers)
+				r.Post("/markers", s.createMarker)
+				r.Get("/chat-settings", s.getChatSettings)
+				r.Patch("/chat-settings", s.patchChatSettings)
+				r.Get("/clips", s.listClips)
+				r.Post("/clips", s.createClip)
+				r.Get("/telemetry", s.getTelemetry)
+				r.Post("/telemetry", s.reportTelemetry)
+				r.Route("/restreams", func(r chi.Router) {
+					r.Get("/", s.listRestreamTargets)
+					r.Post("/", s.createRestreamTarget)
+					r.Route("/{targetID}", func(r chi.Router) {
+						r.Get("/", s.getRestreamTarget)
+						r.Delete("/", s.deleteRestreamTarget)
+						r.Post("/start", s.startRestreamTarget)
+						r.Post("/stop", s.stopRestreamTarget)
+					})
+				})
+			})
+		})
+	})
+	return r
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// sessions reports a conflict when session tracking is unavailable.
+func (s *Server) sessions(w http.ResponseWriter) (ok bool) {
+	if s.Sessions == nil {
+		respondError(w, http.StatusConflict, errors.New("session tracking not configured"))
+		return false
+	}
+	return true
+}
+
+type logoutRequest struct {
+	// AllSessions revokes every session of the requesting user, not
+	// just the one making this request.
+	AllSessions bool `json:"all_sessions,omitempty"`
+}
+
+// logout revokes the requesting session so its token stops working
+// immediately, regardless of its lifetime at the auth provider.
+func (s *Server) logout(w http.ResponseWriter, r *http.Request) {
+	if !s.sessions(w) {
+		return
+	}
+	var req logoutRequest
+	if r.ContentLength > 0 {
+		if err := decodeJSON(r, &req); err != nil {
+			respondError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	if req.AllSessions {
+		n := s.Sessions.RevokeUser(s.user(r).ID)
+		respondJSON(w, http.StatusOK, map[string]interface{}{"revoked": n})
+		return
+	}
+	sessionID := sessionFromContext(r.Context())
+	if sessionID == "" {
+		respondError(w, http.StatusBadRequest, errors.New("request has no session"))
+		return
+	}
+	if err := s.Sessions.Revoke(sessionID); err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]interface{}{"revoked": 1})
+}
+
+// listSessions lists active sessions, optionally filtered by
+// ?user_id=. Non-admins only see their own.
+func (s *Server) listSessions(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "sessions", "list", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if !s.sessions(w) {
+		return
+	}
+	userID := r.URL.Query().Get("user_id")
+	if scope := s.scope(r); scope != "" {
+		userID = scope
+	}
+	respondJSON(w, http.StatusOK, s.Sessions.List(userID))
+}
+
+// deleteSession terminates one session by ID.
+func (s *Server) deleteSession(w http.ResponseWriter, r *http.Request) {
+	if !s.sessions(w) {
+		return
+	}
+	sess, err := s.Sessions.Get(chi.URLParam(r, "sessionID"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	if err := s.authorize(r, "sessions", "delete", ownerLabels(sess.UserID)); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if err := s.Sessions.Revoke(sess.ID); err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]string{"status": "revoked"})
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// listSourceDomains returns every per-user source domain allowlist.
+func (s *Server) listSourceDomains(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "policy", "list", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if s.SourceURLs == nil {
+		respondError(w, http.StatusConflict, errors.New("URL policy is not configured"))
+		return
+	}
+	respondJSON(w, http.StatusOK, s.SourceURLs.UserDomains())
+}
+
+type setSourceDomainsRequest struct {
+	// Domains the user may submit sources from (exact host or any
+	// subdomain of one); empty lifts the restriction.
+	Domains []string `json:"domains"`
+}
+
+// setSourceDomains replaces one user's source domain allowlist.
+func (s *Server) setSourceDomains(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "policy", "update", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if s.SourceURLs == nil {
+		respondError(w, http.StatusConflict, errors.New("URL policy is not configured"))
+		return
+	}
+	var req setSourceDomainsRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	userID := chi.URLParam(r, "userID")
+	s.SourceURLs.SetUserDomains(userID, req.Domains)
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"user_id": userID,
+		"domains": req.Domains,
+	})
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// statsWindowDays is how far back the per-day job series reaches.
+const statsWindowDays = 30
+
+// statsCacheTTL is how long a computed stats snapshot is served before
+// it is recomputed.
+const statsCacheTTL = 10 * time.Second
+
+// systemStats is the aggregated dashboard snapshot GET
+// /v1/system/stats returns.
+type systemStats struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	// JobsByStatus counts all known jobs; JobsByDay buckets them by
+	// submission day (YYYY-MM-DD) over the last 30 days.
+	JobsByStatus map[string]int            `json:"jobs_by_status"`
+	JobsByDay    map[string]map[string]int `json:"jobs_by_day"`
+	// AverageEncodeSpeed is the mean ffmpeg realtime factor sampled
+	// from worker logs; zero before any encode ran.
+	AverageEncodeSpeed float64 `json:"average_encode_speed"`
+	// Worker fleet utilization: busy is workers currently running tasks.
+	WorkersTotal      int     `json:"workers_total"`
+	WorkersBusy       int     `json:"workers_busy"`
+	WorkerUtilization float64 `json:"worker_utilization"`
+	// StorageBytes sums bytes written across all users.
+	StorageBytes int64 `json:"storage_bytes"`
+	// ActiveStreams counts live streams currently on air.
+	ActiveStreams int `json:"active_streams"`
+}
+
+// getSystemStats serves the cached dashboard aggregation, recomputing
+// it at most every statsCacheTTL.
+func (s *Server) getSystemStats(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "system", "read", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	s.statsMu.Lock()
+	if s.statsCache == nil || time.Since(s.statsCache.GeneratedAt) > statsCacheTTL {
+		s.statsCache = s.computeStats()
+	}
+	stats := s.statsCache
+	s.statsMu.Unlock()
+	respondJSON(w, http.StatusOK, stats)
+}
+
+func (s *Server) computeStats() *systemStats {
+	stats := &systemStats{
+		GeneratedAt:  time.Now().UTC(),
+		JobsByStatus: make(map[string]int),
+		JobsByDay:    make(map[string]map[string]int),
+	}
+	cutoff := stats.GeneratedAt.AddDate(0, 0, -statsWindowDays)
+	for _, j := range s.Jobs.List("") {
+		stats.JobsByStatus[string(j.Status)]++
+		if j.CreatedAt.Before(cutoff) {
+			continue
+		}
+		day := j.CreatedAt.UTC().Format("2006-01-02")
+		if stats.JobsByDay[day] == nil {
+			stats.JobsByDay[day] = make(map[string]int)
+		}
+		stats.JobsByDay[day][string(j.Status)]++
+	}
+	if s.Orchestrator != nil {
+		stats.AverageEncodeSpeed = s.Orchestrator.AverageEncodeSpeed()
+	}
+	stats.WorkersTotal, stats.WorkersBusy = s.Workers.Utilization()
+	if stats.WorkersTotal > 0 {
+		stats.WorkerUtilization = float64(stats.WorkersBusy) / float64(stats.WorkersTotal)
+	}
+	if s.Usage != nil {
+		for _, report := range s.Usage.List() {
+			stats.StorageBytes += report.StorageBytes
+		}
+	}
+	for _, stream := range s.Streams.List("") {
+		if stream.Status == types.StreamStatusLive {
+			stats.ActiveStreams++
+		}
+	}
+	return stats
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/rennerdo30/webencode/internal/kernel/live"
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+type createStreamRequest struct {
+	Title     string `json:"title"`
+	SourceURL string `json:"source_url"`
+	// Archive records the stream while live and submits a VOD job when
+	// it ends.
+	Archive bool `json:"archive"`
+}
+
+func (s *Server) createStream(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "streams", "create", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	var req createStreamRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Title == "" {
+		respondError(w, http.StatusBadRequest, errors.New("title is required"))
+		return
+	}
+	// The archive, ABR and clip paths all feed this URL to kernel-side
+	// ffmpeg, so it goes through the same policy as job sources.
+	if err := s.checkSourceURLs(s.user(r).ID, req.SourceURL); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	stream := s.Streams.Create(s.user(r).ID, req.Title, req.SourceURL, req.Archive)
+	respondJSON(w, http.StatusCreated, stream)
+}
+
+// startStream marks a stream live and, when archiving is enabled, starts
+// the recorder.
+func (s *Server) startStream(w http.ResponseWriter, r *http.Request) {
+	stream := s.streamForRequest(w, r, "streams", "update")
+	if stream == nil {
+		return
+	}
+	if err := s.Streams.SetStatus(stream.ID, types.StreamStatusLive); err != nil {
+		respondError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if stream.Archive && s.Archiver != nil {
+		if err := s.Archiver.StartArchive(stream.ID); err != nil {
+			respondError(w, http.StatusConflict, err)
+			return
+		}
+	}
+	stream, _ = s.Streams.Get(stream.ID)
+	respondJSON(w, http.StatusOK, stream)
+}
+
+// stopStream ends a stream; for archived streams this finalizes the
+// recording and submits the VOD job.
+func (s *Server) stopStream(w http.ResponseWriter, r *http.Request) {
+	stream := s.streamForRequest(w, r, "streams", "update")
+	if stream == nil {
+		return
+	}
+	if err := s.Streams.SetStatus(stream.ID, types.StreamStatusEnded); err != nil {
+		respondError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if stream.Archive && s.Archiver != nil {
+		if _, err := s.Archiver.StopArchive(stream.ID); err != nil {
+			respondError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	stream, _ = s.Streams.Get(stream.ID)
+	respondJSON(w, http.StatusOK, stream)
+}
+
+func (s *Server) listStreams(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "streams", "list", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	q, err := parseListQuery(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	streams := filterStreams(s.Streams.List(s.scope(r)), q)
+	if err := sortStreams(streams, q); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	if !q.paged() {
+		respondJSON(w, http.StatusOK, streams)
+		return
+	}
+	respondJSON(w, http.StatusOK, paginate(streams, func(st *types.Stream) string { return st.ID }, q))
+}
+
+// filterStreams applies the shared collection filters to a stream list.
+func filterStreams(streams []*types.Stream, q listQuery) []*types.Stream {
+	out := streams[:0]
+	for _, st := range streams {
+		if q.Status != "" && string(st.Status) != q.Status {
+			continue
+		}
+		if q.User != "" && st.UserID != q.User {
+			continue
+		}
+		if !q.inRange(st.CreatedAt) || !matchSub(st.SourceURL, q.Source) {
+			continue
+		}
+		out = append(out, st)
+	}
+	return out
+}
+
+// sortStreams orders a stream list by the requested field.
+func sortStreams(streams []*types.Stream, q listQuery) error {
+	switch q.Sort {
+	case "", "created_at":
+		sortSlice(streams, func(a, b *types.Stream) bool { return a.CreatedAt.Before(b.CreatedAt) }, q.Desc)
+	case "status":
+		sortSlice(streams, func(a, b *types.Stream) bool { return a.Status < b.Status }, q.Desc)
+	case "title":
+		sortSlice(streams, func(a, b *types.Stream) bool { return a.Title < b.Title }, q.Desc)
+	default:
+		return fmt.Errorf("invalid sort %q for streams", q.Sort)
+	}
+	return nil
+}
+
+func (s *Server) getStream(w http.ResponseWriter, r *http.Request) {
+	stream := s.streamForRequest(w, r, "streams", "read")
+	if stream == nil {
+		return
+	}
+	respondJSON(w, http.StatusOK, stream)
+}
+
+func (s *Server) deleteStream(w http.ResponseWriter, r *http.Request) {
+	if s.streamForRequest(w, r, "streams", "delete") == nil {
+		return
+	}
+	if err := s.Streams.Delete(chi.URLParam(r, "streamID")); err != nil {
+		if errors.Is(err, live.ErrNotFound) {
+			respondError(w, http.StatusNotFound, err)
+			return
+		}
+		respondError(w, http.StatusInternalServerError, err)
+		return
+	}
+	respondJSON(w, http.StatusNoContent, nil)
+}
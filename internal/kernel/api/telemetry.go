@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/rennerdo30/webencode/internal/kernel/events"
+	"github.com/rennerdo30/webencode/internal/kernel/live"
+)
+
+func (s *Server) reportTelemetry(w http.ResponseWriter, r *http.Request) {
+	if s.streamForRequest(w, r, "streams", "update") == nil {
+		return
+	}
+	var sample live.IngestSample
+	if err := decodeJSON(r, &sample); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	streamID := chi.URLParam(r, "streamID")
+	if err := s.Streams.ReportIngest(streamID, sample); err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	if s.Emergency != nil {
+		s.Emergency.Observe(streamID, sample)
+	}
+	if s.Events != nil {
+		// Fan telemetry into the event log so live subscribers (the
+		// WebSocket API) see it without polling.
+		s.Events.Append(events.Event{
+			Type:     "telemetry.ingest",
+			Resource: streamID,
+			Data: map[string]interface{}{
+				"bitrate_kbps": sample.BitrateKbps,
+				"fps":          sample.FPS,
+			},
+		})
+	}
+	respondJSON(w, http.StatusAccepted, nil)
+}
+
+func (s *Server) getTelemetry(w http.ResponseWriter, r *http.Request) {
+	if s.streamForRequest(w, r, "streams", "read") == nil {
+		return
+	}
+	streamID := chi.URLParam(r, "streamID")
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"samples":   s.Streams.IngestSamples(streamID),
+		"emergency": s.Emergency != nil && s.Emergency.Active(streamID),
+	})
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/rennerdo30/webencode/internal/kernel/templates"
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// createTemplate validates and stores a job template. A template
+// carrying a webhook registers it immediately so launches are already
+// wired for notifications.
+func (s *Server) createTemplate(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "templates", "create", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	var t types.JobTemplate
+	if err := decodeJSON(r, &t); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	for _, id := range append(append([]string{}, t.ProfileIDs...), fanOutProfileIDs(t.FanOut)...) {
+		if _, err := s.Profiles.Get(id); err != nil {
+			respondError(w, http.StatusBadRequest, errors.New("unknown profile "+id))
+			return
+		}
+	}
+	if u := s.user(r); !u.IsAdmin() {
+		t.OwnerID = u.ID
+	}
+	if t.Webhook != nil && s.Webhooks != nil {
+		hook := s.Webhooks.Create(s.user(r).ID, t.Webhook.URL, t.Webhook.Secret, t.Webhook.Events)
+		t.WebhookID = hook.ID
+		// The secret lives in the webhook manager now; don't echo it on
+		// every template read.
+		t.Webhook.Secret = ""
+	}
+	created, err := s.Templates.Create(&t)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, created)
+}
+
+// fanOutProfileIDs collects every profile referenced by fan-out specs.
+func fanOutProfileIDs(specs []types.FanOutSpec) []string {
+	var out []string
+	for _, spec := range specs {
+		out = append(out, spec.ProfileIDs...)
+	}
+	return out
+}
+
+func (s *Server) listTemplates(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "templates", "list", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, s.Templates.List(s.scope(r)))
+}
+
+func (s *Server) getTemplate(w http.ResponseWriter, r *http.Request) {
+	t, err := s.Templates.Get(chi.URLParam(r, "templateID"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	if err := s.authorize(r, "templates", "read", ownerLabels(t.OwnerID)); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) deleteTemplate(w http.ResponseWriter, r *http.Request) {
+	t, err := s.Templates.Get(chi.URLParam(r, "templateID"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	if err := s.authorize(r, "templates", "delete", ownerLabels(t.OwnerID)); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if err := s.Templates.Delete(t.ID); err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	respondJSON(w, http.StatusNoContent, nil)
+}
+
+type launchTemplateRequest struct {
+	SourceURL string `json:"source_url"`
+	Title     string `json:"title,omitempty"`
+}
+
+// launchTemplate creates and dispatches a complete job from a template
+// plus a source URL.
+func (s *Server) launchTemplate(w http.ResponseWriter, r *http.Request) {
+	t, err := s.Templates.Get(chi.URLParam(r, "templateID"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	if err := s.authorize(r, "jobs", "create", ownerLabels(t.OwnerID)); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	var req launchTemplateRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.SourceURL == "" {
+		respondError(w, http.StatusBadRequest, errors.New("source_url is required"))
+		return
+	}
+	if err := s.checkSourceURLs(s.user(r).ID, req.SourceURL); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	title := req.Title
+	if title == "" {
+		title = t.Name
+	}
+	job := s.Jobs.Submit(&types.Job{
+		UserID:     s.user(r).ID,
+		Title:      title,
+		SourceURL:  req.SourceURL,
+		ProfileIDs: t.ProfileIDs,
+		FanOut:     t.FanOut,
+		Publish:    t.Publish,
+		Metadata:   map[string]interface{}{"template_id": t.ID},
+	})
+	job.OutputPrefix = templates.ExpandOutput(t, job)
+	if err := s.Jobs.SetOutputPrefix(job.ID, job.OutputPrefix); err != nil {
+		respondError(w, http.StatusInternalServerError, err)
+		return
+	}
+	s.dispatchJob(w, job)
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/rennerdo30/webencode/internal/kernel/usage"
+)
+
+// usageAccountant guards handlers that need usage metering configured.
+func (s *Server) usageAccountant(w http.ResponseWriter) (ok bool) {
+	if s.Usage == nil {
+		respondError(w, http.StatusConflict, errors.New("usage accounting is not configured"))
+		return false
+	}
+	return true
+}
+
+// listUsage reports consumption per user: everything for admins, the
+// caller's own report otherwise.
+func (s *Server) listUsage(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "usage", "list", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if !s.usageAccountant(w) {
+		return
+	}
+	if scope := s.scope(r); scope != "" {
+		respondJSON(w, http.StatusOK, []usage.Report{s.Usage.Get(scope)})
+		return
+	}
+	respondJSON(w, http.StatusOK, s.Usage.List())
+}
+
+func (s *Server) getUsage(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "userID")
+	if err := s.authorize(r, "usage", "read", ownerLabels(userID)); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if !s.usageAccountant(w) {
+		return
+	}
+	respondJSON(w, http.StatusOK, s.Usage.Get(userID))
+}
+
+// setQuota installs a per-user quota override.
+func (s *Server) setQuota(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "usage", "update", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if !s.usageAccountant(w) {
+		return
+	}
+	var q usage.Quota
+	if err := decodeJSON(r, &q); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	userID := chi.URLParam(r, "userID")
+	s.Usage.SetQuota(userID, q)
+	respondJSON(w, http.StatusOK, s.Usage.Get(userID))
+}
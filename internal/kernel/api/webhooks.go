@@ -0,0 +1,99 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type createWebhookRequest struct {
+	URL string `json:"url"`
+	// Secret signs payloads; empty generates one.
+	Secret string `json:"secret,omitempty"`
+	// Events are event-type prefixes to subscribe to; empty means all.
+	Events []string `json:"events,omitempty"`
+}
+
+// createWebhook registers an endpoint and returns it together with the
+// signing secret — the only time the secret is readable.
+func (s *Server) createWebhook(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "webhooks", "create", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	var req createWebhookRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.URL == "" {
+		respondError(w, http.StatusBadRequest, errors.New("url is required"))
+		return
+	}
+	if err := s.checkSourceURLs(s.user(r).ID, req.URL); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	secret := req.Secret
+	if secret == "" {
+		raw := make([]byte, 24)
+		if _, err := rand.Read(raw); err != nil {
+			respondError(w, http.StatusInternalServerError, err)
+			return
+		}
+		secret = hex.EncodeToString(raw)
+	}
+	hook := s.Webhooks.Create(s.user(r).ID, req.URL, secret, req.Events)
+	respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"webhook": hook,
+		"secret":  secret,
+	})
+}
+
+func (s *Server) listWebhooks(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "webhooks", "list", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, s.Webhooks.List(s.scope(r)))
+}
+
+func (s *Server) deleteWebhook(w http.ResponseWriter, r *http.Request) {
+	hook, err := s.Webhooks.Get(chi.URLParam(r, "webhookID"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	if err := s.authorize(r, "webhooks", "delete", ownerLabels(hook.UserID)); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if err := s.Webhooks.Delete(hook.ID); err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	respondJSON(w, http.StatusNoContent, nil)
+}
+
+// listWebhookDeliveries exposes the per-attempt delivery log for
+// debugging endpoints.
+func (s *Server) listWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	hook, err := s.Webhooks.Get(chi.URLParam(r, "webhookID"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	if err := s.authorize(r, "webhooks", "read", ownerLabels(hook.UserID)); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	deliveries, err := s.Webhooks.Deliveries(hook.ID)
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, deliveries)
+}
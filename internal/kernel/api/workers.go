@@ -0,0 +1,194 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/rennerdo30/webencode/internal/bootstrap"
+	"github.com/rennerdo30/webencode/internal/kernel/auth"
+)
+
+// requireAdmin hard-gates an endpoint on the admin role, regardless of
+// what the policy rules say. Enrollment hands out cluster credentials;
+// a permissive or mistyped custom rule must not be able to open it up.
+func (s *Server) requireAdmin(r *http.Request) error {
+	if u := s.user(r); !u.IsAdmin() {
+		return fmt.Errorf("%w: admin role required", auth.ErrForbidden)
+	}
+	return nil
+}
+
+func (s *Server) listWorkers(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "workers", "list", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, s.Workers.List())
+}
+
+func (s *Server) getWorker(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "workers", "read", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	worker, err := s.Workers.Get(chi.URLParam(r, "workerID"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, worker)
+}
+
+// benchmarkWorker dispatches a standardized encode benchmark to one
+// worker; the scores land on the worker record when it completes.
+func (s *Server) benchmarkWorker(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "workers", "write", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if s.Orchestrator == nil {
+		respondError(w, http.StatusConflict, errors.New("orchestrator is not configured"))
+		return
+	}
+	workerID := chi.URLParam(r, "workerID")
+	if _, err := s.Workers.Get(workerID); err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	task, err := s.Orchestrator.SubmitBenchmark(workerID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err)
+		return
+	}
+	respondJSON(w, http.StatusAccepted, task)
+}
+
+// drainWorker asks one worker to stop accepting tasks and finish its
+// in-flight work — the graceful half of scale-down: drain, wait for the
+// node's running set to empty, then terminate it.
+func (s *Server) drainWorker(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "workers", "write", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if s.DrainWorker == nil {
+		respondError(w, http.StatusConflict, errors.New("worker drain is not configured"))
+		return
+	}
+	workerID := chi.URLParam(r, "workerID")
+	if _, err := s.Workers.Get(workerID); err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	if err := s.DrainWorker(workerID); err != nil {
+		respondError(w, http.StatusInternalServerError, err)
+		return
+	}
+	respondJSON(w, http.StatusAccepted, map[string]string{"status": "draining"})
+}
+
+func (s *Server) listWorkerIncidents(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "workers", "read", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	incidents, err := s.Workers.Incidents(chi.URLParam(r, "workerID"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, incidents)
+}
+
+type enrollWorkerRequest struct {
+	Hostname string            `json:"hostname,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+}
+
+// enrollWorker issues a worker identity and auth token for nodes joining
+// without a bootstrap token. Admin-only: an enrolled worker receives
+// other users' tasks, presigned source URLs and content keys.
+func (s *Server) enrollWorker(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "workers", "create", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if err := s.requireAdmin(r); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	var req enrollWorkerRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	enrollment, err := s.Workers.Enroll(req.Hostname, req.Labels)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, enrollment)
+}
+
+type bootstrapTokenRequest struct {
+	Labels map[string]string `json:"labels,omitempty"`
+	// TTL is how long the token stays valid; defaults to 1h.
+	TTL string `json:"ttl,omitempty"`
+}
+
+// createBootstrapToken issues an encrypted join token for a new worker.
+// Admin-only: the token carries the bus URL and credentials.
+func (s *Server) createBootstrapToken(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "workers", "create", nil); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if err := s.requireAdmin(r); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	if len(s.BootstrapKey) == 0 {
+		respondError(w, http.StatusConflict, errors.New("no bootstrap key configured"))
+		return
+	}
+	var req bootstrapTokenRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	ttl := time.Hour
+	if req.TTL != "" {
+		parsed, err := time.ParseDuration(req.TTL)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, err)
+			return
+		}
+		ttl = parsed
+	}
+	// Joining via bootstrap token enrolls the worker up front so its
+	// bus messages authenticate from the first heartbeat.
+	enrollment, err := s.Workers.Enroll("", req.Labels)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err)
+		return
+	}
+	now := time.Now().UTC()
+	token, err := bootstrap.Seal(bootstrap.Token{
+		NATSURL:     s.BootstrapNATSURL,
+		PluginDir:   s.BootstrapPluginDir,
+		WorkerID:    enrollment.WorkerID,
+		WorkerToken: enrollment.Token,
+		Labels:      req.Labels,
+		IssuedAt:    now,
+		ExpiresAt:   now.Add(ttl),
+	}, s.BootstrapKey)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, map[string]string{"token": token})
+}
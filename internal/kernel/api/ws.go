@@ -0,0 +1,129 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/rennerdo30/webencode/internal/kernel/events"
+)
+
+// wsUpgrader upgrades /v1/ws requests. Origins are not restricted: the
+// API authenticates by token, not cookie, so cross-origin pages gain
+// nothing from opening a socket.
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+	CheckOrigin:     func(*http.Request) bool { return true },
+}
+
+// wsSubscribe is the client-to-server control message. Sending one
+// replaces the connection's filter; a connection starts subscribed to
+// every topic.
+type wsSubscribe struct {
+	// Topics are event-type prefixes ("task", "worker", "telemetry",
+	// "live", ...); empty means all.
+	Topics []string `json:"topics,omitempty"`
+	// Resource narrows delivery to events about one resource ID.
+	Resource string `json:"resource,omitempty"`
+}
+
+// wsEnvelope is the server-to-client event frame.
+type wsEnvelope struct {
+	Topic string       `json:"topic"`
+	Event events.Event `json:"event"`
+}
+
+// handleWS multiplexes the event log over one WebSocket: job and task
+// progress, worker status, live telemetry, and notifications all arrive
+// on a single connection, filtered by client-supplied subscriptions —
+// cheaper for frontends and TUIs than one streaming connection per
+// topic.
+func (s *Server) handleWS(w http.ResponseWriter, r *http.Request) {
+	if err := s.authorize(r, "events", "read", ownerLabels(s.user(r).ID)); err != nil {
+		respondAuthError(w, err)
+		return
+	}
+	// Non-admin connections only receive events about their own jobs
+	// and streams, like GET /v1/events.
+	scope := s.scope(r)
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var mu sync.Mutex
+	var filter wsSubscribe
+
+	// Reader: consume subscribe messages until the peer goes away.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			var sub wsSubscribe
+			if err := conn.ReadJSON(&sub); err != nil {
+				return
+			}
+			mu.Lock()
+			filter = sub
+			mu.Unlock()
+		}
+	}()
+
+	ch, cancel := s.Events.Subscribe()
+	defer cancel()
+
+	ping := time.NewTicker(30 * time.Second)
+	defer ping.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second)); err != nil {
+				return
+			}
+		case e := <-ch:
+			if !s.eventVisible(scope, e) {
+				continue
+			}
+			mu.Lock()
+			match := matchesSubscription(filter, e)
+			mu.Unlock()
+			if !match {
+				continue
+			}
+			conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			if err := conn.WriteJSON(wsEnvelope{Topic: eventTopic(e), Event: e}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// eventTopic is the first dotted segment of the event type.
+func eventTopic(e events.Event) string {
+	topic, _, _ := strings.Cut(e.Type, ".")
+	return topic
+}
+
+// matchesSubscription applies a connection's filter to one event.
+func matchesSubscription(sub wsSubscribe, e events.Event) bool {
+	if sub.Resource != "" && e.Resource != sub.Resource {
+		return false
+	}
+	if len(sub.Topics) == 0 {
+		return true
+	}
+	topic := eventTopic(e)
+	for _, t := range sub.Topics {
+		if t == topic {
+			return true
+		}
+	}
+	return false
+}
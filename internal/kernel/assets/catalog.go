@@ -0,0 +1,289 @@
+// Package assets catalogs every source a job ingests and every output
+// it produces, enriched with probe metadata and searchable by it.
+package assets
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rennerdo30/webencode/internal/kernel/probe"
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// ErrNotFound is returned for unknown asset IDs.
+var ErrNotFound = errors.New("asset not found")
+
+// Kind distinguishes ingested sources from produced outputs.
+type Kind string
+
+const (
+	KindSource Kind = "source"
+	KindOutput Kind = "output"
+)
+
+// Asset is one catalogued media file with the probe-derived summary the
+// search filters run against.
+type Asset struct {
+	ID      string `json:"id"`
+	Kind    Kind   `json:"kind"`
+	OwnerID string `json:"owner_id,omitempty"`
+	// Location is the source URL or the output's storage key.
+	Location string   `json:"location"`
+	Title    string   `json:"title,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	// JobIDs links the asset to every job that ingested or produced it.
+	JobIDs []string `json:"job_ids,omitempty"`
+
+	// Probe-derived summary; zero until enrichment has run.
+	DurationSec float64 `json:"duration_sec,omitempty"`
+	Format      string  `json:"format,omitempty"`
+	VideoCodec  string  `json:"video_codec,omitempty"`
+	AudioCodec  string  `json:"audio_codec,omitempty"`
+	Width       int     `json:"width,omitempty"`
+	Height      int     `json:"height,omitempty"`
+	AudioTracks int     `json:"audio_tracks,omitempty"`
+	SizeBytes   int64   `json:"size_bytes,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Filter narrows a catalog search. Zero values match everything.
+type Filter struct {
+	// OwnerID limits results to one owner; empty sees all.
+	OwnerID string
+	// Query substring-matches title, location and tags.
+	Query string
+	Kind  Kind
+	// Codec matches either the video or the audio codec.
+	Codec string
+	Tag   string
+	// MinDurationSec excludes assets shorter than this (and assets not
+	// yet enriched, whose duration is unknown).
+	MinDurationSec float64
+}
+
+// Catalog is the in-memory asset index. Sources registered while a
+// probe store is attached are enriched asynchronously.
+type Catalog struct {
+	// Probes, when set, enriches source assets with ffprobe metadata in
+	// the background.
+	Probes *probe.Store
+
+	mu     sync.RWMutex
+	assets map[string]*Asset
+	// byLocation dedupes: re-ingesting the same source links the new job
+	// to the existing asset instead of minting a duplicate.
+	byLocation map[string]string
+}
+
+// NewCatalog returns an empty asset catalog.
+func NewCatalog() *Catalog {
+	return &Catalog{
+		assets:     make(map[string]*Asset),
+		byLocation: make(map[string]string),
+	}
+}
+
+// RegisterSource records a job's input, reusing the existing asset when
+// the location is already catalogued.
+func (c *Catalog) RegisterSource(job *types.Job) *Asset {
+	c.mu.Lock()
+	if id, ok := c.byLocation[job.SourceURL]; ok {
+		a := c.assets[id]
+		a.JobIDs = appendUnique(a.JobIDs, job.ID)
+		a.UpdatedAt = time.Now().UTC()
+		copied := *a
+		c.mu.Unlock()
+		return &copied
+	}
+	now := time.Now().UTC()
+	a := &Asset{
+		ID:        uuid.NewString(),
+		Kind:      KindSource,
+		OwnerID:   job.UserID,
+		Location:  job.SourceURL,
+		Title:     job.Title,
+		JobIDs:    []string{job.ID},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	c.assets[a.ID] = a
+	c.byLocation[a.Location] = a.ID
+	copied := *a
+	c.mu.Unlock()
+	if c.Probes != nil {
+		go c.enrich(a.ID, a.Location)
+	}
+	return &copied
+}
+
+// RegisterOutput records one produced artifact, summarized from the
+// encode parameters the worker reported.
+func (c *Catalog) RegisterOutput(job *types.Job, artifact types.Artifact) *Asset {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if id, ok := c.byLocation[artifact.Key]; ok {
+		a := c.assets[id]
+		a.JobIDs = appendUnique(a.JobIDs, job.ID)
+		a.UpdatedAt = time.Now().UTC()
+		copied := *a
+		return &copied
+	}
+	now := time.Now().UTC()
+	a := &Asset{
+		ID:         uuid.NewString(),
+		Kind:       KindOutput,
+		OwnerID:    job.UserID,
+		Location:   artifact.Key,
+		Title:      job.Title,
+		JobIDs:     []string{job.ID},
+		VideoCodec: artifact.VideoCodec,
+		AudioCodec: artifact.AudioCodec,
+		Width:      artifact.Width,
+		Height:     artifact.Height,
+		SizeBytes:  artifact.SizeBytes,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	c.assets[a.ID] = a
+	c.byLocation[a.Location] = a.ID
+	copied := *a
+	return &copied
+}
+
+// enrich probes a source and folds the report into the asset. Probe
+// failures leave the asset unenriched; search still finds it by name.
+func (c *Catalog) enrich(id, location string) {
+	result, err := c.Probes.Probe(id, location)
+	if err != nil || result.Report == nil {
+		return
+	}
+	report := result.Report
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	a, ok := c.assets[id]
+	if !ok {
+		return
+	}
+	a.DurationSec = report.Container.DurationSec
+	a.Format = report.Container.Format
+	a.SizeBytes = report.Container.SizeBytes
+	for _, t := range report.Tracks {
+		switch t.Type {
+		case "video":
+			if a.VideoCodec == "" {
+				a.VideoCodec = t.Codec
+				a.Width = t.Width
+				a.Height = t.Height
+			}
+		case "audio":
+			a.AudioTracks++
+			if a.AudioCodec == "" {
+				a.AudioCodec = t.Codec
+			}
+		}
+	}
+	a.UpdatedAt = time.Now().UTC()
+}
+
+// Get returns the asset with the given ID.
+func (c *Catalog) Get(id string) (*Asset, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	a, ok := c.assets[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	copied := *a
+	return &copied, nil
+}
+
+// SetTags replaces an asset's tags.
+func (c *Catalog) SetTags(id string, tags []string) (*Asset, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	a, ok := c.assets[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	a.Tags = append([]string(nil), tags...)
+	a.UpdatedAt = time.Now().UTC()
+	copied := *a
+	return &copied, nil
+}
+
+// Search returns matching assets, newest first.
+func (c *Catalog) Search(f Filter) []*Asset {
+	c.mu.RLock()
+	out := make([]*Asset, 0)
+	for _, a := range c.assets {
+		if !matches(a, f) {
+			continue
+		}
+		copied := *a
+		out = append(out, &copied)
+	}
+	c.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out
+}
+
+func matches(a *Asset, f Filter) bool {
+	if f.OwnerID != "" && a.OwnerID != f.OwnerID {
+		return false
+	}
+	if f.Kind != "" && a.Kind != f.Kind {
+		return false
+	}
+	if f.Codec != "" && !strings.EqualFold(a.VideoCodec, f.Codec) && !strings.EqualFold(a.AudioCodec, f.Codec) {
+		return false
+	}
+	if f.MinDurationSec > 0 && a.DurationSec < f.MinDurationSec {
+		return false
+	}
+	if f.Tag != "" && !containsFold(a.Tags, f.Tag) {
+		return false
+	}
+	if f.Query != "" {
+		q := strings.ToLower(f.Query)
+		if !strings.Contains(strings.ToLower(a.Title), q) &&
+			!strings.Contains(strings.ToLower(a.Location), q) &&
+			!tagContains(a.Tags, q) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsFold(list []string, v string) bool {
+	for _, s := range list {
+		if strings.EqualFold(s, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func tagContains(tags []string, q string) bool {
+	for _, t := range tags {
+		if strings.Contains(strings.ToLower(t), q) {
+			return true
+		}
+	}
+	return false
+}
+
+func appendUnique(list []string, v string) []string {
+	for _, s := range list {
+		if s == v {
+			return list
+		}
+	}
+	return append(list, v)
+}
@@ -0,0 +1,129 @@
+// Package audit records every mutating API call for compliance: who
+// did what to which resource, from where, and what the request carried.
+package audit
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Entry is one audited API call.
+type Entry struct {
+	ID string    `json:"id"`
+	At time.Time `json:"at"`
+	// Actor is the authenticated user ID ("anonymous" before auth is
+	// wired up).
+	Actor string `json:"actor"`
+	// Method and Path identify the call; Resource is the API resource
+	// segment ("jobs", "profiles", ...).
+	Method   string `json:"method"`
+	Path     string `json:"path"`
+	Resource string `json:"resource"`
+	RemoteIP string `json:"remote_ip,omitempty"`
+	// Status is the HTTP status the call returned.
+	Status int `json:"status"`
+	// Request is the JSON request body with secret-looking fields
+	// redacted; it doubles as the after-image of the mutation.
+	Request map[string]interface{} `json:"request,omitempty"`
+}
+
+// Log is a bounded in-memory audit log with time-based retention.
+type Log struct {
+	mu      sync.Mutex
+	entries []Entry
+	max     int
+	maxAge  time.Duration
+}
+
+// NewLog returns a log keeping at most max entries (0 means 100000)
+// for at most maxAge (0 keeps entries until the count cap evicts them).
+func NewLog(max int, maxAge time.Duration) *Log {
+	if max <= 0 {
+		max = 100000
+	}
+	return &Log{max: max, maxAge: maxAge}
+}
+
+// secretFields are request keys whose values never reach the audit log.
+var secretFields = []string{"password", "token", "secret", "key", "credential"}
+
+// Redact replaces secret-looking values in a decoded request body.
+func Redact(body map[string]interface{}) map[string]interface{} {
+	for k, v := range body {
+		lower := strings.ToLower(k)
+		for _, s := range secretFields {
+			if strings.Contains(lower, s) {
+				body[k] = "<redacted>"
+				break
+			}
+		}
+		if nested, ok := v.(map[string]interface{}); ok {
+			body[k] = Redact(nested)
+		}
+	}
+	return body
+}
+
+// Append records one audited call, enforcing retention.
+func (l *Log) Append(e Entry) {
+	e.ID = uuid.NewString()
+	if e.At.IsZero() {
+		e.At = time.Now().UTC()
+	}
+	l.mu.Lock()
+	l.entries = append(l.entries, e)
+	l.pruneLocked()
+	l.mu.Unlock()
+}
+
+// pruneLocked enforces the count and age caps. Caller holds l.mu.
+func (l *Log) pruneLocked() {
+	if len(l.entries) > l.max {
+		l.entries = l.entries[len(l.entries)-l.max:]
+	}
+	if l.maxAge <= 0 {
+		return
+	}
+	cutoff := time.Now().Add(-l.maxAge)
+	first := 0
+	for first < len(l.entries) && l.entries[first].At.Before(cutoff) {
+		first++
+	}
+	l.entries = l.entries[first:]
+}
+
+// Filter selects audit entries for listing and export. Zero values
+// match everything.
+type Filter struct {
+	Since    time.Time
+	Until    time.Time
+	Actor    string
+	Resource string
+}
+
+// List returns matching entries, oldest first.
+func (l *Log) List(f Filter) []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pruneLocked()
+	out := make([]Entry, 0)
+	for _, e := range l.entries {
+		if !f.Since.IsZero() && e.At.Before(f.Since) {
+			continue
+		}
+		if !f.Until.IsZero() && e.At.After(f.Until) {
+			continue
+		}
+		if f.Actor != "" && e.Actor != f.Actor {
+			continue
+		}
+		if f.Resource != "" && e.Resource != f.Resource {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out
+}
@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// keyPrefix marks WebEncode API keys so they are recognizable in logs
+// and secret scanners.
+const keyPrefix = "wek_"
+
+// APIKey is one personal access token for machine clients. Only the
+// SHA-256 hash of the secret is stored; the plaintext is shown once at
+// issuance.
+type APIKey struct {
+	ID     string `json:"id"`
+	UserID string `json:"user_id"`
+	// Name is a human label, e.g. "ci-uploader".
+	Name string `json:"name"`
+	// Prefix is the first characters of the secret, for identifying keys
+	// without revealing them.
+	Prefix string `json:"prefix"`
+	// Scopes limit what the key may do, as "resource:action" pairs with
+	// "*" wildcards (e.g. "jobs:create", "streams:*"). Empty means the
+	// key inherits everything its user can do.
+	Scopes     []string   `json:"scopes,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+
+	// user is the identity snapshot requests authenticated by this key
+	// run as.
+	user types.User
+}
+
+// User returns the identity the key authenticates as.
+func (k *APIKey) User() *types.User {
+	u := k.user
+	return &u
+}
+
+// Allows reports whether the key's scopes permit an action. Keys
+// without scopes defer entirely to the user's policy.
+func (k *APIKey) Allows(resource, action string) bool {
+	if len(k.Scopes) == 0 {
+		return true
+	}
+	want := resource + ":" + action
+	for _, scope := range k.Scopes {
+		if scope == "*" || scope == want || scope == resource+":*" || scope == "*:"+action {
+			return true
+		}
+	}
+	return false
+}
+
+// KeyStore issues and validates API keys. Secrets are stored hashed.
+type KeyStore struct {
+	mu     sync.Mutex
+	keys   map[string]*APIKey
+	byHash map[string]string
+}
+
+// NewKeyStore returns an empty key store.
+func NewKeyStore() *KeyStore {
+	return &KeyStore{
+		keys:   make(map[string]*APIKey),
+		byHash: make(map[string]string),
+	}
+}
+
+// Issue creates a key for the user and returns the plaintext secret —
+// the only time it is available.
+func (s *KeyStore) Issue(user *types.User, name string, scopes []string, ttl time.Duration) (string, *APIKey, error) {
+	for _, scope := range scopes {
+		if scope != "*" && len(strings.SplitN(scope, ":", 2)) != 2 {
+			return "", nil, fmt.Errorf("bad scope %q: want resource:action", scope)
+		}
+	}
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, err
+	}
+	secret := keyPrefix + hex.EncodeToString(raw)
+
+	key := &APIKey{
+		ID:        uuid.NewString(),
+		UserID:    user.ID,
+		Name:      name,
+		Prefix:    secret[:len(keyPrefix)+6],
+		Scopes:    scopes,
+		CreatedAt: time.Now().UTC(),
+		user:      *user,
+	}
+	if ttl > 0 {
+		exp := key.CreatedAt.Add(ttl)
+		key.ExpiresAt = &exp
+	}
+	s.mu.Lock()
+	s.keys[key.ID] = key
+	s.byHash[hashSecret(secret)] = key.ID
+	s.mu.Unlock()
+	return secret, key, nil
+}
+
+// Validate resolves a presented secret to its key, rejecting unknown
+// and expired keys, and records the use.
+func (s *KeyStore) Validate(secret string) (*APIKey, error) {
+	if !strings.HasPrefix(secret, keyPrefix) {
+		return nil, fmt.Errorf("not an API key")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, ok := s.byHash[hashSecret(secret)]
+	if !ok {
+		return nil, fmt.Errorf("unknown API key")
+	}
+	key := s.keys[id]
+	if key.ExpiresAt != nil && time.Now().After(*key.ExpiresAt) {
+		return nil, fmt.Errorf("API key expired")
+	}
+	now := time.Now().UTC()
+	key.LastUsedAt = &now
+	copied := *key
+	return &copied, nil
+}
+
+// ListForUser returns the user's keys, newest first. An empty userID
+// lists every key.
+func (s *KeyStore) ListForUser(userID string) []*APIKey {
+	s.mu.Lock()
+	out := make([]*APIKey, 0)
+	for _, k := range s.keys {
+		if userID != "" && k.UserID != userID {
+			continue
+		}
+		copied := *k
+		out = append(out, &copied)
+	}
+	s.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out
+}
+
+// Get returns one key by ID.
+func (s *KeyStore) Get(id string) (*APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k, ok := s.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("no API key %s", id)
+	}
+	copied := *k
+	return &copied, nil
+}
+
+// Revoke deletes a key immediately.
+func (s *KeyStore) Revoke(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k, ok := s.keys[id]
+	if !ok {
+		return fmt.Errorf("no API key %s", id)
+	}
+	delete(s.keys, id)
+	for hash, keyID := range s.byHash {
+		if keyID == k.ID {
+			delete(s.byHash, hash)
+			break
+		}
+	}
+	return nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,32 @@
+// Package auth contains the kernel's authorization layer. Auth plugins
+// establish identity; everything in this package decides what an
+// authenticated identity is allowed to do.
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// ErrForbidden is returned by an Authorizer when the request is denied.
+// Handlers translate it into a 403 response.
+var ErrForbidden = errors.New("forbidden")
+
+// Request carries everything an authorization backend needs to make a
+// decision about one API call.
+type Request struct {
+	User     *types.User `json:"user"`
+	Resource string      `json:"resource"` // e.g. "jobs", "streams", "plugins"
+	Action   string      `json:"action"`   // e.g. "create", "read", "list", "delete"
+	// Labels carries request-scoped context (resource owner, region, ...)
+	// for policy engines that want more than resource+action.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Authorizer decides whether a request may proceed. Implementations must
+// be safe for concurrent use; the kernel calls Authorize on every request.
+type Authorizer interface {
+	Authorize(ctx context.Context, req Request) error
+}
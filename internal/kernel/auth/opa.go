@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// OPAConfig configures delegation of authorization decisions to an
+// external OPA (or OPA-compatible REST) policy endpoint.
+type OPAConfig struct {
+	// URL of the policy decision endpoint, e.g.
+	// http://opa:8181/v1/data/webencode/authz/allow.
+	URL string
+	// FailOpen controls behaviour when the policy service is unreachable
+	// or returns an error: true allows the request through, false (the
+	// default) denies it.
+	FailOpen bool
+	// CacheTTL is how long a decision for an identical request is reused
+	// before the policy service is asked again. Zero disables caching.
+	CacheTTL time.Duration
+	// Timeout bounds each policy query. Defaults to 5s.
+	Timeout time.Duration
+}
+
+// OPAAuthorizer delegates Authorize decisions to an external policy
+// service, caching results so hot paths don't pay a network round trip
+// per request.
+type OPAAuthorizer struct {
+	cfg    OPAConfig
+	client *http.Client
+
+	mu    sync.Mutex
+	cache map[[32]byte]cachedDecision
+}
+
+type cachedDecision struct {
+	allow   bool
+	expires time.Time
+}
+
+// opaInput is the document sent to the policy endpoint under "input",
+// matching the shape OPA rego policies expect.
+type opaInput struct {
+	Input Request `json:"input"`
+}
+
+type opaResult struct {
+	Result bool `json:"result"`
+}
+
+// NewOPAAuthorizer builds an authorizer backed by the given policy
+// endpoint.
+func NewOPAAuthorizer(cfg OPAConfig) *OPAAuthorizer {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	return &OPAAuthorizer{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		cache:  make(map[[32]byte]cachedDecision),
+	}
+}
+
+// Authorize implements Authorizer by POSTing the request context to the
+// policy endpoint and interpreting the boolean result.
+func (a *OPAAuthorizer) Authorize(ctx context.Context, req Request) error {
+	key, cacheable := a.cacheKey(req)
+	if cacheable {
+		if allow, ok := a.cached(key); ok {
+			return a.decision(req, allow)
+		}
+	}
+
+	allow, err := a.query(ctx, req)
+	if err != nil {
+		if a.cfg.FailOpen {
+			return nil
+		}
+		return fmt.Errorf("%w: policy service unavailable: %v", ErrForbidden, err)
+	}
+	if cacheable {
+		a.store(key, allow)
+	}
+	return a.decision(req, allow)
+}
+
+func (a *OPAAuthorizer) decision(req Request, allow bool) error {
+	if allow {
+		return nil
+	}
+	return fmt.Errorf("%w: denied by policy for %s %s", ErrForbidden, req.Action, req.Resource)
+}
+
+func (a *OPAAuthorizer) query(ctx context.Context, req Request) (bool, error) {
+	body, err := json.Marshal(opaInput{Input: req})
+	if err != nil {
+		return false, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, a.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(httpReq)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("policy endpoint returned %s", resp.Status)
+	}
+	var out opaResult
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return false, err
+	}
+	return out.Result, nil
+}
+
+// cacheKey hashes the request into a fixed-size key. Marshalling failures
+// just mean the decision is not cached.
+func (a *OPAAuthorizer) cacheKey(req Request) ([32]byte, bool) {
+	if a.cfg.CacheTTL <= 0 {
+		return [32]byte{}, false
+	}
+	raw, err := json.Marshal(req)
+	if err != nil {
+		return [32]byte{}, false
+	}
+	return sha256.Sum256(raw), true
+}
+
+func (a *OPAAuthorizer) cached(key [32]byte) (bool, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	d, ok := a.cache[key]
+	if !ok || time.Now().After(d.expires) {
+		delete(a.cache, key)
+		return false, false
+	}
+	return d.allow, true
+}
+
+func (a *OPAAuthorizer) store(key [32]byte, allow bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.cache[key] = cachedDecision{allow: allow, expires: time.Now().Add(a.cfg.CacheTTL)}
+}
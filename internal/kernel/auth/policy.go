@@ -0,0 +1,193 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Effect is what a matching rule does with a request.
+type Effect string
+
+const (
+	EffectAllow Effect = "allow"
+	EffectDeny  Effect = "deny"
+)
+
+// OwnershipAny and OwnershipOwn scope a rule to all resources or to
+// resources owned by the requesting user (matched against the "owner"
+// request label).
+const (
+	OwnershipAny = ""
+	OwnershipOwn = "own"
+)
+
+// Rule is one policy statement. Role, Resource and Action match exactly
+// or via "*"; a zero Effect means allow.
+type Rule struct {
+	ID       string `json:"id"`
+	Role     string `json:"role"`
+	Resource string `json:"resource"`
+	Action   string `json:"action"`
+	// Ownership of "own" restricts the rule to resources whose owner
+	// label equals the requesting user's ID.
+	Ownership string `json:"ownership,omitempty"`
+	Effect    Effect `json:"effect,omitempty"`
+}
+
+// matches reports whether the rule applies to the request.
+func (r *Rule) matches(req Request) bool {
+	if r.Role != "*" && r.Role != string(req.User.Role) {
+		return false
+	}
+	if r.Resource != "*" && r.Resource != req.Resource {
+		return false
+	}
+	if r.Action != "*" && r.Action != req.Action {
+		return false
+	}
+	if r.Ownership == OwnershipOwn {
+		// Own-scoped rules need an owner to compare against. Label-less
+		// requests — the administrative endpoints authorize without
+		// labels — never match them; treating "no owner" as "mine"
+		// would hand every user the admin surface.
+		if owner := req.Labels["owner"]; owner == "" || owner != req.User.ID {
+			return false
+		}
+	}
+	return true
+}
+
+// PolicyAuthorizer is the kernel's policy engine: an ordered rule set
+// evaluated deny-first, defaulting to deny when nothing matches. Rules
+// are editable at runtime through the policy API.
+type PolicyAuthorizer struct {
+	mu    sync.RWMutex
+	rules []Rule
+}
+
+// NewPolicyAuthorizer returns an engine seeded with the given rules.
+func NewPolicyAuthorizer(rules ...Rule) *PolicyAuthorizer {
+	p := &PolicyAuthorizer{}
+	for _, r := range rules {
+		p.Upsert(r)
+	}
+	return p
+}
+
+// DefaultRules is the built-in rule set: admins can do anything;
+// regular users can create and list (lists are scoped to them by the
+// API) and can read, update or delete only what they own. The
+// administrative surfaces — policy rules, plugin management, worker
+// enrollment, quota and system writes — carry explicit deny rules, so
+// the blanket user allows (or a broadly-scoped custom rule) cannot
+// quietly reopen them: deny always wins.
+func DefaultRules() []Rule {
+	return []Rule{
+		{Role: "admin", Resource: "*", Action: "*", Effect: EffectAllow},
+		{Role: "user", Resource: "policy", Action: "*", Effect: EffectDeny},
+		{Role: "user", Resource: "plugins", Action: "*", Effect: EffectDeny},
+		{Role: "user", Resource: "workers", Action: "*", Effect: EffectDeny},
+		{Role: "user", Resource: "usage", Action: "update", Effect: EffectDeny},
+		{Role: "user", Resource: "system", Action: "write", Effect: EffectDeny},
+		{Role: "user", Resource: "system", Action: "create", Effect: EffectDeny},
+		{Role: "user", Resource: "*", Action: "list", Effect: EffectAllow},
+		{Role: "user", Resource: "*", Action: "create", Effect: EffectAllow},
+		// System info (stats, error codes, retry policies) and encoding
+		// profiles are shared reads every user's dashboard needs.
+		{Role: "user", Resource: "system", Action: "read", Effect: EffectAllow},
+		{Role: "user", Resource: "profiles", Action: "read", Effect: EffectAllow},
+		{Role: "user", Resource: "*", Action: "read", Ownership: OwnershipOwn, Effect: EffectAllow},
+		{Role: "user", Resource: "*", Action: "update", Ownership: OwnershipOwn, Effect: EffectAllow},
+		{Role: "user", Resource: "*", Action: "delete", Ownership: OwnershipOwn, Effect: EffectAllow},
+	}
+}
+
+// Authorize implements Authorizer. Deny rules win over allow rules; a
+// request nothing matches is denied.
+func (p *PolicyAuthorizer) Authorize(_ context.Context, req Request) error {
+	if req.User == nil {
+		return fmt.Errorf("%w: no authenticated user", ErrForbidden)
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	allowed := false
+	for i := range p.rules {
+		if !p.rules[i].matches(req) {
+			continue
+		}
+		if p.rules[i].Effect == EffectDeny {
+			return fmt.Errorf("%w: denied by policy rule %s", ErrForbidden, p.rules[i].ID)
+		}
+		allowed = true
+	}
+	if allowed {
+		return nil
+	}
+	return fmt.Errorf("%w: no policy allows %s %s for role %q", ErrForbidden, req.Action, req.Resource, req.User.Role)
+}
+
+// Rules returns a snapshot of the rule set.
+func (p *PolicyAuthorizer) Rules() []Rule {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	out := make([]Rule, len(p.rules))
+	copy(out, p.rules)
+	return out
+}
+
+// Upsert adds a rule, or replaces the rule with the same ID. Rules
+// without an ID get one assigned.
+func (p *PolicyAuthorizer) Upsert(rule Rule) Rule {
+	if rule.Effect == "" {
+		rule.Effect = EffectAllow
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if rule.ID == "" {
+		rule.ID = uuid.NewString()
+		p.rules = append(p.rules, rule)
+		return rule
+	}
+	for i := range p.rules {
+		if p.rules[i].ID == rule.ID {
+			p.rules[i] = rule
+			return rule
+		}
+	}
+	p.rules = append(p.rules, rule)
+	return rule
+}
+
+// Delete removes a rule by ID.
+func (p *PolicyAuthorizer) Delete(id string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i := range p.rules {
+		if p.rules[i].ID == id {
+			p.rules = append(p.rules[:i], p.rules[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("no policy rule %s", id)
+}
+
+// Validate rejects rules the engine cannot evaluate.
+func (r *Rule) Validate() error {
+	if r.Role == "" || r.Resource == "" || r.Action == "" {
+		return fmt.Errorf("role, resource and action are required (use \"*\" for any)")
+	}
+	switch r.Ownership {
+	case OwnershipAny, OwnershipOwn:
+	default:
+		return fmt.Errorf("unknown ownership %q", r.Ownership)
+	}
+	switch r.Effect {
+	case "", EffectAllow, EffectDeny:
+	default:
+		return fmt.Errorf("unknown effect %q", r.Effect)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+func authRequest(role, userID, resource, action string, labels map[string]string) Request {
+	return Request{
+		User:     &types.User{ID: userID, Role: types.Role(role)},
+		Resource: resource,
+		Action:   action,
+		Labels:   labels,
+	}
+}
+
+func TestRuleMatchesOwnership(t *testing.T) {
+	rule := Rule{Role: "user", Resource: "*", Action: "update", Ownership: OwnershipOwn}
+	cases := []struct {
+		name   string
+		labels map[string]string
+		want   bool
+	}{
+		{"own resource", map[string]string{"owner": "u1"}, true},
+		{"someone else's resource", map[string]string{"owner": "u2"}, false},
+		// Label-less requests are the admin surface (policy rules,
+		// plugin config, quotas); an own-scoped rule must never match
+		// them.
+		{"no labels", nil, false},
+		{"empty owner label", map[string]string{"owner": ""}, false},
+	}
+	for _, tc := range cases {
+		req := authRequest("user", "u1", "jobs", "update", tc.labels)
+		if got := rule.matches(req); got != tc.want {
+			t.Errorf("%s: matches = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestDefaultRulesAdminSurface(t *testing.T) {
+	p := NewPolicyAuthorizer(DefaultRules()...)
+	ctx := context.Background()
+
+	// The escalation paths: every one of these authorizes without
+	// labels and must stay admin-only.
+	denied := []struct{ resource, action string }{
+		{"policy", "update"},
+		{"policy", "list"},
+		{"policy", "delete"},
+		{"plugins", "create"},
+		{"plugins", "update"},
+		{"plugins", "read"},
+		{"workers", "create"},
+		{"workers", "write"},
+		{"workers", "list"},
+		{"usage", "update"},
+		{"system", "write"},
+		{"system", "create"},
+	}
+	for _, d := range denied {
+		if err := p.Authorize(ctx, authRequest("user", "u1", d.resource, d.action, nil)); err == nil {
+			t.Errorf("user allowed %s:%s, want deny", d.resource, d.action)
+		}
+		if err := p.Authorize(ctx, authRequest("admin", "a1", d.resource, d.action, nil)); err != nil {
+			t.Errorf("admin denied %s:%s: %v", d.resource, d.action, err)
+		}
+	}
+}
+
+func TestDefaultRulesUserScope(t *testing.T) {
+	p := NewPolicyAuthorizer(DefaultRules()...)
+	ctx := context.Background()
+
+	allowed := []Request{
+		authRequest("user", "u1", "jobs", "create", nil),
+		authRequest("user", "u1", "jobs", "list", nil),
+		authRequest("user", "u1", "jobs", "read", map[string]string{"owner": "u1"}),
+		authRequest("user", "u1", "jobs", "delete", map[string]string{"owner": "u1"}),
+		authRequest("user", "u1", "system", "read", nil),
+		authRequest("user", "u1", "profiles", "read", map[string]string{"owner": "u2"}),
+	}
+	for _, req := range allowed {
+		if err := p.Authorize(ctx, req); err != nil {
+			t.Errorf("user denied %s:%s labels=%v: %v", req.Resource, req.Action, req.Labels, err)
+		}
+	}
+
+	denied := []Request{
+		authRequest("user", "u1", "jobs", "read", map[string]string{"owner": "u2"}),
+		authRequest("user", "u1", "jobs", "update", nil),
+		authRequest("user", "u1", "jobs", "delete", nil),
+		authRequest("user", "u1", "templates", "read", nil),
+	}
+	for _, req := range denied {
+		if err := p.Authorize(ctx, req); err == nil {
+			t.Errorf("user allowed %s:%s labels=%v, want deny", req.Resource, req.Action, req.Labels)
+		} else if !errors.Is(err, ErrForbidden) {
+			t.Errorf("%s:%s: error %v does not wrap ErrForbidden", req.Resource, req.Action, err)
+		}
+	}
+}
+
+func TestDenyBeatsAllow(t *testing.T) {
+	p := NewPolicyAuthorizer(
+		Rule{Role: "user", Resource: "*", Action: "*", Effect: EffectAllow},
+		Rule{Role: "user", Resource: "plugins", Action: "*", Effect: EffectDeny},
+	)
+	ctx := context.Background()
+	if err := p.Authorize(ctx, authRequest("user", "u1", "jobs", "create", nil)); err != nil {
+		t.Errorf("blanket allow denied jobs:create: %v", err)
+	}
+	if err := p.Authorize(ctx, authRequest("user", "u1", "plugins", "create", nil)); err == nil {
+		t.Error("deny rule did not beat the blanket allow")
+	}
+}
+
+func TestDefaultDeny(t *testing.T) {
+	p := NewPolicyAuthorizer(DefaultRules()...)
+	ctx := context.Background()
+	// No rule mentions the anonymous role: everything is denied.
+	if err := p.Authorize(ctx, authRequest("anonymous", "anonymous", "jobs", "list", nil)); err == nil {
+		t.Error("anonymous role allowed jobs:list, want default deny")
+	}
+	if err := p.Authorize(ctx, Request{Resource: "jobs", Action: "list"}); err == nil {
+		t.Error("nil user allowed, want deny")
+	}
+}
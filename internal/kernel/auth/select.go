@@ -0,0 +1,19 @@
+package auth
+
+import (
+	"github.com/rennerdo30/webencode/internal/kernel/config"
+)
+
+// FromConfig returns the authorizer selected by configuration: the OPA
+// delegating authorizer when a policy URL is configured, otherwise the
+// built-in policy engine seeded with the default rules.
+func FromConfig(cfg config.Config) Authorizer {
+	if cfg.OPAURL != "" {
+		return NewOPAAuthorizer(OPAConfig{
+			URL:      cfg.OPAURL,
+			FailOpen: cfg.OPAFailOpen,
+			CacheTTL: cfg.OPACacheTTL,
+		})
+	}
+	return NewPolicyAuthorizer(DefaultRules()...)
+}
@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// ErrSessionNotFound is returned for unknown session IDs.
+var ErrSessionNotFound = errors.New("session not found")
+
+// Session is one authenticated token's kernel-side record. The ID is
+// the token's digest (or JWT jti), so revoking a session invalidates
+// the token even before it expires at the provider.
+type Session struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+	LastSeen  time.Time `json:"last_seen"`
+	Revoked   bool      `json:"revoked,omitempty"`
+}
+
+// SessionStore tracks active sessions and their revocations in memory.
+type SessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]*Session
+}
+
+// NewSessionStore returns an empty session store.
+func NewSessionStore() *SessionStore {
+	return &SessionStore{sessions: make(map[string]*Session)}
+}
+
+// Touch records activity on a session, creating it on first sight. It
+// reports false when the session has been revoked.
+func (s *SessionStore) Touch(id string, u *types.User) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[id]
+	if !ok {
+		now := time.Now().UTC()
+		s.sessions[id] = &Session{
+			ID:        id,
+			UserID:    u.ID,
+			Username:  u.Username,
+			CreatedAt: now,
+			LastSeen:  now,
+		}
+		return true
+	}
+	if sess.Revoked {
+		return false
+	}
+	sess.LastSeen = time.Now().UTC()
+	return true
+}
+
+// Revoke terminates one session.
+func (s *SessionStore) Revoke(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[id]
+	if !ok {
+		return ErrSessionNotFound
+	}
+	sess.Revoked = true
+	return nil
+}
+
+// RevokeUser terminates every session of one user and returns how many
+// were active.
+func (s *SessionStore) RevokeUser(userID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, sess := range s.sessions {
+		if sess.UserID == userID && !sess.Revoked {
+			sess.Revoked = true
+			n++
+		}
+	}
+	return n
+}
+
+// Get returns one session by ID.
+func (s *SessionStore) Get(id string) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[id]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	copied := *sess
+	return &copied, nil
+}
+
+// List returns active sessions, optionally filtered to one user, newest
+// activity first.
+func (s *SessionStore) List(userID string) []*Session {
+	s.mu.Lock()
+	out := make([]*Session, 0, len(s.sessions))
+	for _, sess := range s.sessions {
+		if userID != "" && sess.UserID != userID {
+			continue
+		}
+		if sess.Revoked {
+			continue
+		}
+		copied := *sess
+		out = append(out, &copied)
+	}
+	s.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].LastSeen.After(out[j].LastSeen) })
+	return out
+}
@@ -0,0 +1,316 @@
+// Package config loads kernel configuration, layered as built-in
+// defaults, then an optional flat YAML config file, then environment
+// variables.
+package config
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds kernel-wide settings. Fields are populated by Load from
+// the environment and the config file; zero values mean "feature
+// disabled" or "use the built-in default".
+type Config struct {
+	// ListenAddr is the HTTP API bind address.
+	ListenAddr string
+	// GRPCAddr is the gRPC API bind address; empty disables it.
+	GRPCAddr string
+	// PublicURL is the externally reachable base URL of the kernel API,
+	// used when absolute URLs (e.g. HLS key delivery) are written into
+	// manifests. Empty emits kernel-relative URLs.
+	PublicURL string
+
+	// PluginDir is where plugin executables are discovered.
+	PluginDir string
+	// PluginRegistryURL points at a plugin registry index JSON; empty
+	// disables the catalog and install endpoints.
+	PluginRegistryURL string
+	// AuditMax caps the in-memory audit log size; AuditRetention drops
+	// entries older than the given age (0 keeps them until the cap).
+	AuditMax       int
+	AuditRetention time.Duration
+	// OTLPEndpoint points at an OTLP/HTTP trace collector (e.g.
+	// "http://otel-collector:4318"); empty disables tracing.
+	OTLPEndpoint string
+	// PluginRegistryKey is the hex ed25519 public key of the registry;
+	// when set, every installed binary must carry a valid signature.
+	PluginRegistryKey string
+	// StoragePlugin names the storage plugin used for shared files,
+	// e.g. "storage-s3". Empty disables storage-backed features.
+	StoragePlugin string
+	// StoragePlugins lists storage plugins in priority order; storage
+	// operations route to the first and fail over down the chain.
+	// Overrides StoragePlugin when set.
+	StoragePlugins []string
+	// StorageFailoverOps limits storage failover to these operations
+	// (upload, download, list, delete, presign); empty fails over all
+	// of them.
+	StorageFailoverOps []string
+	// StorageBackends maps backend aliases to storage plugin names
+	// ("hot=storage-s3,archive=storage-b2"); jobs and the files browser
+	// address simultaneous backends by alias.
+	StorageBackends map[string]string
+	// SourceSchemes is the allowlist of source URL schemes; empty keeps
+	// the urlpolicy defaults (http, https, s3).
+	SourceSchemes []string
+	// SourceAllowPrivate permits sources on private, loopback and
+	// link-local addresses — for dev setups whose sources live there.
+	// Off by default to block SSRF against internal services.
+	SourceAllowPrivate bool
+	// SourceFetchTimeout bounds each policy-checked fetch (feeds).
+	SourceFetchTimeout time.Duration
+	// StorageBackendRegions maps backend aliases to region labels
+	// ("hot=eu-west,archive=us-east"); the orchestrator prefers workers
+	// whose region label matches the job's storage backend.
+	StorageBackendRegions map[string]string
+	// RecordDir is the local spool directory for live recordings.
+	RecordDir string
+	// LivePlugin names the live plugin managing stream ingest, e.g.
+	// "live-mediamtx". Empty disables managed ingest.
+	LivePlugin string
+	// AuthPlugin names the auth plugin validating bearer tokens, e.g.
+	// "auth-forge". Empty leaves requests anonymous.
+	AuthPlugin string
+	// AuthPlugins lists auth plugins in priority order; a token is
+	// valid if any plugin in the chain accepts it. Overrides AuthPlugin
+	// when set.
+	AuthPlugins []string
+
+	// NATSURL connects the kernel to the bus shared with workers. Empty
+	// disables worker coordination.
+	NATSURL string
+	// EmbeddedNATS spawns a loopback nats-server when NATSURL is unset,
+	// so one binary runs kernel and bus on a single machine.
+	EmbeddedNATS bool
+	// EmbeddedNATSPort is the loopback port of the embedded bus.
+	EmbeddedNATSPort int
+	// AllInOne also runs a worker inside the kernel process, sharing
+	// its plugin manager — one binary for dev and small installs.
+	AllInOne bool
+	// LeaderElection gates the singleton services (worker monitor,
+	// webhook dispatch, feed polling) behind a bus-wide leadership
+	// lease so multiple kernel replicas can run at once. Requires
+	// JetStream on the bus.
+	LeaderElection bool
+
+	// JetStream provisions per-task-class JetStream streams over the
+	// dispatch subjects for durability and replay; requires JetStream
+	// on the bus. The Stream* fields configure them: max-age and
+	// replication per stream, max-ack-pending on each class's durable
+	// consumer, with per-class overrides as "class=value" pairs
+	// ("transcode=48h").
+	JetStream                    bool
+	StreamMaxAge                 time.Duration
+	StreamReplicas               int
+	StreamMaxAckPending          int
+	StreamMaxAgeOverrides        map[string]string
+	StreamMaxAckPendingOverrides map[string]string
+
+	// RateLimit is the per-caller request budget per window; zero
+	// disables API rate limiting.
+	RateLimit int
+	// RateLimitExpensive is the tighter budget for endpoints that kick
+	// off real work (job submission, uploads).
+	RateLimitExpensive int
+	// RateLimitWindow is the fixed-window length.
+	RateLimitWindow time.Duration
+	// RateLimitOverrides raises or lowers the default budget per
+	// caller, as "caller=limit" pairs.
+	RateLimitOverrides map[string]string
+	// RedisAddr switches the rate limiter to the shared Redis backend
+	// (host:port) so multiple kernel replicas count together.
+	RedisAddr     string
+	RedisPassword string
+	// HeartbeatTimeout is the gap after which a worker is marked
+	// unhealthy.
+	HeartbeatTimeout time.Duration
+	// RequireWorkerAuth rejects bus messages from workers that never
+	// enrolled with the kernel.
+	RequireWorkerAuth bool
+	// ABRRTMPBase is the RTMP base URL live ABR renditions publish back
+	// to, e.g. "rtmp://localhost/abr".
+	ABRRTMPBase string
+	// BootstrapKey is the hex-encoded 32-byte cluster key worker
+	// bootstrap tokens are sealed with.
+	BootstrapKey string
+
+	// SIEMEndpoint, when set, streams audit and error events to a SIEM
+	// ("syslog+tcp://", "syslog+tls://" or an http(s) bulk URL).
+	SIEMEndpoint      string
+	SIEMBatchSize     int
+	SIEMFlushInterval time.Duration
+
+	// QuotaEncodeMinutes and friends are the default per-user quotas
+	// enforced at job submission; zero values are unlimited.
+	QuotaEncodeMinutes  int
+	QuotaStorageBytes   int
+	QuotaEgressBytes    int
+	QuotaConcurrentJobs int
+
+	// TaskMaxRuntime caps task execution time per task type, as
+	// "type=duration" pairs ("transcode=4h,ingest=30m"); the watchdog
+	// fails tasks that overrun. Absent types are uncapped.
+	TaskMaxRuntime map[string]string
+
+	// SchedulerPolicy picks the dispatch order for queued tasks: "fifo",
+	// "fair" (per-user fair share) or "deadline". SchedulerMaxInflight
+	// caps dispatched-but-unfinished tasks; 0 disables queueing and
+	// dispatches immediately (the policy is then irrelevant).
+	SchedulerPolicy      string
+	SchedulerMaxInflight int
+
+	// PricePerEncodeMinute and PricePerGBStored feed job cost estimates;
+	// zero prices estimate durations and sizes but no cost. Currency is
+	// a display label only.
+	PricePerEncodeMinute float64
+	PricePerGBStored     float64
+	PriceCurrency        string
+
+	// OPAURL, when set, delegates authorization decisions to an external
+	// OPA/REST policy endpoint instead of the built-in role checks.
+	OPAURL      string
+	OPAFailOpen bool
+	OPACacheTTL time.Duration
+}
+
+// Load reads configuration from the environment.
+func Load() Config {
+	return Config{
+		ListenAddr:                   envStr("WEBENCODE_LISTEN_ADDR", ":8686"),
+		GRPCAddr:                     envStr("WEBENCODE_GRPC_ADDR", ""),
+		PublicURL:                    envStr("WEBENCODE_PUBLIC_URL", ""),
+		PluginDir:                    envStr("WEBENCODE_PLUGIN_DIR", "plugins"),
+		PluginRegistryURL:            envStr("WEBENCODE_PLUGIN_REGISTRY", ""),
+		PluginRegistryKey:            envStr("WEBENCODE_PLUGIN_REGISTRY_KEY", ""),
+		OTLPEndpoint:                 envStr("WEBENCODE_OTLP_ENDPOINT", ""),
+		AuditMax:                     envInt("WEBENCODE_AUDIT_MAX", 100000),
+		AuditRetention:               envDuration("WEBENCODE_AUDIT_RETENTION", 0),
+		StoragePlugin:                envStr("WEBENCODE_STORAGE_PLUGIN", ""),
+		StoragePlugins:               envList("WEBENCODE_STORAGE_PLUGINS"),
+		StorageFailoverOps:           envList("WEBENCODE_STORAGE_FAILOVER_OPS"),
+		StorageBackends:              envMap("WEBENCODE_STORAGE_BACKENDS"),
+		StorageBackendRegions:        envMap("WEBENCODE_STORAGE_BACKEND_REGIONS"),
+		SourceSchemes:                envList("WEBENCODE_SOURCE_SCHEMES"),
+		SourceAllowPrivate:           envBool("WEBENCODE_SOURCE_ALLOW_PRIVATE", false),
+		SourceFetchTimeout:           envDuration("WEBENCODE_SOURCE_FETCH_TIMEOUT", 30*time.Second),
+		RecordDir:                    envStr("WEBENCODE_RECORD_DIR", "recordings"),
+		LivePlugin:                   envStr("WEBENCODE_LIVE_PLUGIN", ""),
+		AuthPlugin:                   envStr("WEBENCODE_AUTH_PLUGIN", ""),
+		AuthPlugins:                  envList("WEBENCODE_AUTH_PLUGINS"),
+		NATSURL:                      envStr("NATS_URL", ""),
+		EmbeddedNATS:                 envBool("WEBENCODE_EMBEDDED_NATS", false),
+		EmbeddedNATSPort:             envInt("WEBENCODE_EMBEDDED_NATS_PORT", 4222),
+		AllInOne:                     envBool("WEBENCODE_ALL_IN_ONE", false),
+		LeaderElection:               envBool("WEBENCODE_LEADER_ELECTION", false),
+		JetStream:                    envBool("WEBENCODE_JETSTREAM", false),
+		StreamMaxAge:                 envDuration("WEBENCODE_STREAM_MAX_AGE", 24*time.Hour),
+		StreamReplicas:               envInt("WEBENCODE_STREAM_REPLICAS", 1),
+		StreamMaxAckPending:          envInt("WEBENCODE_STREAM_MAX_ACK_PENDING", 0),
+		StreamMaxAgeOverrides:        envMap("WEBENCODE_STREAM_MAX_AGE_OVERRIDES"),
+		StreamMaxAckPendingOverrides: envMap("WEBENCODE_STREAM_MAX_ACK_PENDING_OVERRIDES"),
+		RateLimit:                    envInt("WEBENCODE_RATE_LIMIT", 0),
+		RateLimitExpensive:           envInt("WEBENCODE_RATE_LIMIT_EXPENSIVE", 0),
+		RateLimitWindow:              envDuration("WEBENCODE_RATE_LIMIT_WINDOW", time.Minute),
+		RateLimitOverrides:           envMap("WEBENCODE_RATE_LIMIT_OVERRIDES"),
+		RedisAddr:                    envStr("WEBENCODE_REDIS_ADDR", ""),
+		RedisPassword:                envStr("WEBENCODE_REDIS_PASSWORD", ""),
+		HeartbeatTimeout:             envDuration("WEBENCODE_HEARTBEAT_TIMEOUT", 30*time.Second),
+		ABRRTMPBase:                  envStr("WEBENCODE_ABR_RTMP_BASE", "rtmp://localhost/abr"),
+		BootstrapKey:                 envStr("WEBENCODE_BOOTSTRAP_KEY", ""),
+		SIEMEndpoint:                 envStr("WEBENCODE_SIEM_ENDPOINT", ""),
+		SIEMBatchSize:                envInt("WEBENCODE_SIEM_BATCH_SIZE", 100),
+		SIEMFlushInterval:            envDuration("WEBENCODE_SIEM_FLUSH_INTERVAL", 5*time.Second),
+		QuotaEncodeMinutes:           envInt("WEBENCODE_QUOTA_ENCODE_MINUTES", 0),
+		QuotaStorageBytes:            envInt("WEBENCODE_QUOTA_STORAGE_BYTES", 0),
+		QuotaEgressBytes:             envInt("WEBENCODE_QUOTA_EGRESS_BYTES", 0),
+		QuotaConcurrentJobs:          envInt("WEBENCODE_QUOTA_CONCURRENT_JOBS", 0),
+		TaskMaxRuntime:               envMap("WEBENCODE_TASK_MAX_RUNTIME"),
+		SchedulerPolicy:              envStr("WEBENCODE_SCHEDULER_POLICY", "fifo"),
+		SchedulerMaxInflight:         envInt("WEBENCODE_SCHEDULER_MAX_INFLIGHT", 0),
+		PricePerEncodeMinute:         envFloat("WEBENCODE_PRICE_PER_ENCODE_MINUTE", 0),
+		PricePerGBStored:             envFloat("WEBENCODE_PRICE_PER_GB_STORED", 0),
+		PriceCurrency:                envStr("WEBENCODE_PRICE_CURRENCY", "usd"),
+		OPAURL:                       envStr("WEBENCODE_OPA_URL", ""),
+		OPAFailOpen:                  envBool("WEBENCODE_OPA_FAIL_OPEN", false),
+		OPACacheTTL:                  envDuration("WEBENCODE_OPA_CACHE_TTL", 30*time.Second),
+	}
+}
+
+func envList(key string) []string {
+	var out []string
+	for _, part := range strings.Split(value(key), ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+func envMap(key string) map[string]string {
+	out := make(map[string]string)
+	for _, part := range strings.Split(value(key), ",") {
+		k, v, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found || k == "" || v == "" {
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
+
+func envStr(key, def string) string {
+	if v := value(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func envInt(key string, def int) int {
+	v := value(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+func envFloat(key string, def float64) float64 {
+	v := value(key)
+	if v == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return def
+	}
+	return f
+}
+
+func envBool(key string, def bool) bool {
+	v := value(key)
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return def
+	}
+	return b
+}
+
+func envDuration(key string, def time.Duration) time.Duration {
+	v := value(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return def
+	}
+	return d
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// fileValues holds settings read from the config file; the environment
+// overrides them, so the layering is defaults < file < env.
+var fileValues map[string]string
+
+// FilePath returns the config file to load: WEBENCODE_CONFIG when set,
+// else "webencode.yaml" if it exists, else nothing.
+func FilePath() string {
+	if path := os.Getenv("WEBENCODE_CONFIG"); path != "" {
+		return path
+	}
+	if _, err := os.Stat("webencode.yaml"); err == nil {
+		return "webencode.yaml"
+	}
+	return ""
+}
+
+// LoadFile reads a flat YAML settings file into the file layer. Keys
+// are the setting names in lower case ("listen_addr", "rate_limit");
+// each maps to the corresponding WEBENCODE_* environment variable and
+// takes the same values. Only a flat "key: value" mapping with #
+// comments is supported — the config is a list of settings, not a
+// document tree, and parsing that shape ourselves beats taking on a
+// YAML library dependency.
+func LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || line == "---" {
+			continue
+		}
+		key, value, found := strings.Cut(line, ":")
+		if !found || strings.TrimSpace(key) == "" {
+			return fmt.Errorf("config: %s:%d: expected \"key: value\"", path, lineNo)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 {
+			if (value[0] == '"' && value[len(value)-1] == '"') ||
+				(value[0] == '\'' && value[len(value)-1] == '\'') {
+				value = value[1 : len(value)-1]
+			}
+		}
+		values[envKey(strings.TrimSpace(key))] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	fileValues = values
+	return nil
+}
+
+// envKey maps a file key to its environment variable name:
+// "listen_addr" becomes "WEBENCODE_LISTEN_ADDR". NATS_URL keeps its
+// historical unprefixed name.
+func envKey(key string) string {
+	key = strings.ToUpper(key)
+	if key == "NATS_URL" || strings.HasPrefix(key, "WEBENCODE_") {
+		return key
+	}
+	return "WEBENCODE_" + key
+}
+
+// value resolves one setting through the layers: environment first,
+// then the config file.
+func value(key string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fileValues[key]
+}
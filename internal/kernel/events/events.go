@@ -0,0 +1,118 @@
+// Package events is the kernel's in-process event log. Subsystems append
+// typed events; consumers (API, webhooks, monitors) read or subscribe.
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event is one thing that happened in the system.
+type Event struct {
+	ID string `json:"id"`
+	// Seq orders events monotonically within one kernel run; exporters
+	// use it as a replay cursor.
+	Seq uint64 `json:"seq"`
+	// Type is a dotted event name, e.g. "live.emergency_started".
+	Type string `json:"type"`
+	// Resource identifies the subject, e.g. a stream or job ID.
+	Resource string `json:"resource,omitempty"`
+	Message  string `json:"message,omitempty"`
+	// Data carries event-specific fields.
+	Data map[string]interface{} `json:"data,omitempty"`
+	At   time.Time              `json:"at"`
+}
+
+// Log is a bounded in-memory event log with fan-out to subscribers.
+type Log struct {
+	mu          sync.Mutex
+	events      []Event
+	max         int
+	nextSeq     uint64
+	subscribers map[int]chan Event
+	nextSub     int
+}
+
+// NewLog returns a log keeping at most max events (0 means 10000).
+func NewLog(max int) *Log {
+	if max <= 0 {
+		max = 10000
+	}
+	return &Log{max: max, subscribers: make(map[int]chan Event)}
+}
+
+// Append records an event and fans it out to subscribers. Slow
+// subscribers drop events rather than block producers.
+func (l *Log) Append(e Event) Event {
+	e.ID = uuid.NewString()
+	if e.At.IsZero() {
+		e.At = time.Now().UTC()
+	}
+	l.mu.Lock()
+	l.nextSeq++
+	e.Seq = l.nextSeq
+	l.events = append(l.events, e)
+	if len(l.events) > l.max {
+		l.events = l.events[len(l.events)-l.max:]
+	}
+	for _, ch := range l.subscribers {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+	l.mu.Unlock()
+	return e
+}
+
+// List returns the most recent events, newest last, filtered by resource
+// when resource is non-empty.
+func (l *Log) List(resource string, limit int) []Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make([]Event, 0)
+	for _, e := range l.events {
+		if resource == "" || e.Resource == resource {
+			out = append(out, e)
+		}
+	}
+	if limit > 0 && len(out) > limit {
+		out = out[len(out)-limit:]
+	}
+	return out
+}
+
+// ListAfter returns up to limit events with a sequence number greater
+// than seq, oldest first. Events evicted from the bounded buffer are
+// gone — callers replaying from an old cursor get what is left.
+func (l *Log) ListAfter(seq uint64, limit int) []Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make([]Event, 0)
+	for _, e := range l.events {
+		if e.Seq > seq {
+			out = append(out, e)
+			if limit > 0 && len(out) >= limit {
+				break
+			}
+		}
+	}
+	return out
+}
+
+// Subscribe returns a channel of future events and a cancel func.
+func (l *Log) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, 64)
+	l.mu.Lock()
+	id := l.nextSub
+	l.nextSub++
+	l.subscribers[id] = ch
+	l.mu.Unlock()
+	return ch, func() {
+		l.mu.Lock()
+		delete(l.subscribers, id)
+		l.mu.Unlock()
+	}
+}
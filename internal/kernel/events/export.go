@@ -0,0 +1,167 @@
+package events
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ExportConfig configures the SIEM export pipe.
+type ExportConfig struct {
+	// Endpoint receives the events: "syslog+tcp://host:port",
+	// "syslog+tls://host:port", or an http(s) URL taking NDJSON batches.
+	Endpoint string
+	// BatchSize caps events per delivery; defaults to 100.
+	BatchSize int
+	// FlushInterval is how often pending events are shipped; defaults
+	// to 5s.
+	FlushInterval time.Duration
+	// Cursor resumes the export after the given event sequence number.
+	Cursor uint64
+}
+
+// Exporter ships audit and error events to a SIEM endpoint. It pulls
+// from the event log by cursor, so a slow or down endpoint applies
+// backpressure to the export only — producers are never blocked — and
+// delivery resumes where it stopped.
+type Exporter struct {
+	log *Log
+	cfg ExportConfig
+
+	mu     sync.Mutex
+	cursor uint64
+}
+
+// NewExporter returns an exporter reading from the event log.
+func NewExporter(eventLog *Log, cfg ExportConfig) *Exporter {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 5 * time.Second
+	}
+	return &Exporter{log: eventLog, cfg: cfg, cursor: cfg.Cursor}
+}
+
+// Cursor returns the sequence number of the last delivered event.
+func (e *Exporter) Cursor() uint64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.cursor
+}
+
+// SetCursor rewinds or advances the export position, replaying or
+// skipping buffered events on the next flush.
+func (e *Exporter) SetCursor(seq uint64) {
+	e.mu.Lock()
+	e.cursor = seq
+	e.mu.Unlock()
+}
+
+// Run ships batches until stop is closed. Delivery failures leave the
+// cursor in place and retry on the next tick.
+func (e *Exporter) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(e.cfg.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			e.flush()
+			return
+		case <-ticker.C:
+			e.flush()
+		}
+	}
+}
+
+// flush ships every pending batch, stopping at the first failure.
+func (e *Exporter) flush() {
+	for {
+		batch := e.log.ListAfter(e.Cursor(), e.cfg.BatchSize)
+		if len(batch) == 0 {
+			return
+		}
+		if err := e.deliver(batch); err != nil {
+			log.Printf("events: SIEM export: %v", err)
+			return
+		}
+		e.SetCursor(batch[len(batch)-1].Seq)
+	}
+}
+
+// deliver sends one batch to the configured endpoint.
+func (e *Exporter) deliver(batch []Event) error {
+	u, err := url.Parse(e.cfg.Endpoint)
+	if err != nil {
+		return fmt.Errorf("bad endpoint: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https":
+		return deliverHTTP(e.cfg.Endpoint, batch)
+	case "syslog+tcp":
+		return deliverSyslog(u.Host, false, batch)
+	case "syslog+tls":
+		return deliverSyslog(u.Host, true, batch)
+	default:
+		return fmt.Errorf("unsupported endpoint scheme %q", u.Scheme)
+	}
+}
+
+// deliverHTTP posts the batch as NDJSON.
+func deliverHTTP(endpoint string, batch []Event) error {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, ev := range batch {
+		if err := enc.Encode(ev); err != nil {
+			return err
+		}
+	}
+	resp, err := http.Post(endpoint, "application/x-ndjson", &body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("endpoint returned %s", resp.Status)
+	}
+	return nil
+}
+
+// deliverSyslog writes one RFC 5424 message per event with the event
+// JSON as the payload.
+func deliverSyslog(addr string, useTLS bool, batch []Event) error {
+	var conn net.Conn
+	var err error
+	if useTLS {
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: 10 * time.Second}, "tcp", addr, nil)
+	} else {
+		conn, err = net.DialTimeout("tcp", addr, 10*time.Second)
+	}
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	hostname, _ := os.Hostname()
+	var buf strings.Builder
+	for _, ev := range batch {
+		payload, err := json.Marshal(ev)
+		if err != nil {
+			return err
+		}
+		// <134> is facility local0, severity info.
+		fmt.Fprintf(&buf, "<134>1 %s %s webencode - %s - %s\n",
+			ev.At.Format(time.RFC3339), hostname, ev.ID, payload)
+	}
+	_ = conn.SetWriteDeadline(time.Now().Add(30 * time.Second))
+	_, err = conn.Write([]byte(buf.String()))
+	return err
+}
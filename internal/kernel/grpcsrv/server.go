@@ -0,0 +1,244 @@
+// Package grpcsrv serves the kernel's gRPC API. It fronts the same
+// in-memory managers as the HTTP handlers and enforces the same
+// authorization: callers authenticate with an API key carried in the
+// x-api-key metadata entry, decisions route through the shared
+// Authorizer, and non-admin callers are scoped to their own resources.
+package grpcsrv
+
+import (
+	"context"
+	"log"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/rennerdo30/webencode/internal/kernel/auth"
+	"github.com/rennerdo30/webencode/internal/kernel/jobs"
+	"github.com/rennerdo30/webencode/internal/kernel/live"
+	"github.com/rennerdo30/webencode/internal/kernel/orchestrator"
+	"github.com/rennerdo30/webencode/internal/kernel/profiles"
+	"github.com/rennerdo30/webencode/internal/kernel/urlpolicy"
+	"github.com/rennerdo30/webencode/internal/kernel/workers"
+	"github.com/rennerdo30/webencode/pkg/grpcapi"
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// Server implements grpcapi.KernelServer over the kernel subsystems.
+type Server struct {
+	Jobs     *jobs.Manager
+	Streams  *live.Registry
+	Workers  *workers.Manager
+	Profiles *profiles.Registry
+	// Orchestrator dispatches submitted jobs; nil leaves them queued.
+	Orchestrator *orchestrator.Orchestrator
+	// Authorizer decides what callers may do, shared with the HTTP API.
+	Authorizer auth.Authorizer
+	// Keys validates x-api-key metadata.
+	Keys *auth.KeyStore
+	// SourceURLs validates user-supplied source URLs against the
+	// anti-SSRF policy, shared with the HTTP API; nil skips validation.
+	SourceURLs *urlpolicy.Policy
+	// AuthConfigured mirrors the HTTP API's trust model: once an auth
+	// plugin is configured, calls without a valid API key are rejected;
+	// before that, keyless calls run as the fresh-install admin.
+	AuthConfigured bool
+}
+
+// Serve listens on addr until the listener is closed.
+func (s *Server) Serve(addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	srv := grpc.NewServer(grpc.UnaryInterceptor(s.authInterceptor))
+	grpcapi.RegisterKernelServer(srv, s)
+	log.Printf("kernel: gRPC API on %s", addr)
+	return srv.Serve(lis)
+}
+
+type contextKey string
+
+const (
+	userContextKey contextKey = "webencode.grpc.user"
+	keyContextKey  contextKey = "webencode.grpc.key"
+)
+
+// anonymousAdmin stands in for the caller while no auth plugin is
+// configured, matching the HTTP API's fresh-install behavior.
+var anonymousAdmin = &types.User{ID: "anonymous", Username: "anonymous", Role: types.RoleAdmin}
+
+// authInterceptor establishes the caller identity. A presented API key
+// must validate; with auth configured, a key is mandatory.
+func (s *Server) authInterceptor(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	var secret string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if keys := md.Get("x-api-key"); len(keys) > 0 {
+			secret = keys[0]
+		}
+	}
+	switch {
+	case secret != "":
+		if s.Keys == nil {
+			return nil, status.Error(codes.Unauthenticated, "API keys are not configured")
+		}
+		key, err := s.Keys.Validate(secret)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		ctx = context.WithValue(ctx, keyContextKey, key)
+		ctx = context.WithValue(ctx, userContextKey, key.User())
+	case s.AuthConfigured:
+		return nil, status.Error(codes.Unauthenticated, "x-api-key metadata is required")
+	default:
+		ctx = context.WithValue(ctx, userContextKey, anonymousAdmin)
+	}
+	return handler(ctx, req)
+}
+
+// caller returns the authenticated identity the interceptor attached.
+func caller(ctx context.Context) *types.User {
+	if u, ok := ctx.Value(userContextKey).(*types.User); ok {
+		return u
+	}
+	return anonymousAdmin
+}
+
+// authorize runs the shared authorizer for one call, bounded by the API
+// key's scopes — the same two checks the HTTP handlers make.
+func (s *Server) authorize(ctx context.Context, resource, action string, labels map[string]string) error {
+	if key, ok := ctx.Value(keyContextKey).(*auth.APIKey); ok && !key.Allows(resource, action) {
+		return status.Errorf(codes.PermissionDenied, "API key lacks scope %s:%s", resource, action)
+	}
+	if err := s.Authorizer.Authorize(ctx, auth.Request{
+		User:     caller(ctx),
+		Resource: resource,
+		Action:   action,
+		Labels:   labels,
+	}); err != nil {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	return nil
+}
+
+// ownerLabels builds the request labels for a resource owned by
+// ownerID; empty means unowned.
+func ownerLabels(ownerID string) map[string]string {
+	if ownerID == "" {
+		return nil
+	}
+	return map[string]string{"owner": ownerID}
+}
+
+// checkSourceURLs validates user-supplied URLs against the source URL
+// policy, like the HTTP handlers; a nil policy allows everything.
+func (s *Server) checkSourceURLs(userID string, urls ...string) error {
+	if s.SourceURLs == nil {
+		return nil
+	}
+	for _, u := range urls {
+		if err := s.SourceURLs.Validate(userID, u); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	return nil
+}
+
+// scopeUserID resolves the user ID a call operates as: non-admin
+// callers always act as themselves, whatever the request claims.
+func scopeUserID(ctx context.Context, requested string) string {
+	u := caller(ctx)
+	if !u.IsAdmin() || requested == "" {
+		return u.ID
+	}
+	return requested
+}
+
+func (s *Server) SubmitJob(ctx context.Context, req *grpcapi.SubmitJobRequest) (*types.Job, error) {
+	if err := s.authorize(ctx, "jobs", "create", nil); err != nil {
+		return nil, err
+	}
+	job := req.Job
+	job.UserID = scopeUserID(ctx, job.UserID)
+	urls := []string{job.SourceURL}
+	if job.Edit != nil {
+		urls = append(append(urls, job.Edit.PreRollURLs...), job.Edit.PostRollURLs...)
+	}
+	if err := s.checkSourceURLs(job.UserID, urls...); err != nil {
+		return nil, err
+	}
+	jobProfiles := make([]*types.Profile, 0, len(job.ProfileIDs))
+	for _, id := range job.ProfileIDs {
+		p, err := s.Profiles.Get(id)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown profile %s", id)
+		}
+		jobProfiles = append(jobProfiles, p)
+	}
+	submitted := s.Jobs.Submit(&job)
+	if s.Orchestrator != nil {
+		if err := s.Orchestrator.SubmitJob(submitted, jobProfiles); err != nil {
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+	}
+	return submitted, nil
+}
+
+func (s *Server) GetJob(ctx context.Context, req *grpcapi.GetJobRequest) (*types.Job, error) {
+	job, err := s.Jobs.Get(req.JobID)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err := s.authorize(ctx, "jobs", "read", ownerLabels(job.UserID)); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (s *Server) ListJobs(ctx context.Context, req *grpcapi.ListJobsRequest) (*grpcapi.ListJobsResponse, error) {
+	if err := s.authorize(ctx, "jobs", "list", nil); err != nil {
+		return nil, err
+	}
+	return &grpcapi.ListJobsResponse{Jobs: s.Jobs.List(scopeUserID(ctx, req.UserID))}, nil
+}
+
+func (s *Server) CreateStream(ctx context.Context, req *grpcapi.CreateStreamRequest) (*types.Stream, error) {
+	if err := s.authorize(ctx, "streams", "create", nil); err != nil {
+		return nil, err
+	}
+	if req.Title == "" {
+		return nil, status.Error(codes.InvalidArgument, "title is required")
+	}
+	userID := scopeUserID(ctx, req.UserID)
+	if err := s.checkSourceURLs(userID, req.SourceURL); err != nil {
+		return nil, err
+	}
+	return s.Streams.Create(userID, req.Title, req.SourceURL, req.Archive), nil
+}
+
+func (s *Server) ListStreams(ctx context.Context, req *grpcapi.ListStreamsRequest) (*grpcapi.ListStreamsResponse, error) {
+	if err := s.authorize(ctx, "streams", "list", nil); err != nil {
+		return nil, err
+	}
+	return &grpcapi.ListStreamsResponse{Streams: s.Streams.List(scopeUserID(ctx, req.UserID))}, nil
+}
+
+func (s *Server) GetWorker(ctx context.Context, req *grpcapi.GetWorkerRequest) (*types.Worker, error) {
+	if err := s.authorize(ctx, "workers", "read", nil); err != nil {
+		return nil, err
+	}
+	worker, err := s.Workers.Get(req.WorkerID)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return worker, nil
+}
+
+func (s *Server) ListWorkers(ctx context.Context, _ *grpcapi.ListWorkersRequest) (*grpcapi.ListWorkersResponse, error) {
+	if err := s.authorize(ctx, "workers", "list", nil); err != nil {
+		return nil, err
+	}
+	return &grpcapi.ListWorkersResponse{Workers: s.Workers.List()}, nil
+}
@@ -0,0 +1,102 @@
+package jobs
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// ErrBatchNotFound is returned for unknown batch IDs.
+var ErrBatchNotFound = errors.New("batch not found")
+
+// Batch groups jobs submitted together so they can be tracked,
+// cancelled and retried as one unit.
+type Batch struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id,omitempty"`
+	JobIDs    []string  `json:"job_ids"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BatchProgress is a batch plus aggregate job-state counts, computed
+// from the member jobs at read time.
+type BatchProgress struct {
+	Batch
+	Total     int `json:"total"`
+	Queued    int `json:"queued"`
+	Running   int `json:"running"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+	Cancelled int `json:"cancelled"`
+}
+
+// Batches tracks job batches alongside a job manager.
+type Batches struct {
+	mu      sync.RWMutex
+	jobs    *Manager
+	batches map[string]*Batch
+}
+
+// NewBatches returns an empty batch index over the given job manager.
+func NewBatches(jobs *Manager) *Batches {
+	return &Batches{jobs: jobs, batches: make(map[string]*Batch)}
+}
+
+// Create records a new batch over already-submitted job IDs.
+func (b *Batches) Create(userID string, jobIDs []string) *Batch {
+	batch := &Batch{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		JobIDs:    append([]string(nil), jobIDs...),
+		CreatedAt: time.Now().UTC(),
+	}
+	b.mu.Lock()
+	b.batches[batch.ID] = batch
+	b.mu.Unlock()
+	return batch
+}
+
+// Get returns the batch with the given ID.
+func (b *Batches) Get(id string) (*Batch, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	batch, ok := b.batches[id]
+	if !ok {
+		return nil, ErrBatchNotFound
+	}
+	copied := *batch
+	copied.JobIDs = append([]string(nil), batch.JobIDs...)
+	return &copied, nil
+}
+
+// Progress returns the batch with its jobs bucketed by status.
+func (b *Batches) Progress(id string) (*BatchProgress, error) {
+	batch, err := b.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	p := &BatchProgress{Batch: *batch, Total: len(batch.JobIDs)}
+	for _, jobID := range batch.JobIDs {
+		job, err := b.jobs.Get(jobID)
+		if err != nil {
+			continue
+		}
+		switch job.Status {
+		case types.JobStatusQueued:
+			p.Queued++
+		case types.JobStatusRunning:
+			p.Running++
+		case types.JobStatusCompleted:
+			p.Completed++
+		case types.JobStatusFailed:
+			p.Failed++
+		case types.JobStatusCancelled:
+			p.Cancelled++
+		}
+	}
+	return p, nil
+}
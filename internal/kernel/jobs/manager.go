@@ -0,0 +1,131 @@
+// Package jobs tracks encoding jobs in the kernel.
+package jobs
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rennerdo30/webencode/pkg/errcode"
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// ErrNotFound is returned for unknown job IDs.
+var ErrNotFound = errors.New("job not found")
+
+// Manager is an in-memory index of jobs.
+type Manager struct {
+	mu   sync.RWMutex
+	jobs map[string]*types.Job
+}
+
+// NewManager returns an empty job manager.
+func NewManager() *Manager {
+	return &Manager{jobs: make(map[string]*types.Job)}
+}
+
+// Submit queues a new job.
+func (m *Manager) Submit(job *types.Job) *types.Job {
+	now := time.Now().UTC()
+	job.ID = uuid.NewString()
+	job.Status = types.JobStatusQueued
+	job.CreatedAt = now
+	job.UpdatedAt = now
+	m.mu.Lock()
+	copied := *job
+	m.jobs[job.ID] = &copied
+	m.mu.Unlock()
+	return job
+}
+
+// SetOutputPrefix fills a job's output prefix after submission, for
+// prefixes derived from the assigned job ID.
+func (m *Manager) SetOutputPrefix(id, prefix string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return ErrNotFound
+	}
+	job.OutputPrefix = prefix
+	job.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// SetSourceURL repoints a job's source after submission, for sources
+// materialized by an ingest task.
+func (m *Manager) SetSourceURL(id, sourceURL string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return ErrNotFound
+	}
+	job.SourceURL = sourceURL
+	job.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// Get returns the job with the given ID.
+func (m *Manager) Get(id string) (*types.Job, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	copied := *j
+	return &copied, nil
+}
+
+// List returns jobs newest first, filtered to one owner when ownerID is
+// non-empty.
+func (m *Manager) List(ownerID string) []*types.Job {
+	m.mu.RLock()
+	out := make([]*types.Job, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		if ownerID != "" && j.UserID != ownerID {
+			continue
+		}
+		copied := *j
+		out = append(out, &copied)
+	}
+	m.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out
+}
+
+// Delete removes a job record. Only terminal jobs may be deleted;
+// running work keeps its bookkeeping.
+func (m *Manager) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.jobs[id]
+	if !ok {
+		return ErrNotFound
+	}
+	switch j.Status {
+	case types.JobStatusCompleted, types.JobStatusFailed, types.JobStatusCancelled:
+	default:
+		return errcode.New(errcode.JobNotTerminal, "job is not in a terminal state")
+	}
+	delete(m.jobs, id)
+	return nil
+}
+
+// SetStatus updates a job's state, recording the error for failures.
+func (m *Manager) SetStatus(id string, status types.JobStatus, jobErr string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.jobs[id]
+	if !ok {
+		return ErrNotFound
+	}
+	j.Status = status
+	j.Error = jobErr
+	j.UpdatedAt = time.Now().UTC()
+	return nil
+}
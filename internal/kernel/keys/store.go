@@ -0,0 +1,74 @@
+// Package keys issues and serves HLS segment-encryption keys.
+package keys
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrNotFound is returned when a key ID does not exist.
+var ErrNotFound = errors.New("key not found")
+
+// Key is one issued AES-128 content key.
+type Key struct {
+	ID        string
+	JobID     string
+	ProfileID string
+	// Value is the raw 16-byte key served to players.
+	Value     []byte
+	CreatedAt time.Time
+}
+
+// Provider issues content keys for encrypted outputs. The built-in
+// Store generates random AES-128 keys in memory; external DRM key
+// servers (Widevine/FairPlay) integrate by implementing this interface.
+type Provider interface {
+	Issue(jobID, profileID string) (*Key, error)
+	Get(keyID string) (*Key, error)
+}
+
+// Store is the built-in in-memory key provider.
+type Store struct {
+	mu   sync.Mutex
+	keys map[string]*Key
+}
+
+// NewStore returns an empty key store.
+func NewStore() *Store {
+	return &Store{keys: make(map[string]*Key)}
+}
+
+// Issue generates and stores a fresh random AES-128 key.
+func (s *Store) Issue(jobID, profileID string) (*Key, error) {
+	value := make([]byte, 16)
+	if _, err := rand.Read(value); err != nil {
+		return nil, fmt.Errorf("generate key: %w", err)
+	}
+	k := &Key{
+		ID:        uuid.NewString(),
+		JobID:     jobID,
+		ProfileID: profileID,
+		Value:     value,
+		CreatedAt: time.Now().UTC(),
+	}
+	s.mu.Lock()
+	s.keys[k.ID] = k
+	s.mu.Unlock()
+	return k, nil
+}
+
+// Get returns an issued key by ID.
+func (s *Store) Get(keyID string) (*Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k, ok := s.keys[keyID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return k, nil
+}
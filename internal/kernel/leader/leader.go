@@ -0,0 +1,140 @@
+// Package leader elects one kernel replica to run singleton services
+// (worker health monitoring, webhook dispatch, feed polling) while the
+// HTTP API scales horizontally. The election is a NATS KV lease: the
+// replica that creates the lease key is the leader and keeps refreshing
+// it; when it dies the key's TTL expires and another replica takes
+// over.
+package leader
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+const (
+	// bucket is the KV bucket holding the lease; key is the single
+	// lease all replicas compete for.
+	bucket = "webencode-leader"
+	key    = "kernel"
+	// leaseTTL bounds failover time: a dead leader's lease expires
+	// after this long. Refreshes happen at a third of it.
+	leaseTTL = 15 * time.Second
+)
+
+// Elector competes for the kernel leadership lease.
+type Elector struct {
+	id  string
+	kv  nats.KeyValue
+	ttl time.Duration
+
+	mu     sync.Mutex
+	leader bool
+	rev    uint64
+}
+
+// New prepares an elector for this replica. It requires JetStream on
+// the bus; servers without it return an error and callers should fall
+// back to running singletons locally.
+func New(nc *nats.Conn, id string) (*Elector, error) {
+	js, err := nc.JetStream()
+	if err != nil {
+		return nil, fmt.Errorf("leader: jetstream: %w", err)
+	}
+	kv, err := js.KeyValue(bucket)
+	if err != nil {
+		kv, err = js.CreateKeyValue(&nats.KeyValueConfig{Bucket: bucket, TTL: leaseTTL})
+		if err != nil {
+			return nil, fmt.Errorf("leader: create lease bucket: %w", err)
+		}
+	}
+	return &Elector{id: id, kv: kv, ttl: leaseTTL}, nil
+}
+
+// IsLeader reports whether this replica currently holds the lease.
+func (e *Elector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.leader
+}
+
+// Run competes for the lease until stop closes: acquire it when free,
+// refresh it while held, and release it on shutdown so the next
+// replica takes over without waiting out the TTL.
+func (e *Elector) Run(stop <-chan struct{}) {
+	e.tick()
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			e.mu.Lock()
+			if e.leader {
+				e.kv.Delete(key)
+				e.leader = false
+			}
+			e.mu.Unlock()
+			return
+		case <-ticker.C:
+			e.tick()
+		}
+	}
+}
+
+// tick makes one acquire-or-refresh attempt.
+func (e *Elector) tick() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.leader {
+		rev, err := e.kv.Update(key, []byte(e.id), e.rev)
+		if err != nil {
+			// Someone else took the lease (or the bus hiccuped long
+			// enough for it to expire); step down.
+			log.Printf("leader: lost leadership: %v", err)
+			e.leader = false
+			return
+		}
+		e.rev = rev
+		return
+	}
+	rev, err := e.kv.Create(key, []byte(e.id))
+	if err != nil {
+		// Lease held elsewhere; stay a follower.
+		return
+	}
+	log.Printf("leader: %s acquired leadership", e.id)
+	e.leader = true
+	e.rev = rev
+}
+
+// RunWhenLeader runs fn only while this replica holds leadership: fn
+// is started with a fresh stop channel on gaining the lease, that
+// channel is closed on losing it, and fn is started again if
+// leadership returns. Closing stop ends everything.
+func (e *Elector) RunWhenLeader(fn func(stop <-chan struct{}), stop <-chan struct{}) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	var sub chan struct{}
+	for {
+		select {
+		case <-stop:
+			if sub != nil {
+				close(sub)
+			}
+			return
+		case <-ticker.C:
+		}
+		if e.IsLeader() {
+			if sub == nil {
+				sub = make(chan struct{})
+				go fn(sub)
+			}
+		} else if sub != nil {
+			close(sub)
+			sub = nil
+		}
+	}
+}
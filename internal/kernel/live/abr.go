@@ -0,0 +1,222 @@
+package live
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// RenditionStatus is the state of one live transcode in an ABR ladder.
+type RenditionStatus string
+
+const (
+	RenditionStarting RenditionStatus = "starting"
+	RenditionRunning  RenditionStatus = "running"
+	RenditionError    RenditionStatus = "error"
+	RenditionStopped  RenditionStatus = "stopped"
+)
+
+// Rendition is one rung of a live ABR ladder.
+type Rendition struct {
+	ProfileID string `json:"profile_id"`
+	// OutputURL is where the transcode publishes to (fed back into
+	// MediaMTX for HLS delivery).
+	OutputURL string          `json:"output_url"`
+	Status    RenditionStatus `json:"status"`
+	Restarts  int             `json:"restarts"`
+	LastError string          `json:"last_error,omitempty"`
+}
+
+// ABRManager runs live transcoding ladders: per stream, one supervised
+// ffmpeg process per profile, restarted with backoff when it dies.
+type ABRManager struct {
+	Streams *Registry
+	// LookupProfile resolves a profile ID.
+	LookupProfile func(id string) (*types.Profile, error)
+	// OutputURL templates the rendition publish target; it receives the
+	// stream ID and the profile name.
+	OutputURL func(streamID, profileName string) string
+	// FFmpegPath overrides the ffmpeg binary; empty means PATH.
+	FFmpegPath string
+
+	mu      sync.Mutex
+	ladders map[string]*ladder
+}
+
+type ladder struct {
+	cancel     context.CancelFunc
+	renditions map[string]*Rendition
+}
+
+// NewABRManager returns an ABR manager publishing renditions back into
+// the given MediaMTX-style RTMP base URL, e.g. "rtmp://localhost/abr".
+func NewABRManager(streams *Registry, lookup func(id string) (*types.Profile, error), rtmpBase string) *ABRManager {
+	return &ABRManager{
+		Streams:       streams,
+		LookupProfile: lookup,
+		OutputURL: func(streamID, profileName string) string {
+			return fmt.Sprintf("%s/%s_%s", rtmpBase, streamID, profileName)
+		},
+		ladders: make(map[string]*ladder),
+	}
+}
+
+// StartLadder spins up one live transcode per profile for a stream.
+func (m *ABRManager) StartLadder(streamID string, profileIDs []string) ([]*Rendition, error) {
+	stream, err := m.Streams.Get(streamID)
+	if err != nil {
+		return nil, err
+	}
+	if stream.SourceURL == "" {
+		return nil, fmt.Errorf("stream %s has no source URL", streamID)
+	}
+	profiles := make([]*types.Profile, 0, len(profileIDs))
+	for _, id := range profileIDs {
+		p, err := m.LookupProfile(id)
+		if err != nil {
+			return nil, fmt.Errorf("profile %s: %w", id, err)
+		}
+		profiles = append(profiles, p)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, running := m.ladders[streamID]; running {
+		return nil, fmt.Errorf("stream %s already has a ladder running", streamID)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	l := &ladder{cancel: cancel, renditions: make(map[string]*Rendition)}
+	m.ladders[streamID] = l
+
+	out := make([]*Rendition, 0, len(profiles))
+	for _, p := range profiles {
+		r := &Rendition{
+			ProfileID: p.ID,
+			OutputURL: m.OutputURL(streamID, p.Name),
+			Status:    RenditionStarting,
+		}
+		l.renditions[p.ID] = r
+		copied := *r
+		out = append(out, &copied)
+		go m.superviseRendition(ctx, streamID, p, r)
+	}
+	return out, nil
+}
+
+// StopLadder tears down all renditions of a stream.
+func (m *ABRManager) StopLadder(streamID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	l, ok := m.ladders[streamID]
+	if !ok {
+		return fmt.Errorf("stream %s has no ladder running", streamID)
+	}
+	l.cancel()
+	for _, r := range l.renditions {
+		r.Status = RenditionStopped
+	}
+	delete(m.ladders, streamID)
+	return nil
+}
+
+// Ladder returns the current renditions of a stream's ladder.
+func (m *ABRManager) Ladder(streamID string) []*Rendition {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	l, ok := m.ladders[streamID]
+	if !ok {
+		return nil
+	}
+	out := make([]*Rendition, 0, len(l.renditions))
+	for _, r := range l.renditions {
+		copied := *r
+		out = append(out, &copied)
+	}
+	return out
+}
+
+// superviseRendition keeps one live transcode running, restarting with
+// backoff until the ladder is stopped.
+func (m *ABRManager) superviseRendition(ctx context.Context, streamID string, profile *types.Profile, r *Rendition) {
+	backoff := time.Second
+	for {
+		err := m.runRendition(ctx, streamID, profile, r)
+		if ctx.Err() != nil {
+			return
+		}
+		m.mu.Lock()
+		r.Status = RenditionError
+		r.Restarts++
+		if err != nil {
+			r.LastError = err.Error()
+		}
+		m.mu.Unlock()
+		log.Printf("live: abr rendition %s/%s exited (%v), restarting in %s", streamID, profile.Name, err, backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > maxRenditionBackoff {
+			backoff = maxRenditionBackoff
+		}
+	}
+}
+
+const maxRenditionBackoff = 30 * time.Second
+
+// runRendition executes one live transcode process.
+func (m *ABRManager) runRendition(ctx context.Context, streamID string, profile *types.Profile, r *Rendition) error {
+	stream, err := m.Streams.Get(streamID)
+	if err != nil {
+		return err
+	}
+	ffmpeg := m.FFmpegPath
+	if ffmpeg == "" {
+		ffmpeg = "ffmpeg"
+	}
+	args := []string{
+		"-hide_banner", "-loglevel", "error",
+		"-i", stream.SourceURL,
+		"-c:v", codecOr(profile.VideoCodec, "libx264"),
+		"-preset", presetOr(profile.Preset, "veryfast"), "-tune", "zerolatency",
+		"-b:v", fmt.Sprintf("%dk", profile.VideoBitrateKbps),
+		"-c:a", codecOr(profile.AudioCodec, "aac"),
+	}
+	if profile.AudioBitrateKbps > 0 {
+		args = append(args, "-b:a", fmt.Sprintf("%dk", profile.AudioBitrateKbps))
+	}
+	if profile.Height > 0 {
+		args = append(args, "-vf", "scale=-2:"+strconv.Itoa(profile.Height))
+	}
+	args = append(args, "-f", "flv", r.OutputURL)
+
+	cmd := exec.CommandContext(ctx, ffmpeg, args...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	r.Status = RenditionRunning
+	m.mu.Unlock()
+	return cmd.Wait()
+}
+
+func codecOr(codec, def string) string {
+	if codec != "" {
+		return codec
+	}
+	return def
+}
+
+func presetOr(preset, def string) string {
+	if preset != "" {
+		return preset
+	}
+	return def
+}
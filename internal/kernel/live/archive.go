@@ -0,0 +1,179 @@
+package live
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rennerdo30/webencode/internal/kernel/jobs"
+	"github.com/rennerdo30/webencode/pkg/plugin"
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// Archiver records live streams to storage and turns the recording into
+// a VOD job when the stream ends.
+type Archiver struct {
+	Streams *Registry
+	Jobs    *jobs.Manager
+	// Storage returns the storage plugin recordings are uploaded to.
+	// Nil or erroring means recordings stay on local disk.
+	Storage func() (plugin.StorageService, error)
+	// RecordDir is the local spool directory for in-progress recordings.
+	RecordDir string
+	// FFmpegPath overrides the ffmpeg binary; empty means PATH.
+	FFmpegPath string
+	// VODProfileIDs are the profiles the archive transcode job uses.
+	VODProfileIDs []string
+
+	mu         sync.Mutex
+	recordings map[string]*recording
+	clips      map[string][]*Clip
+}
+
+type recording struct {
+	cancel    context.CancelFunc
+	dir       string
+	startedAt time.Time
+	done      chan struct{}
+}
+
+// NewArchiver returns an archiver spooling recordings under recordDir.
+func NewArchiver(streams *Registry, jobManager *jobs.Manager, recordDir string) *Archiver {
+	return &Archiver{
+		Streams:    streams,
+		Jobs:       jobManager,
+		RecordDir:  recordDir,
+		recordings: make(map[string]*recording),
+	}
+}
+
+// StartArchive begins recording a stream: an ffmpeg remux writes
+// segments into the spool directory until StopArchive.
+func (a *Archiver) StartArchive(streamID string) error {
+	stream, err := a.Streams.Get(streamID)
+	if err != nil {
+		return err
+	}
+	if stream.SourceURL == "" {
+		return fmt.Errorf("stream %s has no source URL", streamID)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, running := a.recordings[streamID]; running {
+		return fmt.Errorf("stream %s is already being archived", streamID)
+	}
+
+	dir := filepath.Join(a.RecordDir, streamID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	rec := &recording{
+		cancel:    cancel,
+		dir:       dir,
+		startedAt: time.Now().UTC(),
+		done:      make(chan struct{}),
+	}
+	a.recordings[streamID] = rec
+
+	ffmpeg := a.FFmpegPath
+	if ffmpeg == "" {
+		ffmpeg = "ffmpeg"
+	}
+	cmd := exec.CommandContext(ctx, ffmpeg,
+		"-hide_banner", "-loglevel", "error",
+		"-i", stream.SourceURL,
+		"-c", "copy",
+		"-f", "segment",
+		"-segment_time", "6",
+		"-reset_timestamps", "1",
+		filepath.Join(dir, "seg_%06d.ts"),
+	)
+	if err := cmd.Start(); err != nil {
+		delete(a.recordings, streamID)
+		cancel()
+		return err
+	}
+	go func() {
+		defer close(rec.done)
+		if err := cmd.Wait(); err != nil && ctx.Err() == nil {
+			log.Printf("live: archive recorder for stream %s exited: %v", streamID, err)
+		}
+	}()
+	return nil
+}
+
+// StopArchive stops the recorder, uploads the recording, resolves
+// markers against the recording start, and submits the VOD transcode
+// job, linking it to the stream.
+func (a *Archiver) StopArchive(streamID string) (*types.Job, error) {
+	a.mu.Lock()
+	rec, ok := a.recordings[streamID]
+	if ok {
+		delete(a.recordings, streamID)
+	}
+	a.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("stream %s is not being archived", streamID)
+	}
+	rec.cancel()
+	<-rec.done
+
+	stream, err := a.Streams.Get(streamID)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceURL := rec.dir
+	prefix := "archives/" + streamID
+	if a.Storage != nil {
+		if storage, err := a.Storage(); err == nil {
+			if err := uploadDir(storage, rec.dir, prefix); err != nil {
+				return nil, fmt.Errorf("upload archive: %w", err)
+			}
+			sourceURL = prefix
+		} else {
+			log.Printf("live: storage unavailable, keeping archive of %s on disk: %v", streamID, err)
+		}
+	}
+
+	markers := a.Streams.ResolveMarkers(streamID, rec.startedAt)
+	job := a.Jobs.Submit(&types.Job{
+		UserID:       stream.UserID,
+		Title:        fmt.Sprintf("VOD: %s", stream.Title),
+		SourceURL:    sourceURL,
+		OutputPrefix: prefix + "/vod",
+		ProfileIDs:   a.VODProfileIDs,
+		Metadata: map[string]interface{}{
+			"stream_id":     streamID,
+			"archive_start": rec.startedAt,
+			"markers":       markers,
+		},
+	})
+	if err := a.Streams.SetArchiveJob(streamID, job.ID); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// uploadDir pushes every file in dir to storage under prefix.
+func uploadDir(storage plugin.StorageService, dir, prefix string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if err := storage.Upload(filepath.Join(dir, e.Name()), prefix+"/"+e.Name()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package live
+
+import (
+	"time"
+
+	"github.com/rennerdo30/webencode/pkg/plugin"
+)
+
+// ChatState is the kernel's view of a stream's chat controls, merged
+// from successive PATCHes.
+type ChatState struct {
+	SlowModeSec     int       `json:"slow_mode_sec"`
+	SubscribersOnly bool      `json:"subscribers_only"`
+	EmoteOnly       bool      `json:"emote_only"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// ApplyChatSettings merges a partial settings update into the stream's
+// chat state and returns the result.
+func (r *Registry) ApplyChatSettings(streamID string, settings plugin.ChatSettings) (ChatState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.streams[streamID]; !ok {
+		return ChatState{}, ErrNotFound
+	}
+	if r.chat == nil {
+		r.chat = make(map[string]*ChatState)
+	}
+	state := r.chat[streamID]
+	if state == nil {
+		state = &ChatState{}
+		r.chat[streamID] = state
+	}
+	if settings.SlowModeSec != nil {
+		state.SlowModeSec = *settings.SlowModeSec
+	}
+	if settings.SubscribersOnly != nil {
+		state.SubscribersOnly = *settings.SubscribersOnly
+	}
+	if settings.EmoteOnly != nil {
+		state.EmoteOnly = *settings.EmoteOnly
+	}
+	state.UpdatedAt = time.Now().UTC()
+	return *state, nil
+}
+
+// ChatSettings returns the stream's current chat state.
+func (r *Registry) ChatSettings(streamID string) ChatState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if state := r.chat[streamID]; state != nil {
+		return *state
+	}
+	return ChatState{}
+}
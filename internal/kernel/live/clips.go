@@ -0,0 +1,167 @@
+package live
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// Clip is a cut from a stream's live recording buffer, typically made by
+// a moderator while the stream is still running.
+type Clip struct {
+	ID       string  `json:"id"`
+	StreamID string  `json:"stream_id"`
+	Title    string  `json:"title"`
+	StartSec float64 `json:"start_sec"`
+	EndSec   float64 `json:"end_sec"`
+	// Key is the storage key (or local path) of the raw, copy-cut clip.
+	Key string `json:"key"`
+	// JobID is the transcode job the clip was submitted as.
+	JobID     string    `json:"job_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CutClip cuts [startSec, endSec) out of the stream's in-progress
+// recording, uploads the raw cut, and submits a transcode job for it.
+// The stream must be live with archiving running — the recording buffer
+// is what we cut from.
+func (a *Archiver) CutClip(streamID, title string, startSec, endSec float64, profileIDs []string) (*Clip, error) {
+	if startSec < 0 || endSec <= startSec {
+		return nil, fmt.Errorf("invalid clip range [%g, %g)", startSec, endSec)
+	}
+	stream, err := a.Streams.Get(streamID)
+	if err != nil {
+		return nil, err
+	}
+	a.mu.Lock()
+	rec, ok := a.recordings[streamID]
+	a.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("stream %s is not being archived", streamID)
+	}
+
+	clip := &Clip{
+		ID:        uuid.NewString(),
+		StreamID:  streamID,
+		Title:     title,
+		StartSec:  startSec,
+		EndSec:    endSec,
+		CreatedAt: time.Now().UTC(),
+	}
+	if clip.Title == "" {
+		clip.Title = fmt.Sprintf("Clip: %s", stream.Title)
+	}
+
+	local, err := a.cutRecording(rec.dir, clip)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceURL := local
+	clip.Key = local
+	if a.Storage != nil {
+		if storage, err := a.Storage(); err == nil {
+			key := fmt.Sprintf("clips/%s/%s.ts", streamID, clip.ID)
+			if err := storage.Upload(local, key); err != nil {
+				return nil, fmt.Errorf("upload clip: %w", err)
+			}
+			clip.Key = key
+			if url, err := storage.PresignGet(key, 24*time.Hour); err == nil {
+				sourceURL = url
+			}
+		}
+	}
+
+	job := a.Jobs.Submit(&types.Job{
+		UserID:       stream.UserID,
+		Title:        clip.Title,
+		SourceURL:    sourceURL,
+		OutputPrefix: fmt.Sprintf("clips/%s/%s", streamID, clip.ID),
+		ProfileIDs:   profileIDs,
+		Metadata: map[string]interface{}{
+			"stream_id": streamID,
+			"clip_id":   clip.ID,
+		},
+	})
+	clip.JobID = job.ID
+
+	a.mu.Lock()
+	if a.clips == nil {
+		a.clips = make(map[string][]*Clip)
+	}
+	a.clips[streamID] = append(a.clips[streamID], clip)
+	a.mu.Unlock()
+	return clip, nil
+}
+
+// Clips returns the clips cut from a stream, oldest first.
+func (a *Archiver) Clips(streamID string) []*Clip {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	out := make([]*Clip, len(a.clips[streamID]))
+	copy(out, a.clips[streamID])
+	return out
+}
+
+// cutRecording concat-copies the spool segments and trims the clip range
+// out without re-encoding. Returns the local path of the cut.
+func (a *Archiver) cutRecording(dir string, clip *Clip) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	var segments []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), "seg_") {
+			segments = append(segments, e.Name())
+		}
+	}
+	if len(segments) == 0 {
+		return "", fmt.Errorf("recording buffer for stream %s is empty", clip.StreamID)
+	}
+	sort.Strings(segments)
+
+	clipDir := filepath.Join(dir, "clips")
+	if err := os.MkdirAll(clipDir, 0o755); err != nil {
+		return "", err
+	}
+	var list bytes.Buffer
+	for _, seg := range segments {
+		fmt.Fprintf(&list, "file '%s'\n", filepath.Join(dir, seg))
+	}
+	listPath := filepath.Join(clipDir, clip.ID+".txt")
+	if err := os.WriteFile(listPath, list.Bytes(), 0o644); err != nil {
+		return "", err
+	}
+	defer os.Remove(listPath)
+
+	ffmpeg := a.FFmpegPath
+	if ffmpeg == "" {
+		ffmpeg = "ffmpeg"
+	}
+	outPath := filepath.Join(clipDir, clip.ID+".ts")
+	cmd := exec.Command(ffmpeg,
+		"-hide_banner", "-loglevel", "error",
+		"-f", "concat", "-safe", "0",
+		"-i", listPath,
+		"-ss", fmt.Sprintf("%g", clip.StartSec),
+		"-to", fmt.Sprintf("%g", clip.EndSec),
+		"-c", "copy",
+		"-y", outPath,
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("cut clip: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return outPath, nil
+}
@@ -0,0 +1,178 @@
+package live
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/rennerdo30/webencode/internal/kernel/events"
+)
+
+// EmergencyConfig tunes when a stream is considered degraded and what
+// the fallback encode looks like.
+type EmergencyConfig struct {
+	// MinBitrateKbps is the ingest bitrate below which a sample counts
+	// as degraded.
+	MinBitrateKbps float64
+	// DegradeAfter is how long samples must stay degraded before the
+	// emergency encode starts.
+	DegradeAfter time.Duration
+	// RecoverAfter is how long samples must stay healthy before the
+	// emergency encode stops.
+	RecoverAfter time.Duration
+	// OutputBitrateKbps is the stable bitrate of the emergency encode.
+	OutputBitrateKbps int
+	// OutputURL is a template for the fallback destination; %s is
+	// replaced with the stream ID.
+	OutputURL string
+}
+
+// DefaultEmergencyConfig is a conservative starting point: a sustained
+// drop below 1.5 Mbit/s for 15s triggers a 1 Mbit/s re-encode.
+var DefaultEmergencyConfig = EmergencyConfig{
+	MinBitrateKbps:    1500,
+	DegradeAfter:      15 * time.Second,
+	RecoverAfter:      30 * time.Second,
+	OutputBitrateKbps: 1000,
+	OutputURL:         "rtmp://localhost/emergency/%s",
+}
+
+// EmergencyMonitor watches ingest telemetry and re-encodes degraded
+// ingests to a lower, stable bitrate until the source recovers.
+type EmergencyMonitor struct {
+	Streams *Registry
+	Events  *events.Log
+	Config  EmergencyConfig
+	// FFmpegPath overrides the ffmpeg binary; empty means PATH.
+	FFmpegPath string
+
+	mu     sync.Mutex
+	active map[string]*emergencyEncode
+	// degradedSince / healthySince track how long a stream has been on
+	// either side of the threshold.
+	degradedSince map[string]time.Time
+	healthySince  map[string]time.Time
+}
+
+type emergencyEncode struct {
+	cancel    context.CancelFunc
+	startedAt time.Time
+}
+
+// NewEmergencyMonitor returns a monitor with the given thresholds.
+func NewEmergencyMonitor(streams *Registry, eventLog *events.Log, cfg EmergencyConfig) *EmergencyMonitor {
+	return &EmergencyMonitor{
+		Streams:       streams,
+		Events:        eventLog,
+		Config:        cfg,
+		active:        make(map[string]*emergencyEncode),
+		degradedSince: make(map[string]time.Time),
+		healthySince:  make(map[string]time.Time),
+	}
+}
+
+// Observe feeds one telemetry sample into the monitor. Call it whenever
+// ingest telemetry arrives.
+func (m *EmergencyMonitor) Observe(streamID string, sample IngestSample) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	degraded := sample.BitrateKbps > 0 && sample.BitrateKbps < m.Config.MinBitrateKbps
+	now := sample.At
+	if now.IsZero() {
+		now = time.Now().UTC()
+	}
+
+	if degraded {
+		delete(m.healthySince, streamID)
+		since, ok := m.degradedSince[streamID]
+		if !ok {
+			m.degradedSince[streamID] = now
+			return
+		}
+		if _, running := m.active[streamID]; !running && now.Sub(since) >= m.Config.DegradeAfter {
+			m.startLocked(streamID, since)
+		}
+		return
+	}
+
+	delete(m.degradedSince, streamID)
+	if _, running := m.active[streamID]; !running {
+		return
+	}
+	since, ok := m.healthySince[streamID]
+	if !ok {
+		m.healthySince[streamID] = now
+		return
+	}
+	if now.Sub(since) >= m.Config.RecoverAfter {
+		m.stopLocked(streamID)
+	}
+}
+
+// startLocked launches the fallback encode. Caller holds m.mu.
+func (m *EmergencyMonitor) startLocked(streamID string, degradedSince time.Time) {
+	stream, err := m.Streams.Get(streamID)
+	if err != nil || stream.SourceURL == "" {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.active[streamID] = &emergencyEncode{cancel: cancel, startedAt: time.Now().UTC()}
+
+	ffmpeg := m.FFmpegPath
+	if ffmpeg == "" {
+		ffmpeg = "ffmpeg"
+	}
+	bitrate := fmt.Sprintf("%dk", m.Config.OutputBitrateKbps)
+	cmd := exec.CommandContext(ctx, ffmpeg,
+		"-hide_banner", "-loglevel", "error",
+		"-i", stream.SourceURL,
+		"-c:v", "libx264", "-preset", "veryfast", "-tune", "zerolatency",
+		"-b:v", bitrate, "-maxrate", bitrate, "-bufsize", bitrate,
+		"-c:a", "aac", "-b:a", "96k",
+		"-f", "flv",
+		fmt.Sprintf(m.Config.OutputURL, streamID),
+	)
+	go func() {
+		if err := cmd.Run(); err != nil && ctx.Err() == nil {
+			log.Printf("live: emergency encode for stream %s exited: %v", streamID, err)
+		}
+	}()
+
+	m.Events.Append(events.Event{
+		Type:     "live.emergency_started",
+		Resource: streamID,
+		Message:  "sustained ingest degradation, switching to emergency encode",
+		Data: map[string]interface{}{
+			"degraded_since":      degradedSince,
+			"output_bitrate_kbps": m.Config.OutputBitrateKbps,
+		},
+	})
+}
+
+// stopLocked ends the fallback encode. Caller holds m.mu.
+func (m *EmergencyMonitor) stopLocked(streamID string) {
+	enc := m.active[streamID]
+	enc.cancel()
+	delete(m.active, streamID)
+	delete(m.healthySince, streamID)
+	m.Events.Append(events.Event{
+		Type:     "live.emergency_ended",
+		Resource: streamID,
+		Message:  "ingest recovered, emergency encode stopped",
+		Data: map[string]interface{}{
+			"emergency_duration": time.Since(enc.startedAt).String(),
+		},
+	})
+}
+
+// Active reports whether a stream is currently in emergency mode.
+func (m *EmergencyMonitor) Active(streamID string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.active[streamID]
+	return ok
+}
@@ -0,0 +1,105 @@
+package live
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Marker is a labeled point in time recorded during a live session
+// ("goal", "guest joins", ...). While the stream is live only the wall
+// clock is known; once the VOD archive exists the marker is resolved to
+// an archive-relative timecode.
+type Marker struct {
+	ID       string `json:"id"`
+	StreamID string `json:"stream_id"`
+	Label    string `json:"label"`
+	// At is the wall-clock moment the marker was set.
+	At time.Time `json:"at"`
+	// ArchiveOffset is the marker position relative to the start of the
+	// VOD archive, filled in when the archive is produced.
+	ArchiveOffset *time.Duration `json:"archive_offset,omitempty"`
+}
+
+// AddMarker records a marker for a stream. A zero at means "now".
+func (r *Registry) AddMarker(streamID, label string, at time.Time) (*Marker, error) {
+	if _, err := r.Get(streamID); err != nil {
+		return nil, err
+	}
+	if at.IsZero() {
+		at = time.Now().UTC()
+	}
+	m := &Marker{
+		ID:       uuid.NewString(),
+		StreamID: streamID,
+		Label:    label,
+		At:       at.UTC(),
+	}
+	r.markerMu.Lock()
+	r.markers[streamID] = append(r.markers[streamID], m)
+	r.markerMu.Unlock()
+	return m, nil
+}
+
+// Markers returns all markers of a stream in chronological order.
+func (r *Registry) Markers(streamID string) []*Marker {
+	r.markerMu.Lock()
+	out := make([]*Marker, 0, len(r.markers[streamID]))
+	for _, m := range r.markers[streamID] {
+		copied := *m
+		out = append(out, &copied)
+	}
+	r.markerMu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].At.Before(out[j].At) })
+	return out
+}
+
+// ResolveMarkers translates a stream's markers to archive-relative
+// offsets given the wall-clock time the archive recording started.
+// Markers set before the recording began are clamped to offset zero.
+func (r *Registry) ResolveMarkers(streamID string, archiveStart time.Time) []*Marker {
+	r.markerMu.Lock()
+	for _, m := range r.markers[streamID] {
+		offset := m.At.Sub(archiveStart)
+		if offset < 0 {
+			offset = 0
+		}
+		o := offset
+		m.ArchiveOffset = &o
+	}
+	r.markerMu.Unlock()
+	return r.Markers(streamID)
+}
+
+// FFMetadataChapters renders resolved markers as an ffmetadata document
+// with one chapter per marker, suitable for embedding into the VOD with
+// ffmpeg's -map_metadata. Each chapter runs to the next marker, the last
+// one to total. Unresolved markers are skipped.
+func FFMetadataChapters(ms []*Marker, total time.Duration) string {
+	resolved := make([]*Marker, 0, len(ms))
+	for _, m := range ms {
+		if m.ArchiveOffset != nil {
+			resolved = append(resolved, m)
+		}
+	}
+	var b strings.Builder
+	b.WriteString(";FFMETADATA1\n")
+	for i, m := range resolved {
+		end := total
+		if i+1 < len(resolved) {
+			end = *resolved[i+1].ArchiveOffset
+		}
+		fmt.Fprintf(&b, "[CHAPTER]\nTIMEBASE=1/1000\nSTART=%d\nEND=%d\ntitle=%s\n",
+			m.ArchiveOffset.Milliseconds(), end.Milliseconds(), escapeFFMetadata(m.Label))
+	}
+	return b.String()
+}
+
+// escapeFFMetadata escapes the characters ffmetadata treats specially.
+func escapeFFMetadata(s string) string {
+	replacer := strings.NewReplacer("=", `\=`, ";", `\;`, "#", `\#`, "\\", `\\`, "\n", `\`+"\n")
+	return replacer.Replace(s)
+}
@@ -0,0 +1,137 @@
+// Package live tracks the kernel's live streams.
+package live
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// ErrNotFound is returned when a stream ID is unknown.
+var ErrNotFound = errors.New("stream not found")
+
+// Registry is an in-memory index of live streams.
+type Registry struct {
+	mu      sync.RWMutex
+	streams map[string]*types.Stream
+
+	// markerMu guards markers separately so marker churn during a busy
+	// stream doesn't contend with stream lookups.
+	markerMu sync.Mutex
+	markers  map[string][]*Marker
+
+	// telemetryMu guards per-stream ingest telemetry, likewise kept off
+	// the hot stream lock.
+	telemetryMu sync.Mutex
+	telemetry   map[string][]IngestSample
+
+	// chat holds per-stream chat controls, guarded by mu.
+	chat map[string]*ChatState
+}
+
+// NewRegistry returns an empty stream registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		streams:   make(map[string]*types.Stream),
+		markers:   make(map[string][]*Marker),
+		telemetry: make(map[string][]IngestSample),
+	}
+}
+
+// Create registers a new stream owned by userID.
+func (r *Registry) Create(userID, title, sourceURL string, archive bool) *types.Stream {
+	s := &types.Stream{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Title:     title,
+		SourceURL: sourceURL,
+		Status:    types.StreamStatusIdle,
+		Archive:   archive,
+		CreatedAt: time.Now().UTC(),
+	}
+	r.mu.Lock()
+	r.streams[s.ID] = s
+	r.mu.Unlock()
+	return s
+}
+
+// Get returns the stream with the given ID.
+func (r *Registry) Get(id string) (*types.Stream, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, ok := r.streams[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	copied := *s
+	return &copied, nil
+}
+
+// List returns streams newest first, filtered to one owner when ownerID
+// is non-empty.
+func (r *Registry) List(ownerID string) []*types.Stream {
+	r.mu.RLock()
+	out := make([]*types.Stream, 0, len(r.streams))
+	for _, s := range r.streams {
+		if ownerID != "" && s.UserID != ownerID {
+			continue
+		}
+		copied := *s
+		out = append(out, &copied)
+	}
+	r.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out
+}
+
+// SetStatus updates a stream's lifecycle state.
+func (r *Registry) SetStatus(id string, status types.StreamStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.streams[id]
+	if !ok {
+		return ErrNotFound
+	}
+	s.Status = status
+	return nil
+}
+
+// SetSourceURL records where the incoming stream can be read from.
+func (r *Registry) SetSourceURL(id, sourceURL string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.streams[id]
+	if !ok {
+		return ErrNotFound
+	}
+	s.SourceURL = sourceURL
+	return nil
+}
+
+// SetArchiveJob links a stream to the VOD job produced from its
+// recording.
+func (r *Registry) SetArchiveJob(id, jobID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.streams[id]
+	if !ok {
+		return ErrNotFound
+	}
+	s.ArchiveJobID = jobID
+	return nil
+}
+
+// Delete removes a stream.
+func (r *Registry) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.streams[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.streams, id)
+	return nil
+}
@@ -0,0 +1,42 @@
+package live
+
+import (
+	"time"
+)
+
+// IngestSample is one telemetry measurement of a stream's ingest.
+type IngestSample struct {
+	BitrateKbps float64   `json:"bitrate_kbps"`
+	FPS         float64   `json:"fps,omitempty"`
+	At          time.Time `json:"at"`
+}
+
+// telemetryWindow bounds how many samples are kept per stream.
+const telemetryWindow = 300
+
+// ReportIngest records an ingest telemetry sample for a stream.
+func (r *Registry) ReportIngest(streamID string, sample IngestSample) error {
+	if _, err := r.Get(streamID); err != nil {
+		return err
+	}
+	if sample.At.IsZero() {
+		sample.At = time.Now().UTC()
+	}
+	r.telemetryMu.Lock()
+	samples := append(r.telemetry[streamID], sample)
+	if len(samples) > telemetryWindow {
+		samples = samples[len(samples)-telemetryWindow:]
+	}
+	r.telemetry[streamID] = samples
+	r.telemetryMu.Unlock()
+	return nil
+}
+
+// IngestSamples returns the recent telemetry of a stream, oldest first.
+func (r *Registry) IngestSamples(streamID string) []IngestSample {
+	r.telemetryMu.Lock()
+	defer r.telemetryMu.Unlock()
+	out := make([]IngestSample, len(r.telemetry[streamID]))
+	copy(out, r.telemetry[streamID])
+	return out
+}
@@ -0,0 +1,137 @@
+package orchestrator
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// sampleInterval is the minimum spacing between stored progress
+	// samples per task; ffmpeg prints progress several times a second
+	// and charts do not need that resolution.
+	sampleInterval = 5 * time.Second
+	// maxSamples caps the stored series per task; when full, the
+	// series is compacted to half resolution so long encodes keep
+	// their full time span.
+	maxSamples = 720
+)
+
+// ProgressSample is one point of a task's encode progress series,
+// parsed from ffmpeg's progress line.
+type ProgressSample struct {
+	At          time.Time `json:"at"`
+	Frame       int64     `json:"frame,omitempty"`
+	FPS         float64   `json:"fps,omitempty"`
+	BitrateKbps float64   `json:"bitrate_kbps,omitempty"`
+	Q           float64   `json:"q,omitempty"`
+	Speed       float64   `json:"speed,omitempty"`
+}
+
+// TaskAnalytics is one task's progress series for charting.
+type TaskAnalytics struct {
+	TaskID  string           `json:"task_id"`
+	Profile string           `json:"profile,omitempty"`
+	Samples []ProgressSample `json:"samples"`
+}
+
+// recordProgress appends one sample to a task's series, enforcing the
+// sampling interval and the size cap.
+func (o *Orchestrator) recordProgress(taskID string, sample ProgressSample) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if _, ok := o.tasks[taskID]; !ok {
+		return
+	}
+	series := o.progress[taskID]
+	if n := len(series); n > 0 && sample.At.Sub(series[n-1].At) < sampleInterval {
+		return
+	}
+	if len(series) >= maxSamples {
+		// Compact to half resolution: long encodes keep their full
+		// time span at coarser granularity.
+		compacted := series[:0]
+		for i := 0; i < len(series); i += 2 {
+			compacted = append(compacted, series[i])
+		}
+		series = compacted
+	}
+	o.progress[taskID] = append(series, sample)
+}
+
+// JobAnalytics returns the progress series of a job's tasks, oldest
+// task first.
+func (o *Orchestrator) JobAnalytics(jobID string) []TaskAnalytics {
+	o.mu.Lock()
+	out := make([]TaskAnalytics, 0)
+	order := make(map[string]time.Time)
+	for id, t := range o.tasks {
+		if t.JobID != jobID {
+			continue
+		}
+		name := ""
+		if t.Profile != nil {
+			name = t.Profile.Name
+		}
+		out = append(out, TaskAnalytics{
+			TaskID:  id,
+			Profile: name,
+			Samples: append([]ProgressSample(nil), o.progress[id]...),
+		})
+		order[id] = t.CreatedAt
+	}
+	o.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return order[out[i].TaskID].Before(order[out[j].TaskID]) })
+	return out
+}
+
+// parseProgress extracts the numeric fields of an ffmpeg progress line
+// ("frame= 1234 fps= 25 q=28.0 ... bitrate=1234.5kbits/s speed=1.01x");
+// ok is false for lines without a speed factor, which filters out
+// non-progress stderr.
+func parseProgress(line string, at time.Time) (ProgressSample, bool) {
+	speed, ok := parseEncodeSpeed(line)
+	if !ok {
+		return ProgressSample{}, false
+	}
+	sample := ProgressSample{At: at, Speed: speed}
+	if v, ok := progressField(line, "frame="); ok {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			sample.Frame = n
+		}
+	}
+	if v, ok := progressField(line, "fps="); ok {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			sample.FPS = f
+		}
+	}
+	if v, ok := progressField(line, "q="); ok {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			sample.Q = f
+		}
+	}
+	if v, ok := progressField(line, "bitrate="); ok {
+		if f, err := strconv.ParseFloat(strings.TrimSuffix(v, "kbits/s"), 64); err == nil {
+			sample.BitrateKbps = f
+		}
+	}
+	return sample, true
+}
+
+// progressField extracts the token following key, skipping the spaces
+// ffmpeg pads short values with.
+func progressField(line, key string) (string, bool) {
+	i := strings.LastIndex(line, key)
+	if i < 0 {
+		return "", false
+	}
+	rest := strings.TrimLeft(line[i+len(key):], " ")
+	if end := strings.IndexByte(rest, ' '); end >= 0 {
+		rest = rest[:end]
+	}
+	if rest == "" {
+		return "", false
+	}
+	return rest, true
+}
@@ -0,0 +1,73 @@
+package orchestrator
+
+import "github.com/rennerdo30/webencode/pkg/types"
+
+// fallbackTaskMinutes is the backlog estimate for a task whose source
+// duration is unknown (untrimmed sources are only measured on the
+// worker). It errs high so autoscalers scale out rather than queue.
+const fallbackTaskMinutes = 10
+
+// AutoscaleStatus is the queue snapshot the autoscale endpoint exposes
+// so an external autoscaler (KEDA, a HorizontalPodAutoscaler on custom
+// metrics) can size the worker deployment.
+type AutoscaleStatus struct {
+	// QueueDepth counts every submitted, unfinished task.
+	QueueDepth int `json:"queue_depth"`
+	// PendingTasks are queued or dispatched but not yet running;
+	// RunningTasks are executing on a worker.
+	PendingTasks int `json:"pending_tasks"`
+	RunningTasks int `json:"running_tasks"`
+	// PendingEncodeMinutes estimates the wall-clock minutes of encode
+	// work in the backlog, from known source durations scaled by the
+	// observed average encode speed.
+	PendingEncodeMinutes float64 `json:"pending_encode_minutes"`
+	WorkersTotal         int     `json:"workers_total"`
+	WorkersBusy          int     `json:"workers_busy"`
+}
+
+// Autoscale returns the current scaling snapshot.
+func (o *Orchestrator) Autoscale() AutoscaleStatus {
+	var s AutoscaleStatus
+	o.mu.Lock()
+	for _, t := range o.pending {
+		s.PendingTasks++
+		s.PendingEncodeMinutes += o.estimateMinutesLocked(t)
+	}
+	for id := range o.inflight {
+		t, ok := o.tasks[id]
+		if !ok {
+			continue
+		}
+		switch t.Status {
+		case types.TaskStatusPending:
+			s.PendingTasks++
+		case types.TaskStatusRunning:
+			s.RunningTasks++
+		default:
+			continue
+		}
+		s.PendingEncodeMinutes += o.estimateMinutesLocked(t)
+	}
+	o.mu.Unlock()
+	s.QueueDepth = s.PendingTasks + s.RunningTasks
+	if o.Workers != nil {
+		s.WorkersTotal, s.WorkersBusy = o.Workers.Utilization()
+	}
+	return s
+}
+
+// estimateMinutesLocked guesses one task's encode minutes: the known
+// source duration divided by the observed average speed factor, or the
+// flat fallback when the duration is unknown. Called with o.mu held.
+func (o *Orchestrator) estimateMinutesLocked(t *types.Task) float64 {
+	if t.DurationSec <= 0 {
+		return fallbackTaskMinutes
+	}
+	minutes := t.DurationSec / 60
+	if o.speedCount > 0 {
+		if speed := o.speedSum / float64(o.speedCount); speed > 0 {
+			minutes /= speed
+		}
+	}
+	return minutes
+}
@@ -0,0 +1,140 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/rennerdo30/webencode/internal/bus"
+	"github.com/rennerdo30/webencode/internal/kernel/events"
+	"github.com/rennerdo30/webencode/pkg/errcode"
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// ErrDeadLetterNotFound is returned for unknown dead-lettered task IDs.
+var ErrDeadLetterNotFound = errors.New("dead-lettered task not found")
+
+// DeadLetter preserves a task that exhausted its retries, with the
+// exact payload and final error for inspection and replay.
+type DeadLetter struct {
+	Task types.Task `json:"task"`
+	// Error is the last failure, including the ffmpeg stderr tail the
+	// worker reported.
+	Error      string           `json:"error"`
+	ErrorClass types.ErrorClass `json:"error_class,omitempty"`
+	WorkerID   string           `json:"worker_id,omitempty"`
+	At         time.Time        `json:"at"`
+}
+
+// deadLetter records a permanently failed task and publishes it on the
+// dead-letter subject for external archival.
+func (o *Orchestrator) deadLetter(t *types.Task, ev types.TaskEvent) {
+	dl := &DeadLetter{
+		Task:       *t,
+		Error:      ev.Error,
+		ErrorClass: ev.ErrorClass,
+		WorkerID:   ev.WorkerID,
+		At:         time.Now().UTC(),
+	}
+	o.mu.Lock()
+	o.deadLetters[t.ID] = dl
+	o.mu.Unlock()
+	o.Events.Append(events.Event{
+		Type:     "task.dead_lettered",
+		Resource: t.ID,
+		Message:  "task exhausted retries",
+		Data: map[string]interface{}{
+			"job_id":      t.JobID,
+			"error":       ev.Error,
+			"error_class": ev.ErrorClass,
+			"error_code":  errcode.DeadLettered,
+		},
+	})
+	data, err := json.Marshal(dl)
+	if err != nil {
+		return
+	}
+	if err := o.conn.Publish(bus.SubjectDeadLetter, data); err != nil {
+		log.Printf("orchestrator: publish dead letter for task %s: %v", t.ID, err)
+	}
+}
+
+// DeadLetters returns all dead-lettered tasks, newest first.
+func (o *Orchestrator) DeadLetters() []*DeadLetter {
+	o.mu.Lock()
+	out := make([]*DeadLetter, 0, len(o.deadLetters))
+	for _, dl := range o.deadLetters {
+		copied := *dl
+		out = append(out, &copied)
+	}
+	o.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].At.After(out[j].At) })
+	return out
+}
+
+// DeadLetter returns one dead-lettered task by its task ID.
+func (o *Orchestrator) DeadLetter(taskID string) (*DeadLetter, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	dl, ok := o.deadLetters[taskID]
+	if !ok {
+		return nil, ErrDeadLetterNotFound
+	}
+	copied := *dl
+	return &copied, nil
+}
+
+// ReplayOverrides optionally adjust a dead-lettered task before it goes
+// back on the bus.
+type ReplayOverrides struct {
+	// TargetWorker pins the replay to one worker.
+	TargetWorker string
+	// Profile replaces the task's encode parameters.
+	Profile *types.Profile
+	// SourceURL replaces the input, e.g. after fixing an expired link.
+	SourceURL string
+}
+
+// Replay requeues a dead-lettered task with a fresh attempt budget and
+// clears its job's failure state.
+func (o *Orchestrator) Replay(taskID string, over ReplayOverrides) (*types.Task, error) {
+	o.mu.Lock()
+	dl, ok := o.deadLetters[taskID]
+	if !ok {
+		o.mu.Unlock()
+		return nil, ErrDeadLetterNotFound
+	}
+	delete(o.deadLetters, taskID)
+
+	t := dl.Task
+	t.Status = types.TaskStatusPending
+	t.Attempts = 0
+	t.Error = ""
+	t.TargetWorker = over.TargetWorker
+	t.ExcludedWorkers = nil
+	if over.Profile != nil {
+		t.Profile = over.Profile
+	}
+	if over.SourceURL != "" {
+		t.SourceURL = over.SourceURL
+	}
+	t.UpdatedAt = time.Now().UTC()
+	o.tasks[t.ID] = &t
+	o.mu.Unlock()
+
+	_ = o.Jobs.SetStatus(t.JobID, types.JobStatusQueued, "")
+	copied := t
+	if o.scheduled() {
+		o.mu.Lock()
+		o.pending = append(o.pending, &copied)
+		o.mu.Unlock()
+		o.pump()
+		return &t, nil
+	}
+	if err := o.dispatchTracked(&copied); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,122 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/rennerdo30/webencode/internal/encode"
+	"github.com/rennerdo30/webencode/pkg/plugin"
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// jobCompleted runs the post-completion hooks of a job: publishing the
+// primary output and spawning the declared fan-out jobs.
+func (o *Orchestrator) jobCompleted(jobID string) {
+	job, err := o.Jobs.Get(jobID)
+	if err != nil {
+		return
+	}
+	if job.Publish != nil {
+		if err := o.publishOutput(job, "", job.Publish); err != nil {
+			log.Printf("orchestrator: publish job %s: %v", jobID, err)
+		}
+	}
+	for i := range job.FanOut {
+		if err := o.spawnFanOut(job, &job.FanOut[i]); err != nil {
+			log.Printf("orchestrator: fan-out %q of job %s: %v", job.FanOut[i].Name, jobID, err)
+		}
+	}
+}
+
+// spawnFanOut submits one derivative job reading from the parent's
+// finished output.
+func (o *Orchestrator) spawnFanOut(parent *types.Job, spec *types.FanOutSpec) error {
+	if o.Profiles == nil {
+		return fmt.Errorf("no profile registry configured")
+	}
+	sourceURL, err := o.outputURL(parent, spec.SourceProfileID)
+	if err != nil {
+		return err
+	}
+	specProfiles := make([]*types.Profile, 0, len(spec.ProfileIDs))
+	for _, id := range spec.ProfileIDs {
+		p, err := o.Profiles.Get(id)
+		if err != nil {
+			return err
+		}
+		specProfiles = append(specProfiles, p)
+	}
+
+	child := o.Jobs.Submit(&types.Job{
+		UserID:          parent.UserID,
+		Title:           fmt.Sprintf("%s — %s", parent.Title, spec.Name),
+		SourceURL:       sourceURL,
+		OutputPrefix:    parent.OutputPrefix + "/" + spec.Name,
+		ProfileIDs:      spec.ProfileIDs,
+		TrimStartSec:    spec.StartSec,
+		TrimDurationSec: spec.DurationSec,
+		Publish:         spec.Publish,
+		Metadata: map[string]interface{}{
+			"parent_job_id": parent.ID,
+			"fan_out":       spec.Name,
+		},
+	})
+	return o.SubmitJob(child, specProfiles)
+}
+
+// publishOutput pushes a job's finished output to the platform named in
+// the spec.
+func (o *Orchestrator) publishOutput(job *types.Job, profileID string, spec *types.PublishSpec) error {
+	if o.Publisher == nil {
+		return fmt.Errorf("no publisher plugins configured")
+	}
+	publisher, err := o.Publisher(spec.Plugin)
+	if err != nil {
+		return err
+	}
+	fileURL, err := o.outputURL(job, profileID)
+	if err != nil {
+		return err
+	}
+	title := spec.Title
+	if title == "" {
+		title = job.Title
+	}
+	_, err = publisher.Publish(plugin.PublishRequest{
+		FileURL:     fileURL,
+		Title:       title,
+		Description: spec.Description,
+		Tags:        spec.Tags,
+		Visibility:  plugin.Visibility(spec.Visibility),
+	})
+	return err
+}
+
+// outputURL resolves the finished output of one of a job's profiles to a
+// URL workers and plugins can fetch. Empty profileID picks the job's
+// first profile.
+func (o *Orchestrator) outputURL(job *types.Job, profileID string) (string, error) {
+	if profileID == "" {
+		if len(job.ProfileIDs) == 0 {
+			return "", fmt.Errorf("job %s has no profiles", job.ID)
+		}
+		profileID = job.ProfileIDs[0]
+	}
+	if o.Profiles == nil {
+		return "", fmt.Errorf("no profile registry configured")
+	}
+	p, err := o.Profiles.Get(profileID)
+	if err != nil {
+		return "", err
+	}
+	key := job.OutputPrefix + "/" + p.Name + encode.OutputExt(p)
+	if o.Storage != nil {
+		if storage, err := o.Storage(); err == nil {
+			if url, err := storage.PresignGet(key, 24*time.Hour); err == nil {
+				return url, nil
+			}
+		}
+	}
+	return key, nil
+}
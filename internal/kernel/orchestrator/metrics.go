@@ -0,0 +1,90 @@
+package orchestrator
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/rennerdo30/webencode/internal/metrics"
+)
+
+// Task and job instrumentation. Labels stay bounded: task types and
+// outcomes are enums, profile names are operator-curated.
+var (
+	tasksFinished = metrics.NewCounter("webencode_tasks_finished_total",
+		"Finished tasks by type and outcome.", "type", "status")
+	taskRetries = metrics.NewCounter("webencode_task_retries_total",
+		"Task redispatches after a failure.", "type")
+	taskDuration = metrics.NewHistogram("webencode_task_duration_seconds",
+		"Wall time from task creation to completion, by profile.",
+		[]float64{10, 30, 60, 120, 300, 600, 1800, 3600, 7200}, "profile")
+	jobDuration = metrics.NewHistogram("webencode_job_duration_seconds",
+		"Wall time from job submission to completion.",
+		[]float64{30, 60, 120, 300, 600, 1800, 3600, 7200, 14400})
+	encodeSpeed = metrics.NewHistogram("webencode_encode_speed",
+		"Realtime speed factor sampled from ffmpeg progress lines.",
+		[]float64{0.25, 0.5, 1, 2, 4, 8, 16})
+)
+
+// registerQueueMetrics exposes the orchestrator's queue depths; they
+// are read on scrape so no bookkeeping leaks into the dispatch path.
+func (o *Orchestrator) registerQueueMetrics() {
+	metrics.NewGaugeFunc("webencode_tasks_pending",
+		"Tasks waiting in the bounded scheduler queue.", nil,
+		func() map[string]float64 {
+			o.mu.Lock()
+			defer o.mu.Unlock()
+			return map[string]float64{"": float64(len(o.pending))}
+		})
+	metrics.NewGaugeFunc("webencode_tasks_inflight",
+		"Tasks dispatched to workers and not yet finished.", nil,
+		func() map[string]float64 {
+			o.mu.Lock()
+			defer o.mu.Unlock()
+			return map[string]float64{"": float64(len(o.inflight))}
+		})
+	metrics.NewGaugeFunc("webencode_pending_encode_minutes",
+		"Estimated encode minutes in the unfinished backlog.", nil,
+		func() map[string]float64 {
+			return map[string]float64{"": o.Autoscale().PendingEncodeMinutes}
+		})
+}
+
+// observeEncodeSpeed feeds one sampled speed factor into the histogram
+// and the rolling average the stats endpoint reports.
+func (o *Orchestrator) observeEncodeSpeed(speed float64) {
+	encodeSpeed.Observe(speed)
+	o.mu.Lock()
+	o.speedSum += speed
+	o.speedCount++
+	o.mu.Unlock()
+}
+
+// AverageEncodeSpeed returns the mean sampled encode speed factor,
+// zero before any sample arrived.
+func (o *Orchestrator) AverageEncodeSpeed() float64 {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.speedCount == 0 {
+		return 0
+	}
+	return o.speedSum / float64(o.speedCount)
+}
+
+// parseEncodeSpeed extracts the "speed=1.23x" factor ffmpeg prints on
+// its progress lines; ok is false for lines without one.
+func parseEncodeSpeed(line string) (float64, bool) {
+	i := strings.LastIndex(line, "speed=")
+	if i < 0 {
+		return 0, false
+	}
+	rest := strings.TrimSpace(line[i+len("speed="):])
+	end := strings.IndexByte(rest, 'x')
+	if end <= 0 {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(rest[:end]), 64)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
@@ -0,0 +1,708 @@
+// Package orchestrator turns jobs into tasks, dispatches them to workers
+// over the bus, and drives retries.
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+
+	"github.com/rennerdo30/webencode/internal/bus"
+	"github.com/rennerdo30/webencode/internal/kernel/assets"
+	"github.com/rennerdo30/webencode/internal/kernel/events"
+	"github.com/rennerdo30/webencode/internal/kernel/jobs"
+	"github.com/rennerdo30/webencode/internal/kernel/keys"
+	"github.com/rennerdo30/webencode/internal/kernel/profiles"
+	"github.com/rennerdo30/webencode/internal/kernel/usage"
+	"github.com/rennerdo30/webencode/internal/kernel/workers"
+	"github.com/rennerdo30/webencode/internal/metrics"
+	"github.com/rennerdo30/webencode/internal/trace"
+	"github.com/rennerdo30/webencode/pkg/errcode"
+	"github.com/rennerdo30/webencode/pkg/plugin"
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// maxAttempts is how often a task is retried before its job fails.
+const maxAttempts = 4
+
+// nodeLocalFailureLimit is how many node-local failures on the same
+// worker a task tolerates before that worker is excluded for it.
+const nodeLocalFailureLimit = 2
+
+// Orchestrator owns task state and dispatch.
+type Orchestrator struct {
+	Jobs    *jobs.Manager
+	Workers *workers.Manager
+	Events  *events.Log
+	// Profiles, Storage and Publisher feed fan-out and publish hooks;
+	// any of them may be nil.
+	Profiles  *profiles.Registry
+	Storage   func() (plugin.StorageService, error)
+	Publisher func(name string) (plugin.PublisherService, error)
+	// Assets, when set, catalogs job sources and reported outputs.
+	Assets *assets.Catalog
+	// Usage, when set, meters consumption and rejects submissions over
+	// quota.
+	Usage *usage.Accountant
+	// Keys, when set, issues segment-encryption keys for encrypted HLS
+	// profiles; KeyURLBase prefixes the delivery URIs written into
+	// manifests (empty emits kernel-relative URIs).
+	Keys       keys.Provider
+	KeyURLBase string
+	// Scheduler picks the next pending task when MaxInflight caps
+	// concurrency; nil or MaxInflight <= 0 dispatches immediately.
+	Scheduler Policy
+	// MaxInflight bounds dispatched-but-unfinished tasks; 0 is
+	// unlimited.
+	MaxInflight int
+	// MaxRuntime caps task execution time per type; the Watchdog fails
+	// tasks that overrun. Absent types are uncapped.
+	MaxRuntime map[types.TaskType]time.Duration
+	// BackendRegions maps storage backend aliases to their region;
+	// tasks of jobs pinned to a backend then prefer workers labelled
+	// with the same region, keeping encode traffic off cross-region
+	// links.
+	BackendRegions map[string]string
+
+	conn *nats.Conn
+
+	mu    sync.Mutex
+	tasks map[string]*types.Task
+	// outputs indexes completed-task artifacts per job.
+	outputs map[string][]types.Artifact
+	// pending holds undispatched tasks while scheduling is capped;
+	// inflight tracks dispatched, unfinished task IDs.
+	pending  []*types.Task
+	inflight map[string]bool
+	// deadLetters preserves tasks that exhausted retries, by task ID.
+	deadLetters map[string]*DeadLetter
+	// workerFailures counts node-local failures per task per worker.
+	workerFailures map[string]map[string]int
+	// spans holds the open dispatch-to-completion trace span per task.
+	spans map[string]*trace.Span
+	// retryPolicies overrides the default retry behavior per task type.
+	retryPolicies map[types.TaskType]RetryPolicy
+	// progress holds the downsampled encode progress series per task.
+	progress map[string][]ProgressSample
+	// speedSum/speedCount average the sampled ffmpeg speed factors.
+	speedSum   float64
+	speedCount int
+}
+
+// New returns an orchestrator publishing on the given connection.
+func New(conn *nats.Conn, jobManager *jobs.Manager, workerManager *workers.Manager, eventLog *events.Log) *Orchestrator {
+	o := &Orchestrator{
+		Jobs:           jobManager,
+		Workers:        workerManager,
+		Events:         eventLog,
+		conn:           conn,
+		tasks:          make(map[string]*types.Task),
+		outputs:        make(map[string][]types.Artifact),
+		inflight:       make(map[string]bool),
+		deadLetters:    make(map[string]*DeadLetter),
+		workerFailures: make(map[string]map[string]int),
+		spans:          make(map[string]*trace.Span),
+		progress:       make(map[string][]ProgressSample),
+	}
+	o.registerQueueMetrics()
+	return o
+}
+
+// Start subscribes to worker task events and log lines.
+func (o *Orchestrator) Start() error {
+	eventSub, err := o.conn.Subscribe(bus.SubjectTaskEvents, func(msg *nats.Msg) {
+		var ev types.TaskEvent
+		if err := json.Unmarshal(msg.Data, &ev); err != nil {
+			log.Printf("orchestrator: malformed task event: %v", err)
+			return
+		}
+		o.handleEvent(ev)
+	})
+	if err != nil {
+		return err
+	}
+	logSub, err := o.conn.Subscribe(bus.SubjectTaskLogs, func(msg *nats.Msg) {
+		var tl types.TaskLog
+		if err := json.Unmarshal(msg.Data, &tl); err != nil {
+			log.Printf("orchestrator: malformed task log: %v", err)
+			return
+		}
+		if !o.Workers.Authenticate(tl.WorkerID, tl.Token) {
+			return
+		}
+		if speed, ok := parseEncodeSpeed(tl.Line); ok {
+			o.observeEncodeSpeed(speed)
+		}
+		if sample, ok := parseProgress(tl.Line, time.Now().UTC()); ok {
+			o.recordProgress(tl.TaskID, sample)
+		}
+		o.Events.Append(events.Event{
+			Type:     "job.log",
+			Resource: tl.JobID,
+			Message:  tl.Line,
+			Data: map[string]interface{}{
+				"level":     tl.Level,
+				"task_id":   tl.TaskID,
+				"worker_id": tl.WorkerID,
+			},
+		})
+	})
+	if err != nil {
+		return err
+	}
+	// Consumer lag by subject: messages NATS has delivered that the
+	// kernel has not processed yet.
+	subs := map[string]*nats.Subscription{
+		bus.SubjectTaskEvents: eventSub,
+		bus.SubjectTaskLogs:   logSub,
+	}
+	metrics.NewGaugeFunc("webencode_bus_pending_messages",
+		"Bus messages delivered but not yet consumed, by subject.",
+		[]string{"subject"}, func() map[string]float64 {
+			out := make(map[string]float64, len(subs))
+			for subject, sub := range subs {
+				n, _, err := sub.Pending()
+				if err != nil {
+					continue
+				}
+				out[metrics.Key(subject)] = float64(n)
+			}
+			return out
+		})
+	return nil
+}
+
+// extractAudioProfile is the synthetic profile behind extract-audio
+// jobs: drop the video stream and copy the audio track untouched.
+var extractAudioProfile = &types.Profile{
+	ID:         "extract-audio",
+	Name:       "extract-audio",
+	AudioOnly:  true,
+	AudioCodec: "copy",
+}
+
+// SubmitJob creates and dispatches one task per profile of the job.
+// Jobs with an ingest spec first get a single ingest task; the
+// transcode tasks launch against the fetched source when it completes.
+func (o *Orchestrator) SubmitJob(job *types.Job, jobProfiles []*types.Profile) error {
+	if o.Usage != nil {
+		if err := o.Usage.Authorize(job.UserID); err != nil {
+			_ = o.Jobs.SetStatus(job.ID, types.JobStatusFailed, err.Error())
+			return err
+		}
+		o.Usage.JobStarted(job.UserID, job.ID)
+	}
+	if o.Assets != nil {
+		o.Assets.RegisterSource(job)
+	}
+	if job.Ingest != nil {
+		now := time.Now().UTC()
+		selector, preferred := o.jobSelector(job)
+		return o.enqueue([]*types.Task{{
+			ID:                uuid.NewString(),
+			JobID:             job.ID,
+			Type:              types.TaskTypeIngest,
+			UserID:            job.UserID,
+			Deadline:          job.Deadline,
+			SourceURL:         job.SourceURL,
+			OutputPrefix:      job.OutputPrefix + "/source",
+			Ingest:            job.Ingest,
+			StorageBackend:    job.StorageBackend,
+			Selector:          selector,
+			SelectorPreferred: preferred,
+			Status:            types.TaskStatusPending,
+			CreatedAt:         now,
+			UpdatedAt:         now,
+		}})
+	}
+	return o.launchTasks(job, jobProfiles)
+}
+
+// launchTasks builds and dispatches the transcode tasks of a job.
+func (o *Orchestrator) launchTasks(job *types.Job, jobProfiles []*types.Profile) error {
+	now := time.Now().UTC()
+	if job.ExtractAudio {
+		jobProfiles = append(jobProfiles, extractAudioProfile)
+	}
+	selector, preferred := o.jobSelector(job)
+	tasks := make([]*types.Task, 0, len(jobProfiles))
+	for _, p := range jobProfiles {
+		t := &types.Task{
+			ID:                uuid.NewString(),
+			JobID:             job.ID,
+			Type:              types.TaskTypeTranscode,
+			UserID:            job.UserID,
+			Deadline:          job.Deadline,
+			SourceURL:         job.SourceURL,
+			OutputPrefix:      job.OutputPrefix,
+			Profile:           p,
+			StorageBackend:    job.StorageBackend,
+			Selector:          selector,
+			SelectorPreferred: preferred,
+			SeekSec:           job.TrimStartSec,
+			DurationSec:       job.TrimDurationSec,
+			Edit:              job.Edit,
+			Status:            types.TaskStatusPending,
+			CreatedAt:         now,
+			UpdatedAt:         now,
+		}
+		if enc := p.Container.Encryption; enc != nil && o.Keys != nil {
+			hlsKeys, err := o.issueHLSKeys(job, p)
+			if err != nil {
+				_ = o.Jobs.SetStatus(job.ID, types.JobStatusFailed, err.Error())
+				return err
+			}
+			t.HLSKeys = hlsKeys
+		}
+		tasks = append(tasks, t)
+	}
+	return o.enqueue(tasks)
+}
+
+// jobSelector derives the label selector for a job's tasks: an
+// explicit job selector is a hard requirement; otherwise a job pinned
+// to a storage backend with a known region prefers workers in that
+// region.
+func (o *Orchestrator) jobSelector(job *types.Job) (selector map[string]string, preferred bool) {
+	if len(job.Selector) > 0 {
+		return job.Selector, false
+	}
+	if region := o.BackendRegions[job.StorageBackend]; region != "" {
+		return map[string]string{"region": region}, true
+	}
+	return nil, false
+}
+
+// enqueue stores tasks and hands them to dispatch, through the bounded
+// queue when scheduling is capped.
+func (o *Orchestrator) enqueue(tasks []*types.Task) error {
+	o.mu.Lock()
+	for _, t := range tasks {
+		o.tasks[t.ID] = t
+	}
+	o.mu.Unlock()
+	if o.scheduled() {
+		o.mu.Lock()
+		o.pending = append(o.pending, tasks...)
+		o.mu.Unlock()
+		o.pump()
+		return nil
+	}
+	for _, t := range tasks {
+		if err := o.dispatchTracked(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ingestCompleted repoints the job at the fetched source and launches
+// its transcode tasks.
+func (o *Orchestrator) ingestCompleted(t *types.Task, ev types.TaskEvent) {
+	if len(ev.Artifacts) == 0 {
+		_ = o.Jobs.SetStatus(t.JobID, types.JobStatusFailed, "ingest produced no output")
+		return
+	}
+	source := ev.Artifacts[0].Key
+	// Storage keys aren't fetchable as-is; presign a long-lived URL the
+	// transcode workers can read from. Local paths pass through.
+	if o.Storage != nil && !strings.HasPrefix(source, "/") {
+		if storage, err := o.Storage(); err == nil {
+			if url, err := storage.PresignGet(source, 24*time.Hour); err == nil {
+				source = url
+			}
+		}
+	}
+	if err := o.Jobs.SetSourceURL(t.JobID, source); err != nil {
+		log.Printf("orchestrator: record ingested source for job %s: %v", t.JobID, err)
+		return
+	}
+	job, err := o.Jobs.Get(t.JobID)
+	if err != nil {
+		log.Printf("orchestrator: load job %s after ingest: %v", t.JobID, err)
+		return
+	}
+	var jobProfiles []*types.Profile
+	if o.Profiles != nil {
+		for _, id := range job.ProfileIDs {
+			p, err := o.Profiles.Get(id)
+			if err != nil {
+				_ = o.Jobs.SetStatus(job.ID, types.JobStatusFailed, "unknown profile "+id)
+				return
+			}
+			jobProfiles = append(jobProfiles, p)
+		}
+	}
+	if err := o.launchTasks(job, jobProfiles); err != nil {
+		log.Printf("orchestrator: launch tasks for ingested job %s: %v", job.ID, err)
+	}
+}
+
+// rotationKeyPool is how many keys an encrypted task with rotation gets
+// up front; the worker cycles through them as the interval elapses.
+const rotationKeyPool = 16
+
+// issueHLSKeys provisions the encryption keys for one encrypted HLS
+// task: a single key, or a rotation pool when the profile rotates.
+func (o *Orchestrator) issueHLSKeys(job *types.Job, p *types.Profile) ([]types.HLSKey, error) {
+	n := 1
+	if p.Container.Encryption.KeyRotationSegments > 0 {
+		n = rotationKeyPool
+	}
+	out := make([]types.HLSKey, 0, n)
+	for i := 0; i < n; i++ {
+		k, err := o.Keys.Issue(job.ID, p.ID)
+		if err != nil {
+			return nil, fmt.Errorf("issue HLS key: %w", err)
+		}
+		out = append(out, types.HLSKey{
+			ID:  k.ID,
+			URI: o.KeyURLBase + "/v1/hls/keys/" + k.ID,
+			Key: k.Value,
+		})
+	}
+	return out, nil
+}
+
+// SubmitBenchmark dispatches a benchmark task pinned to one worker and
+// returns it so callers can track completion.
+func (o *Orchestrator) SubmitBenchmark(workerID string) (*types.Task, error) {
+	now := time.Now().UTC()
+	t := &types.Task{
+		ID:           uuid.NewString(),
+		Type:         types.TaskTypeBenchmark,
+		TargetWorker: workerID,
+		Status:       types.TaskStatusPending,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := o.enqueue([]*types.Task{t}); err != nil {
+		return nil, err
+	}
+	copied := *t
+	return &copied, nil
+}
+
+// scheduled reports whether dispatch runs through the bounded queue.
+func (o *Orchestrator) scheduled() bool {
+	return o.Scheduler != nil && o.MaxInflight > 0
+}
+
+// pump releases pending tasks through the scheduler policy until the
+// in-flight cap is reached.
+func (o *Orchestrator) pump() {
+	for {
+		o.mu.Lock()
+		if len(o.pending) == 0 || len(o.inflight) >= o.MaxInflight {
+			o.mu.Unlock()
+			return
+		}
+		running := make([]*types.Task, 0, len(o.inflight))
+		for id := range o.inflight {
+			if t, ok := o.tasks[id]; ok {
+				copied := *t
+				running = append(running, &copied)
+			}
+		}
+		i := o.Scheduler.Next(o.pending, running)
+		if i < 0 || i >= len(o.pending) {
+			o.mu.Unlock()
+			return
+		}
+		next := o.pending[i]
+		o.pending = append(o.pending[:i], o.pending[i+1:]...)
+		o.inflight[next.ID] = true
+		copied := *next
+		o.mu.Unlock()
+		if err := o.dispatch(&copied); err != nil {
+			log.Printf("orchestrator: dispatch task %s: %v", copied.ID, err)
+			o.mu.Lock()
+			delete(o.inflight, copied.ID)
+			o.pending = append(o.pending, next)
+			o.mu.Unlock()
+			return
+		}
+	}
+}
+
+// dispatchTracked publishes a task immediately, keeping the in-flight
+// set coherent for the unscheduled path.
+func (o *Orchestrator) dispatchTracked(t *types.Task) error {
+	o.mu.Lock()
+	o.inflight[t.ID] = true
+	o.mu.Unlock()
+	if err := o.dispatch(t); err != nil {
+		o.mu.Lock()
+		delete(o.inflight, t.ID)
+		o.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Tasks returns the tasks of one job, oldest first.
+func (o *Orchestrator) Tasks(jobID string) []*types.Task {
+	o.mu.Lock()
+	out := make([]*types.Task, 0)
+	for _, t := range o.tasks {
+		if t.JobID == jobID {
+			copied := *t
+			out = append(out, &copied)
+		}
+	}
+	o.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out
+}
+
+// Outputs returns the artifacts completed tasks of a job have reported,
+// in completion order.
+func (o *Orchestrator) Outputs(jobID string) []types.Artifact {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]types.Artifact(nil), o.outputs[jobID]...)
+}
+
+// dispatch publishes a task on the bus. First dispatches open a trace
+// span that stays open until the task finishes; retries rejoin it.
+func (o *Orchestrator) dispatch(t *types.Task) error {
+	if t.TraceParent == "" {
+		ctx, span := trace.StartSpan(context.Background(), "task."+string(t.Type))
+		span.SetAttribute("task.id", t.ID)
+		span.SetAttribute("job.id", t.JobID)
+		t.TraceParent = trace.TraceParent(ctx)
+		o.mu.Lock()
+		if span != nil {
+			o.spans[t.ID] = span
+		}
+		if stored, ok := o.tasks[t.ID]; ok {
+			stored.TraceParent = t.TraceParent
+		}
+		o.mu.Unlock()
+	}
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	return o.conn.Publish(bus.TaskSubject(string(t.Type)), data)
+}
+
+// endTaskSpanLocked closes the task's trace span. Caller holds o.mu.
+func (o *Orchestrator) endTaskSpanLocked(taskID string, err error) {
+	if span, ok := o.spans[taskID]; ok {
+		span.End(err)
+		delete(o.spans, taskID)
+	}
+}
+
+// handleEvent applies one worker event to task and job state.
+func (o *Orchestrator) handleEvent(ev types.TaskEvent) {
+	if !o.Workers.Authenticate(ev.WorkerID, ev.Token) {
+		log.Printf("orchestrator: rejected task event from unauthenticated worker %s", ev.WorkerID)
+		return
+	}
+	o.mu.Lock()
+	t, ok := o.tasks[ev.TaskID]
+	if !ok {
+		o.mu.Unlock()
+		return
+	}
+	t.UpdatedAt = time.Now().UTC()
+	t.WorkerID = ev.WorkerID
+	// Lifecycle transitions land in the event log next to worker log
+	// lines, so one subscription tails a job end to end.
+	eventData := map[string]interface{}{
+		"task_id":   ev.TaskID,
+		"worker_id": ev.WorkerID,
+	}
+	if ev.Status == types.TaskStatusFailed {
+		// Failure events carry the catalog code so webhook consumers
+		// branch on it instead of parsing the message.
+		eventData["error_class"] = ev.ErrorClass
+		eventData["error_code"] = errcode.FromErrorClass(string(ev.ErrorClass))
+	}
+	o.Events.Append(events.Event{
+		Type:     "task." + string(ev.Status),
+		Resource: t.JobID,
+		Message:  ev.Error,
+		Data:     eventData,
+	})
+
+	switch ev.Status {
+	case types.TaskStatusRunning:
+		t.Status = types.TaskStatusRunning
+		o.mu.Unlock()
+		if o.Usage != nil {
+			if job, err := o.Jobs.Get(t.JobID); err == nil {
+				o.Usage.TaskStarted(ev.TaskID, job.UserID)
+			}
+		}
+		_ = o.Jobs.SetStatus(t.JobID, types.JobStatusRunning, "")
+		return
+	case types.TaskStatusCompleted:
+		t.Status = types.TaskStatusCompleted
+		delete(o.inflight, t.ID)
+		tasksFinished.Inc(string(t.Type), "completed")
+		o.endTaskSpanLocked(t.ID, nil)
+		if t.Profile != nil {
+			taskDuration.Observe(time.Since(t.CreatedAt).Seconds(), t.Profile.Name)
+		}
+		if t.Type == types.TaskTypeBenchmark {
+			o.mu.Unlock()
+			if o.scheduled() {
+				o.pump()
+			}
+			if len(ev.Benchmark) > 0 {
+				o.Workers.RecordBenchmark(ev.WorkerID, ev.Benchmark)
+				o.Events.Append(events.Event{
+					Type:     "worker.benchmark",
+					Resource: ev.WorkerID,
+					Message:  "benchmark completed",
+					Data:     map[string]interface{}{"scores": ev.Benchmark},
+				})
+			}
+			return
+		}
+		if t.Type == types.TaskTypeIngest {
+			o.mu.Unlock()
+			if o.scheduled() {
+				o.pump()
+			}
+			if o.Usage != nil {
+				o.Usage.TaskFinished(ev.TaskID, 0)
+			}
+			o.ingestCompleted(t, ev)
+			return
+		}
+		o.outputs[t.JobID] = append(o.outputs[t.JobID], ev.Artifacts...)
+		jobDone, jobFailed := o.jobOutcomeLocked(t.JobID)
+		o.mu.Unlock()
+		if o.scheduled() {
+			o.pump()
+		}
+		if o.Assets != nil && len(ev.Artifacts) > 0 {
+			if job, err := o.Jobs.Get(t.JobID); err == nil {
+				for _, a := range ev.Artifacts {
+					o.Assets.RegisterOutput(job, a)
+				}
+			}
+		}
+		if o.Usage != nil {
+			var written int64
+			for _, a := range ev.Artifacts {
+				written += a.SizeBytes
+			}
+			o.Usage.TaskFinished(ev.TaskID, written)
+		}
+		if jobDone {
+			status := types.JobStatusCompleted
+			if jobFailed {
+				status = types.JobStatusFailed
+			}
+			_ = o.Jobs.SetStatus(t.JobID, status, "")
+			if job, err := o.Jobs.Get(t.JobID); err == nil {
+				jobDuration.Observe(time.Since(job.CreatedAt).Seconds())
+			}
+			if o.Usage != nil {
+				o.Usage.JobFinished(t.JobID)
+			}
+			if status == types.JobStatusCompleted {
+				go o.jobCompleted(t.JobID)
+			}
+		}
+		return
+	case types.TaskStatusFailed:
+		retry, delay := o.handleFailureLocked(t, ev)
+		delete(o.inflight, t.ID)
+		o.mu.Unlock()
+		if retry != nil {
+			o.scheduleRetry(retry, delay)
+			return
+		}
+		if o.scheduled() {
+			o.pump()
+		}
+		_ = o.Jobs.SetStatus(t.JobID, types.JobStatusFailed, ev.Error)
+		o.mu.Lock()
+		failed := *t
+		o.mu.Unlock()
+		o.deadLetter(&failed, ev)
+		if o.Usage != nil {
+			o.Usage.TaskFinished(ev.TaskID, 0)
+			o.Usage.JobFinished(t.JobID)
+		}
+	}
+}
+
+// handleFailureLocked updates failure bookkeeping and returns the task
+// to redispatch with its backoff delay, or nil when it is permanently
+// failed. Caller holds o.mu.
+func (o *Orchestrator) handleFailureLocked(t *types.Task, ev types.TaskEvent) (*types.Task, time.Duration) {
+	t.Attempts++
+	t.Error = ev.Error
+	policy := o.policyForLocked(t.Type)
+
+	if ev.ErrorClass.NodeLocal() && ev.WorkerID != "" {
+		perWorker := o.workerFailures[t.ID]
+		if perWorker == nil {
+			perWorker = make(map[string]int)
+			o.workerFailures[t.ID] = perWorker
+		}
+		perWorker[ev.WorkerID]++
+		if perWorker[ev.WorkerID] >= nodeLocalFailureLimit && !t.Excluded(ev.WorkerID) {
+			// The same node keeps failing this task with a node-local
+			// error: stop bouncing the task back to it and flag the
+			// worker so operators notice.
+			t.ExcludedWorkers = append(t.ExcludedWorkers, ev.WorkerID)
+			reason := fmt.Sprintf("repeated %s failures on task %s", ev.ErrorClass, t.ID)
+			o.Workers.FlagUnhealthy(ev.WorkerID, reason)
+			o.Events.Append(events.Event{
+				Type:     "task.worker_excluded",
+				Resource: t.ID,
+				Message:  reason,
+				Data: map[string]interface{}{
+					"worker_id":   ev.WorkerID,
+					"error_class": ev.ErrorClass,
+				},
+			})
+		}
+	}
+
+	if t.Attempts >= policy.MaxAttempts || !policy.retryable(ev.ErrorClass) {
+		t.Status = types.TaskStatusFailed
+		tasksFinished.Inc(string(t.Type), "failed")
+		o.endTaskSpanLocked(t.ID, fmt.Errorf("%s", ev.Error))
+		return nil, 0
+	}
+	t.Status = types.TaskStatusPending
+	taskRetries.Inc(string(t.Type))
+	copied := *t
+	return &copied, policy.delay(t.Attempts)
+}
+
+// jobOutcomeLocked reports whether all tasks of a job are finished and
+// whether any failed. Caller holds o.mu.
+func (o *Orchestrator) jobOutcomeLocked(jobID string) (done, failed bool) {
+	done = true
+	for _, t := range o.tasks {
+		if t.JobID != jobID {
+			continue
+		}
+		switch t.Status {
+		case types.TaskStatusCompleted:
+		case types.TaskStatusFailed:
+			failed = true
+		default:
+			done = false
+		}
+	}
+	return done, failed
+}
@@ -0,0 +1,86 @@
+package orchestrator
+
+import (
+	"log"
+	"time"
+
+	"github.com/rennerdo30/webencode/internal/kernel/events"
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// reconcileGrace is how long a dispatched task may go unclaimed before
+// reconciliation redispatches it. It must comfortably exceed the
+// heartbeat interval so a task a worker just picked up is not requeued
+// before its claim shows up.
+const reconcileGrace = 2 * time.Minute
+
+// Reconcile periodically cross-checks in-flight task state against the
+// task claims workers report in their heartbeats, redispatching tasks
+// that are stuck: published but never picked up, or marked running on
+// a worker that no longer claims them. The latter happens when a
+// worker dies without a failure event or the kernel restarts while
+// tasks are in flight — task events travel over core NATS, so anything
+// published while the kernel was down is simply gone. Run it on one
+// replica (leader-gated when the kernel scales out).
+func (o *Orchestrator) Reconcile(interval time.Duration, stop <-chan struct{}) {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			o.reconcile()
+		}
+	}
+}
+
+// reconcile makes one sweep over the in-flight set.
+func (o *Orchestrator) reconcile() {
+	claimed := o.Workers.Claimed()
+	now := time.Now().UTC()
+	var stuck []*types.Task
+
+	o.mu.Lock()
+	for id := range o.inflight {
+		t, ok := o.tasks[id]
+		if !ok {
+			delete(o.inflight, id)
+			continue
+		}
+		if _, ok := claimed[id]; ok {
+			continue
+		}
+		switch t.Status {
+		case types.TaskStatusPending, types.TaskStatusRunning:
+		default:
+			continue
+		}
+		if now.Sub(t.UpdatedAt) < reconcileGrace {
+			continue
+		}
+		t.Status = types.TaskStatusPending
+		t.UpdatedAt = now
+		copied := *t
+		stuck = append(stuck, &copied)
+	}
+	o.mu.Unlock()
+
+	for _, t := range stuck {
+		o.Events.Append(events.Event{
+			Type:     "task.requeued",
+			Resource: t.JobID,
+			Message:  "no worker claims the task, redispatching",
+			Data: map[string]interface{}{
+				"task_id": t.ID,
+			},
+		})
+		log.Printf("orchestrator: redispatching unclaimed task %s (job %s)", t.ID, t.JobID)
+		if err := o.dispatch(t); err != nil {
+			log.Printf("orchestrator: redispatch task %s: %v", t.ID, err)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package orchestrator
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// maxBackoff caps retry delays so a misconfigured base can't park
+// tasks for hours.
+const maxBackoff = 5 * time.Minute
+
+// RetryPolicy controls how failures of one task type are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of tries before the task is
+	// permanently failed.
+	MaxAttempts int `json:"max_attempts"`
+	// Backoff is the delay strategy between attempts: "none", "fixed"
+	// or "exponential" (base doubling per attempt).
+	Backoff string `json:"backoff"`
+	// BackoffBaseSec is the first retry delay in seconds; ignored for
+	// "none".
+	BackoffBaseSec float64 `json:"backoff_base_sec,omitempty"`
+	// RetryableClasses restricts retries to these error classes; empty
+	// retries every class.
+	RetryableClasses []types.ErrorClass `json:"retryable_classes,omitempty"`
+}
+
+// defaultRetryPolicy preserves the orchestrator's historical behavior:
+// four immediate attempts regardless of error class.
+func defaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: maxAttempts, Backoff: "none"}
+}
+
+// retryable reports whether the policy retries the given error class.
+func (p RetryPolicy) retryable(class types.ErrorClass) bool {
+	if len(p.RetryableClasses) == 0 {
+		return true
+	}
+	for _, c := range p.RetryableClasses {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// delay is how long to wait before the given attempt (1 = first
+// retry).
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	base := time.Duration(p.BackoffBaseSec * float64(time.Second))
+	var d time.Duration
+	switch p.Backoff {
+	case "fixed":
+		d = base
+	case "exponential":
+		d = base
+		for i := 1; i < attempt; i++ {
+			d *= 2
+			if d >= maxBackoff {
+				break
+			}
+		}
+	default:
+		return 0
+	}
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+	return d
+}
+
+// validRetryClasses are the classes a policy may name.
+var validRetryClasses = map[types.ErrorClass]bool{
+	types.ErrorClassNone:     true,
+	types.ErrorClassDiskFull: true,
+	types.ErrorClassGPU:      true,
+	types.ErrorClassIO:       true,
+	types.ErrorClassEncode:   true,
+	types.ErrorClassSource:   true,
+}
+
+// validateRetryPolicy rejects policies the orchestrator can't honour.
+func validateRetryPolicy(p RetryPolicy) error {
+	if p.MaxAttempts < 1 {
+		return fmt.Errorf("max_attempts must be at least 1")
+	}
+	switch p.Backoff {
+	case "none", "fixed", "exponential":
+	default:
+		return fmt.Errorf("unknown backoff strategy %q (want none, fixed or exponential)", p.Backoff)
+	}
+	if p.BackoffBaseSec < 0 {
+		return fmt.Errorf("backoff_base_sec must be non-negative")
+	}
+	if p.Backoff != "none" && p.BackoffBaseSec == 0 {
+		return fmt.Errorf("backoff %q needs a backoff_base_sec", p.Backoff)
+	}
+	for _, c := range p.RetryableClasses {
+		if !validRetryClasses[c] {
+			return fmt.Errorf("unknown error class %q", c)
+		}
+	}
+	return nil
+}
+
+// retryTaskTypes are the task types a policy can be set for.
+var retryTaskTypes = map[types.TaskType]bool{
+	types.TaskTypeProbe:     true,
+	types.TaskTypeTranscode: true,
+	types.TaskTypeIngest:    true,
+}
+
+// RetryPolicies returns the effective policy per task type, filling
+// defaults for types without an override.
+func (o *Orchestrator) RetryPolicies() map[types.TaskType]RetryPolicy {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	out := make(map[types.TaskType]RetryPolicy, len(retryTaskTypes))
+	for tt := range retryTaskTypes {
+		out[tt] = o.policyForLocked(tt)
+	}
+	return out
+}
+
+// SetRetryPolicy installs the retry policy for one task type.
+func (o *Orchestrator) SetRetryPolicy(taskType types.TaskType, p RetryPolicy) error {
+	if !retryTaskTypes[taskType] {
+		return fmt.Errorf("unknown task type %q", taskType)
+	}
+	if err := validateRetryPolicy(p); err != nil {
+		return err
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.retryPolicies == nil {
+		o.retryPolicies = make(map[types.TaskType]RetryPolicy)
+	}
+	o.retryPolicies[taskType] = p
+	return nil
+}
+
+// policyForLocked resolves the policy for a task type. Caller holds
+// o.mu.
+func (o *Orchestrator) policyForLocked(taskType types.TaskType) RetryPolicy {
+	if p, ok := o.retryPolicies[taskType]; ok {
+		return p
+	}
+	return defaultRetryPolicy()
+}
+
+// scheduleRetry redispatches a retry, after the policy's backoff delay
+// when one applies.
+func (o *Orchestrator) scheduleRetry(t *types.Task, delay time.Duration) {
+	redispatch := func() {
+		if o.scheduled() {
+			o.mu.Lock()
+			o.pending = append(o.pending, t)
+			o.mu.Unlock()
+			o.pump()
+			return
+		}
+		if err := o.dispatchTracked(t); err != nil {
+			log.Printf("orchestrator: redispatch task %s: %v", t.ID, err)
+		}
+	}
+	if delay <= 0 {
+		redispatch()
+		return
+	}
+	time.AfterFunc(delay, redispatch)
+}
@@ -0,0 +1,100 @@
+package orchestrator
+
+import (
+	"time"
+
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// Policy decides which pending task is dispatched next. Implementations
+// see the undispatched backlog and the tasks currently in flight and
+// return the index into pending to release, or -1 to hold everything.
+type Policy interface {
+	Name() string
+	Next(pending, running []*types.Task) int
+}
+
+// PolicyByName resolves a configured policy name; unknown names fall
+// back to FIFO.
+func PolicyByName(name string) Policy {
+	switch name {
+	case "fair":
+		return fairSharePolicy{}
+	case "deadline":
+		return deadlinePolicy{}
+	default:
+		return fifoPolicy{}
+	}
+}
+
+// fifoPolicy releases the oldest pending task.
+type fifoPolicy struct{}
+
+func (fifoPolicy) Name() string { return "fifo" }
+
+func (fifoPolicy) Next(pending, _ []*types.Task) int {
+	return oldest(pending, func(int) bool { return true })
+}
+
+// fairSharePolicy releases the oldest task of whichever user has the
+// fewest tasks in flight, so one user's bulk submission cannot starve
+// everyone else.
+type fairSharePolicy struct{}
+
+func (fairSharePolicy) Name() string { return "fair" }
+
+func (fairSharePolicy) Next(pending, running []*types.Task) int {
+	if len(pending) == 0 {
+		return -1
+	}
+	inflight := make(map[string]int)
+	for _, t := range running {
+		inflight[t.UserID]++
+	}
+	bestUser := ""
+	bestCount := -1
+	for _, t := range pending {
+		if c := inflight[t.UserID]; bestCount == -1 || c < bestCount {
+			bestUser, bestCount = t.UserID, c
+		}
+	}
+	return oldest(pending, func(i int) bool { return pending[i].UserID == bestUser })
+}
+
+// deadlinePolicy releases the task with the earliest deadline;
+// deadline-less tasks run FIFO behind every deadlined one.
+type deadlinePolicy struct{}
+
+func (deadlinePolicy) Name() string { return "deadline" }
+
+func (deadlinePolicy) Next(pending, _ []*types.Task) int {
+	best := -1
+	var bestDeadline time.Time
+	for i, t := range pending {
+		if t.Deadline.IsZero() {
+			continue
+		}
+		if best == -1 || t.Deadline.Before(bestDeadline) {
+			best, bestDeadline = i, t.Deadline
+		}
+	}
+	if best != -1 {
+		return best
+	}
+	return oldest(pending, func(int) bool { return true })
+}
+
+// oldest returns the index of the earliest-created pending task
+// matching the filter, or -1.
+func oldest(pending []*types.Task, match func(int) bool) int {
+	best := -1
+	for i, t := range pending {
+		if !match(i) {
+			continue
+		}
+		if best == -1 || t.CreatedAt.Before(pending[best].CreatedAt) {
+			best = i
+		}
+	}
+	return best
+}
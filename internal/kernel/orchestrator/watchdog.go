@@ -0,0 +1,139 @@
+package orchestrator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rennerdo30/webencode/internal/kernel/events"
+	"github.com/rennerdo30/webencode/pkg/errcode"
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// Watchdog periodically sweeps running tasks against the per-type
+// runtime caps in MaxRuntime and fails the ones that overran, sending
+// them through the normal retry machinery. Pair it with wiring
+// RequeueWorkerTasks into the worker manager's OnUnhealthy hook so
+// tasks on a silent worker are requeued the moment its incident opens.
+func (o *Orchestrator) Watchdog(interval time.Duration, stop <-chan struct{}) {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			o.sweepOverruns()
+		}
+	}
+}
+
+// sweepOverruns fails running tasks that exceeded their type's cap.
+func (o *Orchestrator) sweepOverruns() {
+	now := time.Now().UTC()
+	type overrun struct {
+		taskID   string
+		workerID string
+		limit    time.Duration
+	}
+	var hits []overrun
+
+	o.mu.Lock()
+	for id := range o.inflight {
+		t, ok := o.tasks[id]
+		if !ok || t.Status != types.TaskStatusRunning {
+			continue
+		}
+		limit := o.MaxRuntime[t.Type]
+		if limit <= 0 {
+			continue
+		}
+		// UpdatedAt was last bumped by the running event, so this is
+		// how long the task has been executing.
+		if now.Sub(t.UpdatedAt) > limit {
+			hits = append(hits, overrun{taskID: id, workerID: t.WorkerID, limit: limit})
+		}
+	}
+	o.mu.Unlock()
+
+	for _, h := range hits {
+		o.failStalled(h.taskID, h.workerID, fmt.Sprintf("exceeded the %s runtime cap", h.limit), errcode.TaskOverrun)
+	}
+}
+
+// RequeueWorkerTasks sends every in-flight task a dead worker claimed
+// through the failure path, so they retry elsewhere instead of sitting
+// assigned to a node that will never report back. It plugs into the
+// worker manager's OnUnhealthy hook and returns the actions taken for
+// the incident record.
+func (o *Orchestrator) RequeueWorkerTasks(workerID string, taskIDs []string) []string {
+	var actions []string
+	for _, id := range taskIDs {
+		o.mu.Lock()
+		t, ok := o.tasks[id]
+		stalled := ok && o.inflight[id] && t.WorkerID == workerID
+		o.mu.Unlock()
+		if !stalled {
+			continue
+		}
+		if o.failStalled(id, workerID, "worker heartbeats stopped", errcode.WorkerLost) {
+			actions = append(actions, "requeued task "+id)
+		} else {
+			actions = append(actions, "failed task "+id)
+		}
+	}
+	return actions
+}
+
+// failStalled runs one stalled task through the same failure handling
+// a worker-reported failure gets, and reports whether it was requeued
+// (false: retries exhausted, it went to the dead letter queue).
+func (o *Orchestrator) failStalled(taskID, workerID, reason string, code errcode.Code) bool {
+	ev := types.TaskEvent{
+		TaskID:   taskID,
+		WorkerID: workerID,
+		Status:   types.TaskStatusFailed,
+		Error:    reason,
+	}
+
+	o.mu.Lock()
+	t, ok := o.tasks[taskID]
+	if !ok || !o.inflight[taskID] {
+		o.mu.Unlock()
+		return false
+	}
+	t.UpdatedAt = time.Now().UTC()
+	o.Events.Append(events.Event{
+		Type:     "task.stalled",
+		Resource: t.JobID,
+		Message:  reason,
+		Data: map[string]interface{}{
+			"task_id":    taskID,
+			"worker_id":  workerID,
+			"error_code": code,
+		},
+	})
+	retry, delay := o.handleFailureLocked(t, ev)
+	delete(o.inflight, taskID)
+	o.mu.Unlock()
+
+	if retry != nil {
+		o.scheduleRetry(retry, delay)
+		return true
+	}
+	if o.scheduled() {
+		o.pump()
+	}
+	_ = o.Jobs.SetStatus(t.JobID, types.JobStatusFailed, reason)
+	o.mu.Lock()
+	failed := *t
+	o.mu.Unlock()
+	o.deadLetter(&failed, ev)
+	if o.Usage != nil {
+		o.Usage.TaskFinished(taskID, 0)
+		o.Usage.JobFinished(t.JobID)
+	}
+	return false
+}
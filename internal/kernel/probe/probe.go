@@ -0,0 +1,334 @@
+// Package probe runs ffprobe against sources and keeps the full JSON
+// output alongside a structured deep media info report.
+package probe
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Track is one media stream (track) of a probed asset.
+type Track struct {
+	Index     int    `json:"index"`
+	Type      string `json:"type"` // video, audio, subtitle, data
+	Codec     string `json:"codec"`
+	CodecLong string `json:"codec_long,omitempty"`
+	Language  string `json:"language,omitempty"`
+
+	// Video details.
+	Width          int    `json:"width,omitempty"`
+	Height         int    `json:"height,omitempty"`
+	PixelFormat    string `json:"pixel_format,omitempty"`
+	BitDepth       int    `json:"bit_depth,omitempty"`
+	Framerate      string `json:"framerate,omitempty"`
+	ColorPrimaries string `json:"color_primaries,omitempty"`
+	ColorTransfer  string `json:"color_transfer,omitempty"`
+	ColorSpace     string `json:"color_space,omitempty"`
+	// HDR is derived from the transfer function: PQ (smpte2084) or HLG
+	// (arib-std-b67).
+	HDR string `json:"hdr,omitempty"`
+	// Rotation is the display rotation in degrees from stream side data
+	// or the legacy rotate tag.
+	Rotation int `json:"rotation,omitempty"`
+
+	// Audio details.
+	Channels      int    `json:"channels,omitempty"`
+	ChannelLayout string `json:"channel_layout,omitempty"`
+	SampleRate    int    `json:"sample_rate,omitempty"`
+
+	BitrateKbps int     `json:"bitrate_kbps,omitempty"`
+	DurationSec float64 `json:"duration_sec,omitempty"`
+}
+
+// Container summarizes the wrapping format.
+type Container struct {
+	Format      string            `json:"format"`
+	FormatLong  string            `json:"format_long,omitempty"`
+	DurationSec float64           `json:"duration_sec,omitempty"`
+	SizeBytes   int64             `json:"size_bytes,omitempty"`
+	BitrateKbps int               `json:"bitrate_kbps,omitempty"`
+	TrackCount  int               `json:"track_count"`
+	Tags        map[string]string `json:"tags,omitempty"`
+}
+
+// Chapter is one chapter marker of a probed asset.
+type Chapter struct {
+	ID       int64   `json:"id"`
+	Title    string  `json:"title,omitempty"`
+	StartSec float64 `json:"start_sec"`
+	EndSec   float64 `json:"end_sec"`
+}
+
+// Report is the deep media info view of one asset.
+type Report struct {
+	Container Container `json:"container"`
+	Tracks    []Track   `json:"tracks"`
+	Chapters  []Chapter `json:"chapters,omitempty"`
+	ProbedAt  time.Time `json:"probed_at"`
+}
+
+// Result pairs the structured report with the untouched ffprobe output
+// for consumers that need fields the summary doesn't carry.
+type Result struct {
+	Report *Report         `json:"report"`
+	Raw    json.RawMessage `json:"raw"`
+}
+
+// Store caches probe results by asset ID.
+type Store struct {
+	// FFprobePath overrides the ffprobe binary; empty means PATH.
+	FFprobePath string
+
+	mu      sync.Mutex
+	results map[string]*Result
+	// byContent caches by source identity (etag/size for URLs,
+	// size/mtime for files), so re-probing identical content is free
+	// even under a different asset ID.
+	byContent map[string]*Result
+}
+
+// NewStore returns an empty probe store.
+func NewStore() *Store {
+	return &Store{
+		results:   make(map[string]*Result),
+		byContent: make(map[string]*Result),
+	}
+}
+
+// Get returns the cached result for an asset, or nil.
+func (s *Store) Get(assetID string) *Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.results[assetID]
+}
+
+// Probe runs ffprobe on the source, caches the result under assetID,
+// and returns it. An existing cached result is returned as is.
+func (s *Store) Probe(assetID, sourceURL string) (*Result, error) {
+	if cached := s.Get(assetID); cached != nil {
+		return cached, nil
+	}
+	key := contentKey(sourceURL)
+	if key != "" {
+		s.mu.Lock()
+		if cached, ok := s.byContent[key]; ok {
+			s.results[assetID] = cached
+			s.mu.Unlock()
+			return cached, nil
+		}
+		s.mu.Unlock()
+	}
+	raw, err := s.run(sourceURL)
+	if err != nil {
+		return nil, err
+	}
+	report, err := Summarize(raw)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{Report: report, Raw: raw}
+	s.mu.Lock()
+	s.results[assetID] = result
+	if key != "" {
+		s.byContent[key] = result
+	}
+	s.mu.Unlock()
+	return result, nil
+}
+
+// contentKey derives a cheap source identity: etag (or last-modified)
+// plus size for HTTP sources, size plus mtime for local files. Empty
+// means the source cannot be fingerprinted without reading it.
+func contentKey(sourceURL string) string {
+	if strings.HasPrefix(sourceURL, "http://") || strings.HasPrefix(sourceURL, "https://") {
+		client := &http.Client{Timeout: 5 * time.Second}
+		resp, err := client.Head(sourceURL)
+		if err != nil {
+			return ""
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return ""
+		}
+		tag := resp.Header.Get("ETag")
+		if tag == "" {
+			tag = resp.Header.Get("Last-Modified")
+		}
+		if tag == "" {
+			return ""
+		}
+		return tag + "|" + resp.Header.Get("Content-Length")
+	}
+	info, err := os.Stat(sourceURL)
+	if err != nil || info.IsDir() {
+		return ""
+	}
+	return fmt.Sprintf("%s|%d|%d", sourceURL, info.Size(), info.ModTime().UnixNano())
+}
+
+// run executes the ffprobe pass.
+func (s *Store) run(sourceURL string) (json.RawMessage, error) {
+	ffprobe := s.FFprobePath
+	if ffprobe == "" {
+		ffprobe = "ffprobe"
+	}
+	cmd := exec.Command(ffprobe,
+		"-v", "error",
+		"-print_format", "json",
+		"-show_format", "-show_streams", "-show_chapters",
+		sourceURL,
+	)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffprobe: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// ffprobe JSON shapes, limited to the fields the report uses.
+type ffprobeOutput struct {
+	Streams  []ffprobeStream  `json:"streams"`
+	Format   ffprobeFormat    `json:"format"`
+	Chapters []ffprobeChapter `json:"chapters"`
+}
+
+type ffprobeChapter struct {
+	ID        int64             `json:"id"`
+	StartTime string            `json:"start_time"`
+	EndTime   string            `json:"end_time"`
+	Tags      map[string]string `json:"tags"`
+}
+
+type ffprobeSideData struct {
+	SideDataType string `json:"side_data_type"`
+	// Rotation arrives as a float in some builds; accept either.
+	Rotation json.Number `json:"rotation"`
+}
+
+type ffprobeStream struct {
+	Index            int               `json:"index"`
+	CodecType        string            `json:"codec_type"`
+	CodecName        string            `json:"codec_name"`
+	CodecLongName    string            `json:"codec_long_name"`
+	Width            int               `json:"width"`
+	Height           int               `json:"height"`
+	PixFmt           string            `json:"pix_fmt"`
+	BitsPerRawSample string            `json:"bits_per_raw_sample"`
+	RFrameRate       string            `json:"r_frame_rate"`
+	ColorPrimaries   string            `json:"color_primaries"`
+	ColorTransfer    string            `json:"color_transfer"`
+	ColorSpace       string            `json:"color_space"`
+	Channels         int               `json:"channels"`
+	ChannelLayout    string            `json:"channel_layout"`
+	SampleRate       string            `json:"sample_rate"`
+	BitRate          string            `json:"bit_rate"`
+	Duration         string            `json:"duration"`
+	Tags             map[string]string `json:"tags"`
+	SideDataList     []ffprobeSideData `json:"side_data_list"`
+}
+
+type ffprobeFormat struct {
+	FormatName     string            `json:"format_name"`
+	FormatLongName string            `json:"format_long_name"`
+	Duration       string            `json:"duration"`
+	Size           string            `json:"size"`
+	BitRate        string            `json:"bit_rate"`
+	NbStreams      int               `json:"nb_streams"`
+	Tags           map[string]string `json:"tags"`
+}
+
+// Summarize turns raw ffprobe JSON into the structured report.
+func Summarize(raw json.RawMessage) (*Report, error) {
+	var out ffprobeOutput
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("parse ffprobe output: %w", err)
+	}
+	report := &Report{
+		Container: Container{
+			Format:      out.Format.FormatName,
+			FormatLong:  out.Format.FormatLongName,
+			DurationSec: parseFloat(out.Format.Duration),
+			SizeBytes:   int64(parseFloat(out.Format.Size)),
+			BitrateKbps: int(parseFloat(out.Format.BitRate) / 1000),
+			TrackCount:  out.Format.NbStreams,
+			Tags:        out.Format.Tags,
+		},
+		ProbedAt: time.Now().UTC(),
+	}
+	for _, st := range out.Streams {
+		track := Track{
+			Index:          st.Index,
+			Type:           st.CodecType,
+			Codec:          st.CodecName,
+			CodecLong:      st.CodecLongName,
+			Language:       st.Tags["language"],
+			Width:          st.Width,
+			Height:         st.Height,
+			PixelFormat:    st.PixFmt,
+			BitDepth:       int(parseFloat(st.BitsPerRawSample)),
+			Framerate:      st.RFrameRate,
+			ColorPrimaries: st.ColorPrimaries,
+			ColorTransfer:  st.ColorTransfer,
+			ColorSpace:     st.ColorSpace,
+			HDR:            hdrKind(st.ColorTransfer),
+			Channels:       st.Channels,
+			ChannelLayout:  st.ChannelLayout,
+			SampleRate:     int(parseFloat(st.SampleRate)),
+			BitrateKbps:    int(parseFloat(st.BitRate) / 1000),
+			DurationSec:    parseFloat(st.Duration),
+			Rotation:       rotation(st),
+		}
+		report.Tracks = append(report.Tracks, track)
+	}
+	for _, ch := range out.Chapters {
+		report.Chapters = append(report.Chapters, Chapter{
+			ID:       ch.ID,
+			Title:    ch.Tags["title"],
+			StartSec: parseFloat(ch.StartTime),
+			EndSec:   parseFloat(ch.EndTime),
+		})
+	}
+	return report, nil
+}
+
+// rotation reads the display rotation from stream side data, falling
+// back to the legacy rotate tag older muxers write.
+func rotation(st ffprobeStream) int {
+	for _, sd := range st.SideDataList {
+		if sd.SideDataType == "Display Matrix" || sd.Rotation != "" {
+			if f, err := sd.Rotation.Float64(); err == nil && f != 0 {
+				return int(f)
+			}
+		}
+	}
+	return int(parseFloat(st.Tags["rotate"]))
+}
+
+// hdrKind maps the transfer function to an HDR label.
+func hdrKind(colorTransfer string) string {
+	switch colorTransfer {
+	case "smpte2084":
+		return "HDR10/PQ"
+	case "arib-std-b67":
+		return "HLG"
+	}
+	return ""
+}
+
+func parseFloat(s string) float64 {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return f
+}
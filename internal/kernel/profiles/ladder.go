@@ -0,0 +1,131 @@
+package profiles
+
+import (
+	"fmt"
+
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// LadderRequest describes the source (usually straight from a probe)
+// and the target codec an ABR ladder is generated for.
+type LadderRequest struct {
+	// Name prefixes the generated profile names ("web" yields
+	// "web-1080p", "web-720p", ...).
+	Name string `json:"name"`
+	// SourceWidth/SourceHeight/SourceFramerate come from the source
+	// probe; rungs larger than the source are dropped (no upscaling).
+	SourceWidth     int     `json:"source_width"`
+	SourceHeight    int     `json:"source_height"`
+	SourceFramerate float64 `json:"source_framerate,omitempty"`
+	// VideoCodec is the target codec; bitrates are scaled for its
+	// efficiency ("libx264" default, "libx265", "libsvtav1").
+	VideoCodec string `json:"video_codec,omitempty"`
+	// SourceBitrateKbps, when set (per-title analysis from the probe),
+	// scales the reference ladder toward the source's actual complexity.
+	SourceBitrateKbps int `json:"source_bitrate_kbps,omitempty"`
+	// Segmented emits HLS renditions (6s segments); unset produces
+	// fragmented CMAF MP4s usable for both HLS and DASH packaging.
+	Segmented bool `json:"segmented,omitempty"`
+}
+
+// rung is one reference ladder entry, tuned for H.264 at standard
+// framerates per the usual HLS authoring recommendations.
+type rung struct {
+	height      int
+	bitrateKbps int
+}
+
+var referenceLadder = []rung{
+	{2160, 18000},
+	{1440, 10000},
+	{1080, 6000},
+	{720, 3000},
+	{480, 1500},
+	{360, 800},
+	{240, 400},
+}
+
+// codecEfficiency scales the H.264 reference bitrates for more
+// efficient codecs.
+var codecEfficiency = map[string]float64{
+	"libx264":   1.0,
+	"libx265":   0.65,
+	"libsvtav1": 0.5,
+}
+
+// GenerateLadder builds the ABR profiles for a ladder request without
+// storing them.
+func GenerateLadder(req *LadderRequest) ([]*types.Profile, error) {
+	if req.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if req.SourceWidth <= 0 || req.SourceHeight <= 0 {
+		return nil, fmt.Errorf("source_width and source_height are required")
+	}
+	codec := req.VideoCodec
+	if codec == "" {
+		codec = "libx264"
+	}
+	efficiency, ok := codecEfficiency[codec]
+	if !ok {
+		return nil, fmt.Errorf("no ladder recommendations for video codec %q", codec)
+	}
+
+	// Per-title refinement: sources encoded well below the top reference
+	// rung are simple content and the whole ladder can come down with
+	// them; clamp so a bad probe can't wreck the ladder.
+	complexity := 1.0
+	if req.SourceBitrateKbps > 0 {
+		top := referenceLadder[0]
+		for _, r := range referenceLadder {
+			if r.height <= req.SourceHeight {
+				top = r
+				break
+			}
+		}
+		complexity = float64(req.SourceBitrateKbps) / float64(top.bitrateKbps)
+		if complexity < 0.5 {
+			complexity = 0.5
+		}
+		if complexity > 1.5 {
+			complexity = 1.5
+		}
+	}
+
+	// High-framerate sources need more bits per rung to hold quality.
+	frameFactor := 1.0
+	if req.SourceFramerate > 30 {
+		frameFactor = 1.5
+	}
+
+	container := types.ContainerOptions{Format: "mp4", Fragmented: true}
+	if req.Segmented {
+		container = types.ContainerOptions{Format: "hls", SegmentDurationSec: 6}
+	}
+
+	var out []*types.Profile
+	for _, r := range referenceLadder {
+		if r.height > req.SourceHeight {
+			continue
+		}
+		bitrate := int(float64(r.bitrateKbps) * efficiency * complexity * frameFactor)
+		audioBitrate := 128
+		if r.height <= 360 {
+			audioBitrate = 64
+		}
+		out = append(out, &types.Profile{
+			Name:             fmt.Sprintf("%s-%dp", req.Name, r.height),
+			VideoCodec:       codec,
+			Height:           r.height,
+			VideoBitrateKbps: bitrate,
+			Framerate:        req.SourceFramerate,
+			AudioCodec:       "aac",
+			AudioBitrateKbps: audioBitrate,
+			Container:        container,
+		})
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("source %dx%d is below the smallest ladder rung", req.SourceWidth, req.SourceHeight)
+	}
+	return out, nil
+}
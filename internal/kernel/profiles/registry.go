@@ -0,0 +1,140 @@
+// Package profiles manages the kernel's encoding profiles.
+package profiles
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/rennerdo30/webencode/internal/encode"
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// ErrNotFound is returned for unknown profile IDs.
+var ErrNotFound = errors.New("profile not found")
+
+// Registry is an in-memory index of encoding profiles.
+type Registry struct {
+	mu       sync.RWMutex
+	profiles map[string]*types.Profile
+}
+
+// NewRegistry returns an empty profile registry.
+func NewRegistry() *Registry {
+	return &Registry{profiles: make(map[string]*types.Profile)}
+}
+
+// Validate checks a profile before it is accepted.
+func Validate(p *types.Profile) error {
+	if p.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if p.VideoCodec == "" && p.AudioCodec == "" {
+		return fmt.Errorf("at least one of video_codec or audio_codec is required")
+	}
+	if p.CRF > 0 && p.VideoBitrateKbps > 0 {
+		return fmt.Errorf("crf and video_bitrate_kbps are mutually exclusive")
+	}
+	if t := p.LoudnessTargetLUFS; t != 0 && (t < -70 || t > -5) {
+		return fmt.Errorf("loudness_target_lufs must be between -70 and -5, got %g", t)
+	}
+	if p.AudioOnly {
+		if p.AudioCodec == "" {
+			return fmt.Errorf("audio_only requires audio_codec")
+		}
+		if p.VideoCodec != "" {
+			return fmt.Errorf("audio_only and video_codec are mutually exclusive")
+		}
+	}
+	switch p.AspectMode {
+	case types.AspectModeStretch:
+	case types.AspectModePad, types.AspectModeCrop, types.AspectModeSmart:
+		if p.Width <= 0 || p.Height <= 0 {
+			return fmt.Errorf("aspect_mode %q requires width and height", p.AspectMode)
+		}
+	default:
+		return fmt.Errorf("unknown aspect_mode %q", p.AspectMode)
+	}
+	if err := encode.ValidateCodecOptions(p); err != nil {
+		return err
+	}
+	if err := encode.ValidateHDR(p); err != nil {
+		return err
+	}
+	if err := encode.ValidateFilters(&p.Filters); err != nil {
+		return err
+	}
+	return encode.ValidateContainer(&p.Container)
+}
+
+// Create validates and stores a new profile.
+func (r *Registry) Create(p *types.Profile) (*types.Profile, error) {
+	if err := Validate(p); err != nil {
+		return nil, err
+	}
+	p.ID = uuid.NewString()
+	r.mu.Lock()
+	copied := *p
+	r.profiles[p.ID] = &copied
+	r.mu.Unlock()
+	return p, nil
+}
+
+// Update validates and replaces an existing profile.
+func (r *Registry) Update(id string, p *types.Profile) (*types.Profile, error) {
+	if err := Validate(p); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.profiles[id]; !ok {
+		return nil, ErrNotFound
+	}
+	p.ID = id
+	copied := *p
+	r.profiles[id] = &copied
+	return p, nil
+}
+
+// Get returns the profile with the given ID.
+func (r *Registry) Get(id string) (*types.Profile, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.profiles[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	copied := *p
+	return &copied, nil
+}
+
+// List returns profiles sorted by name. A non-empty ownerID filters to
+// that owner's profiles plus the shared (unowned) ones.
+func (r *Registry) List(ownerID string) []*types.Profile {
+	r.mu.RLock()
+	out := make([]*types.Profile, 0, len(r.profiles))
+	for _, p := range r.profiles {
+		if ownerID != "" && p.OwnerID != "" && p.OwnerID != ownerID {
+			continue
+		}
+		copied := *p
+		out = append(out, &copied)
+	}
+	r.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Delete removes a profile.
+func (r *Registry) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.profiles[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.profiles, id)
+	return nil
+}
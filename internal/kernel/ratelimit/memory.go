@@ -0,0 +1,44 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryLimiter is the single-replica backend: fixed windows counted
+// in process memory.
+type MemoryLimiter struct {
+	mu      sync.Mutex
+	windows map[string]*window
+}
+
+type window struct {
+	count int
+	start time.Time
+}
+
+// NewMemoryLimiter returns an empty in-memory limiter.
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{windows: make(map[string]*window)}
+}
+
+// Allow charges one request against the caller's bucket.
+func (l *MemoryLimiter) Allow(bucket, caller string, limit int, windowLen time.Duration) (Decision, error) {
+	now := time.Now()
+	key := bucket + ":" + caller
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	wi, ok := l.windows[key]
+	if !ok || now.Sub(wi.start) >= windowLen {
+		wi = &window{start: now}
+		l.windows[key] = wi
+	}
+	d := Decision{Limit: limit, Reset: wi.start.Add(windowLen)}
+	if wi.count >= limit {
+		return d, nil
+	}
+	wi.count++
+	d.Allowed = true
+	d.Remaining = limit - wi.count
+	return d, nil
+}
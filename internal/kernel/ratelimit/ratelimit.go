@@ -0,0 +1,24 @@
+// Package ratelimit enforces per-caller request quotas for the API.
+// The kernel picks a backend at startup: the in-memory limiter for a
+// single replica, or the Redis limiter when replicas must share
+// buckets.
+package ratelimit
+
+import "time"
+
+// Decision is the outcome of charging one request against a bucket;
+// the API reflects it in RateLimit-* response headers.
+type Decision struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is when the current window ends and the bucket refills.
+	Reset time.Time
+}
+
+// Limiter charges requests against fixed-window buckets. Bucket names
+// separate traffic classes (e.g. "default", "expensive"); caller is
+// the user, API key or client address being limited.
+type Limiter interface {
+	Allow(bucket, caller string, limit int, window time.Duration) (Decision, error)
+}
@@ -0,0 +1,151 @@
+package ratelimit
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RedisLimiter counts windows in Redis so multiple kernel replicas
+// share one set of buckets. It speaks just enough RESP itself —
+// INCR/PEXPIRE/PTTL on one pooled connection — rather than pulling in
+// a client library for three commands.
+type RedisLimiter struct {
+	addr     string
+	password string
+
+	mu   sync.Mutex
+	conn net.Conn
+	br   *bufio.Reader
+}
+
+// NewRedisLimiter returns a limiter against the given Redis address
+// (host:port). The connection is established lazily and re-dialed
+// after errors.
+func NewRedisLimiter(addr, password string) *RedisLimiter {
+	return &RedisLimiter{addr: addr, password: password}
+}
+
+// Allow charges one request against the caller's bucket.
+func (l *RedisLimiter) Allow(bucket, caller string, limit int, window time.Duration) (Decision, error) {
+	key := "webencode:ratelimit:" + bucket + ":" + caller
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	count, err := l.intCmd("INCR", key)
+	if err != nil {
+		// One reconnect attempt covers dropped idle connections.
+		l.reset()
+		if count, err = l.intCmd("INCR", key); err != nil {
+			return Decision{}, err
+		}
+	}
+	if count == 1 {
+		if _, err := l.intCmd("PEXPIRE", key, strconv.FormatInt(window.Milliseconds(), 10)); err != nil {
+			return Decision{}, err
+		}
+	}
+	ttl, err := l.intCmd("PTTL", key)
+	if err != nil || ttl < 0 {
+		ttl = window.Milliseconds()
+	}
+	d := Decision{
+		Limit: limit,
+		Reset: time.Now().Add(time.Duration(ttl) * time.Millisecond),
+	}
+	if count > int64(limit) {
+		return d, nil
+	}
+	d.Allowed = true
+	d.Remaining = limit - int(count)
+	return d, nil
+}
+
+// reset drops the pooled connection so the next command re-dials.
+func (l *RedisLimiter) reset() {
+	if l.conn != nil {
+		l.conn.Close()
+		l.conn = nil
+		l.br = nil
+	}
+}
+
+// dial connects and authenticates. Caller holds l.mu.
+func (l *RedisLimiter) dial() error {
+	conn, err := net.DialTimeout("tcp", l.addr, 2*time.Second)
+	if err != nil {
+		return fmt.Errorf("ratelimit: dial redis: %w", err)
+	}
+	l.conn = conn
+	l.br = bufio.NewReader(conn)
+	if l.password != "" {
+		if _, err := l.roundTrip("AUTH", l.password); err != nil {
+			l.reset()
+			return fmt.Errorf("ratelimit: redis auth: %w", err)
+		}
+	}
+	return nil
+}
+
+// intCmd runs one command expecting an integer reply. Caller holds
+// l.mu.
+func (l *RedisLimiter) intCmd(args ...string) (int64, error) {
+	reply, err := l.roundTrip(args...)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseInt(reply, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("ratelimit: unexpected redis reply %q", reply)
+	}
+	return n, nil
+}
+
+// roundTrip writes one RESP command and reads the reply line. Caller
+// holds l.mu.
+func (l *RedisLimiter) roundTrip(args ...string) (string, error) {
+	if l.conn == nil {
+		if err := l.dial(); err != nil {
+			return "", err
+		}
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(a), a)
+	}
+	l.conn.SetDeadline(time.Now().Add(2 * time.Second))
+	if _, err := l.conn.Write([]byte(b.String())); err != nil {
+		return "", err
+	}
+	line, err := l.br.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return "", fmt.Errorf("ratelimit: empty redis reply")
+	}
+	switch line[0] {
+	case ':', '+':
+		return line[1:], nil
+	case '-':
+		return "", fmt.Errorf("ratelimit: redis error: %s", line[1:])
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return "", nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(l.br, buf); err != nil {
+			return "", err
+		}
+		return string(buf[:n]), nil
+	default:
+		return "", fmt.Errorf("ratelimit: unsupported redis reply %q", line)
+	}
+}
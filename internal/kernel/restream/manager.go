@@ -0,0 +1,288 @@
+package restream
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rennerdo30/webencode/internal/proc"
+)
+
+// ErrNotFound is returned for unknown target IDs.
+var ErrNotFound = errors.New("restream target not found")
+
+// maxBackoff caps the reconnection delay between relay attempts.
+const maxBackoff = 30 * time.Second
+
+// Manager owns all restream targets and their relay processes.
+type Manager struct {
+	// FFmpegPath overrides the ffmpeg binary, for tests and exotic
+	// installs. Empty means "ffmpeg" from PATH.
+	FFmpegPath string
+
+	mu      sync.Mutex
+	targets map[string]*Target
+	cancels map[string]context.CancelFunc
+}
+
+// NewManager returns an empty restream manager.
+func NewManager() *Manager {
+	return &Manager{
+		targets: make(map[string]*Target),
+		cancels: make(map[string]context.CancelFunc),
+	}
+}
+
+// AddTarget registers a new destination for a stream. The relay is not
+// started until StartTarget is called.
+func (m *Manager) AddTarget(streamID, name, url string) *Target {
+	t := &Target{
+		ID:        uuid.NewString(),
+		StreamID:  streamID,
+		Name:      name,
+		URL:       url,
+		Status:    TargetStatusIdle,
+		CreatedAt: time.Now().UTC(),
+	}
+	m.mu.Lock()
+	m.targets[t.ID] = t
+	m.mu.Unlock()
+	return t
+}
+
+// RemoveTarget stops and deletes a target.
+func (m *Manager) RemoveTarget(id string) error {
+	if err := m.StopTarget(id); err != nil && !errors.Is(err, errNotRunning) {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.targets[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.targets, id)
+	return nil
+}
+
+// Get returns a snapshot of one target.
+func (m *Manager) Get(id string) (*Target, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.targets[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	copied := *t
+	return &copied, nil
+}
+
+// ListForStream returns snapshots of all targets of one stream, oldest
+// first.
+func (m *Manager) ListForStream(streamID string) []*Target {
+	m.mu.Lock()
+	out := make([]*Target, 0)
+	for _, t := range m.targets {
+		if t.StreamID == streamID {
+			copied := *t
+			out = append(out, &copied)
+		}
+	}
+	m.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out
+}
+
+// StartTarget launches the relay for a target. It keeps reconnecting
+// with backoff until StopTarget is called or the manager shuts down.
+func (m *Manager) StartTarget(id, sourceURL string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.targets[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if _, running := m.cancels[id]; running {
+		return fmt.Errorf("target %s already running", id)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancels[id] = cancel
+	now := time.Now().UTC()
+	t.Status = TargetStatusConnecting
+	t.StartedAt = &now
+	t.LastError = ""
+	go m.relayLoop(ctx, id, sourceURL)
+	return nil
+}
+
+var errNotRunning = errors.New("target not running")
+
+// StopTarget stops the relay but keeps the target registered.
+func (m *Manager) StopTarget(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.targets[id]; !ok {
+		return ErrNotFound
+	}
+	cancel, running := m.cancels[id]
+	if !running {
+		return errNotRunning
+	}
+	cancel()
+	delete(m.cancels, id)
+	m.targets[id].Status = TargetStatusStopped
+	return nil
+}
+
+// relayLoop runs ffmpeg copy relays until the context is cancelled,
+// reconnecting with exponential backoff on failure.
+func (m *Manager) relayLoop(ctx context.Context, id, sourceURL string) {
+	backoff := time.Second
+	for {
+		err := m.runRelay(ctx, id, sourceURL)
+		if ctx.Err() != nil {
+			return
+		}
+		m.mu.Lock()
+		if t, ok := m.targets[id]; ok {
+			t.Status = TargetStatusError
+			t.Reconnects++
+			if err != nil {
+				t.LastError = err.Error()
+			}
+		}
+		m.mu.Unlock()
+		log.Printf("restream: target %s relay ended (%v), reconnecting in %s", id, err, backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// runRelay executes one ffmpeg relay process and feeds its progress
+// stream into the target stats.
+func (m *Manager) runRelay(ctx context.Context, id, sourceURL string) error {
+	m.mu.Lock()
+	t, ok := m.targets[id]
+	if !ok {
+		m.mu.Unlock()
+		return ErrNotFound
+	}
+	destURL := t.URL
+	t.Status = TargetStatusConnecting
+	m.mu.Unlock()
+
+	ffmpeg := m.FFmpegPath
+	if ffmpeg == "" {
+		ffmpeg = "ffmpeg"
+	}
+	cmd := exec.CommandContext(ctx, ffmpeg,
+		"-hide_banner", "-loglevel", "error",
+		"-i", sourceURL,
+		"-c", "copy",
+		"-f", "flv",
+		"-progress", "pipe:1",
+		destURL,
+	)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	// Relays are soft real-time: viewers notice a stall immediately.
+	// Lowering niceness needs privileges, so failure is only advisory.
+	if err := proc.SetNice(cmd.Process.Pid, proc.RelayNice); err != nil {
+		log.Printf("restream: renice relay %s: %v", id, err)
+	}
+
+	m.mu.Lock()
+	if t, ok := m.targets[id]; ok {
+		t.Status = TargetStatusRunning
+	}
+	m.mu.Unlock()
+
+	go m.consumeProgress(id, stdout)
+	return cmd.Wait()
+}
+
+// consumeProgress parses ffmpeg's key=value progress output into stats.
+func (m *Manager) consumeProgress(id string, r interface{ Read([]byte) (int, error) }) {
+	scanner := bufio.NewScanner(r)
+	var stats Stats
+	for scanner.Scan() {
+		key, value, found := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "bitrate":
+			if v, err := strconv.ParseFloat(strings.TrimSuffix(value, "kbits/s"), 64); err == nil {
+				stats.BitrateKbps = v
+			}
+		case "total_size":
+			if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+				stats.BytesSent = v
+			}
+		case "speed":
+			if v, err := strconv.ParseFloat(strings.TrimSuffix(value, "x"), 64); err == nil {
+				stats.Speed = v
+			}
+		case "progress":
+			stats.UpdatedAt = time.Now().UTC()
+			m.mu.Lock()
+			if t, ok := m.targets[id]; ok {
+				t.Stats = stats
+			}
+			m.mu.Unlock()
+		}
+	}
+}
+
+// degradedSpeed is the relay speed below which live output is falling
+// behind realtime and batch work should yield the node's CPU.
+const degradedSpeed = 0.95
+
+// Degraded reports whether any running relay is falling behind
+// realtime. Relays without stats yet (just connected) don't count.
+func (m *Manager) Degraded() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, t := range m.targets {
+		if t.Status != TargetStatusRunning {
+			continue
+		}
+		if !t.Stats.UpdatedAt.IsZero() && t.Stats.Speed < degradedSpeed {
+			return true
+		}
+	}
+	return false
+}
+
+// Shutdown stops all running relays.
+func (m *Manager) Shutdown() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, cancel := range m.cancels {
+		cancel()
+		delete(m.cancels, id)
+		if t, ok := m.targets[id]; ok {
+			t.Status = TargetStatusStopped
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// Package restream pushes an incoming live stream to one or more
+// external RTMP destinations (simulcast). Each target runs as an
+// independent relay with its own status, stats and reconnection loop, so
+// one platform having a bad day doesn't take the others down.
+package restream
+
+import (
+	"time"
+)
+
+// TargetStatus is the lifecycle state of a single relay target.
+type TargetStatus string
+
+const (
+	TargetStatusIdle       TargetStatus = "idle"
+	TargetStatusConnecting TargetStatus = "connecting"
+	TargetStatusRunning    TargetStatus = "running"
+	TargetStatusError      TargetStatus = "error"
+	TargetStatusStopped    TargetStatus = "stopped"
+)
+
+// Stats is the relay's most recent output measurements, parsed from the
+// ffmpeg progress stream.
+type Stats struct {
+	// BitrateKbps is the current output bitrate.
+	BitrateKbps float64 `json:"bitrate_kbps"`
+	// BytesSent is the total output size so far.
+	BytesSent int64 `json:"bytes_sent"`
+	// Speed is the relay speed relative to realtime; healthy relays sit
+	// at ~1.0.
+	Speed     float64   `json:"speed"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Target is one simulcast destination of a stream.
+type Target struct {
+	ID       string `json:"id"`
+	StreamID string `json:"stream_id"`
+	// Name is a human label, e.g. "twitch-main".
+	Name string `json:"name"`
+	// URL is the destination RTMP(S) endpoint including stream key.
+	URL    string       `json:"url"`
+	Status TargetStatus `json:"status"`
+	Stats  Stats        `json:"stats"`
+	// Reconnects counts how often the relay restarted after a failure.
+	Reconnects int        `json:"reconnects"`
+	LastError  string     `json:"last_error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+}
@@ -0,0 +1,197 @@
+// Package s3ingest turns S3 bucket notifications into job submissions:
+// when a new object lands under a configured prefix, the matching
+// rule's template is launched against it. Events arrive over the
+// kernel's webhook endpoint, which MinIO and SeaweedFS can post to
+// directly; an SQS poller can feed the same listener.
+package s3ingest
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrNotFound is returned for unknown rule IDs.
+var ErrNotFound = errors.New("ingest rule not found")
+
+// Rule maps a bucket/prefix to a job template.
+type Rule struct {
+	ID      string `json:"id"`
+	OwnerID string `json:"owner_id,omitempty"`
+	// Bucket limits the rule to one bucket; empty matches any.
+	Bucket string `json:"bucket,omitempty"`
+	// Prefix and Suffix filter object keys ("incoming/", ".mp4").
+	Prefix string `json:"prefix,omitempty"`
+	Suffix string `json:"suffix,omitempty"`
+	// TemplateID names the job template each matching object launches.
+	TemplateID string `json:"template_id"`
+
+	// ObjectsSubmitted counts objects turned into jobs.
+	ObjectsSubmitted int       `json:"objects_submitted"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// Submitter launches one job for a newly landed object.
+type Submitter func(rule *Rule, bucket, key string) error
+
+// Listener matches incoming object events against rules and submits
+// jobs, deduplicating keys it has already processed (bucket
+// notifications are at-least-once).
+type Listener struct {
+	// Submit is required before events are handled; nil drops matches.
+	Submit Submitter
+
+	mu    sync.Mutex
+	rules map[string]*Rule
+	// processed remembers bucket/key pairs already submitted.
+	processed map[string]bool
+}
+
+// NewListener returns an empty listener.
+func NewListener() *Listener {
+	return &Listener{
+		rules:     make(map[string]*Rule),
+		processed: make(map[string]bool),
+	}
+}
+
+// CreateRule validates and registers a rule.
+func (l *Listener) CreateRule(r *Rule) (*Rule, error) {
+	if r.TemplateID == "" {
+		return nil, fmt.Errorf("template_id is required")
+	}
+	if r.Bucket == "" && r.Prefix == "" && r.Suffix == "" {
+		return nil, fmt.Errorf("at least one of bucket, prefix or suffix is required")
+	}
+	r.ID = uuid.NewString()
+	r.CreatedAt = time.Now().UTC()
+	l.mu.Lock()
+	copied := *r
+	l.rules[r.ID] = &copied
+	l.mu.Unlock()
+	return r, nil
+}
+
+// GetRule returns one rule.
+func (l *Listener) GetRule(id string) (*Rule, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	r, ok := l.rules[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	copied := *r
+	return &copied, nil
+}
+
+// ListRules returns rules owned by ownerID; empty returns everything.
+func (l *Listener) ListRules(ownerID string) []*Rule {
+	l.mu.Lock()
+	out := make([]*Rule, 0, len(l.rules))
+	for _, r := range l.rules {
+		if ownerID != "" && r.OwnerID != ownerID {
+			continue
+		}
+		copied := *r
+		out = append(out, &copied)
+	}
+	l.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out
+}
+
+// DeleteRule unregisters a rule.
+func (l *Listener) DeleteRule(id string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.rules[id]; !ok {
+		return ErrNotFound
+	}
+	delete(l.rules, id)
+	return nil
+}
+
+// Notification is the S3 event envelope MinIO/SeaweedFS post; only the
+// fields the listener reads.
+type Notification struct {
+	Records []struct {
+		EventName string `json:"eventName"`
+		S3        struct {
+			Bucket struct {
+				Name string `json:"name"`
+			} `json:"bucket"`
+			Object struct {
+				Key string `json:"key"`
+			} `json:"object"`
+		} `json:"s3"`
+	} `json:"Records"`
+}
+
+// Handle processes one notification and returns how many objects were
+// submitted. Non-creation events and duplicates are ignored.
+func (l *Listener) Handle(n Notification) int {
+	submitted := 0
+	for _, rec := range n.Records {
+		if !strings.Contains(rec.EventName, "ObjectCreated") {
+			continue
+		}
+		// Object keys arrive URL-encoded in S3 events.
+		key := rec.S3.Object.Key
+		if decoded, err := url.QueryUnescape(key); err == nil {
+			key = decoded
+		}
+		if l.handleObject(rec.S3.Bucket.Name, key) {
+			submitted++
+		}
+	}
+	return submitted
+}
+
+// handleObject matches one created object against the rules.
+func (l *Listener) handleObject(bucket, key string) bool {
+	seen := bucket + "/" + key
+	l.mu.Lock()
+	if l.processed[seen] {
+		l.mu.Unlock()
+		return false
+	}
+	var match *Rule
+	for _, r := range l.rules {
+		if r.Bucket != "" && r.Bucket != bucket {
+			continue
+		}
+		if r.Prefix != "" && !strings.HasPrefix(key, r.Prefix) {
+			continue
+		}
+		if r.Suffix != "" && !strings.HasSuffix(key, r.Suffix) {
+			continue
+		}
+		match = r
+		break
+	}
+	if match == nil {
+		l.mu.Unlock()
+		return false
+	}
+	l.processed[seen] = true
+	l.mu.Unlock()
+
+	if l.Submit == nil {
+		return false
+	}
+	if err := l.Submit(match, bucket, key); err != nil {
+		log.Printf("s3ingest: submit %s: %v", seen, err)
+		return false
+	}
+	l.mu.Lock()
+	match.ObjectsSubmitted++
+	l.mu.Unlock()
+	return true
+}
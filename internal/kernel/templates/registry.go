@@ -0,0 +1,131 @@
+// Package templates manages job templates: reusable bundles of
+// profiles, output location, publish destinations, and notifications.
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// ErrNotFound is returned for unknown template IDs.
+var ErrNotFound = errors.New("template not found")
+
+// Registry is an in-memory index of job templates.
+type Registry struct {
+	mu        sync.RWMutex
+	templates map[string]*types.JobTemplate
+}
+
+// NewRegistry returns an empty template registry.
+func NewRegistry() *Registry {
+	return &Registry{templates: make(map[string]*types.JobTemplate)}
+}
+
+// Validate checks a template before it is accepted.
+func Validate(t *types.JobTemplate) error {
+	if t.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(t.ProfileIDs) == 0 {
+		return fmt.Errorf("at least one profile is required")
+	}
+	for _, spec := range t.FanOut {
+		if spec.Name == "" {
+			return fmt.Errorf("fan_out entries need a name")
+		}
+	}
+	if t.Webhook != nil && t.Webhook.URL == "" {
+		return fmt.Errorf("webhook needs a url")
+	}
+	return nil
+}
+
+// Create validates and stores a new template.
+func (r *Registry) Create(t *types.JobTemplate) (*types.JobTemplate, error) {
+	if err := Validate(t); err != nil {
+		return nil, err
+	}
+	t.ID = uuid.NewString()
+	t.CreatedAt = time.Now().UTC()
+	r.mu.Lock()
+	r.templates[t.ID] = t
+	r.mu.Unlock()
+	return t, nil
+}
+
+// Get returns one template.
+func (r *Registry) Get(id string) (*types.JobTemplate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.templates[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return t, nil
+}
+
+// List returns shared templates plus those owned by ownerID; an empty
+// ownerID returns everything.
+func (r *Registry) List(ownerID string) []*types.JobTemplate {
+	r.mu.RLock()
+	out := make([]*types.JobTemplate, 0, len(r.templates))
+	for _, t := range r.templates {
+		if ownerID != "" && t.OwnerID != "" && t.OwnerID != ownerID {
+			continue
+		}
+		out = append(out, t)
+	}
+	r.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Delete removes a template.
+func (r *Registry) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.templates[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.templates, id)
+	return nil
+}
+
+// ExpandOutput fills a template's output pattern for one job. An empty
+// pattern defaults to the job ID under "encoded/".
+func ExpandOutput(t *types.JobTemplate, job *types.Job) string {
+	pattern := t.OutputPattern
+	if pattern == "" {
+		pattern = "encoded/{job_id}"
+	}
+	replacer := strings.NewReplacer(
+		"{job_id}", job.ID,
+		"{title}", sanitize(job.Title),
+		"{date}", time.Now().UTC().Format("2006-01-02"),
+		"{template}", sanitize(t.Name),
+	)
+	return replacer.Replace(pattern)
+}
+
+// sanitize makes a free-form string safe for use in an object key.
+func sanitize(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '_':
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,225 @@
+// Package urlpolicy validates user-supplied URLs before anything
+// fetches them. Job sources, edit bumpers and feed URLs all end up in
+// ffmpeg, yt-dlp or a plain HTTP client; without a policy a crafted URL
+// reaches whatever the kernel or a worker can reach — internal
+// services, cloud metadata endpoints, the loopback API itself. The
+// policy enforces a scheme allowlist, blocks private and link-local
+// address ranges, and can pin individual users to admin-approved
+// source domains.
+package urlpolicy
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Policy is the URL validation configuration. The zero value rejects
+// everything; use New for sane defaults.
+type Policy struct {
+	// Schemes is the allowlist of URL schemes; schemeless strings
+	// (storage keys, local paths) pass untouched.
+	Schemes []string
+	// PushSchemes is the allowlist for push destinations (restream
+	// targets). Unlike sources, destinations must be real URLs: the
+	// kernel-side ffmpeg writes to them, so a bare path would let a
+	// caller overwrite local files.
+	PushSchemes []string
+	// AllowPrivate permits loopback, RFC 1918 and link-local targets —
+	// for dev setups whose sources genuinely live there.
+	AllowPrivate bool
+	// FetchTimeout bounds each policy-checked fetch.
+	FetchTimeout time.Duration
+
+	mu sync.Mutex
+	// userDomains pins users to admin-approved source domains; a user
+	// with no entry may use any public host.
+	userDomains map[string][]string
+}
+
+// New returns a policy allowing http, https and s3 sources plus the
+// live ingest protocols (rtmp, rtmps, srt) to public hosts, and rtmp(s)
+// push destinations.
+func New() *Policy {
+	return &Policy{
+		Schemes:      []string{"http", "https", "s3", "rtmp", "rtmps", "srt"},
+		PushSchemes:  []string{"rtmp", "rtmps"},
+		FetchTimeout: 30 * time.Second,
+		userDomains:  make(map[string][]string),
+	}
+}
+
+// Validate checks one user-supplied URL against the policy. Empty and
+// schemeless values (storage keys, local paths) pass; they never reach
+// an HTTP client.
+func (p *Policy) Validate(userID, raw string) error {
+	if raw == "" || !strings.Contains(raw, "://") {
+		return nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid source URL: %w", err)
+	}
+	if !p.schemeAllowed(u.Scheme) {
+		return fmt.Errorf("source URL scheme %q is not allowed", u.Scheme)
+	}
+	host := u.Hostname()
+	if host == "" {
+		return fmt.Errorf("source URL %q has no host", raw)
+	}
+	if err := p.hostAllowed(userID, host); err != nil {
+		return err
+	}
+	// Everything but s3 is dialed directly (by ffmpeg, ffprobe or an
+	// HTTP client); an s3 host is a bucket name resolved by the storage
+	// backend, not by us.
+	if !strings.EqualFold(u.Scheme, "s3") {
+		return p.addrAllowed(host)
+	}
+	return nil
+}
+
+// ValidatePush checks a user-supplied push destination (restream
+// target). Destinations are held to a stricter standard than sources:
+// they must be URLs on an allowed push scheme — no schemeless pass for
+// paths — and their hosts go through the same per-user allowlist and
+// private-address checks.
+func (p *Policy) ValidatePush(userID, raw string) error {
+	if !strings.Contains(raw, "://") {
+		return fmt.Errorf("push destination %q must be a URL", raw)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid push destination: %w", err)
+	}
+	allowed := false
+	for _, s := range p.PushSchemes {
+		if strings.EqualFold(s, u.Scheme) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return fmt.Errorf("push destination scheme %q is not allowed", u.Scheme)
+	}
+	host := u.Hostname()
+	if host == "" {
+		return fmt.Errorf("push destination %q has no host", raw)
+	}
+	if err := p.hostAllowed(userID, host); err != nil {
+		return err
+	}
+	return p.addrAllowed(host)
+}
+
+func (p *Policy) schemeAllowed(scheme string) bool {
+	for _, s := range p.Schemes {
+		if strings.EqualFold(s, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostAllowed enforces the user's domain allowlist: the host must equal
+// an approved domain or be a subdomain of one. Users without an entry
+// may use any host.
+func (p *Policy) hostAllowed(userID, host string) error {
+	p.mu.Lock()
+	domains := p.userDomains[userID]
+	p.mu.Unlock()
+	if len(domains) == 0 {
+		return nil
+	}
+	host = strings.ToLower(host)
+	for _, d := range domains {
+		d = strings.ToLower(d)
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return nil
+		}
+	}
+	return fmt.Errorf("source domain %q is not on the allowlist for this user", host)
+}
+
+// addrAllowed resolves the host and rejects private, loopback and
+// link-local targets — the ranges internal services and cloud metadata
+// endpoints live in. Resolution here can diverge from the one at fetch
+// time (DNS rebinding); Client re-checks every connection at dial time.
+func (p *Policy) addrAllowed(host string) error {
+	if p.AllowPrivate {
+		return nil
+	}
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return fmt.Errorf("resolve source host %q: %w", host, err)
+	}
+	for _, ip := range ips {
+		if !p.ipAllowed(ip) {
+			return fmt.Errorf("source host %q resolves to restricted address %s", host, ip)
+		}
+	}
+	return nil
+}
+
+func (p *Policy) ipAllowed(ip net.IP) bool {
+	if p.AllowPrivate {
+		return true
+	}
+	if ip == nil {
+		return false
+	}
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast())
+}
+
+// Client returns an HTTP client that re-enforces the address policy on
+// every connection — including redirects and fresh DNS answers, closing
+// the rebinding gap Validate leaves — and applies the fetch timeout.
+// Use it for any fetch of a URL that went through Validate.
+func (p *Policy) Client() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if !p.ipAllowed(net.ParseIP(host)) {
+				return fmt.Errorf("connection to restricted address %s blocked by URL policy", host)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout:   p.FetchTimeout,
+		Transport: &http.Transport{DialContext: dialer.DialContext},
+	}
+}
+
+// SetUserDomains replaces one user's source domain allowlist; an empty
+// list removes the restriction.
+func (p *Policy) SetUserDomains(userID string, domains []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(domains) == 0 {
+		delete(p.userDomains, userID)
+		return
+	}
+	p.userDomains[userID] = append([]string(nil), domains...)
+}
+
+// UserDomains returns every per-user domain allowlist.
+func (p *Policy) UserDomains() map[string][]string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make(map[string][]string, len(p.userDomains))
+	for user, domains := range p.userDomains {
+		out[user] = append([]string(nil), domains...)
+	}
+	return out
+}
@@ -0,0 +1,181 @@
+package urlpolicy
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+// Tests stick to literal IPs and the s3 scheme (which skips address
+// resolution) so they never depend on DNS.
+
+func TestValidateSchemes(t *testing.T) {
+	p := New()
+	for _, raw := range []string{
+		"",                  // empty passes
+		"media/input.mp4",   // storage key, no scheme
+		"/mnt/media/in.mp4", // local path
+		"s3://bucket/key.mp4",
+		"https://93.184.216.34/video.mp4",
+	} {
+		if err := p.Validate("u1", raw); err != nil {
+			t.Errorf("Validate(%q) = %v, want nil", raw, err)
+		}
+	}
+	for _, raw := range []string{
+		"file:///etc/passwd",
+		"ftp://93.184.216.34/video.mp4",
+		"gopher://93.184.216.34/",
+		"http://", // no host
+	} {
+		if err := p.Validate("u1", raw); err == nil {
+			t.Errorf("Validate(%q) = nil, want error", raw)
+		}
+	}
+}
+
+func TestValidateBlocksPrivateAddresses(t *testing.T) {
+	p := New()
+	blocked := []string{
+		"http://127.0.0.1/v1/jobs",
+		"http://10.0.0.8/internal",
+		"http://172.16.0.1/",
+		"http://192.168.1.1/router",
+		"http://169.254.169.254/latest/meta-data/", // cloud metadata
+		"http://0.0.0.0/",
+		"http://[::1]/",
+	}
+	for _, raw := range blocked {
+		if err := p.Validate("u1", raw); err == nil {
+			t.Errorf("Validate(%q) = nil, want private-address error", raw)
+		}
+	}
+
+	p.AllowPrivate = true
+	for _, raw := range blocked {
+		if err := p.Validate("u1", raw); err != nil {
+			t.Errorf("AllowPrivate: Validate(%q) = %v, want nil", raw, err)
+		}
+	}
+}
+
+func TestValidateUserDomains(t *testing.T) {
+	p := New()
+	p.SetUserDomains("u1", []string{"example.com", "cdn.net"})
+
+	allowed := []string{
+		"s3://example.com/key.mp4",
+		"s3://assets.example.com/key.mp4", // subdomain
+		"s3://CDN.NET/key.mp4",            // case-insensitive
+	}
+	for _, raw := range allowed {
+		if err := p.Validate("u1", raw); err != nil {
+			t.Errorf("Validate(u1, %q) = %v, want nil", raw, err)
+		}
+	}
+	denied := []string{
+		"s3://evil.com/key.mp4",
+		"s3://notexample.com/key.mp4", // suffix must be a label boundary
+	}
+	for _, raw := range denied {
+		if err := p.Validate("u1", raw); err == nil {
+			t.Errorf("Validate(u1, %q) = nil, want allowlist error", raw)
+		}
+	}
+
+	// Users without an entry may use any host.
+	if err := p.Validate("u2", "s3://evil.com/key.mp4"); err != nil {
+		t.Errorf("Validate(u2, unrestricted) = %v, want nil", err)
+	}
+
+	// An empty list removes the restriction.
+	p.SetUserDomains("u1", nil)
+	if err := p.Validate("u1", "s3://evil.com/key.mp4"); err != nil {
+		t.Errorf("Validate after clearing allowlist = %v, want nil", err)
+	}
+	if domains := p.UserDomains(); len(domains) != 0 {
+		t.Errorf("UserDomains after clearing = %v, want empty", domains)
+	}
+}
+
+func TestValidateLiveSchemes(t *testing.T) {
+	p := New()
+	if err := p.Validate("u1", "rtmp://93.184.216.34/live/key"); err != nil {
+		t.Errorf("Validate(rtmp public) = %v, want nil", err)
+	}
+	// Address checks apply to every dialed scheme, not just http(s).
+	if err := p.Validate("u1", "rtmp://127.0.0.1/live/key"); err == nil {
+		t.Error("Validate(rtmp loopback) = nil, want private-address error")
+	}
+	if err := p.Validate("u1", "srt://10.0.0.8:9000"); err == nil {
+		t.Error("Validate(srt private) = nil, want private-address error")
+	}
+}
+
+func TestValidatePush(t *testing.T) {
+	p := New()
+	if err := p.ValidatePush("u1", "rtmp://93.184.216.34/app/key"); err != nil {
+		t.Errorf("ValidatePush(rtmp public) = %v, want nil", err)
+	}
+	denied := []string{
+		"/var/lib/webencode/overwrite.flv", // local path
+		"out.flv",
+		"http://93.184.216.34/push", // not a push scheme
+		"file:///etc/passwd",
+		"rtmp://127.0.0.1/app/key", // loopback
+		"rtmp://192.168.1.20/app",  // private
+		"rtmp://",                  // no host
+	}
+	for _, raw := range denied {
+		if err := p.ValidatePush("u1", raw); err == nil {
+			t.Errorf("ValidatePush(%q) = nil, want error", raw)
+		}
+	}
+
+	// The per-user domain allowlist binds push destinations too; use a
+	// host that is also a literal IP so no DNS is involved.
+	p.AllowPrivate = true
+	p.SetUserDomains("u1", []string{"10.0.0.9"})
+	if err := p.ValidatePush("u1", "rtmp://10.0.0.9/app/key"); err != nil {
+		t.Errorf("ValidatePush(allowlisted host) = %v, want nil", err)
+	}
+	if err := p.ValidatePush("u1", "rtmp://10.0.0.10/app/key"); err == nil {
+		t.Error("ValidatePush(non-allowlisted host) = nil, want allowlist error")
+	}
+}
+
+func TestIPAllowed(t *testing.T) {
+	p := New()
+	cases := []struct {
+		ip   string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"8.8.8.8", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"172.31.255.255", false},
+		{"192.168.0.1", false},
+		{"169.254.169.254", false},
+		{"0.0.0.0", false},
+		{"::1", false},
+		{"fe80::1", false},
+	}
+	for _, tc := range cases {
+		if got := p.ipAllowed(net.ParseIP(tc.ip)); got != tc.want {
+			t.Errorf("ipAllowed(%s) = %v, want %v", tc.ip, got, tc.want)
+		}
+	}
+	if p.ipAllowed(nil) {
+		t.Error("ipAllowed(nil) = true, want false")
+	}
+}
+
+func TestClientAppliesFetchTimeout(t *testing.T) {
+	p := New()
+	p.FetchTimeout = 7 * time.Second
+	if got := p.Client().Timeout; got != 7*time.Second {
+		t.Errorf("Client().Timeout = %v, want 7s", got)
+	}
+}
@@ -0,0 +1,207 @@
+// Package usage aggregates per-user resource consumption (encode time,
+// storage, egress, concurrency) and enforces quotas at job submission.
+package usage
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rennerdo30/webencode/pkg/errcode"
+)
+
+// ErrQuotaExceeded wraps every quota rejection so callers can map it to
+// a distinct HTTP status and error code.
+var ErrQuotaExceeded error = errcode.New(errcode.QuotaExceeded, "quota exceeded")
+
+// Quota bounds one user's consumption. Zero values are unlimited.
+type Quota struct {
+	MaxEncodeMinutes  float64 `json:"max_encode_minutes,omitempty"`
+	MaxStorageBytes   int64   `json:"max_storage_bytes,omitempty"`
+	MaxEgressBytes    int64   `json:"max_egress_bytes,omitempty"`
+	MaxConcurrentJobs int     `json:"max_concurrent_jobs,omitempty"`
+}
+
+// Usage is one user's aggregated consumption since kernel start.
+type Usage struct {
+	UserID        string  `json:"user_id"`
+	EncodeMinutes float64 `json:"encode_minutes"`
+	// StorageBytes counts bytes written by completed tasks.
+	StorageBytes int64 `json:"storage_bytes"`
+	// EgressBytes counts bytes handed out via presigned output links —
+	// an upper bound, since links may go unused.
+	EgressBytes    int64 `json:"egress_bytes"`
+	JobsSubmitted  int   `json:"jobs_submitted"`
+	ConcurrentJobs int   `json:"concurrent_jobs"`
+}
+
+// Accountant tracks usage and quotas per user.
+type Accountant struct {
+	// Default applies to users without an explicit quota.
+	Default Quota
+
+	mu     sync.Mutex
+	usage  map[string]*Usage
+	quotas map[string]Quota
+	// taskStarts times running tasks so completion can charge wall-clock
+	// encode minutes.
+	taskStarts map[string]taskStart
+	// activeJobs maps job ID to owner so terminal transitions release
+	// exactly one concurrency slot no matter how often they fire.
+	activeJobs map[string]string
+}
+
+type taskStart struct {
+	userID string
+	at     time.Time
+}
+
+// NewAccountant returns an empty accountant with the given defaults.
+func NewAccountant(def Quota) *Accountant {
+	return &Accountant{
+		Default:    def,
+		usage:      make(map[string]*Usage),
+		quotas:     make(map[string]Quota),
+		taskStarts: make(map[string]taskStart),
+		activeJobs: make(map[string]string),
+	}
+}
+
+func (a *Accountant) userLocked(userID string) *Usage {
+	u, ok := a.usage[userID]
+	if !ok {
+		u = &Usage{UserID: userID}
+		a.usage[userID] = u
+	}
+	return u
+}
+
+func (a *Accountant) quotaLocked(userID string) Quota {
+	if q, ok := a.quotas[userID]; ok {
+		return q
+	}
+	return a.Default
+}
+
+// Authorize checks whether userID may submit another job. It charges
+// nothing; call JobStarted once the job is accepted.
+func (a *Accountant) Authorize(userID string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	u := a.userLocked(userID)
+	q := a.quotaLocked(userID)
+	if q.MaxConcurrentJobs > 0 && u.ConcurrentJobs >= q.MaxConcurrentJobs {
+		return fmt.Errorf("%w: %d jobs already active (limit %d)", ErrQuotaExceeded, u.ConcurrentJobs, q.MaxConcurrentJobs)
+	}
+	if q.MaxEncodeMinutes > 0 && u.EncodeMinutes >= q.MaxEncodeMinutes {
+		return fmt.Errorf("%w: %.1f encode minutes used (limit %.1f)", ErrQuotaExceeded, u.EncodeMinutes, q.MaxEncodeMinutes)
+	}
+	if q.MaxStorageBytes > 0 && u.StorageBytes >= q.MaxStorageBytes {
+		return fmt.Errorf("%w: %d storage bytes written (limit %d)", ErrQuotaExceeded, u.StorageBytes, q.MaxStorageBytes)
+	}
+	if q.MaxEgressBytes > 0 && u.EgressBytes >= q.MaxEgressBytes {
+		return fmt.Errorf("%w: %d egress bytes served (limit %d)", ErrQuotaExceeded, u.EgressBytes, q.MaxEgressBytes)
+	}
+	return nil
+}
+
+// JobStarted counts a newly accepted job against concurrency.
+func (a *Accountant) JobStarted(userID, jobID string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.activeJobs[jobID]; ok {
+		return
+	}
+	a.activeJobs[jobID] = userID
+	u := a.userLocked(userID)
+	u.JobsSubmitted++
+	u.ConcurrentJobs++
+}
+
+// JobFinished releases the job's concurrency slot when it reaches a
+// terminal state. Safe to call more than once.
+func (a *Accountant) JobFinished(jobID string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	userID, ok := a.activeJobs[jobID]
+	if !ok {
+		return
+	}
+	delete(a.activeJobs, jobID)
+	u := a.userLocked(userID)
+	if u.ConcurrentJobs > 0 {
+		u.ConcurrentJobs--
+	}
+}
+
+// TaskStarted marks the start of a running task for encode-time
+// accounting.
+func (a *Accountant) TaskStarted(taskID, userID string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.taskStarts[taskID]; !ok {
+		a.taskStarts[taskID] = taskStart{userID: userID, at: time.Now()}
+	}
+}
+
+// TaskFinished charges the task's wall-clock runtime and the bytes it
+// wrote.
+func (a *Accountant) TaskFinished(taskID string, bytesWritten int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	start, ok := a.taskStarts[taskID]
+	if !ok {
+		return
+	}
+	delete(a.taskStarts, taskID)
+	u := a.userLocked(start.userID)
+	u.EncodeMinutes += time.Since(start.at).Minutes()
+	u.StorageBytes += bytesWritten
+}
+
+// RecordEgress charges presigned-download bytes.
+func (a *Accountant) RecordEgress(userID string, bytes int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.userLocked(userID).EgressBytes += bytes
+}
+
+// SetQuota installs a per-user quota override.
+func (a *Accountant) SetQuota(userID string, q Quota) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.quotas[userID] = q
+}
+
+// GetQuota returns the quota in effect for userID.
+func (a *Accountant) GetQuota(userID string) Quota {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.quotaLocked(userID)
+}
+
+// Report is one user's usage next to the quota in effect.
+type Report struct {
+	Usage
+	Quota Quota `json:"quota"`
+}
+
+// Get returns one user's report.
+func (a *Accountant) Get(userID string) Report {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return Report{Usage: *a.userLocked(userID), Quota: a.quotaLocked(userID)}
+}
+
+// List returns all users' reports, sorted by user ID.
+func (a *Accountant) List() []Report {
+	a.mu.Lock()
+	out := make([]Report, 0, len(a.usage))
+	for id, u := range a.usage {
+		out = append(out, Report{Usage: *u, Quota: a.quotaLocked(id)})
+	}
+	a.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].UserID < out[j].UserID })
+	return out
+}
@@ -0,0 +1,300 @@
+// Package webhooks delivers kernel events to external HTTP endpoints.
+// Payloads are HMAC-signed, failed deliveries retry with exponential
+// backoff until they dead-letter, and every attempt is recorded for
+// debugging.
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rennerdo30/webencode/internal/kernel/events"
+)
+
+// ErrNotFound is returned for unknown webhook IDs.
+var ErrNotFound = errors.New("webhook not found")
+
+// SignatureHeader carries the hex HMAC-SHA256 of the payload, prefixed
+// with the scheme: "sha256=<hex>".
+const SignatureHeader = "X-WebEncode-Signature"
+
+const (
+	// maxAttempts bounds delivery retries before dead-lettering.
+	maxAttempts = 5
+	// deliveryLogSize bounds the per-webhook delivery history.
+	deliveryLogSize = 200
+	// responseSnippet bounds how much of a response body is recorded.
+	responseSnippet = 512
+)
+
+// Delivery states.
+const (
+	StatePending   = "pending"
+	StateDelivered = "delivered"
+	StateDead      = "dead"
+)
+
+// Webhook is one registered endpoint. The signing secret is write-only
+// through the API.
+type Webhook struct {
+	ID     string `json:"id"`
+	UserID string `json:"user_id,omitempty"`
+	URL    string `json:"url"`
+	secret string
+	// Events are event-type prefixes the endpoint subscribes to
+	// ("task", "worker", ...); empty subscribes to everything.
+	Events    []string  `json:"events,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Attempt records one delivery try.
+type Attempt struct {
+	At        time.Time `json:"at"`
+	Status    int       `json:"status,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	LatencyMS int64     `json:"latency_ms"`
+	// Body is the start of the endpoint's response body.
+	Body string `json:"body,omitempty"`
+}
+
+// Delivery tracks one event on its way to one webhook.
+type Delivery struct {
+	ID        string    `json:"id"`
+	WebhookID string    `json:"webhook_id"`
+	EventID   string    `json:"event_id"`
+	EventType string    `json:"event_type"`
+	State     string    `json:"state"`
+	Attempts  []Attempt `json:"attempts"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Manager owns webhook registrations and their delivery pipeline.
+type Manager struct {
+	Events *events.Log
+	// Backoff is the initial retry delay, doubled per attempt. The
+	// default is 2s; tests shrink it.
+	Backoff time.Duration
+
+	client     *http.Client
+	mu         sync.Mutex
+	hooks      map[string]*Webhook
+	deliveries map[string][]*Delivery
+}
+
+// NewManager returns a webhook manager delivering events from log.
+func NewManager(log *events.Log) *Manager {
+	return &Manager{
+		Events:     log,
+		Backoff:    2 * time.Second,
+		client:     &http.Client{Timeout: 15 * time.Second},
+		hooks:      make(map[string]*Webhook),
+		deliveries: make(map[string][]*Delivery),
+	}
+}
+
+// Create registers an endpoint and returns it with its signing secret.
+func (m *Manager) Create(userID, url, secret string, eventPrefixes []string) *Webhook {
+	h := &Webhook{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		URL:       url,
+		secret:    secret,
+		Events:    eventPrefixes,
+		CreatedAt: time.Now().UTC(),
+	}
+	m.mu.Lock()
+	m.hooks[h.ID] = h
+	m.mu.Unlock()
+	return h
+}
+
+// Get returns one webhook.
+func (m *Manager) Get(id string) (*Webhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h, ok := m.hooks[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return h, nil
+}
+
+// List returns webhooks, optionally filtered to one owner.
+func (m *Manager) List(userID string) []*Webhook {
+	m.mu.Lock()
+	out := make([]*Webhook, 0, len(m.hooks))
+	for _, h := range m.hooks {
+		if userID != "" && h.UserID != userID {
+			continue
+		}
+		out = append(out, h)
+	}
+	m.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out
+}
+
+// Delete removes a webhook registration.
+func (m *Manager) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.hooks[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.hooks, id)
+	delete(m.deliveries, id)
+	return nil
+}
+
+// Deliveries returns a webhook's delivery log, newest first.
+func (m *Manager) Deliveries(id string) ([]*Delivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.hooks[id]; !ok {
+		return nil, ErrNotFound
+	}
+	log := m.deliveries[id]
+	out := make([]*Delivery, 0, len(log))
+	for i := len(log) - 1; i >= 0; i-- {
+		copied := *log[i]
+		out = append(out, &copied)
+	}
+	return out, nil
+}
+
+// Run subscribes to the event log and delivers matching events until
+// stop is closed.
+func (m *Manager) Run(stop <-chan struct{}) {
+	ch, cancel := m.Events.Subscribe()
+	defer cancel()
+	for {
+		select {
+		case <-stop:
+			return
+		case e := <-ch:
+			m.mu.Lock()
+			for _, h := range m.hooks {
+				if h.matches(e.Type) {
+					go m.deliver(h, e)
+				}
+			}
+			m.mu.Unlock()
+		}
+	}
+}
+
+// matches reports whether the hook subscribes to the event type.
+func (h *Webhook) matches(eventType string) bool {
+	if len(h.Events) == 0 {
+		return true
+	}
+	topic, _, _ := strings.Cut(eventType, ".")
+	for _, want := range h.Events {
+		if want == topic || want == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// deliver pushes one event to one endpoint, retrying with backoff and
+// dead-lettering after maxAttempts.
+func (m *Manager) deliver(h *Webhook, e events.Event) {
+	d := &Delivery{
+		ID:        uuid.NewString(),
+		WebhookID: h.ID,
+		EventID:   e.ID,
+		EventType: e.Type,
+		State:     StatePending,
+		CreatedAt: time.Now().UTC(),
+	}
+	m.record(d)
+
+	payload, err := json.Marshal(e)
+	if err != nil {
+		m.update(d, func(d *Delivery) { d.State = StateDead })
+		return
+	}
+	backoff := m.Backoff
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		a := m.attempt(h, payload)
+		ok := a.Error == "" && a.Status >= 200 && a.Status <= 299
+		m.update(d, func(d *Delivery) {
+			d.Attempts = append(d.Attempts, a)
+			if ok {
+				d.State = StateDelivered
+			}
+		})
+		if ok {
+			return
+		}
+	}
+	m.update(d, func(d *Delivery) { d.State = StateDead })
+}
+
+// attempt performs one signed POST and records its outcome.
+func (m *Manager) attempt(h *Webhook, payload []byte) Attempt {
+	a := Attempt{At: time.Now().UTC()}
+	req, err := http.NewRequest(http.MethodPost, h.URL, bytes.NewReader(payload))
+	if err != nil {
+		a.Error = err.Error()
+		return a
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(h.secret, payload))
+	}
+	start := time.Now()
+	resp, err := m.client.Do(req)
+	a.LatencyMS = time.Since(start).Milliseconds()
+	if err != nil {
+		a.Error = err.Error()
+		return a
+	}
+	defer resp.Body.Close()
+	a.Status = resp.StatusCode
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, responseSnippet))
+	a.Body = string(snippet)
+	return a
+}
+
+// Sign computes the hex HMAC-SHA256 of payload under secret.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// record appends a delivery to the bounded per-hook log.
+func (m *Manager) record(d *Delivery) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	log := append(m.deliveries[d.WebhookID], d)
+	if len(log) > deliveryLogSize {
+		log = log[len(log)-deliveryLogSize:]
+	}
+	m.deliveries[d.WebhookID] = log
+}
+
+// update mutates a delivery under the manager lock.
+func (m *Manager) update(d *Delivery, fn func(*Delivery)) {
+	m.mu.Lock()
+	fn(d)
+	m.mu.Unlock()
+}
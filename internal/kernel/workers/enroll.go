@@ -0,0 +1,65 @@
+package workers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+
+	"github.com/google/uuid"
+
+	"github.com/rennerdo30/webencode/internal/kernel/events"
+)
+
+// Enrollment is the identity the kernel hands a worker at registration.
+// The token authenticates the worker's bus messages; only its hash is
+// kept kernel-side.
+type Enrollment struct {
+	WorkerID string `json:"worker_id"`
+	Token    string `json:"token"`
+}
+
+// Enroll registers a new worker identity and returns its credentials —
+// the only time the token plaintext is available.
+func (m *Manager) Enroll(hostname string, labels map[string]string) (Enrollment, error) {
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return Enrollment{}, err
+	}
+	e := Enrollment{
+		WorkerID: uuid.NewString(),
+		Token:    hex.EncodeToString(raw),
+	}
+	m.mu.Lock()
+	if m.tokenHashes == nil {
+		m.tokenHashes = make(map[string]string)
+	}
+	m.tokenHashes[e.WorkerID] = hashToken(e.Token)
+	m.mu.Unlock()
+	m.Events.Append(events.Event{
+		Type:     "worker.enrolled",
+		Resource: e.WorkerID,
+		Message:  "worker identity issued",
+		Data:     map[string]interface{}{"hostname": hostname, "labels": labels},
+	})
+	return e, nil
+}
+
+// Authenticate verifies a bus message's worker credentials. Messages
+// from enrolled workers must carry the matching token; messages from
+// unenrolled worker IDs are only accepted while RequireAuth is off.
+func (m *Manager) Authenticate(workerID, token string) bool {
+	m.mu.Lock()
+	want, enrolled := m.tokenHashes[workerID]
+	m.mu.Unlock()
+	if !enrolled {
+		return !m.RequireAuth
+	}
+	got := hashToken(token)
+	return subtle.ConstantTimeCompare([]byte(want), []byte(got)) == 1
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,305 @@
+// Package workers tracks worker nodes, their heartbeats and health
+// incidents.
+package workers
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rennerdo30/webencode/internal/kernel/events"
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// ErrNotFound is returned for unknown worker IDs.
+var ErrNotFound = errors.New("worker not found")
+
+// Incident records one period during which a worker was considered
+// unhealthy, so flapping infrastructure is visible rather than silently
+// absorbed.
+type Incident struct {
+	ID       string `json:"id"`
+	WorkerID string `json:"worker_id"`
+	// Reason is why the incident opened, e.g. "heartbeat gap".
+	Reason    string     `json:"reason"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	// AffectedTasks are the task IDs that were in flight on the worker
+	// when the incident opened.
+	AffectedTasks []string `json:"affected_tasks,omitempty"`
+	// Actions are the automatic remediations taken (requeues etc.).
+	Actions []string `json:"actions,omitempty"`
+}
+
+// Manager is the kernel-side worker registry.
+type Manager struct {
+	Events *events.Log
+	// HeartbeatTimeout is the gap after which a worker is marked
+	// unhealthy.
+	HeartbeatTimeout time.Duration
+	// OnUnhealthy, when set, is called with the worker's in-flight tasks
+	// when an incident opens and returns the remediation actions taken
+	// (e.g. requeues). It runs outside the manager lock.
+	OnUnhealthy func(workerID string, runningTasks []string) []string
+	// RequireAuth rejects bus messages from worker IDs that never
+	// enrolled. Off by default so unmanaged dev setups keep working.
+	RequireAuth bool
+
+	mu        sync.Mutex
+	workers   map[string]*types.Worker
+	running   map[string][]string
+	incidents map[string][]*Incident
+	open      map[string]*Incident
+	// tokenHashes holds the per-worker auth token hashes by worker ID.
+	tokenHashes map[string]string
+}
+
+// NewManager returns a worker registry marking workers unhealthy after
+// timeout without a heartbeat.
+func NewManager(eventLog *events.Log, timeout time.Duration) *Manager {
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return &Manager{
+		Events:           eventLog,
+		HeartbeatTimeout: timeout,
+		workers:          make(map[string]*types.Worker),
+		running:          make(map[string][]string),
+		incidents:        make(map[string][]*Incident),
+		open:             make(map[string]*Incident),
+	}
+}
+
+// Observe ingests one heartbeat, registering unknown workers and closing
+// any open incident for recovered ones.
+func (m *Manager) Observe(hb types.Heartbeat) {
+	now := hb.At
+	if now.IsZero() {
+		now = time.Now().UTC()
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w, ok := m.workers[hb.WorkerID]
+	if !ok {
+		w = &types.Worker{ID: hb.WorkerID, FirstSeen: now}
+		m.workers[hb.WorkerID] = w
+	}
+	w.Hostname = hb.Hostname
+	w.Labels = hb.Labels
+	w.Draining = hb.Draining
+	w.LastHeartbeat = now
+	w.DiskFreeBytes = hb.DiskFreeBytes
+	w.DiskTotalBytes = hb.DiskTotalBytes
+	m.running[hb.WorkerID] = hb.RunningTasks
+
+	if hb.Shutdown {
+		// Farewell heartbeat of a draining worker: take it offline now
+		// instead of waiting out the heartbeat timeout.
+		w.Status = types.WorkerStatusOffline
+		delete(m.running, w.ID)
+		m.Events.Append(events.Event{
+			Type:     "worker.shutdown",
+			Resource: w.ID,
+			Message:  "worker announced shutdown",
+		})
+		return
+	}
+
+	if w.Status != types.WorkerStatusHealthy {
+		w.Status = types.WorkerStatusHealthy
+		if inc, open := m.open[w.ID]; open {
+			ended := now
+			inc.EndedAt = &ended
+			delete(m.open, w.ID)
+			m.Events.Append(events.Event{
+				Type:     "worker.recovered",
+				Resource: w.ID,
+				Message:  "heartbeats resumed, incident closed",
+				Data:     map[string]interface{}{"incident_id": inc.ID},
+			})
+		}
+	}
+}
+
+// Utilization reports the fleet size and how many workers reported
+// running tasks in their last heartbeat.
+func (m *Manager) Utilization() (total, busy int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id := range m.workers {
+		total++
+		if len(m.running[id]) > 0 {
+			busy++
+		}
+	}
+	return total, busy
+}
+
+// RecordBenchmark stores a worker's benchmark scores; the scheduler
+// can weight dispatch by them.
+func (m *Manager) RecordBenchmark(id string, scores map[string]float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w, ok := m.workers[id]
+	if !ok {
+		return
+	}
+	w.Benchmark = scores
+	w.BenchmarkAt = time.Now().UTC()
+}
+
+// Claimed returns the task IDs healthy workers claimed in their last
+// heartbeat, mapped to the claiming worker. Unhealthy and offline
+// workers are excluded: their claims are stale by definition.
+func (m *Manager) Claimed() map[string]string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make(map[string]string)
+	for id, tasks := range m.running {
+		w, ok := m.workers[id]
+		if !ok || w.Status != types.WorkerStatusHealthy {
+			continue
+		}
+		for _, taskID := range tasks {
+			out[taskID] = id
+		}
+	}
+	return out
+}
+
+// CheckHealth sweeps for heartbeat gaps, opening incidents for workers
+// that went quiet. Call it periodically.
+func (m *Manager) CheckHealth() {
+	now := time.Now().UTC()
+	type opened struct {
+		workerID string
+		tasks    []string
+		incident *Incident
+	}
+	var newIncidents []opened
+
+	m.mu.Lock()
+	for id, w := range m.workers {
+		if w.Status != types.WorkerStatusHealthy || now.Sub(w.LastHeartbeat) < m.HeartbeatTimeout {
+			continue
+		}
+		w.Status = types.WorkerStatusUnhealthy
+		inc := &Incident{
+			ID:            uuid.NewString(),
+			WorkerID:      id,
+			Reason:        "heartbeat gap",
+			StartedAt:     now,
+			AffectedTasks: append([]string(nil), m.running[id]...),
+		}
+		m.incidents[id] = append(m.incidents[id], inc)
+		m.open[id] = inc
+		newIncidents = append(newIncidents, opened{workerID: id, tasks: inc.AffectedTasks, incident: inc})
+	}
+	m.mu.Unlock()
+
+	for _, o := range newIncidents {
+		m.Events.Append(events.Event{
+			Type:     "worker.unhealthy",
+			Resource: o.workerID,
+			Message:  "no heartbeat received, incident opened",
+			Data: map[string]interface{}{
+				"incident_id":    o.incident.ID,
+				"affected_tasks": o.tasks,
+			},
+		})
+		if m.OnUnhealthy != nil {
+			actions := m.OnUnhealthy(o.workerID, o.tasks)
+			m.mu.Lock()
+			o.incident.Actions = actions
+			m.mu.Unlock()
+		}
+	}
+}
+
+// FlagUnhealthy marks a worker unhealthy for a reason other than a
+// heartbeat gap (e.g. repeated node-local task failures), opening an
+// incident. The worker returns to healthy on its next heartbeat.
+func (m *Manager) FlagUnhealthy(id, reason string) {
+	now := time.Now().UTC()
+	m.mu.Lock()
+	w, ok := m.workers[id]
+	if !ok || w.Status != types.WorkerStatusHealthy {
+		m.mu.Unlock()
+		return
+	}
+	w.Status = types.WorkerStatusUnhealthy
+	inc := &Incident{
+		ID:            uuid.NewString(),
+		WorkerID:      id,
+		Reason:        reason,
+		StartedAt:     now,
+		AffectedTasks: append([]string(nil), m.running[id]...),
+	}
+	m.incidents[id] = append(m.incidents[id], inc)
+	m.open[id] = inc
+	m.mu.Unlock()
+
+	m.Events.Append(events.Event{
+		Type:     "worker.unhealthy",
+		Resource: id,
+		Message:  reason,
+		Data:     map[string]interface{}{"incident_id": inc.ID},
+	})
+}
+
+// Monitor runs CheckHealth every interval until stop is closed.
+func (m *Manager) Monitor(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			m.CheckHealth()
+		}
+	}
+}
+
+// Get returns one worker.
+func (m *Manager) Get(id string) (*types.Worker, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w, ok := m.workers[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	copied := *w
+	return &copied, nil
+}
+
+// List returns all workers sorted by ID.
+func (m *Manager) List() []*types.Worker {
+	m.mu.Lock()
+	out := make([]*types.Worker, 0, len(m.workers))
+	for _, w := range m.workers {
+		copied := *w
+		out = append(out, &copied)
+	}
+	m.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// Incidents returns a worker's incident history, oldest first.
+func (m *Manager) Incidents(id string) ([]*Incident, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.workers[id]; !ok {
+		return nil, ErrNotFound
+	}
+	out := make([]*Incident, 0, len(m.incidents[id]))
+	for _, inc := range m.incidents[id] {
+		copied := *inc
+		out = append(out, &copied)
+	}
+	return out, nil
+}
@@ -0,0 +1,237 @@
+// Package metrics is a small dependency-free Prometheus client: it
+// keeps counters, gauges and histograms in a process-wide registry and
+// renders them in the text exposition format on /metrics. Label sets
+// are fixed at registration so instrumentation stays cardinality-safe.
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// registry holds every registered metric, keyed by name.
+var registry = struct {
+	mu      sync.Mutex
+	metrics map[string]renderer
+}{metrics: make(map[string]renderer)}
+
+// renderer is one registered metric family.
+type renderer interface {
+	render(w *strings.Builder)
+}
+
+func register(name string, r renderer) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if _, dup := registry.metrics[name]; dup {
+		panic("metrics: duplicate registration of " + name)
+	}
+	registry.metrics[name] = r
+}
+
+// Handler serves the registry in the Prometheus text format.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		registry.mu.Lock()
+		names := make([]string, 0, len(registry.metrics))
+		for name := range registry.metrics {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var b strings.Builder
+		for _, name := range names {
+			registry.metrics[name].render(&b)
+		}
+		registry.mu.Unlock()
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_, _ = w.Write([]byte(b.String()))
+	})
+}
+
+// labelKey joins label values into a map key; \xff cannot appear in
+// label values we generate.
+func labelKey(values []string) string { return strings.Join(values, "\xff") }
+
+// formatLabels renders a {k="v",...} block, empty for no labels.
+func formatLabels(names []string, key string, extra string) string {
+	if len(names) == 0 && extra == "" {
+		return ""
+	}
+	values := strings.Split(key, "\xff")
+	parts := make([]string, 0, len(names)+1)
+	for i, n := range names {
+		v := ""
+		if i < len(values) {
+			v = values[i]
+		}
+		v = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+		parts = append(parts, fmt.Sprintf("%s=%q", n, v))
+	}
+	if extra != "" {
+		parts = append(parts, extra)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// sortedKeys returns the series keys of a map in stable order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Counter is a monotonically increasing metric family.
+type Counter struct {
+	name, help string
+	labelNames []string
+
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+// NewCounter registers a counter family.
+func NewCounter(name, help string, labelNames ...string) *Counter {
+	c := &Counter{name: name, help: help, labelNames: labelNames, values: make(map[string]float64)}
+	register(name, c)
+	return c
+}
+
+// Inc adds one to the series for the given label values.
+func (c *Counter) Inc(labelValues ...string) { c.Add(1, labelValues...) }
+
+// Add adds delta to the series for the given label values.
+func (c *Counter) Add(delta float64, labelValues ...string) {
+	c.mu.Lock()
+	c.values[labelKey(labelValues)] += delta
+	c.mu.Unlock()
+}
+
+func (c *Counter) render(b *strings.Builder) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	for _, key := range sortedKeys(c.values) {
+		fmt.Fprintf(b, "%s%s %g\n", c.name, formatLabels(c.labelNames, key, ""), c.values[key])
+	}
+}
+
+// Gauge is a metric family that can go up and down.
+type Gauge struct {
+	name, help string
+	labelNames []string
+
+	mu     sync.Mutex
+	values map[string]float64
+	fn     func() map[string]float64
+}
+
+// NewGauge registers a gauge family.
+func NewGauge(name, help string, labelNames ...string) *Gauge {
+	g := &Gauge{name: name, help: help, labelNames: labelNames, values: make(map[string]float64)}
+	register(name, g)
+	return g
+}
+
+// NewGaugeFunc registers a gauge family collected on scrape. The
+// callback returns one value per label-value key (see Key); a family
+// without labels returns map[""]value.
+func NewGaugeFunc(name, help string, labelNames []string, fn func() map[string]float64) {
+	register(name, &Gauge{name: name, help: help, labelNames: labelNames, fn: fn})
+}
+
+// Key builds the map key NewGaugeFunc callbacks index their result by.
+func Key(labelValues ...string) string { return labelKey(labelValues) }
+
+// Set sets the series for the given label values.
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.mu.Lock()
+	g.values[labelKey(labelValues)] = v
+	g.mu.Unlock()
+}
+
+func (g *Gauge) render(b *strings.Builder) {
+	values := g.values
+	if g.fn != nil {
+		values = g.fn()
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s gauge\n", g.name, g.help, g.name)
+	for _, key := range sortedKeys(values) {
+		fmt.Fprintf(b, "%s%s %g\n", g.name, formatLabels(g.labelNames, key, ""), values[key])
+	}
+}
+
+// histogramSeries is one label combination's bucket state.
+type histogramSeries struct {
+	buckets []uint64
+	sum     float64
+	count   uint64
+}
+
+// Histogram is a cumulative-bucket distribution family.
+type Histogram struct {
+	name, help string
+	labelNames []string
+	bounds     []float64
+
+	mu     sync.Mutex
+	values map[string]*histogramSeries
+}
+
+// NewHistogram registers a histogram family with the given upper
+// bounds (ascending; +Inf is implicit).
+func NewHistogram(name, help string, bounds []float64, labelNames ...string) *Histogram {
+	h := &Histogram{name: name, help: help, labelNames: labelNames, bounds: bounds, values: make(map[string]*histogramSeries)}
+	register(name, h)
+	return h
+}
+
+// Observe records one sample for the given label values.
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	key := labelKey(labelValues)
+	h.mu.Lock()
+	s := h.values[key]
+	if s == nil {
+		s = &histogramSeries{buckets: make([]uint64, len(h.bounds))}
+		h.values[key] = s
+	}
+	for i, bound := range h.bounds {
+		if v <= bound {
+			s.buckets[i]++
+		}
+	}
+	s.sum += v
+	s.count++
+	h.mu.Unlock()
+}
+
+func (h *Histogram) render(b *strings.Builder) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	for _, key := range sortedKeys(h.values) {
+		s := h.values[key]
+		for i, bound := range h.bounds {
+			le := fmt.Sprintf("le=%q", formatFloat(bound))
+			fmt.Fprintf(b, "%s_bucket%s %d\n", h.name, formatLabels(h.labelNames, key, le), s.buckets[i])
+		}
+		fmt.Fprintf(b, "%s_bucket%s %d\n", h.name, formatLabels(h.labelNames, key, `le="+Inf"`), s.count)
+		fmt.Fprintf(b, "%s_sum%s %g\n", h.name, formatLabels(h.labelNames, key, ""), s.sum)
+		fmt.Fprintf(b, "%s_count%s %d\n", h.name, formatLabels(h.labelNames, key, ""), s.count)
+	}
+}
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return fmt.Sprintf("%g", f)
+}
@@ -0,0 +1,148 @@
+package pluginmgr
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/rennerdo30/webencode/pkg/plugin"
+)
+
+// AuthChain returns an AuthService that tries the named auth plugins in
+// priority order until one validates the token. A plugin that is down
+// or errors is skipped; a token every reachable plugin rejects is
+// invalid.
+func (m *Manager) AuthChain(names []string) plugin.AuthService {
+	return &authChain{m: m, names: names}
+}
+
+type authChain struct {
+	m     *Manager
+	names []string
+}
+
+func (c *authChain) Name() (string, error) {
+	return strings.Join(c.names, ","), nil
+}
+
+func (c *authChain) Authenticate(token string) (*plugin.Identity, error) {
+	var lastErr error
+	invalid := false
+	for _, name := range c.names {
+		svc, err := c.m.Auth(name)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		id, err := svc.Authenticate(token)
+		if err == nil {
+			return id, nil
+		}
+		if plugin.IsInvalidToken(err) {
+			invalid = true
+			continue
+		}
+		lastErr = err
+	}
+	if invalid {
+		return nil, plugin.ErrInvalidToken
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no auth plugins configured")
+	}
+	return nil, lastErr
+}
+
+// StorageChain returns a StorageService that routes each operation to
+// the highest-priority plugin and fails over to the next on error.
+// failoverOps limits which operations fail over (upload, download,
+// list, delete, presign); empty enables failover for all of them.
+func (m *Manager) StorageChain(names, failoverOps []string) plugin.StorageService {
+	failover := make(map[string]bool)
+	if len(failoverOps) == 0 {
+		for _, op := range []string{"upload", "download", "list", "delete", "presign"} {
+			failover[op] = true
+		}
+	} else {
+		for _, op := range failoverOps {
+			failover[op] = true
+		}
+	}
+	return &storageChain{m: m, names: names, failover: failover}
+}
+
+type storageChain struct {
+	m        *Manager
+	names    []string
+	failover map[string]bool
+}
+
+// do runs fn against the chain in priority order. A dispense failure
+// always moves on to the next plugin; an operation failure only does
+// when failover is enabled for op.
+func (c *storageChain) do(op string, fn func(plugin.StorageService) error) error {
+	var lastErr error
+	for _, name := range c.names {
+		svc, err := c.m.Storage(name)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if err := fn(svc); err != nil {
+			if !c.failover[op] {
+				return err
+			}
+			log.Printf("pluginmgr: storage %s: %s failed, failing over: %v", name, op, err)
+			lastErr = err
+			continue
+		}
+		return nil
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no storage plugins configured")
+	}
+	return lastErr
+}
+
+func (c *storageChain) Name() (string, error) {
+	return strings.Join(c.names, ","), nil
+}
+
+func (c *storageChain) Upload(localPath, key string) error {
+	return c.do("upload", func(svc plugin.StorageService) error {
+		return svc.Upload(localPath, key)
+	})
+}
+
+func (c *storageChain) Download(key, localPath string) error {
+	return c.do("download", func(svc plugin.StorageService) error {
+		return svc.Download(key, localPath)
+	})
+}
+
+func (c *storageChain) List(prefix string) ([]plugin.ObjectInfo, error) {
+	var out []plugin.ObjectInfo
+	err := c.do("list", func(svc plugin.StorageService) error {
+		var listErr error
+		out, listErr = svc.List(prefix)
+		return listErr
+	})
+	return out, err
+}
+
+func (c *storageChain) Delete(key string) error {
+	return c.do("delete", func(svc plugin.StorageService) error {
+		return svc.Delete(key)
+	})
+}
+
+func (c *storageChain) PresignGet(key string, expiry time.Duration) (string, error) {
+	var url string
+	err := c.do("presign", func(svc plugin.StorageService) error {
+		var presignErr error
+		url, presignErr = svc.PresignGet(key, expiry)
+		return presignErr
+	})
+	return url, err
+}
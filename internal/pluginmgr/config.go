@@ -0,0 +1,141 @@
+package pluginmgr
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rennerdo30/webencode/pkg/plugin"
+)
+
+// configurable dispenses the named plugin's config service.
+func (m *Manager) configurable(name string) (plugin.Configurable, error) {
+	raw, err := m.dispense(name, "config")
+	if err != nil {
+		return nil, err
+	}
+	c, ok := raw.(plugin.Configurable)
+	if !ok {
+		return nil, fmt.Errorf("plugin %s does not implement config", name)
+	}
+	return c, nil
+}
+
+// ConfigSchema returns the config fields the named plugin declares.
+func (m *Manager) ConfigSchema(name string) ([]plugin.ConfigField, error) {
+	c, err := m.configurable(name)
+	if err != nil {
+		return nil, err
+	}
+	return c.ConfigSchema()
+}
+
+// SetConfig validates values against the plugin's declared schema,
+// pushes them to the running plugin and stores them so they survive
+// reloads and supervisor restarts.
+func (m *Manager) SetConfig(name string, values map[string]string) error {
+	c, err := m.configurable(name)
+	if err != nil {
+		return err
+	}
+	fields, err := c.ConfigSchema()
+	if err != nil {
+		return err
+	}
+	if err := validateConfig(fields, values); err != nil {
+		return err
+	}
+	if err := c.Configure(values); err != nil {
+		return err
+	}
+	copied := make(map[string]string, len(values))
+	for k, v := range values {
+		copied[k] = v
+	}
+	m.mu.Lock()
+	m.configs[name] = copied
+	m.mu.Unlock()
+	return nil
+}
+
+// Config returns the stored config for the named plugin with secret
+// values redacted.
+func (m *Manager) Config(name string) (map[string]string, error) {
+	fields, err := m.ConfigSchema(name)
+	if err != nil {
+		return nil, err
+	}
+	secret := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		secret[f.Name] = f.Secret
+	}
+	m.mu.Lock()
+	stored := m.configs[name]
+	out := make(map[string]string, len(stored))
+	for k, v := range stored {
+		if secret[k] {
+			v = "<redacted>"
+		}
+		out[k] = v
+	}
+	m.mu.Unlock()
+	return out, nil
+}
+
+// pushConfig re-applies the stored config after a relaunch; failures
+// are returned so callers can surface them.
+func (m *Manager) pushConfig(name string) error {
+	m.mu.Lock()
+	stored := m.configs[name]
+	m.mu.Unlock()
+	if len(stored) == 0 {
+		return nil
+	}
+	c, err := m.configurable(name)
+	if err != nil {
+		return err
+	}
+	return c.Configure(stored)
+}
+
+// validateConfig checks submitted values against the declared fields:
+// no unknown keys, required fields present, typed values parseable.
+func validateConfig(fields []plugin.ConfigField, values map[string]string) error {
+	byName := make(map[string]plugin.ConfigField, len(fields))
+	for _, f := range fields {
+		byName[f.Name] = f
+	}
+	for key, value := range values {
+		f, ok := byName[key]
+		if !ok {
+			return fmt.Errorf("unknown config field %q", key)
+		}
+		switch f.Type {
+		case "", "string":
+		case "int":
+			if _, err := strconv.Atoi(value); err != nil {
+				return fmt.Errorf("config field %q: %q is not an integer", key, value)
+			}
+		case "float":
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				return fmt.Errorf("config field %q: %q is not a number", key, value)
+			}
+		case "bool":
+			if _, err := strconv.ParseBool(value); err != nil {
+				return fmt.Errorf("config field %q: %q is not a boolean", key, value)
+			}
+		case "duration":
+			if _, err := time.ParseDuration(value); err != nil {
+				return fmt.Errorf("config field %q: %q is not a duration", key, value)
+			}
+		default:
+			return fmt.Errorf("config field %q has unknown type %q", key, f.Type)
+		}
+	}
+	for _, f := range fields {
+		if f.Required && values[f.Name] == "" {
+			return fmt.Errorf("config field %q is required", f.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package pluginmgr
+
+import (
+	"fmt"
+
+	"github.com/rennerdo30/webencode/pkg/plugin"
+)
+
+// negotiateEntry fetches a freshly launched plugin's PluginInfo and
+// refuses it when the build is incompatible: wrong SDK protocol
+// version, or missing the capability its kind requires. Failures are
+// recorded in the plugin's stats so the plugins API shows why it was
+// rejected. Binaries built against a pre-info SDK pass untouched.
+func (m *Manager) negotiateEntry(name string, e *entry) {
+	rpcClient, err := e.client.Client()
+	if err != nil {
+		return
+	}
+	raw, err := rpcClient.Dispense("info")
+	if err != nil {
+		return
+	}
+	provider, ok := raw.(plugin.InfoProvider)
+	if !ok {
+		return
+	}
+	info, err := provider.PluginInfo()
+	if err != nil {
+		e.infoErr = fmt.Errorf("plugin %s: info: %w", name, err)
+	} else if info.ProtoVersion != int(plugin.Handshake.ProtocolVersion) {
+		e.infoErr = fmt.Errorf("plugin %s: built for SDK protocol %d, kernel speaks %d",
+			name, info.ProtoVersion, plugin.Handshake.ProtocolVersion)
+	} else if req := plugin.RequiredCapability(name); req != 0 && info.Capabilities&req == 0 {
+		e.infoErr = fmt.Errorf("plugin %s: does not declare the capability its kind requires", name)
+	}
+
+	m.mu.Lock()
+	st := m.stat(name)
+	if e.infoErr != nil {
+		st.Health = "incompatible"
+		st.LastError = e.infoErr.Error()
+		if m.entries[name] == e {
+			delete(m.entries, name)
+		}
+		m.mu.Unlock()
+		e.client.Kill()
+		return
+	}
+	if info != nil {
+		st.Version = info.Version
+	}
+	m.mu.Unlock()
+}
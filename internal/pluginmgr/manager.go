@@ -0,0 +1,295 @@
+// Package pluginmgr launches and tracks plugin subprocesses. Plugins are
+// executables in the configured plugin dir, named <kind>-<impl>
+// (storage-s3, publisher-youtube, ...); the kind selects which SDK
+// service is dispensed from the binary.
+package pluginmgr
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	goplugin "github.com/hashicorp/go-plugin"
+
+	"github.com/rennerdo30/webencode/internal/trace"
+	"github.com/rennerdo30/webencode/pkg/plugin"
+)
+
+// entry tracks one running plugin subprocess.
+type entry struct {
+	client *goplugin.Client
+	// modTime is the binary's mtime at launch; the watcher compares
+	// against it to detect redeployed binaries.
+	modTime time.Time
+	// inflight counts dispense calls in progress against this client so
+	// a reload can drain them before killing the subprocess.
+	inflight sync.WaitGroup
+	// negotiate runs capability negotiation once per launch; infoErr
+	// holds the failure that makes this plugin unusable.
+	negotiate sync.Once
+	infoErr   error
+}
+
+// Manager owns the running plugin subprocesses.
+type Manager struct {
+	// PluginDir is where plugin executables live.
+	PluginDir string
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	// stats outlives entries so restart counts and health survive the
+	// subprocess being replaced.
+	stats map[string]*stats
+	// configs holds kernel-managed plugin configuration, re-pushed on
+	// every relaunch.
+	configs map[string]map[string]string
+}
+
+// NewManager returns a manager loading plugins from dir.
+func NewManager(dir string) *Manager {
+	return &Manager{
+		PluginDir: dir,
+		entries:   make(map[string]*entry),
+		stats:     make(map[string]*stats),
+		configs:   make(map[string]map[string]string),
+	}
+}
+
+// launch starts a client for the named plugin binary. Callers hold m.mu.
+func (m *Manager) launch(name string) *entry {
+	path := filepath.Join(m.PluginDir, name)
+	e := &entry{
+		client: goplugin.NewClient(&goplugin.ClientConfig{
+			HandshakeConfig: plugin.Handshake,
+			Plugins: map[string]goplugin.Plugin{
+				"storage":   &plugin.StoragePlugin{},
+				"publisher": &plugin.PublisherPlugin{},
+				"live":      &plugin.LivePlugin{},
+				"auth":      &plugin.AuthPlugin{},
+				"health":    &plugin.HealthPlugin{},
+				"info":      &plugin.InfoPlugin{},
+				"encoder":   &plugin.EncoderPlugin{},
+			},
+			Cmd: exec.Command(path),
+			// Go plugins serve net/rpc; non-Go plugins (Python, Node)
+			// advertise gRPC in their handshake instead.
+			AllowedProtocols: []goplugin.Protocol{
+				goplugin.ProtocolNetRPC,
+				goplugin.ProtocolGRPC,
+			},
+		}),
+	}
+	if info, err := os.Stat(path); err == nil {
+		e.modTime = info.ModTime()
+	}
+	return e
+}
+
+// dispense launches (or reuses) the named plugin binary and returns the
+// service registered under kind.
+func (m *Manager) dispense(name, kind string) (interface{}, error) {
+	m.mu.Lock()
+	e, ok := m.entries[name]
+	if !ok {
+		e = m.launch(name)
+		m.entries[name] = e
+	}
+	e.inflight.Add(1)
+	m.mu.Unlock()
+	defer e.inflight.Done()
+
+	rpcClient, err := e.client.Client()
+	if err != nil {
+		pluginRPCErrors.Inc(name)
+		return nil, fmt.Errorf("plugin %s: %w", name, err)
+	}
+	if kind != "info" {
+		e.negotiate.Do(func() { m.negotiateEntry(name, e) })
+		if e.infoErr != nil {
+			return nil, e.infoErr
+		}
+	}
+	_, span := trace.StartSpan(context.Background(), "plugin.dispense")
+	span.SetAttribute("plugin.name", name)
+	span.SetAttribute("plugin.kind", kind)
+	raw, err := rpcClient.Dispense(kind)
+	span.End(err)
+	if err != nil {
+		pluginRPCErrors.Inc(name)
+		return nil, fmt.Errorf("plugin %s: dispense %s: %w", name, kind, err)
+	}
+	return raw, nil
+}
+
+// Storage returns the named storage plugin.
+func (m *Manager) Storage(name string) (plugin.StorageService, error) {
+	raw, err := m.dispense(name, "storage")
+	if err != nil {
+		return nil, err
+	}
+	svc, ok := raw.(plugin.StorageService)
+	if !ok {
+		return nil, fmt.Errorf("plugin %s does not implement storage", name)
+	}
+	return svc, nil
+}
+
+// Publisher returns the named publisher plugin.
+func (m *Manager) Publisher(name string) (plugin.PublisherService, error) {
+	raw, err := m.dispense(name, "publisher")
+	if err != nil {
+		return nil, err
+	}
+	svc, ok := raw.(plugin.PublisherService)
+	if !ok {
+		return nil, fmt.Errorf("plugin %s does not implement publisher", name)
+	}
+	return svc, nil
+}
+
+// Live returns the named live plugin.
+func (m *Manager) Live(name string) (plugin.LiveService, error) {
+	raw, err := m.dispense(name, "live")
+	if err != nil {
+		return nil, err
+	}
+	svc, ok := raw.(plugin.LiveService)
+	if !ok {
+		return nil, fmt.Errorf("plugin %s does not implement live", name)
+	}
+	return svc, nil
+}
+
+// Auth returns the named auth plugin.
+func (m *Manager) Auth(name string) (plugin.AuthService, error) {
+	raw, err := m.dispense(name, "auth")
+	if err != nil {
+		return nil, err
+	}
+	svc, ok := raw.(plugin.AuthService)
+	if !ok {
+		return nil, fmt.Errorf("plugin %s does not implement auth", name)
+	}
+	return svc, nil
+}
+
+// Encoder returns the named encoder plugin.
+func (m *Manager) Encoder(name string) (plugin.EncoderService, error) {
+	raw, err := m.dispense(name, "encoder")
+	if err != nil {
+		return nil, err
+	}
+	svc, ok := raw.(plugin.EncoderService)
+	if !ok {
+		return nil, fmt.Errorf("plugin %s does not implement encoder", name)
+	}
+	return svc, nil
+}
+
+// Load eagerly launches the named plugin so its first RPC does not pay
+// the subprocess startup cost.
+func (m *Manager) Load(name string) error {
+	if _, err := os.Stat(filepath.Join(m.PluginDir, name)); err != nil {
+		return fmt.Errorf("plugin %s: %w", name, err)
+	}
+	m.mu.Lock()
+	e, ok := m.entries[name]
+	if !ok {
+		e = m.launch(name)
+		m.entries[name] = e
+	}
+	m.mu.Unlock()
+	if _, err := e.client.Client(); err != nil {
+		return fmt.Errorf("plugin %s: %w", name, err)
+	}
+	e.negotiate.Do(func() { m.negotiateEntry(name, e) })
+	if e.infoErr != nil {
+		return e.infoErr
+	}
+	if !ok {
+		if err := m.pushConfig(name); err != nil {
+			log.Printf("pluginmgr: push config to %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// Unload drains in-flight dispense calls against the named plugin and
+// kills its subprocess. The next dispense relaunches it.
+func (m *Manager) Unload(name string) error {
+	m.mu.Lock()
+	e, ok := m.entries[name]
+	delete(m.entries, name)
+	m.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("plugin %s is not running", name)
+	}
+	e.inflight.Wait()
+	e.client.Kill()
+	return nil
+}
+
+// Reload restarts the named plugin against the current binary: the old
+// subprocess is drained and killed, and the new one launches
+// immediately. Services dispensed from the old subprocess fail on their
+// next RPC; callers re-dispense per request.
+func (m *Manager) Reload(name string) error {
+	if _, err := os.Stat(filepath.Join(m.PluginDir, name)); err != nil {
+		return fmt.Errorf("plugin %s: %w", name, err)
+	}
+	m.mu.Lock()
+	old := m.entries[name]
+	delete(m.entries, name)
+	m.mu.Unlock()
+	if old != nil {
+		old.inflight.Wait()
+		old.client.Kill()
+	}
+	return m.Load(name)
+}
+
+// Watch polls the plugin dir and reloads any running plugin whose
+// binary has been replaced since launch, so redeploys take effect
+// without a kernel restart.
+func (m *Manager) Watch(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		m.mu.Lock()
+		var stale []string
+		for name, e := range m.entries {
+			info, err := os.Stat(filepath.Join(m.PluginDir, name))
+			if err != nil || info.ModTime().Equal(e.modTime) {
+				continue
+			}
+			stale = append(stale, name)
+		}
+		m.mu.Unlock()
+		for _, name := range stale {
+			log.Printf("pluginmgr: binary for %s changed, reloading", name)
+			if err := m.Reload(name); err != nil {
+				log.Printf("pluginmgr: reload %s: %v", name, err)
+			}
+		}
+	}
+}
+
+// Shutdown kills all plugin subprocesses.
+func (m *Manager) Shutdown() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for name, e := range m.entries {
+		e.client.Kill()
+		delete(m.entries, name)
+	}
+}
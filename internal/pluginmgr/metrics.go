@@ -0,0 +1,15 @@
+package pluginmgr
+
+import "github.com/rennerdo30/webencode/internal/metrics"
+
+// Plugin instrumentation; the "plugin" label is the binary name, which
+// operators install deliberately, so cardinality stays bounded.
+var (
+	pluginRestarts = metrics.NewCounter("webencode_plugin_restarts_total",
+		"Supervisor-initiated plugin relaunches after a crash.", "plugin")
+	pluginRPCErrors = metrics.NewCounter("webencode_plugin_rpc_errors_total",
+		"Failed plugin dispense/RPC setups.", "plugin")
+	pluginPingLatency = metrics.NewHistogram("webencode_plugin_ping_seconds",
+		"Plugin health-check round-trip time.",
+		[]float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5}, "plugin")
+)
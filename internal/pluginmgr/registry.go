@@ -0,0 +1,147 @@
+package pluginmgr
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// RegistryPlugin is one entry in a plugin registry index.
+type RegistryPlugin struct {
+	Name        string             `json:"name"`
+	Version     string             `json:"version"`
+	Description string             `json:"description,omitempty"`
+	Artifacts   []RegistryArtifact `json:"artifacts"`
+}
+
+// RegistryArtifact is one downloadable binary for a plugin.
+type RegistryArtifact struct {
+	OS   string `json:"os"`
+	Arch string `json:"arch"`
+	URL  string `json:"url"`
+	// SHA256 is the hex digest of the binary; verified on install.
+	SHA256 string `json:"sha256"`
+	// Signature is the hex ed25519 signature over the binary; required
+	// when the installer has a public key configured.
+	Signature string `json:"signature,omitempty"`
+}
+
+// registryIndex is the JSON document a registry serves.
+type registryIndex struct {
+	Plugins []RegistryPlugin `json:"plugins"`
+}
+
+// Installer fetches plugins from a remote registry index into a plugin
+// dir, verifying checksums and (when a key is configured) signatures.
+type Installer struct {
+	// IndexURL points at the registry's index JSON.
+	IndexURL string
+	// PluginDir is where verified binaries are installed.
+	PluginDir string
+	// PublicKey, when set, makes a valid ed25519 signature mandatory
+	// for every installed binary.
+	PublicKey ed25519.PublicKey
+	// Client defaults to a 30s-timeout HTTP client.
+	Client *http.Client
+}
+
+func (in *Installer) client() *http.Client {
+	if in.Client != nil {
+		return in.Client
+	}
+	return &http.Client{Timeout: 30 * time.Second}
+}
+
+// Available fetches and parses the registry index.
+func (in *Installer) Available() ([]RegistryPlugin, error) {
+	resp, err := in.client().Get(in.IndexURL)
+	if err != nil {
+		return nil, fmt.Errorf("fetch registry index: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch registry index: %s", resp.Status)
+	}
+	var idx registryIndex
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 10<<20)).Decode(&idx); err != nil {
+		return nil, fmt.Errorf("parse registry index: %w", err)
+	}
+	return idx.Plugins, nil
+}
+
+// Install downloads the named plugin's binary for this OS/arch,
+// verifies it and moves it into the plugin dir.
+func (in *Installer) Install(name string) error {
+	plugins, err := in.Available()
+	if err != nil {
+		return err
+	}
+	var entry *RegistryPlugin
+	for i := range plugins {
+		if plugins[i].Name == name {
+			entry = &plugins[i]
+			break
+		}
+	}
+	if entry == nil {
+		return fmt.Errorf("plugin %s not found in registry", name)
+	}
+	var artifact *RegistryArtifact
+	for i := range entry.Artifacts {
+		if entry.Artifacts[i].OS == runtime.GOOS && entry.Artifacts[i].Arch == runtime.GOARCH {
+			artifact = &entry.Artifacts[i]
+			break
+		}
+	}
+	if artifact == nil {
+		return fmt.Errorf("plugin %s has no artifact for %s/%s", name, runtime.GOOS, runtime.GOARCH)
+	}
+
+	resp, err := in.client().Get(artifact.URL)
+	if err != nil {
+		return fmt.Errorf("download %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download %s: %s", name, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 512<<20))
+	if err != nil {
+		return fmt.Errorf("download %s: %w", name, err)
+	}
+
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != artifact.SHA256 {
+		return fmt.Errorf("plugin %s: checksum mismatch (index %s, downloaded %s)", name, artifact.SHA256, got)
+	}
+	if len(in.PublicKey) > 0 {
+		sig, err := hex.DecodeString(artifact.Signature)
+		if err != nil {
+			return fmt.Errorf("plugin %s: malformed signature: %w", name, err)
+		}
+		if !ed25519.Verify(in.PublicKey, data, sig) {
+			return fmt.Errorf("plugin %s: signature verification failed", name)
+		}
+	}
+
+	// Write next to the final path and rename so a crash mid-install
+	// never leaves a half-written executable.
+	dest := filepath.Join(in.PluginDir, name)
+	tmp := dest + ".partial"
+	if err := os.WriteFile(tmp, data, 0o755); err != nil {
+		return fmt.Errorf("install %s: %w", name, err)
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("install %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package pluginmgr
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// Status describes one plugin's deployment state: a configured name
+// whose binary is gone, or a binary nothing references.
+type Status struct {
+	Name string `json:"name"`
+	// Kind is the service kind from the binary naming convention
+	// (<kind>-<impl>).
+	Kind string `json:"kind"`
+	// State is "ok" (configured and present), "missing" (configured but
+	// no binary in the plugin dir), or "unused" (binary present but not
+	// configured). Publishers are resolved per request, so present
+	// publisher binaries count as ok.
+	State string `json:"state"`
+	// Running reports whether the subprocess has been launched this
+	// kernel run.
+	Running bool `json:"running"`
+	// Health is the supervisor's last verdict ("healthy", "unhealthy",
+	// "crashed", "restarting"); empty until the plugin has been checked.
+	Health string `json:"health,omitempty"`
+	// Restarts counts supervisor-initiated relaunches after crashes.
+	Restarts int `json:"restarts,omitempty"`
+	// LastError is the most recent health or restart failure.
+	LastError string `json:"last_error,omitempty"`
+	// Version is the semver the plugin reported during negotiation.
+	Version string `json:"version,omitempty"`
+}
+
+// Scan compares the plugin dir against the configured plugin names and
+// reports every plugin's state.
+func (m *Manager) Scan(configured []string) []Status {
+	present := make(map[string]bool)
+	if entries, err := os.ReadDir(m.PluginDir); err == nil {
+		for _, e := range entries {
+			if e.IsDir() {
+				continue
+			}
+			present[e.Name()] = true
+		}
+	}
+	m.mu.Lock()
+	running := make(map[string]bool, len(m.entries))
+	for name := range m.entries {
+		running[name] = true
+	}
+	health := make(map[string]stats, len(m.stats))
+	for name, st := range m.stats {
+		health[name] = *st
+	}
+	m.mu.Unlock()
+
+	status := func(name, state string) Status {
+		st := health[name]
+		return Status{
+			Name:      name,
+			Kind:      pluginKind(name),
+			State:     state,
+			Running:   running[name],
+			Health:    st.Health,
+			Restarts:  st.Restarts,
+			LastError: st.LastError,
+			Version:   st.Version,
+		}
+	}
+
+	wanted := make(map[string]bool, len(configured))
+	var out []Status
+	for _, name := range configured {
+		if name == "" {
+			continue
+		}
+		wanted[name] = true
+		state := "ok"
+		if !present[name] {
+			state = "missing"
+		}
+		out = append(out, status(name, state))
+	}
+	for name := range present {
+		if wanted[name] {
+			continue
+		}
+		state := "unused"
+		if pluginKind(name) == "publisher" {
+			state = "ok"
+		}
+		out = append(out, status(name, state))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// pluginKind extracts the service kind from the <kind>-<impl> binary
+// naming convention.
+func pluginKind(name string) string {
+	kind, _, found := strings.Cut(name, "-")
+	if !found {
+		return ""
+	}
+	return kind
+}
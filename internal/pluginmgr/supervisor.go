@@ -0,0 +1,148 @@
+package pluginmgr
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rennerdo30/webencode/pkg/plugin"
+)
+
+// stats is supervisor state for one plugin name. It outlives individual
+// subprocesses so restart counts accumulate across crashes.
+type stats struct {
+	// Restarts counts supervisor-initiated relaunches after a crash.
+	Restarts int
+	// Health is "healthy", "unhealthy", "crashed" or "restarting";
+	// empty until the supervisor has checked the plugin.
+	Health string
+	// LastError is the most recent health or restart failure.
+	LastError string
+	// Version is the semver the plugin reported during negotiation.
+	Version string
+
+	// failures drives the exponential restart backoff; reset on a
+	// healthy check.
+	failures int
+	// notBefore delays the next restart attempt.
+	notBefore time.Time
+}
+
+// stat returns (creating if needed) the stats for name. Callers hold
+// m.mu.
+func (m *Manager) stat(name string) *stats {
+	st, ok := m.stats[name]
+	if !ok {
+		st = &stats{}
+		m.stats[name] = st
+	}
+	return st
+}
+
+// Supervise pings every running plugin on interval and restarts crashed
+// subprocesses with exponential backoff (1s doubling to a 64s cap).
+func (m *Manager) Supervise(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		m.mu.Lock()
+		names := make([]string, 0, len(m.entries))
+		for name := range m.entries {
+			names = append(names, name)
+		}
+		m.mu.Unlock()
+		for _, name := range names {
+			m.checkPlugin(name)
+		}
+	}
+}
+
+// checkPlugin health-checks one plugin and restarts it if the
+// subprocess has died.
+func (m *Manager) checkPlugin(name string) {
+	m.mu.Lock()
+	e, ok := m.entries[name]
+	st := m.stat(name)
+	m.mu.Unlock()
+	if !ok {
+		return
+	}
+	if e.client.Exited() {
+		m.restartPlugin(name, e, st)
+		return
+	}
+	start := time.Now()
+	err := pingPlugin(e)
+	pluginPingLatency.Observe(time.Since(start).Seconds(), name)
+	m.mu.Lock()
+	if err != nil {
+		st.Health = "unhealthy"
+		st.LastError = err.Error()
+	} else {
+		st.Health = "healthy"
+		st.LastError = ""
+		st.failures = 0
+	}
+	m.mu.Unlock()
+}
+
+// pingPlugin runs the plugin's HealthCheck RPC, falling back to a
+// protocol ping for binaries built against an SDK without it.
+func pingPlugin(e *entry) error {
+	rpcClient, err := e.client.Client()
+	if err != nil {
+		return err
+	}
+	raw, err := rpcClient.Dispense("health")
+	if err != nil {
+		return rpcClient.Ping()
+	}
+	hc, ok := raw.(plugin.HealthChecker)
+	if !ok {
+		return rpcClient.Ping()
+	}
+	return hc.HealthCheck()
+}
+
+// restartPlugin relaunches a crashed plugin unless it is still inside
+// its backoff window.
+func (m *Manager) restartPlugin(name string, e *entry, st *stats) {
+	m.mu.Lock()
+	if time.Now().Before(st.notBefore) {
+		st.Health = "crashed"
+		m.mu.Unlock()
+		return
+	}
+	delay := time.Second << uint(min(st.failures, 6))
+	st.failures++
+	st.Restarts++
+	pluginRestarts.Inc(name)
+	st.notBefore = time.Now().Add(delay)
+	st.Health = "restarting"
+	if m.entries[name] == e {
+		delete(m.entries, name)
+	}
+	m.mu.Unlock()
+
+	e.client.Kill()
+	if _, err := os.Stat(filepath.Join(m.PluginDir, name)); err != nil {
+		m.mu.Lock()
+		st.LastError = err.Error()
+		m.mu.Unlock()
+		log.Printf("pluginmgr: cannot restart %s: %v", name, err)
+		return
+	}
+	log.Printf("pluginmgr: plugin %s exited, restarting (attempt %d, next backoff %s)", name, st.Restarts, delay)
+	if err := m.Load(name); err != nil {
+		m.mu.Lock()
+		st.LastError = err.Error()
+		m.mu.Unlock()
+		log.Printf("pluginmgr: restart %s: %v", name, err)
+	}
+}
@@ -0,0 +1,12 @@
+// Package proc adjusts OS scheduling of media processes: live relays
+// run above batch encodes, and batch encodes can be paused outright
+// when live output is at risk.
+package proc
+
+// Niceness levels for the two process classes. Relays get soft
+// real-time treatment; batch encodes yield to everything else on the
+// node.
+const (
+	RelayNice = -5
+	BatchNice = 10
+)
@@ -0,0 +1,21 @@
+//go:build linux
+
+package proc
+
+import "syscall"
+
+// SetNice adjusts a process's scheduling niceness. Lowering below 0
+// needs privileges; callers treat failure as advisory.
+func SetNice(pid, nice int) error {
+	return syscall.Setpriority(syscall.PRIO_PROCESS, pid, nice)
+}
+
+// Pause stops a process (SIGSTOP) until Resume.
+func Pause(pid int) error {
+	return syscall.Kill(pid, syscall.SIGSTOP)
+}
+
+// Resume continues a paused process (SIGCONT).
+func Resume(pid int) error {
+	return syscall.Kill(pid, syscall.SIGCONT)
+}
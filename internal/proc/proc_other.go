@@ -0,0 +1,12 @@
+//go:build !linux
+
+package proc
+
+// SetNice is a no-op on platforms without priority control.
+func SetNice(pid, nice int) error { return nil }
+
+// Pause is a no-op on platforms without job control signals.
+func Pause(pid int) error { return nil }
+
+// Resume is a no-op on platforms without job control signals.
+func Resume(pid int) error { return nil }
@@ -0,0 +1,260 @@
+// Package trace is a minimal distributed tracer: it records spans with
+// W3C trace-context propagation and ships them to an OTLP/HTTP
+// collector as JSON, without pulling in the OpenTelemetry SDK. Tracing
+// is a no-op until Init is called with a collector endpoint.
+package trace
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// exporter is the process-wide trace sink; nil disables tracing.
+var exporter *otlpExporter
+
+// Init points the tracer at an OTLP/HTTP collector (e.g.
+// "http://otel-collector:4318") and names this process's service. An
+// empty endpoint leaves tracing disabled.
+func Init(endpoint, service string) {
+	if endpoint == "" {
+		return
+	}
+	exporter = &otlpExporter{
+		url:     strings.TrimSuffix(endpoint, "/") + "/v1/traces",
+		service: service,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+	go exporter.run()
+}
+
+// Span is one traced operation. All methods are nil-safe so call sites
+// need no enabled checks.
+type Span struct {
+	traceID  string
+	spanID   string
+	parentID string
+	name     string
+	start    time.Time
+	attrs    []attribute
+	mu       sync.Mutex
+}
+
+type attribute struct{ key, value string }
+
+type spanContextKey struct{}
+
+// spanContext is what propagates: the IDs, not the live span.
+type spanContext struct{ traceID, spanID string }
+
+// StartSpan begins a span under the parent found in ctx (a fresh trace
+// when there is none) and returns a context carrying it for children.
+func StartSpan(ctx context.Context, name string) (context.Context, *Span) {
+	if exporter == nil {
+		return ctx, nil
+	}
+	s := &Span{name: name, spanID: randomHex(8), start: time.Now()}
+	if parent, ok := ctx.Value(spanContextKey{}).(spanContext); ok {
+		s.traceID = parent.traceID
+		s.parentID = parent.spanID
+	} else {
+		s.traceID = randomHex(16)
+	}
+	return context.WithValue(ctx, spanContextKey{}, spanContext{s.traceID, s.spanID}), s
+}
+
+// SetAttribute attaches a key/value to the span.
+func (s *Span) SetAttribute(key, value string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.attrs = append(s.attrs, attribute{key, value})
+	s.mu.Unlock()
+}
+
+// End finishes the span; a non-nil err marks it failed and records the
+// message.
+func (s *Span) End(err error) {
+	if s == nil || exporter == nil {
+		return
+	}
+	exporter.enqueue(finishedSpan{
+		Span: s,
+		end:  time.Now(),
+		err:  err,
+	})
+}
+
+// TraceParent renders the span context in ctx as a W3C traceparent
+// value, empty when there is none.
+func TraceParent(ctx context.Context) string {
+	sc, ok := ctx.Value(spanContextKey{}).(spanContext)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("00-%s-%s-01", sc.traceID, sc.spanID)
+}
+
+// ContextWithRemote attaches a received traceparent value to ctx so
+// spans started from it join the caller's trace. Malformed values are
+// ignored.
+func ContextWithRemote(ctx context.Context, traceparent string) context.Context {
+	parts := strings.Split(traceparent, "-")
+	if len(parts) != 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return ctx
+	}
+	return context.WithValue(ctx, spanContextKey{}, spanContext{parts[1], parts[2]})
+}
+
+// Middleware traces HTTP requests, honouring an incoming traceparent
+// header.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if exporter == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx := ContextWithRemote(r.Context(), r.Header.Get("traceparent"))
+		ctx, span := StartSpan(ctx, "HTTP "+r.Method+" "+r.URL.Path)
+		span.SetAttribute("http.method", r.Method)
+		span.SetAttribute("http.target", r.URL.Path)
+		next.ServeHTTP(w, r.WithContext(ctx))
+		span.End(nil)
+	})
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// finishedSpan is a span plus its outcome, queued for export.
+type finishedSpan struct {
+	*Span
+	end time.Time
+	err error
+}
+
+// otlpExporter batches finished spans and posts them to the collector.
+type otlpExporter struct {
+	url     string
+	service string
+	client  *http.Client
+
+	mu  sync.Mutex
+	buf []finishedSpan
+}
+
+// flushInterval and flushBatch bound how long and how many spans wait
+// before export.
+const (
+	flushInterval = 5 * time.Second
+	flushBatch    = 512
+)
+
+func (e *otlpExporter) enqueue(s finishedSpan) {
+	e.mu.Lock()
+	e.buf = append(e.buf, s)
+	full := len(e.buf) >= flushBatch
+	e.mu.Unlock()
+	if full {
+		e.flush()
+	}
+}
+
+func (e *otlpExporter) run() {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		e.flush()
+	}
+}
+
+func (e *otlpExporter) flush() {
+	e.mu.Lock()
+	batch := e.buf
+	e.buf = nil
+	e.mu.Unlock()
+	if len(batch) == 0 {
+		return
+	}
+	body, err := json.Marshal(e.payload(batch))
+	if err != nil {
+		return
+	}
+	resp, err := e.client.Post(e.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Printf("trace: export %d spans: %v", len(batch), err)
+		return
+	}
+	resp.Body.Close()
+}
+
+// OTLP/HTTP JSON wire structures, limited to what we emit.
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+func (e *otlpExporter) payload(batch []finishedSpan) map[string]interface{} {
+	spans := make([]otlpSpan, 0, len(batch))
+	for _, s := range batch {
+		out := otlpSpan{
+			TraceID:           s.traceID,
+			SpanID:            s.spanID,
+			ParentSpanID:      s.parentID,
+			Name:              s.name,
+			StartTimeUnixNano: fmt.Sprintf("%d", s.start.UnixNano()),
+			EndTimeUnixNano:   fmt.Sprintf("%d", s.end.UnixNano()),
+			Status:            otlpStatus{Code: 1},
+		}
+		for _, a := range s.attrs {
+			out.Attributes = append(out.Attributes, otlpAttribute{a.key, otlpValue{a.value}})
+		}
+		if s.err != nil {
+			out.Status = otlpStatus{Code: 2, Message: s.err.Error()}
+		}
+		spans = append(spans, out)
+	}
+	return map[string]interface{}{
+		"resourceSpans": []interface{}{map[string]interface{}{
+			"resource": map[string]interface{}{
+				"attributes": []otlpAttribute{{"service.name", otlpValue{e.service}}},
+			},
+			"scopeSpans": []interface{}{map[string]interface{}{
+				"scope": map[string]string{"name": "webencode"},
+				"spans": spans,
+			}},
+		}},
+	}
+}
@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/rennerdo30/webencode/internal/encode"
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// benchmarkEncoders are the standardized encoders a benchmark task
+// scores, in scoring order. Encoders the node's ffmpeg build lacks are
+// skipped.
+var benchmarkEncoders = []string{"libx264", "libx265", "h264_nvenc"}
+
+// benchmarkFrames is how many 720p30 frames each encoder renders; the
+// score is encoded frames per wall-clock second. The source is
+// ffmpeg's synthetic testsrc2, so every node encodes the exact same
+// input without a bundled sample file.
+const benchmarkFrames = 300
+
+// benchmark runs the standardized encode benchmarks and stores the
+// scores for the completion event to pick up.
+func (w *Worker) benchmark(ctx context.Context, task types.Task) error {
+	available := encode.DetectEncoders(benchmarkEncoders...)
+	scores := make(map[string]float64)
+	for _, encoder := range benchmarkEncoders {
+		if !available[encoder] {
+			continue
+		}
+		fps, err := w.benchmarkEncoder(ctx, task, encoder)
+		if err != nil {
+			w.publishTaskLog(task, "warn", fmt.Sprintf("benchmark %s: %v", encoder, err))
+			continue
+		}
+		scores[encoder] = fps
+		w.publishTaskLog(task, "info", fmt.Sprintf("benchmark %s: %.1f fps", encoder, fps))
+	}
+	if len(scores) == 0 {
+		return errors.New("no benchmark encoder available")
+	}
+	w.mu.Lock()
+	w.benchScores[task.ID] = scores
+	w.mu.Unlock()
+	return nil
+}
+
+// benchmarkEncoder scores one encoder: encoded frames per second over
+// a fixed synthetic clip at fixed settings.
+func (w *Worker) benchmarkEncoder(ctx context.Context, task types.Task, encoder string) (float64, error) {
+	args := []string{
+		"-hide_banner", "-y",
+		"-f", "lavfi", "-i", "testsrc2=size=1280x720:rate=30",
+		"-frames:v", fmt.Sprint(benchmarkFrames),
+		"-c:v", encoder, "-preset", "medium",
+		"-f", "null", "-",
+	}
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+	w.trackProcess(task.ID, cmd.Process)
+	defer w.trackProcess(task.ID, nil)
+	if err := cmd.Wait(); err != nil {
+		return 0, err
+	}
+	elapsed := time.Since(start).Seconds()
+	if elapsed <= 0 {
+		return 0, errors.New("benchmark finished implausibly fast")
+	}
+	return benchmarkFrames / elapsed, nil
+}
+
+// takeBenchScores removes and returns the stored scores of a finished
+// benchmark task, nil for other tasks.
+func (w *Worker) takeBenchScores(taskID string) map[string]float64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	scores := w.benchScores[taskID]
+	delete(w.benchScores, taskID)
+	return scores
+}
@@ -0,0 +1,325 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/rennerdo30/webencode/internal/encode"
+	"github.com/rennerdo30/webencode/internal/trace"
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// checkpoint is the persisted resume state of a chunked encode. It
+// lives outside the per-attempt workdir so a retry on the same node
+// picks up where the last attempt stopped.
+type checkpoint struct {
+	// PositionSec is how much of the source is already encoded.
+	PositionSec float64 `json:"position_sec"`
+	// Parts are the finished chunk files, in order.
+	Parts []string `json:"parts"`
+	// PartSums are the SHA-256 digests of Parts, index-aligned, so a
+	// restore from shared storage can verify what it downloaded.
+	PartSums []string `json:"part_sums,omitempty"`
+}
+
+// checkpointDir is where a task's chunks and resume state live.
+func checkpointDir(taskID string) string {
+	return filepath.Join(os.TempDir(), "webencode-checkpoints", taskID)
+}
+
+// loadCheckpoint reads a task's resume state; a zero checkpoint means
+// start from the beginning.
+func loadCheckpoint(taskID string) checkpoint {
+	var cp checkpoint
+	data, err := os.ReadFile(filepath.Join(checkpointDir(taskID), "checkpoint.json"))
+	if err != nil || json.Unmarshal(data, &cp) != nil {
+		return checkpoint{}
+	}
+	return cp
+}
+
+// saveCheckpoint persists resume state after each finished chunk.
+func saveCheckpoint(taskID string, cp checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(checkpointDir(taskID), "checkpoint.json")
+	if err := os.WriteFile(path+".partial", data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(path+".partial", path)
+}
+
+// checkpointKey is the storage prefix a task's offloaded resume state
+// lives under.
+func checkpointKey(taskID string) string {
+	return "checkpoints/" + taskID
+}
+
+// offloadCheckpoint pushes a task's finished chunks and resume state to
+// shared storage so another node can pick the encode up where this one
+// stopped — the seam that lets work migrate off preempted spot nodes.
+// Best effort: without storage or a checkpoint there is nothing to do.
+func (w *Worker) offloadCheckpoint(task types.Task) {
+	cp := loadCheckpoint(task.ID)
+	if cp.PositionSec == 0 {
+		return
+	}
+	storage, err := w.storageFor(task.StorageBackend)
+	if err != nil {
+		return
+	}
+	prefix := checkpointKey(task.ID)
+	for _, part := range cp.Parts {
+		if err := storage.Upload(part, prefix+"/"+filepath.Base(part)); err != nil {
+			log.Printf("worker: offload checkpoint part for task %s: %v", task.ID, err)
+			return
+		}
+	}
+	if err := storage.Upload(filepath.Join(checkpointDir(task.ID), "checkpoint.json"), prefix+"/checkpoint.json"); err != nil {
+		log.Printf("worker: offload checkpoint for task %s: %v", task.ID, err)
+		return
+	}
+	w.publishTaskLog(task, "info", fmt.Sprintf("checkpoint at %.0fs offloaded for migration", cp.PositionSec))
+}
+
+// restoreCheckpoint pulls offloaded resume state down from storage when
+// the local disk has none, so a task migrated off another node resumes
+// here instead of restarting.
+func (w *Worker) restoreCheckpoint(task types.Task) {
+	if cp := loadCheckpoint(task.ID); cp.PositionSec > 0 {
+		return
+	}
+	storage, err := w.storageFor(task.StorageBackend)
+	if err != nil {
+		return
+	}
+	prefix := checkpointKey(task.ID)
+	cpdir := checkpointDir(task.ID)
+	local := filepath.Join(cpdir, "checkpoint.json")
+	if err := storage.Download(prefix+"/checkpoint.json", local); err != nil {
+		return
+	}
+	cp := loadCheckpoint(task.ID)
+	for i, part := range cp.Parts {
+		dest := filepath.Join(cpdir, filepath.Base(part))
+		if err := storage.Download(prefix+"/"+filepath.Base(part), dest); err != nil {
+			log.Printf("worker: restore checkpoint part for task %s: %v", task.ID, err)
+			// Half a checkpoint restarts from the beginning.
+			_ = os.Remove(local)
+			return
+		}
+		if i < len(cp.PartSums) {
+			sum, sumErr := fileSHA256(dest)
+			if sumErr != nil || sum != cp.PartSums[i] {
+				log.Printf("worker: restored checkpoint part %s for task %s failed its checksum", filepath.Base(part), task.ID)
+				// A corrupted checkpoint restarts from the beginning.
+				_ = os.Remove(local)
+				return
+			}
+		}
+		cp.Parts[i] = dest
+	}
+	// The parts now live under this node's checkpoint dir.
+	_ = saveCheckpoint(task.ID, cp)
+	w.publishTaskLog(task, "info", fmt.Sprintf("resuming migrated encode at %.0fs from offloaded checkpoint", cp.PositionSec))
+}
+
+// dropRemoteCheckpoint deletes offloaded resume state once the encode
+// made it to storage.
+func (w *Worker) dropRemoteCheckpoint(task types.Task) {
+	storage, err := w.storageFor(task.StorageBackend)
+	if err != nil {
+		return
+	}
+	objects, err := storage.List(checkpointKey(task.ID))
+	if err != nil {
+		return
+	}
+	for _, o := range objects {
+		_ = storage.Delete(o.Key)
+	}
+}
+
+// checkpointable reports whether a task can be encoded in resumable
+// chunks. Segmented outputs, encryption, loudness normalization and
+// edit pre-processing all need the whole source in one pass.
+func checkpointable(task types.Task) bool {
+	p := task.Profile
+	if p == nil || p.AudioOnly || task.Edit != nil {
+		return false
+	}
+	switch p.Container.Format {
+	case "", "mp4", "mkv", "matroska":
+	default:
+		return false
+	}
+	return p.Container.Encryption == nil && p.LoudnessTargetLUFS == 0
+}
+
+// chunkedTranscode encodes the source in fixed chunks, checkpointing
+// after each one, so a retried multi-hour encode resumes instead of
+// restarting. Chunks are encoded as MPEG-TS parts and stream-copied
+// into the final container at the end.
+func (w *Worker) chunkedTranscode(ctx context.Context, task types.Task) ([]types.Artifact, error) {
+	chunkSec := w.cfg.CheckpointChunk.Seconds()
+	cpdir := checkpointDir(task.ID)
+	if err := os.MkdirAll(cpdir, 0o755); err != nil {
+		return nil, err
+	}
+	w.restoreCheckpoint(task)
+
+	total, err := w.probeDuration(task.SourceURL)
+	if err != nil {
+		return nil, fmt.Errorf("probe duration: %w", err)
+	}
+	start := task.SeekSec
+	total -= start
+	if task.DurationSec > 0 && task.DurationSec < total {
+		total = task.DurationSec
+	}
+
+	// Chunks carry the profile's codec settings in an MPEG-TS wrapper
+	// so finished parts concatenate cleanly.
+	partProfile := *task.Profile
+	partProfile.Container = types.ContainerOptions{Format: "mpegts"}
+
+	cp := loadCheckpoint(task.ID)
+	if cp.PositionSec > 0 {
+		w.publishTaskLog(task, "info", fmt.Sprintf("resuming encode at %.0fs from checkpoint", cp.PositionSec))
+	}
+	for cp.PositionSec < total {
+		length := chunkSec
+		if remaining := total - cp.PositionSec; remaining < length {
+			length = remaining
+		}
+		part := filepath.Join(cpdir, fmt.Sprintf("part_%06d.ts", len(cp.Parts)))
+		args := []string{
+			"-hide_banner", "-loglevel", "error", "-y",
+			"-ss", fmt.Sprintf("%g", start+cp.PositionSec),
+			"-i", task.SourceURL,
+			"-t", fmt.Sprintf("%g", length),
+		}
+		args = append(args, encode.OutputArgs(&partProfile, part)...)
+		_, span := trace.StartSpan(ctx, "encode.chunk")
+		err := w.runEncode(task, args)
+		span.End(err)
+		if err != nil {
+			return nil, err
+		}
+		cp.PositionSec += length
+		cp.Parts = append(cp.Parts, part)
+		sum, err := fileSHA256(part)
+		if err != nil {
+			return nil, err
+		}
+		cp.PartSums = append(cp.PartSums, sum)
+		if err := saveCheckpoint(task.ID, cp); err != nil {
+			return nil, fmt.Errorf("save checkpoint: %w", err)
+		}
+	}
+
+	workdir := filepath.Join(os.TempDir(), "webencode", task.ID)
+	if err := os.MkdirAll(workdir, 0o755); err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(workdir)
+	if err := w.concatParts(cp.Parts, workdir, task.Profile); err != nil {
+		return nil, err
+	}
+	_, storeSpan := trace.StartSpan(ctx, "store.outputs")
+	artifacts, err := w.storeOutputs(task, workdir)
+	storeSpan.End(err)
+	if err != nil {
+		return nil, err
+	}
+	// The encode made it to storage; the checkpoint — local and any
+	// offloaded copy — has served its purpose.
+	w.dropRemoteCheckpoint(task)
+	_ = os.RemoveAll(cpdir)
+	return artifacts, nil
+}
+
+// runEncode runs one ffmpeg invocation for a chunk, wiring stderr into
+// the task log like the single-pass path.
+func (w *Worker) runEncode(task types.Task, args []string) error {
+	cmd := w.ffmpegCommand(task.Profile, remoteInput(task.SourceURL),
+		sandboxDirs(checkpointDir(task.ID), task.SourceURL), args)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	w.trackProcess(task.ID, cmd.Process)
+	defer w.trackProcess(task.ID, nil)
+	if err := cmd.Wait(); err != nil {
+		for _, line := range strings.Split(strings.TrimSpace(stderr.String()), "\n") {
+			if line != "" {
+				w.publishTaskLog(task, "error", line)
+			}
+		}
+		return fmt.Errorf("ffmpeg: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// concatParts stream-copies the finished chunks into the profile's
+// final container. It runs under the same sandbox as the encodes, with
+// the parts directory mounted alongside the workdir and no network.
+func (w *Worker) concatParts(parts []string, workdir string, p *types.Profile) error {
+	list := filepath.Join(workdir, "parts.txt")
+	var b strings.Builder
+	for _, part := range parts {
+		fmt.Fprintf(&b, "file '%s'\n", part)
+	}
+	if err := os.WriteFile(list, []byte(b.String()), 0o644); err != nil {
+		return err
+	}
+	output := filepath.Join(workdir, p.Name+encode.OutputExt(p))
+	args := []string{
+		"-hide_banner", "-loglevel", "error", "-y",
+		"-f", "concat", "-safe", "0",
+		"-i", list,
+		"-c", "copy",
+	}
+	args = append(args, output)
+	dirs := []string{workdir}
+	if len(parts) > 0 {
+		if d := filepath.Dir(parts[0]); d != workdir {
+			dirs = append(dirs, d)
+		}
+	}
+	cmd := w.ffmpegCommand(p, false, dirs, args)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("concat parts: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	// The parts list must not end up in storage next to the output.
+	return os.Remove(list)
+}
+
+// probeDuration asks ffprobe for the source duration in seconds. The
+// source is untrusted, so the probe runs under the same sandbox as the
+// encodes, with the network only for remote inputs.
+func (w *Worker) probeDuration(sourceURL string) (float64, error) {
+	cmd := w.ffprobeCommand(remoteInput(sourceURL), sandboxDirs("", sourceURL), []string{
+		"-v", "error",
+		"-show_entries", "format=duration",
+		"-of", "csv=p=0",
+		sourceURL,
+	})
+	out, err := cmd.Output()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(strings.TrimSpace(string(out)), 64)
+}
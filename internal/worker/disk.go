@@ -0,0 +1,95 @@
+package worker
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// staleWorkdirAge is how old an orphaned workdir or checkpoint must be
+// before startup housekeeping removes it. Checkpoints younger than this
+// may still be resumed by a retry.
+const staleWorkdirAge = 24 * time.Hour
+
+// workRoots are the temp directories the worker writes under; startup
+// housekeeping sweeps them for leftovers of crashed runs.
+func workRoots() []string {
+	return []string{
+		filepath.Join(os.TempDir(), "webencode"),
+		filepath.Join(os.TempDir(), "webencode-checkpoints"),
+	}
+}
+
+// cleanWorkdirs removes temp directories stranded by crashed runs.
+// Workdirs are removed unconditionally (a fresh worker has no in-flight
+// tasks); checkpoints are kept until they age out so retries can still
+// resume them.
+func cleanWorkdirs() {
+	for _, root := range workRoots() {
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			continue
+		}
+		keepYoung := filepath.Base(root) == "webencode-checkpoints"
+		for _, e := range entries {
+			path := filepath.Join(root, e.Name())
+			if keepYoung {
+				info, err := e.Info()
+				if err != nil || time.Since(info.ModTime()) < staleWorkdirAge {
+					continue
+				}
+			}
+			if err := os.RemoveAll(path); err != nil {
+				log.Printf("worker: clean stale workdir %s: %v", path, err)
+				continue
+			}
+			log.Printf("worker: removed stale workdir %s", path)
+		}
+	}
+}
+
+// dirSize sums the regular files directly under dir.
+func dirSize(dir string) int64 {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0
+	}
+	var total int64
+	for _, e := range entries {
+		if info, err := e.Info(); err == nil && !e.IsDir() {
+			total += info.Size()
+		}
+	}
+	return total
+}
+
+// estimateFootprint guesses how many bytes a task will write locally.
+// Zero means unknown; only the free-space floor applies then.
+func estimateFootprint(task types.Task) uint64 {
+	p := task.Profile
+	if p == nil || task.DurationSec <= 0 {
+		return 0
+	}
+	kbps := p.VideoBitrateKbps + p.AudioBitrateKbps
+	if kbps <= 0 {
+		return 0
+	}
+	// Bitrate times duration, plus a quarter of headroom for muxing
+	// overhead and CRF overshoot.
+	bytes := float64(kbps) * 1000 / 8 * task.DurationSec
+	return uint64(bytes * 1.25)
+}
+
+// hasSpace reports whether the node can take a task without running the
+// workdir filesystem below the configured floor. Unknown usage (other
+// platforms, statfs failure) never blocks.
+func (w *Worker) hasSpace(task types.Task) bool {
+	free, _, err := diskUsage(os.TempDir())
+	if err != nil || free == 0 {
+		return true
+	}
+	return free >= w.cfg.MinFreeBytes+estimateFootprint(task)
+}
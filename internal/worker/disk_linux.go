@@ -0,0 +1,16 @@
+//go:build linux
+
+package worker
+
+import "syscall"
+
+// diskUsage reports free and total bytes on the filesystem holding
+// path.
+func diskUsage(path string) (free, total uint64, err error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, 0, err
+	}
+	bsize := uint64(st.Bsize)
+	return st.Bavail * bsize, st.Blocks * bsize, nil
+}
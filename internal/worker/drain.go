@@ -0,0 +1,47 @@
+package worker
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/rennerdo30/webencode/internal/bus"
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// SubscribeDrain listens for kernel drain requests. A drained worker
+// stops accepting new tasks but finishes its in-flight work and keeps
+// heartbeating until the process is terminated — the hook scale-down
+// uses to empty a node before deleting its pod or VM.
+func (w *Worker) SubscribeDrain() error {
+	_, err := w.conn.Subscribe(bus.SubjectWorkerDrain, func(msg *nats.Msg) {
+		var d types.WorkerDrain
+		if err := json.Unmarshal(msg.Data, &d); err != nil {
+			log.Printf("worker: malformed drain request: %v", err)
+			return
+		}
+		if d.WorkerID != w.ID {
+			return
+		}
+		w.drain()
+	})
+	return err
+}
+
+// drain drops the task subscription and flags the node as draining in
+// its heartbeats; in-flight tasks run to completion.
+func (w *Worker) drain() {
+	w.mu.Lock()
+	already := w.drained
+	w.drained = true
+	w.mu.Unlock()
+	if already {
+		return
+	}
+	if w.taskSub != nil {
+		_ = w.taskSub.Unsubscribe()
+	}
+	log.Printf("worker: %s draining, no longer accepting tasks", w.ID)
+	w.heartbeat()
+}
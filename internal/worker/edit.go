@@ -0,0 +1,199 @@
+package worker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// prepareEdit builds the concatenated input for a task with an edit
+// spec: the EDL keep-ranges are cut out of the source with stream
+// copies, then pre-rolls + cuts + post-rolls are stitched together with
+// the concat demuxer. The assembled file becomes the transcode input.
+func (w *Worker) prepareEdit(task types.Task, workdir string) (string, error) {
+	edit := task.Edit
+	dir := filepath.Join(workdir, "edit")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	parts := append([]string{}, edit.PreRollURLs...)
+	if len(edit.Ranges) == 0 {
+		parts = append(parts, task.SourceURL)
+	} else {
+		for i, rg := range edit.Ranges {
+			cut := filepath.Join(dir, fmt.Sprintf("cut_%02d.mkv", i))
+			err := w.runFFmpeg(remoteInput(task.SourceURL), sandboxDirs(dir, task.SourceURL),
+				"-hide_banner", "-loglevel", "error", "-y",
+				"-ss", fmt.Sprintf("%g", rg.StartSec),
+				"-i", task.SourceURL,
+				"-t", fmt.Sprintf("%g", rg.EndSec-rg.StartSec),
+				"-c", "copy", cut,
+			)
+			if err != nil {
+				return "", fmt.Errorf("cut range %d: %w", i, err)
+			}
+			parts = append(parts, cut)
+		}
+	}
+	parts = append(parts, edit.PostRollURLs...)
+
+	if err := w.checkConcatCompat(parts); err != nil {
+		return "", err
+	}
+
+	list := filepath.Join(dir, "concat.txt")
+	var lines strings.Builder
+	for _, p := range parts {
+		// The concat demuxer's quoting: single quotes, with embedded
+		// quotes closed, escaped and reopened.
+		fmt.Fprintf(&lines, "file '%s'\n", strings.ReplaceAll(p, "'", `'\''`))
+	}
+	if err := os.WriteFile(list, []byte(lines.String()), 0o644); err != nil {
+		return "", err
+	}
+
+	assembled := filepath.Join(dir, "assembled.mkv")
+	err := w.runFFmpeg(anyRemote(parts), concatDirs(dir, parts),
+		"-hide_banner", "-loglevel", "error", "-y",
+		"-f", "concat", "-safe", "0",
+		"-protocol_whitelist", "file,http,https,tcp,tls",
+		"-i", list,
+		"-c", "copy", assembled,
+	)
+	if err != nil {
+		return "", fmt.Errorf("concat: %w", err)
+	}
+	return assembled, nil
+}
+
+// anyRemote reports whether any concat input has to be fetched over the
+// network.
+func anyRemote(parts []string) bool {
+	for _, p := range parts {
+		if remoteInput(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// concatDirs lists the host directories the concat pass touches: the
+// edit workdir plus the directory of every local input.
+func concatDirs(dir string, parts []string) []string {
+	dirs := []string{dir}
+	for _, p := range parts {
+		if remoteInput(p) {
+			continue
+		}
+		d := filepath.Dir(p)
+		seen := false
+		for _, have := range dirs {
+			if have == d {
+				seen = true
+				break
+			}
+		}
+		if !seen {
+			dirs = append(dirs, d)
+		}
+	}
+	return dirs
+}
+
+// concatSignature is the per-input stream shape that must match across
+// all pieces for a stream-copy concat to produce a playable file.
+type concatSignature struct {
+	VideoCodec string
+	Width      int
+	Height     int
+	AudioCodec string
+}
+
+func (s concatSignature) String() string {
+	return fmt.Sprintf("%s %dx%d + %s", s.VideoCodec, s.Width, s.Height, s.AudioCodec)
+}
+
+// checkConcatCompat probes every concat input and rejects the edit when
+// codecs or resolutions differ, before ffmpeg produces a broken file.
+func (w *Worker) checkConcatCompat(parts []string) error {
+	if len(parts) < 2 {
+		return nil
+	}
+	first, err := w.concatProbe(parts[0])
+	if err != nil {
+		return err
+	}
+	for _, p := range parts[1:] {
+		sig, err := w.concatProbe(p)
+		if err != nil {
+			return err
+		}
+		if sig != first {
+			return fmt.Errorf("concat input %s (%s) is not stream-compatible with %s (%s)", p, sig, parts[0], first)
+		}
+	}
+	return nil
+}
+
+// concatProbe reads the stream signature of one concat input, through
+// the same sandbox as the encode itself.
+func (w *Worker) concatProbe(source string) (concatSignature, error) {
+	cmd := w.ffprobeCommand(remoteInput(source), sandboxDirs("", source), []string{
+		"-v", "error",
+		"-print_format", "json",
+		"-show_streams",
+		source,
+	})
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return concatSignature{}, fmt.Errorf("probe %s: %w: %s", source, err, strings.TrimSpace(stderr.String()))
+	}
+	var out struct {
+		Streams []struct {
+			CodecType string `json:"codec_type"`
+			CodecName string `json:"codec_name"`
+			Width     int    `json:"width"`
+			Height    int    `json:"height"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return concatSignature{}, fmt.Errorf("probe %s: %w", source, err)
+	}
+	var sig concatSignature
+	for _, st := range out.Streams {
+		switch st.CodecType {
+		case "video":
+			if sig.VideoCodec == "" {
+				sig.VideoCodec = st.CodecName
+				sig.Width = st.Width
+				sig.Height = st.Height
+			}
+		case "audio":
+			if sig.AudioCodec == "" {
+				sig.AudioCodec = st.CodecName
+			}
+		}
+	}
+	return sig, nil
+}
+
+// runFFmpeg runs one sandboxed ffmpeg pre-processing pass to
+// completion; network opens the sandbox to remote inputs and dirs lists
+// the host directories the pass may touch.
+func (w *Worker) runFFmpeg(network bool, dirs []string, args ...string) error {
+	cmd := w.ffmpegCommand(nil, network, dirs, args)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ffmpeg: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
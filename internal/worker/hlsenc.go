@@ -0,0 +1,83 @@
+package worker
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// setupHLSEncryption writes the key material for an encrypted HLS
+// encode and returns the extra ffmpeg arguments plus a stop function
+// for the rotation loop. The key files live in a subdirectory so
+// storeOutputs never uploads them.
+func (w *Worker) setupHLSEncryption(task types.Task, workdir string) ([]string, func(), error) {
+	dir := filepath.Join(workdir, "keys")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, nil, err
+	}
+	for i, k := range task.HLSKeys {
+		if err := os.WriteFile(keyPath(dir, i), k.Key, 0o600); err != nil {
+			return nil, nil, err
+		}
+	}
+	info := filepath.Join(dir, "keyinfo")
+	if err := writeKeyInfo(info, task.HLSKeys[0].URI, keyPath(dir, 0)); err != nil {
+		return nil, nil, err
+	}
+
+	args := []string{"-hls_key_info_file", info}
+	stop := func() {}
+	if rot := task.Profile.Container.Encryption.KeyRotationSegments; rot > 0 && len(task.HLSKeys) > 1 {
+		// periodic_rekey makes ffmpeg re-read the key info file before
+		// each segment; the rotation loop swaps it on the interval.
+		args = append(args, "-hls_flags", "periodic_rekey")
+		done := make(chan struct{})
+		go w.rotateHLSKeys(task, dir, info, done)
+		stop = func() { close(done) }
+	}
+	return args, stop, nil
+}
+
+// rotateHLSKeys advances the key info file through the task's key pool,
+// one step per rotation interval, until the pool or the encode ends.
+func (w *Worker) rotateHLSKeys(task types.Task, dir, info string, done <-chan struct{}) {
+	c := task.Profile.Container
+	segment := c.SegmentDurationSec
+	if segment <= 0 {
+		// The hls muxer's default segment length.
+		segment = 2
+	}
+	interval := time.Duration(c.Encryption.KeyRotationSegments*segment) * time.Second
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for i := 1; i < len(task.HLSKeys); i++ {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+		if err := writeKeyInfo(info, task.HLSKeys[i].URI, keyPath(dir, i)); err != nil {
+			log.Printf("worker: rotate HLS key for task %s: %v", task.ID, err)
+			return
+		}
+	}
+	log.Printf("worker: task %s exhausted its HLS key pool; keeping the last key", task.ID)
+}
+
+// writeKeyInfo writes ffmpeg's key info file (key URI line, key file
+// line) atomically, since the muxer re-reads it mid-encode.
+func writeKeyInfo(path, uri, keyFile string) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(uri+"\n"+keyFile+"\n"), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func keyPath(dir string, i int) string {
+	return filepath.Join(dir, fmt.Sprintf("key_%02d.bin", i))
+}
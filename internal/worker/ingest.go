@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rennerdo30/webencode/internal/trace"
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// ingest fetches an external source with yt-dlp into the workdir and
+// stores the result like any other task output. The kernel launches the
+// job's transcode tasks against the fetched file when this completes.
+func (w *Worker) ingest(ctx context.Context, task types.Task) ([]types.Artifact, error) {
+	workdir := filepath.Join(os.TempDir(), "webencode", task.ID)
+	if err := os.MkdirAll(workdir, 0o755); err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(workdir)
+
+	format := "bv*+ba/b"
+	// The worker-wide bandwidth cap applies unless the task asks for a
+	// tighter one of its own.
+	rateLimit := w.transfers.rateLimitArg()
+	if task.Ingest != nil {
+		if task.Ingest.Format != "" {
+			format = task.Ingest.Format
+		}
+		if task.Ingest.RateLimit != "" {
+			rateLimit = task.Ingest.RateLimit
+		}
+	}
+	args := []string{
+		"--no-playlist", "--no-progress",
+		"-f", format,
+		"-o", filepath.Join(workdir, "source.%(ext)s"),
+	}
+	if rateLimit != "" {
+		args = append(args, "--limit-rate", rateLimit)
+	}
+	args = append(args, task.SourceURL)
+
+	cmd := exec.Command("yt-dlp", args...)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	w.transfers.acquire()
+	start := time.Now()
+	_, dlSpan := trace.StartSpan(ctx, "ingest.download")
+	err := cmd.Run()
+	dlSpan.End(err)
+	if err != nil {
+		w.transfers.release()
+		return nil, fmt.Errorf("yt-dlp: %w: %s", err, strings.TrimSpace(output.String()))
+	}
+	if size := dirSize(workdir); size > 0 {
+		w.publishTaskLog(task, "info", fmt.Sprintf("downloaded %d bytes (%s)", size, transferRate(size, time.Since(start))))
+	}
+	w.transfers.release()
+	_, storeSpan := trace.StartSpan(ctx, "store.outputs")
+	artifacts, err := w.storeOutputs(task, workdir)
+	storeSpan.End(err)
+	return artifacts, err
+}
@@ -0,0 +1,69 @@
+package worker
+
+import (
+	"log"
+	"net/http"
+	"time"
+)
+
+// evictMargin is reserved at the end of the preemption grace period for
+// killing, requeueing and checkpoint-offloading whatever did not finish
+// before the node dies.
+const evictMargin = 5 * time.Second
+
+// WatchPreemption polls the configured cloud preemption notice URL (the
+// EC2 spot instance-action or GCE preemption metadata endpoint); any
+// 200 response means the node is about to be reclaimed and eviction
+// starts. Run it in a goroutine when Config.PreemptionURL is set.
+func (w *Worker) WatchPreemption(stop <-chan struct{}) {
+	ticker := time.NewTicker(w.cfg.PreemptionPoll)
+	defer ticker.Stop()
+	client := &http.Client{Timeout: 2 * time.Second}
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		resp, err := client.Get(w.cfg.PreemptionURL)
+		if err != nil {
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			continue
+		}
+		w.evict()
+		return
+	}
+}
+
+// evict handles a preemption notice: stop accepting tasks, give
+// in-flight work what is left of the grace period to finish (chunked
+// encodes keep checkpointing meanwhile), then signal Preempted so the
+// normal drain path requeues the rest — with checkpoints offloaded to
+// storage — and announces the node offline.
+func (w *Worker) evict() {
+	log.Printf("worker: %s preempted, %s to evacuate", w.ID, w.cfg.PreemptionGrace)
+	w.drain()
+	deadline := time.Now().Add(w.cfg.PreemptionGrace - evictMargin)
+	for time.Now().Before(deadline) {
+		w.mu.Lock()
+		n := len(w.running)
+		w.mu.Unlock()
+		if n == 0 {
+			break
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	// Whatever is still running will not finish in time; cap the drain
+	// wait so shutdown kills and requeues it within the margin.
+	w.mu.Lock()
+	w.cfg.DrainTimeout = time.Second
+	w.mu.Unlock()
+	close(w.preempted)
+}
+
+// Preempted is closed once a preemption notice has been handled and the
+// node evacuated; the caller should shut the worker down.
+func (w *Worker) Preempted() <-chan struct{} { return w.preempted }
@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rennerdo30/webencode/pkg/plugin"
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// remotePollInterval is how often a remote encode is polled.
+const remotePollInterval = 10 * time.Second
+
+// remoteTranscode offloads a transcode to the configured encoder
+// plugin (e.g. AWS MediaConvert) and polls until the backend finishes,
+// so installs without local encode capacity keep the same pipeline.
+func (w *Worker) remoteTranscode(task types.Task) ([]types.Artifact, error) {
+	enc, err := w.plugins.Encoder(w.cfg.EncoderPlugin)
+	if err != nil {
+		return nil, err
+	}
+	p := task.Profile
+	id, err := enc.Submit(plugin.EncodeSpec{
+		SourceURL:        task.SourceURL,
+		OutputPrefix:     task.OutputPrefix,
+		VideoCodec:       p.VideoCodec,
+		AudioCodec:       p.AudioCodec,
+		Width:            p.Width,
+		Height:           p.Height,
+		VideoBitrateKbps: p.VideoBitrateKbps,
+		AudioBitrateKbps: p.AudioBitrateKbps,
+		Framerate:        p.Framerate,
+		Container:        p.Container.Format,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("remote encode submit: %w", err)
+	}
+	w.publishTaskLog(task, "info", fmt.Sprintf("remote encode %s submitted via %s", id, w.cfg.EncoderPlugin))
+
+	for {
+		time.Sleep(remotePollInterval)
+		st, err := enc.Status(id)
+		if err != nil {
+			return nil, fmt.Errorf("remote encode %s: %w", id, err)
+		}
+		switch st.State {
+		case "complete":
+			artifacts := make([]types.Artifact, 0, len(st.OutputURLs))
+			for _, u := range st.OutputURLs {
+				artifacts = append(artifacts, types.Artifact{
+					Kind:        types.ArtifactRendition,
+					Key:         u,
+					ProfileID:   p.ID,
+					ProfileName: p.Name,
+					VideoCodec:  p.VideoCodec,
+					AudioCodec:  p.AudioCodec,
+					Width:       p.Width,
+					Height:      p.Height,
+				})
+			}
+			return artifacts, nil
+		case "error":
+			return nil, fmt.Errorf("remote encode %s failed: %s", id, st.Error)
+		case "canceled":
+			return nil, fmt.Errorf("remote encode %s was canceled", id)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package worker
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// sandboxed reports whether this node runs ffmpeg inside containers.
+func (w *Worker) sandboxed() bool { return w.cfg.SandboxImage != "" }
+
+// ffmpegCommand builds one ffmpeg invocation via the sandbox wrapper.
+func (w *Worker) ffmpegCommand(p *types.Profile, network bool, dirs []string, args []string) *exec.Cmd {
+	return w.sandboxCommand("ffmpeg", p, network, dirs, args)
+}
+
+// ffprobeCommand builds one ffprobe invocation via the sandbox wrapper;
+// probes get no resource caps beyond the worker defaults.
+func (w *Worker) ffprobeCommand(network bool, dirs []string, args []string) *exec.Cmd {
+	return w.sandboxCommand("ffprobe", nil, network, dirs, args)
+}
+
+// sandboxCommand builds one media-tool invocation. With a sandbox image
+// configured the process runs in a disposable container instead: the
+// given host directories are bind-mounted at their host paths so the
+// argv works unchanged, CPU and memory are capped (profile limits beat
+// the worker defaults), and the network is disabled unless the input is
+// remote — an untrusted local file cannot phone home or exhaust the
+// node. Killing the returned process kills the docker client; --init
+// makes the container exit with it.
+func (w *Worker) sandboxCommand(bin string, p *types.Profile, network bool, dirs []string, args []string) *exec.Cmd {
+	if !w.sandboxed() {
+		return exec.Command(bin, args...)
+	}
+	run := []string{"run", "--rm", "--init"}
+	if !network {
+		run = append(run, "--network", "none")
+	}
+	cpus, memMB := w.cfg.SandboxCPUs, w.cfg.SandboxMemoryMB
+	if p != nil {
+		if p.SandboxCPUs > 0 {
+			cpus = p.SandboxCPUs
+		}
+		if p.SandboxMemoryMB > 0 {
+			memMB = p.SandboxMemoryMB
+		}
+	}
+	if cpus > 0 {
+		run = append(run, "--cpus", fmt.Sprintf("%g", cpus))
+	}
+	if memMB > 0 {
+		run = append(run, "--memory", fmt.Sprintf("%dm", memMB))
+	}
+	for _, d := range dirs {
+		run = append(run, "-v", d+":"+d)
+	}
+	run = append(run, w.cfg.SandboxImage, bin)
+	run = append(run, args...)
+	return exec.Command("docker", run...)
+}
+
+// remoteInput reports whether ffmpeg needs the network to read an
+// input.
+func remoteInput(url string) bool {
+	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://")
+}
+
+// sandboxDirs lists the host directories one encode touches: its
+// workdir and, for local inputs, the directory the source lives in.
+func sandboxDirs(workdir, input string) []string {
+	var dirs []string
+	if workdir != "" {
+		dirs = append(dirs, workdir)
+	}
+	if input != "" && !remoteInput(input) {
+		if d := filepath.Dir(input); d != workdir {
+			dirs = append(dirs, d)
+		}
+	}
+	return dirs
+}
@@ -0,0 +1,524 @@
+package worker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/rennerdo30/webencode/internal/bus"
+	"github.com/rennerdo30/webencode/internal/encode"
+	"github.com/rennerdo30/webencode/internal/proc"
+	"github.com/rennerdo30/webencode/internal/trace"
+	"github.com/rennerdo30/webencode/pkg/plugin"
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// SubscribeTasks joins the shared task queue group. Each dispatched task
+// lands on exactly one worker.
+func (w *Worker) SubscribeTasks() error {
+	sub, err := w.conn.QueueSubscribe(bus.SubjectTasksAll, bus.TaskQueueGroup, func(msg *nats.Msg) {
+		var task types.Task
+		if err := json.Unmarshal(msg.Data, &task); err != nil {
+			log.Printf("worker: malformed task: %v", err)
+			return
+		}
+		if task.Excluded(w.ID) || (task.TargetWorker != "" && task.TargetWorker != w.ID) || !w.hasSpace(task) {
+			// This node is excluded, the task is pinned elsewhere, or it
+			// would not fit on local disk; put it back on the bus after a
+			// beat so another worker gets it.
+			go func(data []byte) {
+				time.Sleep(time.Second)
+				if err := w.conn.Publish(bus.TaskSubject(string(task.Type)), data); err != nil {
+					log.Printf("worker: requeue excluded task: %v", err)
+				}
+			}(msg.Data)
+			return
+		}
+		if data, miss := w.selectorMiss(&task); miss {
+			// The task prefers (or requires) differently-labelled
+			// workers; step aside and let one of them take it.
+			go func() {
+				time.Sleep(time.Second)
+				if err := w.conn.Publish(bus.TaskSubject(string(task.Type)), data); err != nil {
+					log.Printf("worker: requeue task on selector miss: %v", err)
+				}
+			}()
+			return
+		}
+		go w.runTask(task)
+	})
+	if err != nil {
+		return err
+	}
+	w.taskSub = sub
+	return nil
+}
+
+// selectorMissLimit is how many times a preferred selector may bounce
+// a task between non-matching workers before one runs it anyway.
+const selectorMissLimit = 3
+
+// selectorMiss reports whether this node should step aside for the
+// task's label selector, returning the payload to requeue with the
+// miss recorded.
+func (w *Worker) selectorMiss(task *types.Task) ([]byte, bool) {
+	if len(task.Selector) == 0 || w.matchesLabels(task.Selector) {
+		return nil, false
+	}
+	if task.SelectorPreferred && task.SelectorMisses >= selectorMissLimit {
+		return nil, false
+	}
+	task.SelectorMisses++
+	data, err := json.Marshal(task)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// matchesLabels reports whether every selector pair matches this
+// node's labels.
+func (w *Worker) matchesLabels(selector map[string]string) bool {
+	for k, v := range selector {
+		if w.cfg.Labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// runTask executes one task and reports lifecycle events.
+func (w *Worker) runTask(task types.Task) {
+	w.trackTask(task, true)
+	defer w.trackTask(task, false)
+	w.publishTaskEvent(task, types.TaskStatusRunning, "", types.ErrorClassNone)
+
+	// Join the kernel's dispatch trace so download/encode/upload phases
+	// show up under the same job timeline.
+	ctx := trace.ContextWithRemote(context.Background(), task.TraceParent)
+	ctx, span := trace.StartSpan(ctx, "worker."+string(task.Type))
+	span.SetAttribute("task.id", task.ID)
+	span.SetAttribute("worker.id", w.ID)
+	artifacts, err := w.execute(ctx, task)
+	span.End(err)
+	if err != nil {
+		if w.isDraining() {
+			// Shutdown killed this task and already requeued it; a
+			// failure report would trigger a duplicate retry.
+			return
+		}
+		w.publishTaskEvent(task, types.TaskStatusFailed, err.Error(), classifyError(err))
+		return
+	}
+	w.publishTaskCompleted(task, artifacts)
+}
+
+// execute performs the actual work of a task and returns what it
+// produced.
+func (w *Worker) execute(ctx context.Context, task types.Task) ([]types.Artifact, error) {
+	switch task.Type {
+	case types.TaskTypeTranscode:
+		return w.transcode(ctx, task)
+	case types.TaskTypeIngest:
+		return w.ingest(ctx, task)
+	case types.TaskTypeBenchmark:
+		return nil, w.benchmark(ctx, task)
+	default:
+		return nil, fmt.Errorf("unsupported task type %q", task.Type)
+	}
+}
+
+// transcode renders the task source through its profile into the
+// worker's workdir.
+func (w *Worker) transcode(ctx context.Context, task types.Task) ([]types.Artifact, error) {
+	if task.Profile == nil {
+		return nil, fmt.Errorf("transcode task %s has no profile", task.ID)
+	}
+	if w.cfg.EncoderPlugin != "" {
+		_, span := trace.StartSpan(ctx, "encode.remote")
+		artifacts, err := w.remoteTranscode(task)
+		span.End(err)
+		return artifacts, err
+	}
+	if w.cfg.CheckpointChunk > 0 && checkpointable(task) {
+		return w.chunkedTranscode(ctx, task)
+	}
+	workdir := filepath.Join(os.TempDir(), "webencode", task.ID)
+	if err := os.MkdirAll(workdir, 0o755); err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(workdir)
+
+	input := task.SourceURL
+	if task.Edit != nil {
+		edited, err := w.prepareEdit(task, workdir)
+		if err != nil {
+			return nil, fmt.Errorf("prepare edit: %w", err)
+		}
+		input = edited
+	}
+
+	ext := encode.OutputExt(task.Profile)
+	outputPath := filepath.Join(workdir, task.Profile.Name+ext)
+	args := []string{"-hide_banner", "-loglevel", "error", "-y"}
+	if task.SeekSec > 0 {
+		args = append(args, "-ss", fmt.Sprintf("%g", task.SeekSec))
+	}
+	args = append(args, "-i", input)
+	if task.DurationSec > 0 {
+		args = append(args, "-t", fmt.Sprintf("%g", task.DurationSec))
+	}
+	var detectedCrop string
+	if task.Profile.AspectMode == types.AspectModeSmart {
+		detectedCrop = w.detectCrop(input)
+	}
+	var loudness *types.LoudnessReport
+	if target := task.Profile.LoudnessTargetLUFS; target != 0 {
+		m, err := w.measureLoudness(input, target)
+		if err != nil {
+			return nil, fmt.Errorf("loudnorm measurement: %w", err)
+		}
+		// -af is an output option; it can precede the rest of them.
+		args = append(args, "-af", encode.LoudnormFilter(target, m))
+		loudness = m.Report(target)
+	}
+	if task.Profile.Container.Encryption != nil && len(task.HLSKeys) > 0 {
+		encArgs, stopRotation, err := w.setupHLSEncryption(task, workdir)
+		if err != nil {
+			return nil, fmt.Errorf("setup HLS encryption: %w", err)
+		}
+		defer stopRotation()
+		args = append(args, encArgs...)
+	}
+	args = append(args, encode.OutputArgsWithCrop(task.Profile, outputPath, detectedCrop)...)
+
+	cmd := w.ffmpegCommand(task.Profile, remoteInput(input), sandboxDirs(workdir, input), args)
+	stderrPipe, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	// Tail stderr live: each line goes on the bus for log streaming and
+	// into a buffer for the failure message.
+	var stderr bytes.Buffer
+	stderrDone := make(chan struct{})
+	go func() {
+		defer close(stderrDone)
+		sc := bufio.NewScanner(stderrPipe)
+		for sc.Scan() {
+			line := sc.Text()
+			stderr.WriteString(line)
+			stderr.WriteByte('\n')
+			// -loglevel error means everything here is an error line.
+			w.publishTaskLog(task, "error", line)
+		}
+	}()
+	// Batch encodes yield to any live workload on the node; if relays
+	// are currently degraded the encode starts paused.
+	if err := proc.SetNice(cmd.Process.Pid, proc.BatchNice); err != nil {
+		log.Printf("worker: renice encode for task %s: %v", task.ID, err)
+	}
+	w.trackProcess(task.ID, cmd.Process)
+	defer w.trackProcess(task.ID, nil)
+	_, encSpan := trace.StartSpan(ctx, "encode.ffmpeg")
+	<-stderrDone
+	waitErr := cmd.Wait()
+	encSpan.End(waitErr)
+	if waitErr != nil {
+		return nil, fmt.Errorf("ffmpeg: %w: %s", waitErr, strings.TrimSpace(stderr.String()))
+	}
+	_, storeSpan := trace.StartSpan(ctx, "store.outputs")
+	artifacts, err := w.storeOutputs(task, workdir)
+	storeSpan.End(err)
+	if err != nil {
+		return nil, err
+	}
+	if loudness != nil {
+		for i := range artifacts {
+			if artifacts[i].Kind == types.ArtifactRendition {
+				artifacts[i].Loudness = loudness
+			}
+		}
+	}
+	return artifacts, nil
+}
+
+// measureLoudness runs the loudnorm measurement pass over the input and
+// parses the filter's JSON report off stderr.
+func (w *Worker) measureLoudness(input string, targetLUFS float64) (*encode.LoudnormMeasurement, error) {
+	cmd := w.ffmpegCommand(nil, remoteInput(input), sandboxDirs("", input), encode.LoudnormMeasureArgs(input, targetLUFS))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return encode.ParseLoudnormMeasurement(stderr.String())
+}
+
+// publishTaskLog puts one worker log line on the bus; best effort.
+func (w *Worker) publishTaskLog(task types.Task, level, line string) {
+	tl := types.TaskLog{
+		TaskID:   task.ID,
+		JobID:    task.JobID,
+		WorkerID: w.ID,
+		Token:    w.cfg.WorkerToken,
+		Level:    level,
+		Line:     line,
+		At:       time.Now().UTC(),
+	}
+	data, err := json.Marshal(tl)
+	if err != nil {
+		return
+	}
+	if err := w.conn.Publish(bus.SubjectTaskLogs, data); err != nil {
+		log.Printf("worker: publish task log: %v", err)
+	}
+}
+
+// detectCrop samples the source with cropdetect to find the active
+// picture area for smart reframing. Failures fall back to center-crop.
+func (w *Worker) detectCrop(sourceURL string) string {
+	cmd := w.ffmpegCommand(nil, remoteInput(sourceURL), sandboxDirs("", sourceURL), encode.CropDetectArgs(sourceURL, 10))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		log.Printf("worker: cropdetect pre-pass: %v", err)
+		return ""
+	}
+	return encode.ParseCropDetect(stderr.String())
+}
+
+// storeOutputs uploads everything the encode produced and describes
+// each file as an artifact. Without a storage plugin outputs stay in a
+// local output dir next to the workdir.
+func (w *Worker) storeOutputs(task types.Task, workdir string) ([]types.Artifact, error) {
+	entries, err := os.ReadDir(workdir)
+	if err != nil {
+		return nil, err
+	}
+	storage, storageErr := w.storageFor(task.StorageBackend)
+	var artifacts []types.Artifact
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		local := filepath.Join(workdir, e.Name())
+		key := task.OutputPrefix + "/" + e.Name()
+		sum, err := fileSHA256(local)
+		if err != nil {
+			return nil, err
+		}
+		if storageErr == nil {
+			if err := w.upload(task, storage, local, key); err != nil {
+				return nil, err
+			}
+			// The artifact's digest must describe what storage actually
+			// holds, not what we intended to send.
+			if err := w.verifyUpload(storage, key, sum); err != nil {
+				return nil, err
+			}
+		} else {
+			dest := filepath.Join(os.TempDir(), "webencode-outputs", task.JobID, e.Name())
+			if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+				return nil, err
+			}
+			if err := os.Rename(local, dest); err != nil {
+				return nil, err
+			}
+			local, key = dest, dest
+		}
+		a := describeArtifact(task, local, key)
+		a.SHA256 = sum
+		artifacts = append(artifacts, a)
+	}
+	return artifacts, nil
+}
+
+// upload pushes one output file through the transfer limiter, pacing
+// it against the bandwidth cap and reporting the achieved rate on the
+// task log.
+func (w *Worker) upload(task types.Task, storage plugin.StorageService, local, key string) error {
+	w.transfers.acquire()
+	defer w.transfers.release()
+	var size int64
+	if info, err := os.Stat(local); err == nil {
+		size = info.Size()
+	}
+	start := time.Now()
+	if err := storage.Upload(local, key); err != nil {
+		return fmt.Errorf("upload %s: %w", key, err)
+	}
+	w.publishTaskLog(task, "info", fmt.Sprintf("uploaded %s (%d bytes, %s)", key, size, transferRate(size, time.Since(start))))
+	w.transfers.pace(start, size)
+	return nil
+}
+
+// verifyUpload reads a just-uploaded object back from storage and
+// compares its SHA-256 against the local file's. The storage interface
+// exposes no backend etag, so a read-back is the only confirmation that
+// the bytes survived the transfer.
+func (w *Worker) verifyUpload(storage plugin.StorageService, key, wantSHA256 string) error {
+	tmp, err := os.CreateTemp("", "webencode-verify-*")
+	if err != nil {
+		return err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+	if err := storage.Download(key, tmp.Name()); err != nil {
+		return fmt.Errorf("verify %s: read back: %w", key, err)
+	}
+	sum, err := fileSHA256(tmp.Name())
+	if err != nil {
+		return err
+	}
+	if sum != wantSHA256 {
+		return fmt.Errorf("verify %s: stored checksum %s does not match uploaded %s", key, sum, wantSHA256)
+	}
+	return nil
+}
+
+// fileSHA256 hashes one file for artifact integrity metadata.
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// describeArtifact classifies one output file by extension and, for
+// renditions, copies the encode parameters off the task profile.
+func describeArtifact(task types.Task, local, key string) types.Artifact {
+	a := types.Artifact{
+		Kind:      artifactKind(key),
+		Key:       key,
+		TaskID:    task.ID,
+		CreatedAt: time.Now().UTC(),
+	}
+	if info, err := os.Stat(local); err == nil {
+		a.SizeBytes = info.Size()
+	}
+	if a.Kind == types.ArtifactRendition && task.Profile != nil {
+		a.ProfileID = task.Profile.ID
+		a.ProfileName = task.Profile.Name
+		a.VideoCodec = task.Profile.VideoCodec
+		a.AudioCodec = task.Profile.AudioCodec
+		a.Width = task.Profile.Width
+		a.Height = task.Profile.Height
+		a.BitrateKbps = task.Profile.VideoBitrateKbps
+	}
+	return a
+}
+
+// artifactKind buckets an output file by its extension.
+func artifactKind(key string) types.ArtifactKind {
+	switch strings.ToLower(filepath.Ext(key)) {
+	case ".m3u8", ".mpd":
+		return types.ArtifactManifest
+	case ".ts", ".m4s":
+		return types.ArtifactSegment
+	case ".jpg", ".jpeg", ".png", ".webp":
+		return types.ArtifactThumbnail
+	case ".vtt", ".srt", ".ass":
+		return types.ArtifactSubtitle
+	case ".mp4", ".mkv", ".webm", ".mov", ".mp3", ".aac", ".flac", ".ogg", ".opus", ".m4a", ".wav":
+		return types.ArtifactRendition
+	default:
+		return types.ArtifactOther
+	}
+}
+
+// classifyError buckets a failure into an error class so the kernel can
+// tell node problems from bad inputs.
+func classifyError(err error) types.ErrorClass {
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "no space left"):
+		return types.ErrorClassDiskFull
+	case strings.Contains(msg, "cuda") || strings.Contains(msg, "nvenc") || strings.Contains(msg, "vaapi"):
+		return types.ErrorClassGPU
+	case strings.Contains(msg, "input/output error"):
+		return types.ErrorClassIO
+	case strings.Contains(msg, "connection refused") || strings.Contains(msg, "404") || strings.Contains(msg, "name or service not known"):
+		return types.ErrorClassSource
+	default:
+		return types.ErrorClassEncode
+	}
+}
+
+// publishTaskEvent reports a lifecycle change to the kernel.
+func (w *Worker) publishTaskEvent(task types.Task, status types.TaskStatus, errMsg string, class types.ErrorClass) {
+	w.publishEvent(types.TaskEvent{
+		TaskID:     task.ID,
+		JobID:      task.JobID,
+		WorkerID:   w.ID,
+		Token:      w.cfg.WorkerToken,
+		Status:     status,
+		Error:      errMsg,
+		ErrorClass: class,
+		At:         time.Now().UTC(),
+	})
+}
+
+// publishTaskCompleted reports completion along with the artifacts the
+// task produced, so the kernel can index job outputs.
+func (w *Worker) publishTaskCompleted(task types.Task, artifacts []types.Artifact) {
+	w.publishEvent(types.TaskEvent{
+		TaskID:    task.ID,
+		JobID:     task.JobID,
+		WorkerID:  w.ID,
+		Token:     w.cfg.WorkerToken,
+		Status:    types.TaskStatusCompleted,
+		Artifacts: artifacts,
+		Benchmark: w.takeBenchScores(task.ID),
+		At:        time.Now().UTC(),
+	})
+}
+
+func (w *Worker) publishEvent(ev types.TaskEvent) {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	if err := w.conn.Publish(bus.SubjectTaskEvents, data); err != nil {
+		log.Printf("worker: publish task event: %v", err)
+	}
+}
+
+// trackTask maintains the running-task list reported in heartbeats and
+// the active payloads shutdown requeues.
+func (w *Worker) trackTask(task types.Task, running bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if running {
+		w.running = append(w.running, task.ID)
+		w.active[task.ID] = task
+		return
+	}
+	delete(w.active, task.ID)
+	for i, id := range w.running {
+		if id == task.ID {
+			w.running = append(w.running[:i], w.running[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package worker
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/rennerdo30/webencode/internal/bus"
+	"github.com/rennerdo30/webencode/internal/proc"
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// SubscribeThrottle listens for kernel throttle broadcasts. While a
+// throttle is active all batch encode processes on this node are paused
+// so live relays get the CPU; they resume when the throttle clears.
+func (w *Worker) SubscribeThrottle() error {
+	_, err := w.conn.Subscribe(bus.SubjectThrottle, func(msg *nats.Msg) {
+		var t types.Throttle
+		if err := json.Unmarshal(msg.Data, &t); err != nil {
+			log.Printf("worker: malformed throttle: %v", err)
+			return
+		}
+		w.applyThrottle(t)
+	})
+	return err
+}
+
+// applyThrottle pauses or resumes every tracked encode process.
+func (w *Worker) applyThrottle(t types.Throttle) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if t.Active == w.throttled {
+		return
+	}
+	w.throttled = t.Active
+	if t.Active {
+		log.Printf("worker: pausing %d encode(s): %s", len(w.procs), t.Reason)
+	} else {
+		log.Printf("worker: resuming %d encode(s)", len(w.procs))
+	}
+	for taskID, p := range w.procs {
+		var err error
+		if t.Active {
+			err = proc.Pause(p.Pid)
+		} else {
+			err = proc.Resume(p.Pid)
+		}
+		if err != nil {
+			log.Printf("worker: throttle task %s: %v", taskID, err)
+		}
+	}
+}
+
+// trackProcess registers a running encode process for throttling; a nil
+// process removes the entry. Encodes started under an active throttle
+// are paused straight away.
+func (w *Worker) trackProcess(taskID string, p *os.Process) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if p == nil {
+		delete(w.procs, taskID)
+		return
+	}
+	w.procs[taskID] = p
+	if w.throttled {
+		if err := proc.Pause(p.Pid); err != nil {
+			log.Printf("worker: pause task %s: %v", taskID, err)
+		}
+	}
+}
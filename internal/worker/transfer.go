@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"fmt"
+	"time"
+)
+
+// transferLimiter bounds how hard a worker hits shared storage: a slot
+// semaphore caps parallel transfer streams, and an optional bandwidth
+// cap paces each transfer so the node's average throughput stays under
+// the configured rate. Pacing happens around whole transfers because
+// the bytes themselves move inside storage plugins and external tools.
+type transferLimiter struct {
+	slots chan struct{}
+	kbps  int
+}
+
+// newTransferLimiter builds a limiter for the given parallel stream
+// count and bandwidth cap in kbit/s (zero means unlimited bandwidth).
+func newTransferLimiter(concurrency, kbps int) *transferLimiter {
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	return &transferLimiter{
+		slots: make(chan struct{}, concurrency),
+		kbps:  kbps,
+	}
+}
+
+// acquire blocks until a transfer slot is free.
+func (l *transferLimiter) acquire() { l.slots <- struct{}{} }
+
+// release returns a transfer slot.
+func (l *transferLimiter) release() { <-l.slots }
+
+// pace sleeps out the remainder of the transfer's bandwidth budget: a
+// transfer of n bytes under a cap of kbps must occupy at least
+// n*8/(kbps*1000) seconds end to end.
+func (l *transferLimiter) pace(start time.Time, n int64) {
+	if l.kbps <= 0 || n <= 0 {
+		return
+	}
+	budget := time.Duration(float64(n*8) / float64(l.kbps*1000) * float64(time.Second))
+	if elapsed := time.Since(start); elapsed < budget {
+		time.Sleep(budget - elapsed)
+	}
+}
+
+// rateLimitArg formats the bandwidth cap for yt-dlp's --limit-rate.
+func (l *transferLimiter) rateLimitArg() string {
+	if l.kbps <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%dK", l.kbps/8)
+}
+
+// transferRate renders a human-readable throughput for task logs.
+func transferRate(n int64, elapsed time.Duration) string {
+	if elapsed <= 0 {
+		return "instant"
+	}
+	mbps := float64(n*8) / elapsed.Seconds() / 1e6
+	return fmt.Sprintf("%.1f Mbit/s", mbps)
+}
@@ -0,0 +1,331 @@
+// Package worker implements the WebEncode worker node: it joins the bus,
+// heartbeats, and executes tasks dispatched by the kernel.
+package worker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+
+	"github.com/rennerdo30/webencode/internal/bus"
+	"github.com/rennerdo30/webencode/internal/pluginmgr"
+	"github.com/rennerdo30/webencode/pkg/plugin"
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// Config is everything a worker needs to run.
+type Config struct {
+	NATSURL   string
+	NATSCreds string
+	// WorkerID and WorkerToken are the enrollment issued by the kernel;
+	// unset keeps a random unauthenticated identity for dev setups.
+	WorkerID    string
+	WorkerToken string
+	PluginDir   string
+	// StoragePlugin names the storage plugin outputs are uploaded with;
+	// empty keeps outputs on local disk.
+	StoragePlugin string
+	// StorageBackends maps backend aliases (e.g. "hot", "archive") to
+	// plugin names; tasks pick a backend by alias.
+	StorageBackends map[string]string
+	// EncoderPlugin, when set, offloads transcode tasks to a remote
+	// encoder plugin instead of running ffmpeg locally.
+	EncoderPlugin string
+	Labels        map[string]string
+	// HeartbeatInterval defaults to 10s.
+	HeartbeatInterval time.Duration
+	// DrainTimeout is how long shutdown waits for in-flight tasks to
+	// finish before killing and requeueing them. Defaults to 30s.
+	DrainTimeout time.Duration
+	// CheckpointChunk, when positive, encodes eligible transcode tasks
+	// in chunks of this duration with a checkpoint after each, so a
+	// retried multi-hour encode resumes instead of starting over.
+	CheckpointChunk time.Duration
+	// MinFreeBytes is the free-space floor on the workdir filesystem;
+	// tasks that would push it below the floor are requeued for another
+	// node. Defaults to 1 GiB.
+	MinFreeBytes uint64
+	// TransferConcurrency caps parallel download/upload streams per
+	// node. Defaults to 4.
+	TransferConcurrency int
+	// TransferBandwidthKbps caps the node's average transfer throughput
+	// in kbit/s; zero is unlimited.
+	TransferBandwidthKbps int
+	// SandboxImage, when set, runs ffmpeg inside a disposable Docker
+	// container from this image (which must provide ffmpeg), isolating
+	// untrusted inputs from the node: no network for local sources,
+	// only the task's directories mounted in.
+	SandboxImage string
+	// SandboxCPUs/SandboxMemoryMB cap each sandboxed encode; profiles
+	// can override them per task. Zero leaves the resource unlimited.
+	SandboxCPUs     float64
+	SandboxMemoryMB int
+	// PreemptionURL, when set, is polled for a cloud preemption notice
+	// (e.g. the EC2 spot instance-action or GCE preemption metadata
+	// endpoint); any 200 response starts eviction.
+	PreemptionURL string
+	// PreemptionPoll is the notice poll interval. Defaults to 5s.
+	PreemptionPoll time.Duration
+	// PreemptionGrace is how long the cloud leaves the node running
+	// after a notice. Defaults to 30s.
+	PreemptionGrace time.Duration
+}
+
+// Worker is one running worker node.
+type Worker struct {
+	ID      string
+	cfg     Config
+	conn    *nats.Conn
+	plugins *pluginmgr.Manager
+	// ownsPlugins is false for in-process workers sharing the kernel's
+	// plugin manager; shutdown then leaves the manager running.
+	ownsPlugins bool
+	transfers   *transferLimiter
+
+	// taskSub is the task queue subscription, dropped first on drain.
+	taskSub *nats.Subscription
+
+	mu      sync.Mutex
+	running []string
+	procs   map[string]*os.Process
+	active  map[string]types.Task
+	// benchScores stages finished benchmark results until the
+	// completion event is published.
+	benchScores map[string]map[string]float64
+	throttled   bool
+	// drained marks a worker told to stop accepting tasks ahead of
+	// scale-down; it finishes in-flight work and waits for termination.
+	drained bool
+	// preempted is closed once a preemption notice has been handled and
+	// the node is ready to shut down.
+	preempted chan struct{}
+	// draining suppresses failure reports for tasks shutdown kills and
+	// requeues itself.
+	draining bool
+}
+
+// isDraining reports whether shutdown has started.
+func (w *Worker) isDraining() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.draining
+}
+
+// storage resolves the worker's default storage plugin.
+func (w *Worker) storage() (plugin.StorageService, error) {
+	if w.cfg.StoragePlugin == "" {
+		return nil, errors.New("no storage plugin configured")
+	}
+	return w.plugins.Storage(w.cfg.StoragePlugin)
+}
+
+// storageFor resolves the storage plugin behind a backend alias; an
+// empty alias uses the default plugin.
+func (w *Worker) storageFor(backend string) (plugin.StorageService, error) {
+	if backend == "" {
+		return w.storage()
+	}
+	name, ok := w.cfg.StorageBackends[backend]
+	if !ok {
+		return nil, fmt.Errorf("unknown storage backend %q", backend)
+	}
+	return w.plugins.Storage(name)
+}
+
+// New connects to the bus and returns a worker ready to Run.
+func New(cfg Config) (*Worker, error) {
+	return newWorker(cfg, nil)
+}
+
+// NewInProcess is New sharing an existing plugin manager, for
+// all-in-one deployments where kernel and worker live in one process.
+// The caller keeps ownership of the manager's lifecycle.
+func NewInProcess(cfg Config, plugins *pluginmgr.Manager) (*Worker, error) {
+	return newWorker(cfg, plugins)
+}
+
+func newWorker(cfg Config, plugins *pluginmgr.Manager) (*Worker, error) {
+	if cfg.HeartbeatInterval <= 0 {
+		cfg.HeartbeatInterval = 10 * time.Second
+	}
+	if cfg.DrainTimeout <= 0 {
+		cfg.DrainTimeout = 30 * time.Second
+	}
+	if cfg.MinFreeBytes == 0 {
+		cfg.MinFreeBytes = 1 << 30
+	}
+	if cfg.PreemptionPoll <= 0 {
+		cfg.PreemptionPoll = 5 * time.Second
+	}
+	if cfg.PreemptionGrace <= 0 {
+		cfg.PreemptionGrace = 30 * time.Second
+	}
+	cleanWorkdirs()
+	opts := []nats.Option{
+		nats.MaxReconnects(-1),
+		nats.ReconnectWait(2 * time.Second),
+		nats.Name("webencode-worker"),
+	}
+	if cfg.NATSCreds != "" {
+		opts = append(opts, nats.UserCredentials(cfg.NATSCreds))
+	}
+	conn, err := nats.Connect(cfg.NATSURL, opts...)
+	if err != nil {
+		return nil, err
+	}
+	id := cfg.WorkerID
+	if id == "" {
+		id = uuid.NewString()
+	}
+	ownsPlugins := plugins == nil
+	if plugins == nil {
+		plugins = pluginmgr.NewManager(cfg.PluginDir)
+	}
+	return &Worker{
+		ID:          id,
+		cfg:         cfg,
+		conn:        conn,
+		plugins:     plugins,
+		ownsPlugins: ownsPlugins,
+		transfers:   newTransferLimiter(cfg.TransferConcurrency, cfg.TransferBandwidthKbps),
+		procs:       make(map[string]*os.Process),
+		active:      make(map[string]types.Task),
+		benchScores: make(map[string]map[string]float64),
+		preempted:   make(chan struct{}),
+	}, nil
+}
+
+// Run heartbeats until stop is closed.
+func (w *Worker) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(w.cfg.HeartbeatInterval)
+	defer ticker.Stop()
+	w.heartbeat()
+	for {
+		select {
+		case <-stop:
+			w.shutdown()
+			return
+		case <-ticker.C:
+			w.heartbeat()
+		}
+	}
+}
+
+// shutdown drains the worker: stop consuming, let in-flight tasks
+// finish within the drain timeout (killing and requeueing the rest),
+// then announce the node offline so the kernel reassigns work quickly.
+func (w *Worker) shutdown() {
+	if w.taskSub != nil {
+		_ = w.taskSub.Unsubscribe()
+	}
+	w.mu.Lock()
+	w.draining = true
+	w.mu.Unlock()
+	deadline := time.Now().Add(w.cfg.DrainTimeout)
+	for time.Now().Before(deadline) {
+		w.mu.Lock()
+		n := len(w.running)
+		w.mu.Unlock()
+		if n == 0 {
+			break
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	w.requeueActive()
+	w.farewell()
+	_ = w.conn.Flush()
+	if w.ownsPlugins {
+		w.plugins.Shutdown()
+	}
+	w.conn.Close()
+}
+
+// requeueActive kills tasks that outlived the drain deadline and puts
+// them back on the bus, excluding this node, for redelivery.
+func (w *Worker) requeueActive() {
+	w.mu.Lock()
+	stranded := make([]types.Task, 0, len(w.active))
+	for id, task := range w.active {
+		if !task.Excluded(w.ID) {
+			task.ExcludedWorkers = append(task.ExcludedWorkers, w.ID)
+		}
+		stranded = append(stranded, task)
+		if p := w.procs[id]; p != nil {
+			_ = p.Kill()
+		}
+	}
+	w.mu.Unlock()
+	for _, task := range stranded {
+		if checkpointable(task) {
+			// A surviving checkpoint lets whoever picks the task up
+			// resume instead of restarting; push it to shared storage
+			// since this node is going away.
+			w.offloadCheckpoint(task)
+		}
+		data, err := json.Marshal(&task)
+		if err != nil {
+			continue
+		}
+		if err := w.conn.Publish(bus.TaskSubject(string(task.Type)), data); err != nil {
+			log.Printf("worker: requeue task %s on shutdown: %v", task.ID, err)
+		}
+	}
+}
+
+// farewell emits the final heartbeat announcing the shutdown.
+func (w *Worker) farewell() {
+	hostname, _ := os.Hostname()
+	hb := types.Heartbeat{
+		WorkerID: w.ID,
+		Token:    w.cfg.WorkerToken,
+		Hostname: hostname,
+		Labels:   w.cfg.Labels,
+		Shutdown: true,
+		At:       time.Now().UTC(),
+	}
+	data, err := json.Marshal(hb)
+	if err != nil {
+		return
+	}
+	if err := w.conn.Publish(bus.SubjectHeartbeat, data); err != nil {
+		log.Printf("worker: publish farewell heartbeat: %v", err)
+	}
+}
+
+// heartbeat publishes the worker's current state on the bus.
+func (w *Worker) heartbeat() {
+	hostname, _ := os.Hostname()
+	w.mu.Lock()
+	running := append([]string(nil), w.running...)
+	draining := w.drained
+	w.mu.Unlock()
+	free, total, err := diskUsage(os.TempDir())
+	if err != nil {
+		free, total = 0, 0
+	}
+	hb := types.Heartbeat{
+		WorkerID:       w.ID,
+		Token:          w.cfg.WorkerToken,
+		Hostname:       hostname,
+		Labels:         w.cfg.Labels,
+		RunningTasks:   running,
+		Draining:       draining,
+		DiskFreeBytes:  free,
+		DiskTotalBytes: total,
+		At:             time.Now().UTC(),
+	}
+	data, err := json.Marshal(hb)
+	if err != nil {
+		return
+	}
+	if err := w.conn.Publish(bus.SubjectHeartbeat, data); err != nil {
+		log.Printf("worker: publish heartbeat: %v", err)
+	}
+}
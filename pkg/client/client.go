@@ -0,0 +1,156 @@
+// Package client is the official Go SDK for the WebEncode kernel API.
+// It wraps the REST surface (jobs, streams, profiles, workers, files)
+// and the WebSocket event feed with auth handling and retries, so
+// external Go services and the CLI consume WebEncode without hand-rolled
+// HTTP.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Client talks to one kernel.
+type Client struct {
+	baseURL    string
+	apiKey     string
+	token      string
+	httpClient *http.Client
+	retries    int
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithAPIKey authenticates requests with an API key (wek_...).
+func WithAPIKey(key string) Option {
+	return func(c *Client) { c.apiKey = key }
+}
+
+// WithToken authenticates requests with a bearer token resolved by the
+// kernel's auth plugin.
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithHTTPClient replaces the default HTTP client.
+func WithHTTPClient(h *http.Client) Option {
+	return func(c *Client) { c.httpClient = h }
+}
+
+// WithRetries sets how many times transient failures (network errors,
+// 5xx) are retried. The default is 2.
+func WithRetries(n int) Option {
+	return func(c *Client) { c.retries = n }
+}
+
+// New returns a client for the kernel at baseURL, e.g.
+// "http://localhost:8686".
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		retries:    2,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// APIError is a non-2xx response from the kernel.
+type APIError struct {
+	Status  int
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("kernel returned %d: %s", e.Status, e.Message)
+}
+
+// do performs one API call, retrying transient failures with
+// exponential backoff. body is marshaled as JSON; a non-nil out
+// receives the decoded response.
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	backoff := 500 * time.Millisecond
+	var lastErr error
+	for attempt := 0; attempt <= c.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+		retryable, err := c.once(ctx, method, path, payload, out)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retryable {
+			return err
+		}
+	}
+	return lastErr
+}
+
+// once performs a single request and reports whether a failure is worth
+// retrying.
+func (c *Client) once(ctx context.Context, method, path string, payload []byte, out interface{}) (retryable bool, err error) {
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return false, err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	c.authenticate(req.Header)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		var envelope struct {
+			Error string `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&envelope)
+		if envelope.Error == "" {
+			envelope.Error = resp.Status
+		}
+		return resp.StatusCode >= 500, &APIError{Status: resp.StatusCode, Message: envelope.Error}
+	}
+	if out == nil {
+		return false, nil
+	}
+	return false, json.NewDecoder(resp.Body).Decode(out)
+}
+
+// authenticate sets the configured credentials on a request header.
+func (c *Client) authenticate(h http.Header) {
+	if c.apiKey != "" {
+		h.Set("X-API-Key", c.apiKey)
+		return
+	}
+	if c.token != "" {
+		h.Set("Authorization", "Bearer "+c.token)
+	}
+}
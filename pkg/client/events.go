@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Event is one entry from the kernel's event feed.
+type Event struct {
+	ID       string                 `json:"id"`
+	Seq      uint64                 `json:"seq"`
+	Type     string                 `json:"type"`
+	Resource string                 `json:"resource,omitempty"`
+	Message  string                 `json:"message,omitempty"`
+	Data     map[string]interface{} `json:"data,omitempty"`
+	At       time.Time              `json:"at"`
+}
+
+// wsEnvelope mirrors the kernel's WebSocket frame.
+type wsEnvelope struct {
+	Topic string `json:"topic"`
+	Event Event  `json:"event"`
+}
+
+// Watch opens the kernel's WebSocket event feed filtered to the given
+// topics (event-type prefixes; nil means all) and optional resource ID.
+// Events arrive on the returned channel until the context ends or the
+// connection drops, after which the channel closes.
+func (c *Client) Watch(ctx context.Context, topics []string, resource string) (<-chan Event, error) {
+	wsURL := strings.Replace(c.baseURL, "http", "ws", 1) + "/v1/ws"
+	header := make(map[string][]string)
+	c.authenticate(header)
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, wsURL, header)
+	if err != nil {
+		return nil, err
+	}
+	if resp != nil && resp.Body != nil {
+		resp.Body.Close()
+	}
+	if topics != nil || resource != "" {
+		sub, err := json.Marshal(map[string]interface{}{"topics": topics, "resource": resource})
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		if err := conn.WriteMessage(websocket.TextMessage, sub); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	ch := make(chan Event, 64)
+	go func() {
+		defer close(ch)
+		defer conn.Close()
+		go func() {
+			<-ctx.Done()
+			conn.Close()
+		}()
+		for {
+			var envelope wsEnvelope
+			if err := conn.ReadJSON(&envelope); err != nil {
+				return
+			}
+			select {
+			case ch <- envelope.Event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
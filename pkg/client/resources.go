@@ -0,0 +1,191 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// JobRequest is the body of SubmitJob; it mirrors POST /v1/jobs.
+type JobRequest struct {
+	Title        string             `json:"title,omitempty"`
+	SourceURL    string             `json:"source_url"`
+	OutputPrefix string             `json:"output_prefix,omitempty"`
+	ProfileIDs   []string           `json:"profile_ids"`
+	FanOut       []types.FanOutSpec `json:"fan_out,omitempty"`
+	Publish      *types.PublishSpec `json:"publish,omitempty"`
+}
+
+// SubmitJob creates and dispatches a transcode job.
+func (c *Client) SubmitJob(ctx context.Context, req JobRequest) (*types.Job, error) {
+	var job types.Job
+	if err := c.do(ctx, "POST", "/v1/jobs", req, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetJob fetches one job.
+func (c *Client) GetJob(ctx context.Context, jobID string) (*types.Job, error) {
+	var job types.Job
+	if err := c.do(ctx, "GET", "/v1/jobs/"+url.PathEscape(jobID), nil, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ListJobs lists the jobs visible to the caller.
+func (c *Client) ListJobs(ctx context.Context) ([]*types.Job, error) {
+	var jobs []*types.Job
+	if err := c.do(ctx, "GET", "/v1/jobs", nil, &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// ListJobTasks lists a job's tasks.
+func (c *Client) ListJobTasks(ctx context.Context, jobID string) ([]*types.Task, error) {
+	var tasks []*types.Task
+	if err := c.do(ctx, "GET", "/v1/jobs/"+url.PathEscape(jobID)+"/tasks", nil, &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// StreamRequest is the body of CreateStream; it mirrors POST
+// /v1/streams.
+type StreamRequest struct {
+	Title     string `json:"title"`
+	SourceURL string `json:"source_url"`
+	Archive   bool   `json:"archive"`
+}
+
+// CreateStream registers a live stream.
+func (c *Client) CreateStream(ctx context.Context, req StreamRequest) (*types.Stream, error) {
+	var stream types.Stream
+	if err := c.do(ctx, "POST", "/v1/streams", req, &stream); err != nil {
+		return nil, err
+	}
+	return &stream, nil
+}
+
+// GetStream fetches one stream.
+func (c *Client) GetStream(ctx context.Context, streamID string) (*types.Stream, error) {
+	var stream types.Stream
+	if err := c.do(ctx, "GET", "/v1/streams/"+url.PathEscape(streamID), nil, &stream); err != nil {
+		return nil, err
+	}
+	return &stream, nil
+}
+
+// ListStreams lists the streams visible to the caller.
+func (c *Client) ListStreams(ctx context.Context) ([]*types.Stream, error) {
+	var streams []*types.Stream
+	if err := c.do(ctx, "GET", "/v1/streams", nil, &streams); err != nil {
+		return nil, err
+	}
+	return streams, nil
+}
+
+// StartStream marks a stream live.
+func (c *Client) StartStream(ctx context.Context, streamID string) error {
+	return c.do(ctx, "POST", "/v1/streams/"+url.PathEscape(streamID)+"/start", nil, nil)
+}
+
+// StopStream marks a stream ended.
+func (c *Client) StopStream(ctx context.Context, streamID string) error {
+	return c.do(ctx, "POST", "/v1/streams/"+url.PathEscape(streamID)+"/stop", nil, nil)
+}
+
+// DeleteStream removes a stream.
+func (c *Client) DeleteStream(ctx context.Context, streamID string) error {
+	return c.do(ctx, "DELETE", "/v1/streams/"+url.PathEscape(streamID), nil, nil)
+}
+
+// ListProfiles lists encoding profiles.
+func (c *Client) ListProfiles(ctx context.Context) ([]*types.Profile, error) {
+	var profiles []*types.Profile
+	if err := c.do(ctx, "GET", "/v1/profiles", nil, &profiles); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// CreateProfile registers an encoding profile.
+func (c *Client) CreateProfile(ctx context.Context, p types.Profile) (*types.Profile, error) {
+	var created types.Profile
+	if err := c.do(ctx, "POST", "/v1/profiles", p, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// ListWorkers lists known worker nodes.
+func (c *Client) ListWorkers(ctx context.Context) ([]*types.Worker, error) {
+	var workers []*types.Worker
+	if err := c.do(ctx, "GET", "/v1/workers", nil, &workers); err != nil {
+		return nil, err
+	}
+	return workers, nil
+}
+
+// BrowseFiles lists storage objects under a prefix.
+func (c *Client) BrowseFiles(ctx context.Context, prefix string) ([]map[string]interface{}, error) {
+	var objects []map[string]interface{}
+	path := "/v1/files"
+	if prefix != "" {
+		path += "?path=" + url.QueryEscape(prefix)
+	}
+	if err := c.do(ctx, "GET", path, nil, &objects); err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+// EncodeFileRequest is the body of EncodeFile; it mirrors POST
+// /v1/files/encode.
+type EncodeFileRequest struct {
+	Path         string   `json:"path"`
+	ProfileIDs   []string `json:"profile_ids"`
+	Title        string   `json:"title,omitempty"`
+	OutputPrefix string   `json:"output_prefix,omitempty"`
+}
+
+// EncodeFile submits a job straight from a browsed storage path.
+func (c *Client) EncodeFile(ctx context.Context, req EncodeFileRequest) (*types.Job, error) {
+	var job types.Job
+	if err := c.do(ctx, "POST", "/v1/files/encode", req, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// WatchJob polls a job until it reaches a terminal status or the
+// context ends, reporting each status change through onChange (which
+// may be nil).
+func (c *Client) WatchJob(ctx context.Context, jobID string, onChange func(*types.Job)) (*types.Job, error) {
+	var last types.JobStatus
+	for {
+		job, err := c.GetJob(ctx, jobID)
+		if err != nil {
+			return nil, err
+		}
+		if job.Status != last {
+			last = job.Status
+			if onChange != nil {
+				onChange(job)
+			}
+		}
+		switch job.Status {
+		case types.JobStatusCompleted, types.JobStatusFailed:
+			return job, nil
+		}
+		select {
+		case <-ctx.Done():
+			return job, ctx.Err()
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// Package errcode is the catalog of machine-readable WebEncode error
+// codes. API error responses and error events carry one of these codes
+// next to the human-readable message, so UIs and webhook consumers
+// branch on the code instead of parsing strings.
+package errcode
+
+// Code identifies one catalog entry, e.g. "WE-JOB-004".
+type Code string
+
+const (
+	// General codes, derived from the HTTP status when a handler did
+	// not attach a more specific one.
+	Unknown       Code = "WE-GEN-000"
+	Invalid       Code = "WE-GEN-001"
+	NotFound      Code = "WE-GEN-002"
+	Forbidden     Code = "WE-GEN-003"
+	Conflict      Code = "WE-GEN-004"
+	RateLimited   Code = "WE-GEN-005"
+	Unprocessable Code = "WE-GEN-006"
+
+	// Job lifecycle codes.
+	JobNotTerminal    Code = "WE-JOB-001"
+	QuotaExceeded     Code = "WE-JOB-002"
+	UnknownProfile    Code = "WE-JOB-003"
+	SourceUnreachable Code = "WE-JOB-004"
+
+	// Task failure codes, mirroring the worker error classes.
+	DiskFull     Code = "WE-TASK-001"
+	GPUError     Code = "WE-TASK-002"
+	IOError      Code = "WE-TASK-003"
+	EncodeError  Code = "WE-TASK-004"
+	WorkerLost   Code = "WE-TASK-005"
+	TaskFailed   Code = "WE-TASK-006"
+	TaskOverrun  Code = "WE-TASK-007"
+	DeadLettered Code = "WE-TASK-008"
+)
+
+// Error pairs a catalog code with a human-readable message. Handlers
+// return one (or wrap an existing error in one) when a specific code
+// applies; the response layer extracts the code with errors.As.
+type Error struct {
+	Code    Code
+	Message string
+	// Cause is the wrapped error, if any.
+	Cause error
+}
+
+// New returns a coded error with the given message.
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Wrap attaches a code to an existing error, keeping its message.
+func Wrap(code Code, err error) *Error {
+	return &Error{Code: code, Message: err.Error(), Cause: err}
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// Unwrap exposes the cause to errors.Is and errors.As.
+func (e *Error) Unwrap() error { return e.Cause }
+
+// FromErrorClass maps a worker error class to its catalog code.
+func FromErrorClass(class string) Code {
+	switch class {
+	case "disk_full":
+		return DiskFull
+	case "gpu_error":
+		return GPUError
+	case "io_error":
+		return IOError
+	case "encode_error":
+		return EncodeError
+	case "source_unreachable":
+		return SourceUnreachable
+	default:
+		return TaskFailed
+	}
+}
+
+// FromStatus maps an HTTP status to the general code used when no
+// specific one was attached.
+func FromStatus(status int) Code {
+	switch status {
+	case 400:
+		return Invalid
+	case 403:
+		return Forbidden
+	case 404:
+		return NotFound
+	case 409:
+		return Conflict
+	case 422:
+		return Unprocessable
+	case 429:
+		return RateLimited
+	default:
+		return Unknown
+	}
+}
+
+// Entry describes one catalog code for the catalog listing.
+type Entry struct {
+	Code        Code   `json:"code"`
+	Description string `json:"description"`
+}
+
+// Catalog lists every code with its meaning, in catalog order.
+func Catalog() []Entry {
+	return []Entry{
+		{Unknown, "unclassified error"},
+		{Invalid, "malformed or invalid request"},
+		{NotFound, "resource not found"},
+		{Forbidden, "not authorized for this action"},
+		{Conflict, "request conflicts with current state or configuration"},
+		{RateLimited, "rate limit exceeded"},
+		{Unprocessable, "request understood but not processable"},
+		{JobNotTerminal, "job is not in a terminal state"},
+		{QuotaExceeded, "per-user quota exceeded"},
+		{UnknownProfile, "job references an unknown profile"},
+		{SourceUnreachable, "source could not be fetched"},
+		{DiskFull, "worker ran out of disk space"},
+		{GPUError, "worker GPU failure"},
+		{IOError, "worker I/O failure"},
+		{EncodeError, "encoder failed"},
+		{WorkerLost, "worker stopped heartbeating while the task ran"},
+		{TaskFailed, "task failed without a more specific class"},
+		{TaskOverrun, "task exceeded its runtime cap"},
+		{DeadLettered, "task exhausted its retries"},
+	}
+}
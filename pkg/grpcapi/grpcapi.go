@@ -0,0 +1,226 @@
+// Package grpcapi is the kernel's versioned gRPC surface. The service
+// is defined in Go rather than protobuf — messages reuse pkg/types and
+// travel through a JSON codec — so backend services and the CLI share
+// the exact REST types without a protoc toolchain. The wire contract is
+// versioned through the service name (webencode.v1.Kernel); breaking
+// changes get a new version, same as the REST prefix.
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+
+	"github.com/rennerdo30/webencode/pkg/types"
+)
+
+// ServiceName is the fully qualified gRPC service name.
+const ServiceName = "webencode.v1.Kernel"
+
+// CodecName is the content-subtype clients must request
+// (grpc.CallContentSubtype(CodecName)).
+const CodecName = "json"
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+// jsonCodec marshals gRPC messages as JSON.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+func (jsonCodec) Name() string { return CodecName }
+
+// SubmitJobRequest creates and dispatches a job.
+type SubmitJobRequest struct {
+	Job types.Job `json:"job"`
+}
+
+// GetJobRequest fetches one job.
+type GetJobRequest struct {
+	JobID string `json:"job_id"`
+}
+
+// ListJobsRequest lists jobs, optionally scoped to one owner.
+type ListJobsRequest struct {
+	UserID string `json:"user_id,omitempty"`
+}
+
+// ListJobsResponse carries the job list.
+type ListJobsResponse struct {
+	Jobs []*types.Job `json:"jobs"`
+}
+
+// CreateStreamRequest registers a live stream.
+type CreateStreamRequest struct {
+	UserID    string `json:"user_id,omitempty"`
+	Title     string `json:"title"`
+	SourceURL string `json:"source_url"`
+	Archive   bool   `json:"archive,omitempty"`
+}
+
+// ListStreamsRequest lists streams, optionally scoped to one owner.
+type ListStreamsRequest struct {
+	UserID string `json:"user_id,omitempty"`
+}
+
+// ListStreamsResponse carries the stream list.
+type ListStreamsResponse struct {
+	Streams []*types.Stream `json:"streams"`
+}
+
+// GetWorkerRequest fetches one worker.
+type GetWorkerRequest struct {
+	WorkerID string `json:"worker_id"`
+}
+
+// ListWorkersRequest lists all known workers.
+type ListWorkersRequest struct{}
+
+// ListWorkersResponse carries the worker list.
+type ListWorkersResponse struct {
+	Workers []*types.Worker `json:"workers"`
+}
+
+// KernelServer is implemented by the kernel's gRPC endpoint.
+type KernelServer interface {
+	SubmitJob(ctx context.Context, req *SubmitJobRequest) (*types.Job, error)
+	GetJob(ctx context.Context, req *GetJobRequest) (*types.Job, error)
+	ListJobs(ctx context.Context, req *ListJobsRequest) (*ListJobsResponse, error)
+	CreateStream(ctx context.Context, req *CreateStreamRequest) (*types.Stream, error)
+	ListStreams(ctx context.Context, req *ListStreamsRequest) (*ListStreamsResponse, error)
+	GetWorker(ctx context.Context, req *GetWorkerRequest) (*types.Worker, error)
+	ListWorkers(ctx context.Context, req *ListWorkersRequest) (*ListWorkersResponse, error)
+}
+
+// RegisterKernelServer registers impl on a grpc.Server.
+func RegisterKernelServer(s *grpc.Server, impl KernelServer) {
+	s.RegisterService(&kernelServiceDesc, impl)
+}
+
+// unaryHandler adapts one typed method into a grpc.ServiceDesc handler.
+func unaryHandler[Req any, Resp any](
+	method string,
+	call func(KernelServer, context.Context, *Req) (Resp, error),
+) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: method,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			req := new(Req)
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+			if interceptor == nil {
+				return call(srv.(KernelServer), ctx, req)
+			}
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + ServiceName + "/" + method}
+			return interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return call(srv.(KernelServer), ctx, req.(*Req))
+			})
+		},
+	}
+}
+
+var kernelServiceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*KernelServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryHandler("SubmitJob", func(s KernelServer, ctx context.Context, req *SubmitJobRequest) (*types.Job, error) {
+			return s.SubmitJob(ctx, req)
+		}),
+		unaryHandler("GetJob", func(s KernelServer, ctx context.Context, req *GetJobRequest) (*types.Job, error) {
+			return s.GetJob(ctx, req)
+		}),
+		unaryHandler("ListJobs", func(s KernelServer, ctx context.Context, req *ListJobsRequest) (*ListJobsResponse, error) {
+			return s.ListJobs(ctx, req)
+		}),
+		unaryHandler("CreateStream", func(s KernelServer, ctx context.Context, req *CreateStreamRequest) (*types.Stream, error) {
+			return s.CreateStream(ctx, req)
+		}),
+		unaryHandler("ListStreams", func(s KernelServer, ctx context.Context, req *ListStreamsRequest) (*ListStreamsResponse, error) {
+			return s.ListStreams(ctx, req)
+		}),
+		unaryHandler("GetWorker", func(s KernelServer, ctx context.Context, req *GetWorkerRequest) (*types.Worker, error) {
+			return s.GetWorker(ctx, req)
+		}),
+		unaryHandler("ListWorkers", func(s KernelServer, ctx context.Context, req *ListWorkersRequest) (*ListWorkersResponse, error) {
+			return s.ListWorkers(ctx, req)
+		}),
+	},
+}
+
+// KernelClient calls the kernel's gRPC service.
+type KernelClient struct {
+	conn *grpc.ClientConn
+}
+
+// NewKernelClient wraps an established connection.
+func NewKernelClient(conn *grpc.ClientConn) *KernelClient {
+	return &KernelClient{conn: conn}
+}
+
+// invoke performs one unary call with the JSON codec.
+func (c *KernelClient) invoke(ctx context.Context, method string, req, resp interface{}) error {
+	return c.conn.Invoke(ctx, "/"+ServiceName+"/"+method, req, resp, grpc.CallContentSubtype(CodecName))
+}
+
+func (c *KernelClient) SubmitJob(ctx context.Context, req *SubmitJobRequest) (*types.Job, error) {
+	var job types.Job
+	if err := c.invoke(ctx, "SubmitJob", req, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (c *KernelClient) GetJob(ctx context.Context, req *GetJobRequest) (*types.Job, error) {
+	var job types.Job
+	if err := c.invoke(ctx, "GetJob", req, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (c *KernelClient) ListJobs(ctx context.Context, req *ListJobsRequest) (*ListJobsResponse, error) {
+	var resp ListJobsResponse
+	if err := c.invoke(ctx, "ListJobs", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *KernelClient) CreateStream(ctx context.Context, req *CreateStreamRequest) (*types.Stream, error) {
+	var stream types.Stream
+	if err := c.invoke(ctx, "CreateStream", req, &stream); err != nil {
+		return nil, err
+	}
+	return &stream, nil
+}
+
+func (c *KernelClient) ListStreams(ctx context.Context, req *ListStreamsRequest) (*ListStreamsResponse, error) {
+	var resp ListStreamsResponse
+	if err := c.invoke(ctx, "ListStreams", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *KernelClient) GetWorker(ctx context.Context, req *GetWorkerRequest) (*types.Worker, error) {
+	var worker types.Worker
+	if err := c.invoke(ctx, "GetWorker", req, &worker); err != nil {
+		return nil, err
+	}
+	return &worker, nil
+}
+
+func (c *KernelClient) ListWorkers(ctx context.Context, req *ListWorkersRequest) (*ListWorkersResponse, error) {
+	var resp ListWorkersResponse
+	if err := c.invoke(ctx, "ListWorkers", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
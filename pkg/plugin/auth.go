@@ -0,0 +1,104 @@
+package plugin
+
+import (
+	"errors"
+	"net/rpc"
+	"strings"
+
+	goplugin "github.com/hashicorp/go-plugin"
+)
+
+// Identity is the authenticated user an auth plugin resolves a token
+// to. Role is one of the kernel's coarse roles ("admin", "user").
+type Identity struct {
+	ID       string
+	Username string
+	Email    string
+	Role     string
+	Labels   map[string]string
+}
+
+// ErrInvalidToken is returned by auth plugins for tokens that are
+// expired, revoked, or otherwise not valid — as opposed to the plugin
+// itself failing.
+var ErrInvalidToken = errors.New("invalid token")
+
+// IsInvalidToken reports whether err is the invalid-token sentinel,
+// including after a trip through the plugin RPC boundary.
+func IsInvalidToken(err error) bool {
+	return err != nil && strings.Contains(err.Error(), ErrInvalidToken.Error())
+}
+
+// AuthService is implemented by plugins that turn bearer tokens into
+// WebEncode identities (OAuth providers, LDAP, SSO gateways, ...).
+type AuthService interface {
+	Name() (string, error)
+	// Authenticate resolves a bearer token to an identity. Tokens that
+	// fail validation return ErrInvalidToken.
+	Authenticate(token string) (*Identity, error)
+}
+
+// ServeAuth runs an auth plugin. It never returns.
+func ServeAuth(impl AuthService) {
+	goplugin.Serve(&goplugin.ServeConfig{
+		HandshakeConfig: Handshake,
+		Plugins: map[string]goplugin.Plugin{
+			"auth":   &AuthPlugin{Impl: impl},
+			"health": &HealthPlugin{Impl: healthImpl(impl)},
+			"config": &ConfigPlugin{Impl: configImpl(impl)},
+			"info":   &InfoPlugin{Impl: infoImpl(impl, "auth")},
+		},
+	})
+}
+
+// AuthPlugin wires AuthService into go-plugin.
+type AuthPlugin struct {
+	Impl AuthService
+}
+
+// Server implements goplugin.Plugin.
+func (p *AuthPlugin) Server(*goplugin.MuxBroker) (interface{}, error) {
+	return &authRPCServer{impl: p.Impl}, nil
+}
+
+// Client implements goplugin.Plugin.
+func (p *AuthPlugin) Client(_ *goplugin.MuxBroker, c *rpc.Client) (interface{}, error) {
+	return &authRPCClient{client: c}, nil
+}
+
+type authRPCServer struct {
+	impl AuthService
+}
+
+func (s *authRPCServer) Name(_ struct{}, resp *string) error {
+	name, err := s.impl.Name()
+	*resp = name
+	return err
+}
+
+func (s *authRPCServer) Authenticate(token string, resp *Identity) error {
+	id, err := s.impl.Authenticate(token)
+	if err != nil {
+		return err
+	}
+	*resp = *id
+	return nil
+}
+
+type authRPCClient struct {
+	client *rpc.Client
+}
+
+func (c *authRPCClient) Name() (string, error) {
+	var name string
+	err := c.client.Call("Plugin.Name", struct{}{}, &name)
+	return name, err
+}
+
+func (c *authRPCClient) Authenticate(token string) (*Identity, error) {
+	var id Identity
+	if err := c.client.Call("Plugin.Authenticate", token, &id); err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
@@ -0,0 +1,89 @@
+package plugin
+
+import (
+	"net/rpc"
+
+	goplugin "github.com/hashicorp/go-plugin"
+)
+
+// ConfigField describes one configuration knob a plugin accepts, typed
+// so the kernel can validate values and UIs can generate forms.
+type ConfigField struct {
+	Name string
+	// Type is "string", "int", "float", "bool" or "duration".
+	Type        string
+	Description string
+	// Default is the value applied when unset, rendered as a string.
+	Default  string
+	Required bool
+	// Secret marks values that must be redacted when read back.
+	Secret bool
+}
+
+// Configurable is optionally implemented by plugin services that accept
+// kernel-managed configuration instead of reading env vars directly.
+type Configurable interface {
+	// ConfigSchema declares the fields the plugin accepts.
+	ConfigSchema() ([]ConfigField, error)
+	// Configure applies a set of values the kernel has validated
+	// against the schema.
+	Configure(values map[string]string) error
+}
+
+// nopConfig is the default for services without their own
+// configuration: an empty schema and a no-op apply.
+type nopConfig struct{}
+
+func (nopConfig) ConfigSchema() ([]ConfigField, error) { return nil, nil }
+func (nopConfig) Configure(map[string]string) error    { return nil }
+
+// configImpl returns the service's own Configurable when it has one.
+func configImpl(impl interface{}) Configurable {
+	if c, ok := impl.(Configurable); ok {
+		return c
+	}
+	return nopConfig{}
+}
+
+// ConfigPlugin wires Configurable into go-plugin.
+type ConfigPlugin struct {
+	Impl Configurable
+}
+
+// Server implements goplugin.Plugin.
+func (p *ConfigPlugin) Server(*goplugin.MuxBroker) (interface{}, error) {
+	return &configRPCServer{impl: p.Impl}, nil
+}
+
+// Client implements goplugin.Plugin.
+func (p *ConfigPlugin) Client(_ *goplugin.MuxBroker, c *rpc.Client) (interface{}, error) {
+	return &configRPCClient{client: c}, nil
+}
+
+type configRPCServer struct {
+	impl Configurable
+}
+
+func (s *configRPCServer) ConfigSchema(_ struct{}, resp *[]ConfigField) error {
+	fields, err := s.impl.ConfigSchema()
+	*resp = fields
+	return err
+}
+
+func (s *configRPCServer) Configure(values map[string]string, _ *struct{}) error {
+	return s.impl.Configure(values)
+}
+
+type configRPCClient struct {
+	client *rpc.Client
+}
+
+func (c *configRPCClient) ConfigSchema() ([]ConfigField, error) {
+	var fields []ConfigField
+	err := c.client.Call("Plugin.ConfigSchema", struct{}{}, &fields)
+	return fields, err
+}
+
+func (c *configRPCClient) Configure(values map[string]string) error {
+	return c.client.Call("Plugin.Configure", values, &struct{}{})
+}
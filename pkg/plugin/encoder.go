@@ -0,0 +1,141 @@
+package plugin
+
+import (
+	"net/rpc"
+
+	goplugin "github.com/hashicorp/go-plugin"
+)
+
+// EncodeSpec describes one transcode for a remote encoder backend. It
+// is a flattened subset of a profile — remote services accept far fewer
+// knobs than ffmpeg, so plugins map what they support and reject specs
+// they cannot honour.
+type EncodeSpec struct {
+	// SourceURL is where the backend fetches the input (s3://,
+	// https://).
+	SourceURL string
+	// OutputPrefix is the destination the backend writes outputs under
+	// (e.g. s3://bucket/prefix).
+	OutputPrefix string
+
+	VideoCodec       string
+	AudioCodec       string
+	Width            int
+	Height           int
+	VideoBitrateKbps int
+	AudioBitrateKbps int
+	Framerate        float64
+	Container        string
+}
+
+// EncodeStatus reports a remote encode's progress.
+type EncodeStatus struct {
+	ID string
+	// State is "submitted", "progressing", "complete", "canceled" or
+	// "error".
+	State string
+	// ProgressPct is 0-100 where the backend reports it.
+	ProgressPct int
+	// Error is the backend's failure message for the error state.
+	Error string
+	// OutputURLs lists produced files once complete, when the backend
+	// reports them.
+	OutputURLs []string
+}
+
+// EncoderService is implemented by plugins that offload transcodes to
+// an external service (AWS MediaConvert, ...): submit, then poll.
+type EncoderService interface {
+	Name() (string, error)
+	// Submit starts a remote encode and returns the backend's job ID.
+	Submit(spec EncodeSpec) (string, error)
+	// Status polls a previously submitted encode.
+	Status(id string) (*EncodeStatus, error)
+	// Cancel aborts a running encode.
+	Cancel(id string) error
+}
+
+// ServeEncoder runs an encoder plugin. It never returns.
+func ServeEncoder(impl EncoderService) {
+	goplugin.Serve(&goplugin.ServeConfig{
+		HandshakeConfig: Handshake,
+		Plugins: map[string]goplugin.Plugin{
+			"encoder": &EncoderPlugin{Impl: impl},
+			"health":  &HealthPlugin{Impl: healthImpl(impl)},
+			"config":  &ConfigPlugin{Impl: configImpl(impl)},
+			"info":    &InfoPlugin{Impl: infoImpl(impl, "encoder")},
+		},
+	})
+}
+
+// EncoderPlugin wires EncoderService into go-plugin.
+type EncoderPlugin struct {
+	Impl EncoderService
+}
+
+// Server implements goplugin.Plugin.
+func (p *EncoderPlugin) Server(*goplugin.MuxBroker) (interface{}, error) {
+	return &encoderRPCServer{impl: p.Impl}, nil
+}
+
+// Client implements goplugin.Plugin.
+func (p *EncoderPlugin) Client(_ *goplugin.MuxBroker, c *rpc.Client) (interface{}, error) {
+	return &encoderRPCClient{client: c}, nil
+}
+
+type encoderRPCServer struct {
+	impl EncoderService
+}
+
+func (s *encoderRPCServer) Name(_ struct{}, resp *string) error {
+	name, err := s.impl.Name()
+	*resp = name
+	return err
+}
+
+func (s *encoderRPCServer) Submit(spec EncodeSpec, resp *string) error {
+	id, err := s.impl.Submit(spec)
+	*resp = id
+	return err
+}
+
+func (s *encoderRPCServer) Status(id string, resp *EncodeStatus) error {
+	st, err := s.impl.Status(id)
+	if err != nil {
+		return err
+	}
+	*resp = *st
+	return nil
+}
+
+func (s *encoderRPCServer) Cancel(id string, _ *struct{}) error {
+	return s.impl.Cancel(id)
+}
+
+type encoderRPCClient struct {
+	client *rpc.Client
+}
+
+func (c *encoderRPCClient) Name() (string, error) {
+	var name string
+	err := c.client.Call("Plugin.Name", struct{}{}, &name)
+	return name, err
+}
+
+func (c *encoderRPCClient) Submit(spec EncodeSpec) (string, error) {
+	var id string
+	err := c.client.Call("Plugin.Submit", spec, &id)
+	return id, err
+}
+
+func (c *encoderRPCClient) Status(id string) (*EncodeStatus, error) {
+	var st EncodeStatus
+	if err := c.client.Call("Plugin.Status", id, &st); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+func (c *encoderRPCClient) Cancel(id string) error {
+	return c.client.Call("Plugin.Cancel", id, &struct{}{})
+}
@@ -0,0 +1,133 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+
+	goplugin "github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+// Publisher plugins may alternatively speak gRPC, so they can be
+// written in any language go-plugin supports (Python, Node, ...). The
+// service is defined in Go with a JSON codec — the same
+// no-protoc-toolchain approach as pkg/grpcapi — and the wire contract
+// is documented in docs/plugin-sdk.md. Go plugins keep using net/rpc
+// via ServePublisher; the kernel accepts either protocol.
+
+// GRPCPublisherService is the fully qualified gRPC service name a
+// non-Go publisher plugin must register.
+const GRPCPublisherService = "webencode.plugin.v1.Publisher"
+
+// GRPCCodecName is the content-subtype plugin servers must accept and
+// clients request: plain JSON message bodies.
+const GRPCCodecName = "json"
+
+func init() {
+	encoding.RegisterCodec(pluginJSONCodec{})
+}
+
+// pluginJSONCodec marshals plugin gRPC messages as JSON.
+type pluginJSONCodec struct{}
+
+func (pluginJSONCodec) Marshal(v interface{}) ([]byte, error) { return json.Marshal(v) }
+func (pluginJSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+func (pluginJSONCodec) Name() string { return GRPCCodecName }
+
+// GRPCServer implements goplugin.GRPCPlugin; it registers the publisher
+// service on the plugin-side server (used when a Go publisher opts into
+// gRPC).
+func (p *PublisherPlugin) GRPCServer(_ *goplugin.MuxBroker, s *grpc.Server) error {
+	s.RegisterService(&publisherGRPCDesc, p.Impl)
+	return nil
+}
+
+// GRPCClient implements goplugin.GRPCPlugin; the kernel uses it to talk
+// to publishers that advertised the gRPC protocol in their handshake.
+func (p *PublisherPlugin) GRPCClient(_ context.Context, _ *goplugin.MuxBroker, conn *grpc.ClientConn) (interface{}, error) {
+	return &publisherGRPCClient{conn: conn}, nil
+}
+
+// nameResponse carries a Name reply; a bare string is not a valid JSON
+// message for every gRPC implementation.
+type nameResponse struct {
+	Name string
+}
+
+// publisherUnary adapts one typed method into a grpc.ServiceDesc
+// handler, mirroring grpcapi's unaryHandler.
+func publisherUnary[Req any, Resp any](
+	method string,
+	call func(PublisherService, *Req) (Resp, error),
+) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: method,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			req := new(Req)
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+			if interceptor == nil {
+				return call(srv.(PublisherService), req)
+			}
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + GRPCPublisherService + "/" + method}
+			return interceptor(ctx, req, info, func(_ context.Context, req interface{}) (interface{}, error) {
+				return call(srv.(PublisherService), req.(*Req))
+			})
+		},
+	}
+}
+
+var publisherGRPCDesc = grpc.ServiceDesc{
+	ServiceName: GRPCPublisherService,
+	HandlerType: (*PublisherService)(nil),
+	Methods: []grpc.MethodDesc{
+		publisherUnary("Name", func(s PublisherService, _ *struct{}) (*nameResponse, error) {
+			name, err := s.Name()
+			if err != nil {
+				return nil, err
+			}
+			return &nameResponse{Name: name}, nil
+		}),
+		publisherUnary("Publish", func(s PublisherService, req *PublishRequest) (*PublishResult, error) {
+			return s.Publish(*req)
+		}),
+		publisherUnary("SetChatSettings", func(s PublisherService, req *ChatSettingsArgs) (*struct{}, error) {
+			if err := s.SetChatSettings(req.ChannelID, req.Settings); err != nil {
+				return nil, err
+			}
+			return &struct{}{}, nil
+		}),
+	},
+}
+
+// publisherGRPCClient adapts a plugin's gRPC connection to
+// PublisherService.
+type publisherGRPCClient struct {
+	conn *grpc.ClientConn
+}
+
+func (c *publisherGRPCClient) invoke(method string, req, resp interface{}) error {
+	return c.conn.Invoke(context.Background(), "/"+GRPCPublisherService+"/"+method, req, resp, grpc.CallContentSubtype(GRPCCodecName))
+}
+
+func (c *publisherGRPCClient) Name() (string, error) {
+	var resp nameResponse
+	err := c.invoke("Name", &struct{}{}, &resp)
+	return resp.Name, err
+}
+
+func (c *publisherGRPCClient) Publish(req PublishRequest) (*PublishResult, error) {
+	var out PublishResult
+	if err := c.invoke("Publish", &req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *publisherGRPCClient) SetChatSettings(channelID string, settings ChatSettings) error {
+	return c.invoke("SetChatSettings", &ChatSettingsArgs{ChannelID: channelID, Settings: settings}, &struct{}{})
+}
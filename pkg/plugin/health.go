@@ -0,0 +1,61 @@
+package plugin
+
+import (
+	"net/rpc"
+
+	goplugin "github.com/hashicorp/go-plugin"
+)
+
+// HealthChecker is optionally implemented by plugin services that can
+// verify their backend is usable (credentials valid, bucket reachable,
+// upstream responding). Every plugin binary serves the health RPC; for
+// services that do not implement HealthChecker, a responsive subprocess
+// counts as healthy.
+type HealthChecker interface {
+	HealthCheck() error
+}
+
+// nopHealth is the default health implementation for services without
+// their own check.
+type nopHealth struct{}
+
+func (nopHealth) HealthCheck() error { return nil }
+
+// healthImpl returns the service's own checker when it has one.
+func healthImpl(impl interface{}) HealthChecker {
+	if hc, ok := impl.(HealthChecker); ok {
+		return hc
+	}
+	return nopHealth{}
+}
+
+// HealthPlugin wires HealthChecker into go-plugin.
+type HealthPlugin struct {
+	Impl HealthChecker
+}
+
+// Server implements goplugin.Plugin.
+func (p *HealthPlugin) Server(*goplugin.MuxBroker) (interface{}, error) {
+	return &healthRPCServer{impl: p.Impl}, nil
+}
+
+// Client implements goplugin.Plugin.
+func (p *HealthPlugin) Client(_ *goplugin.MuxBroker, c *rpc.Client) (interface{}, error) {
+	return &healthRPCClient{client: c}, nil
+}
+
+type healthRPCServer struct {
+	impl HealthChecker
+}
+
+func (s *healthRPCServer) HealthCheck(_ struct{}, _ *struct{}) error {
+	return s.impl.HealthCheck()
+}
+
+type healthRPCClient struct {
+	client *rpc.Client
+}
+
+func (c *healthRPCClient) HealthCheck() error {
+	return c.client.Call("Plugin.HealthCheck", struct{}{}, &struct{}{})
+}
@@ -0,0 +1,137 @@
+package plugin
+
+import (
+	"net/rpc"
+	"strings"
+
+	goplugin "github.com/hashicorp/go-plugin"
+)
+
+// Capability is a bitmask of the features a plugin implements; the
+// kernel refuses plugins that do not declare the capability their kind
+// requires instead of failing on the first RPC.
+type Capability uint64
+
+const (
+	CapStorage Capability = 1 << iota
+	CapPresign
+	CapPublish
+	CapChatControls
+	CapLive
+	CapAuth
+	CapHealth
+	CapConfig
+	CapEncode
+)
+
+// PluginInfo identifies a plugin build and what it can do.
+type PluginInfo struct {
+	// Name is the binary's <kind>-<impl> identifier.
+	Name string
+	// Version is the plugin's own semver.
+	Version string
+	// ProtoVersion is the SDK protocol version the plugin was built
+	// against; it must match Handshake.ProtocolVersion.
+	ProtoVersion int
+	// Capabilities declares the implemented feature set.
+	Capabilities Capability
+}
+
+// InfoProvider is optionally implemented by plugin services to report
+// their build and capabilities; services that do not implement it get
+// a default derived from their kind.
+type InfoProvider interface {
+	PluginInfo() (*PluginInfo, error)
+}
+
+// defaultInfo synthesizes PluginInfo for services without their own,
+// declaring the baseline capabilities every SDK-built plugin of that
+// kind has.
+type defaultInfo struct {
+	kind string
+}
+
+// kindCapabilities maps a service kind to the capabilities its SDK
+// interface implies.
+var kindCapabilities = map[string]Capability{
+	"storage":   CapStorage | CapPresign,
+	"publisher": CapPublish | CapChatControls,
+	"live":      CapLive,
+	"auth":      CapAuth,
+	"encoder":   CapEncode,
+}
+
+func (d defaultInfo) PluginInfo() (*PluginInfo, error) {
+	return &PluginInfo{
+		Version:      "0.0.0",
+		ProtoVersion: int(Handshake.ProtocolVersion),
+		Capabilities: kindCapabilities[d.kind] | CapHealth | CapConfig,
+	}, nil
+}
+
+// infoImpl returns the service's own InfoProvider when it has one.
+func infoImpl(impl interface{}, kind string) InfoProvider {
+	if p, ok := impl.(InfoProvider); ok {
+		return p
+	}
+	return defaultInfo{kind: kind}
+}
+
+// RequiredCapability returns the capability a plugin of the given kind
+// must declare; names follow the <kind>-<impl> binary convention.
+func RequiredCapability(name string) Capability {
+	kind, _, _ := strings.Cut(name, "-")
+	switch kind {
+	case "storage":
+		return CapStorage
+	case "publisher":
+		return CapPublish
+	case "live":
+		return CapLive
+	case "auth":
+		return CapAuth
+	case "encoder":
+		return CapEncode
+	}
+	return 0
+}
+
+// InfoPlugin wires InfoProvider into go-plugin.
+type InfoPlugin struct {
+	Impl InfoProvider
+}
+
+// Server implements goplugin.Plugin.
+func (p *InfoPlugin) Server(*goplugin.MuxBroker) (interface{}, error) {
+	return &infoRPCServer{impl: p.Impl}, nil
+}
+
+// Client implements goplugin.Plugin.
+func (p *InfoPlugin) Client(_ *goplugin.MuxBroker, c *rpc.Client) (interface{}, error) {
+	return &infoRPCClient{client: c}, nil
+}
+
+type infoRPCServer struct {
+	impl InfoProvider
+}
+
+func (s *infoRPCServer) PluginInfo(_ struct{}, resp *PluginInfo) error {
+	info, err := s.impl.PluginInfo()
+	if err != nil {
+		return err
+	}
+	*resp = *info
+	return nil
+}
+
+type infoRPCClient struct {
+	client *rpc.Client
+}
+
+func (c *infoRPCClient) PluginInfo() (*PluginInfo, error) {
+	var info PluginInfo
+	if err := c.client.Call("Plugin.PluginInfo", struct{}{}, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
@@ -0,0 +1,147 @@
+package plugin
+
+import (
+	"net/rpc"
+
+	goplugin "github.com/hashicorp/go-plugin"
+)
+
+// IngestEndpoints is what a live plugin hands a broadcaster to start
+// sending a stream. Not every backend offers every protocol; unset
+// fields mean "not available".
+type IngestEndpoints struct {
+	// RTMPURL is the classic rtmp:// ingest including the stream key.
+	RTMPURL string
+	// SRTURL is the srt:// ingest with streamid (and passphrase where
+	// required) encoded as query parameters.
+	SRTURL string
+	// SRTPassphrase is the encryption passphrase for the SRT link, also
+	// embedded in SRTURL; kept separate for UIs that show it on its own.
+	SRTPassphrase string
+	// WHIPURL accepts WebRTC ingest (WHIP), enabling browser-based
+	// streaming without OBS.
+	WHIPURL string
+	// PlaybackURL is where the stream can be watched (HLS).
+	PlaybackURL string
+	// WHEPURL is the low-latency WebRTC playback (WHEP) endpoint.
+	WHEPURL string
+}
+
+// SRTStats are link statistics for an SRT ingest, useful for broadcast
+// contributors on lossy links.
+type SRTStats struct {
+	RTTMs             float64
+	PacketsRetrans    int64
+	PacketsLost       int64
+	LinkBandwidthMbps float64
+}
+
+// LiveTelemetry is a live plugin's view of one ingest.
+type LiveTelemetry struct {
+	Connected   bool
+	BitrateKbps float64
+	// SRT is non-nil when the contributor is connected over SRT.
+	SRT *SRTStats
+}
+
+// LiveService is implemented by plugins that manage live stream ingest
+// and playback (MediaMTX, ...).
+type LiveService interface {
+	Name() (string, error)
+	// StartIngest provisions ingest endpoints for a stream ID.
+	StartIngest(streamID string) (*IngestEndpoints, error)
+	// StopIngest tears the ingest down and disconnects publishers.
+	StopIngest(streamID string) error
+	// Telemetry reports the current state of an ingest.
+	Telemetry(streamID string) (*LiveTelemetry, error)
+}
+
+// ServeLive runs a live plugin. It never returns.
+func ServeLive(impl LiveService) {
+	goplugin.Serve(&goplugin.ServeConfig{
+		HandshakeConfig: Handshake,
+		Plugins: map[string]goplugin.Plugin{
+			"live":   &LivePlugin{Impl: impl},
+			"health": &HealthPlugin{Impl: healthImpl(impl)},
+			"config": &ConfigPlugin{Impl: configImpl(impl)},
+			"info":   &InfoPlugin{Impl: infoImpl(impl, "live")},
+		},
+	})
+}
+
+// LivePlugin wires LiveService into go-plugin.
+type LivePlugin struct {
+	Impl LiveService
+}
+
+// Server implements goplugin.Plugin.
+func (p *LivePlugin) Server(*goplugin.MuxBroker) (interface{}, error) {
+	return &liveRPCServer{impl: p.Impl}, nil
+}
+
+// Client implements goplugin.Plugin.
+func (p *LivePlugin) Client(_ *goplugin.MuxBroker, c *rpc.Client) (interface{}, error) {
+	return &liveRPCClient{client: c}, nil
+}
+
+type liveRPCServer struct {
+	impl LiveService
+}
+
+func (s *liveRPCServer) Name(_ struct{}, resp *string) error {
+	name, err := s.impl.Name()
+	*resp = name
+	return err
+}
+
+func (s *liveRPCServer) StartIngest(streamID string, resp *IngestEndpoints) error {
+	out, err := s.impl.StartIngest(streamID)
+	if err != nil {
+		return err
+	}
+	*resp = *out
+	return nil
+}
+
+func (s *liveRPCServer) StopIngest(streamID string, _ *struct{}) error {
+	return s.impl.StopIngest(streamID)
+}
+
+func (s *liveRPCServer) Telemetry(streamID string, resp *LiveTelemetry) error {
+	out, err := s.impl.Telemetry(streamID)
+	if err != nil {
+		return err
+	}
+	*resp = *out
+	return nil
+}
+
+type liveRPCClient struct {
+	client *rpc.Client
+}
+
+func (c *liveRPCClient) Name() (string, error) {
+	var name string
+	err := c.client.Call("Plugin.Name", struct{}{}, &name)
+	return name, err
+}
+
+func (c *liveRPCClient) StartIngest(streamID string) (*IngestEndpoints, error) {
+	var out IngestEndpoints
+	if err := c.client.Call("Plugin.StartIngest", streamID, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *liveRPCClient) StopIngest(streamID string) error {
+	return c.client.Call("Plugin.StopIngest", streamID, &struct{}{})
+}
+
+func (c *liveRPCClient) Telemetry(streamID string) (*LiveTelemetry, error) {
+	var out LiveTelemetry
+	if err := c.client.Call("Plugin.Telemetry", streamID, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,32 @@
+// Package plugin is the WebEncode plugin SDK. Plugins are separate
+// executables launched by the kernel's plugin manager and spoken to over
+// hashicorp/go-plugin net/rpc. Each service kind (publisher, storage,
+// encoder, ...) gets an interface here plus the RPC glue so plugin
+// authors only implement the interface and call Serve.
+package plugin
+
+import (
+	goplugin "github.com/hashicorp/go-plugin"
+)
+
+// Handshake is the shared handshake between the kernel and every plugin
+// executable. A mismatch means the binary in the plugin dir is not a
+// WebEncode plugin (or targets an incompatible SDK).
+var Handshake = goplugin.HandshakeConfig{
+	ProtocolVersion:  1,
+	MagicCookieKey:   "WEBENCODE_PLUGIN",
+	MagicCookieValue: "d3ebenc0de",
+}
+
+// ServePublisher runs a publisher plugin. It never returns.
+func ServePublisher(impl PublisherService) {
+	goplugin.Serve(&goplugin.ServeConfig{
+		HandshakeConfig: Handshake,
+		Plugins: map[string]goplugin.Plugin{
+			"publisher": &PublisherPlugin{Impl: impl},
+			"health":    &HealthPlugin{Impl: healthImpl(impl)},
+			"config":    &ConfigPlugin{Impl: configImpl(impl)},
+			"info":      &InfoPlugin{Impl: infoImpl(impl, "publisher")},
+		},
+	})
+}
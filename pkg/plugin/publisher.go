@@ -0,0 +1,153 @@
+package plugin
+
+import (
+	"errors"
+	"net/rpc"
+	"strings"
+	"time"
+
+	goplugin "github.com/hashicorp/go-plugin"
+)
+
+// Visibility controls who can see a published video on the target
+// platform.
+type Visibility string
+
+const (
+	VisibilityPublic   Visibility = "public"
+	VisibilityUnlisted Visibility = "unlisted"
+	VisibilityPrivate  Visibility = "private"
+)
+
+// PublishRequest describes one upload to an external platform. Beyond
+// the file itself it carries the presentation metadata platforms accept;
+// plugins map what they support and ignore the rest.
+type PublishRequest struct {
+	// FileURL is where the plugin fetches the finished output from,
+	// typically a presigned storage URL.
+	FileURL     string
+	Title       string
+	Description string
+
+	// ThumbnailURL, when set, is uploaded as the custom thumbnail.
+	ThumbnailURL string
+	Tags         []string
+	// Category is a platform category identifier (e.g. YouTube category
+	// ID, Twitch game ID). Empty leaves the platform default.
+	Category string
+	// Playlists the video should be added to after upload, by platform
+	// playlist ID.
+	Playlists []string
+	// PublishAt schedules the video to go public at the given time.
+	// Requires Visibility to be private until then on most platforms.
+	PublishAt *time.Time
+	// Visibility defaults to private when empty.
+	Visibility Visibility
+}
+
+// PublishResult reports where the video ended up.
+type PublishResult struct {
+	// VideoID is the platform-assigned identifier.
+	VideoID string
+	// URL is the public watch URL, when the platform returns one.
+	URL string
+}
+
+// ChatSettings are per-channel chat controls. Nil fields are left
+// unchanged on the platform; plugins map what they support.
+type ChatSettings struct {
+	// SlowModeSec enables slow mode with the given wait; 0 disables it.
+	SlowModeSec *int
+	// SubscribersOnly restricts chat to subscribers/members.
+	SubscribersOnly *bool
+	// EmoteOnly restricts chat to emotes.
+	EmoteOnly *bool
+}
+
+// ErrChatControlsUnsupported is returned by publishers whose platform
+// has no (or no accessible) chat controls.
+var ErrChatControlsUnsupported = errors.New("chat controls not supported")
+
+// IsChatControlsUnsupported reports whether err is the unsupported
+// sentinel, including after a trip through the plugin RPC boundary.
+func IsChatControlsUnsupported(err error) bool {
+	return err != nil && strings.Contains(err.Error(), ErrChatControlsUnsupported.Error())
+}
+
+// PublisherService is implemented by plugins that push finished outputs
+// to external platforms (YouTube, Vimeo, Twitch, ...).
+type PublisherService interface {
+	// Name returns the platform identifier, e.g. "youtube".
+	Name() (string, error)
+	// Publish uploads the file and applies the request metadata.
+	Publish(req PublishRequest) (*PublishResult, error)
+	// SetChatSettings applies chat controls to a channel. Platforms
+	// without chat controls return ErrChatControlsUnsupported.
+	SetChatSettings(channelID string, settings ChatSettings) error
+}
+
+// PublisherPlugin wires PublisherService into go-plugin.
+type PublisherPlugin struct {
+	Impl PublisherService
+}
+
+// Server implements goplugin.Plugin.
+func (p *PublisherPlugin) Server(*goplugin.MuxBroker) (interface{}, error) {
+	return &publisherRPCServer{impl: p.Impl}, nil
+}
+
+// Client implements goplugin.Plugin.
+func (p *PublisherPlugin) Client(_ *goplugin.MuxBroker, c *rpc.Client) (interface{}, error) {
+	return &publisherRPCClient{client: c}, nil
+}
+
+type publisherRPCServer struct {
+	impl PublisherService
+}
+
+func (s *publisherRPCServer) Name(_ struct{}, resp *string) error {
+	name, err := s.impl.Name()
+	*resp = name
+	return err
+}
+
+// ChatSettingsArgs carries one SetChatSettings call over RPC.
+type ChatSettingsArgs struct {
+	ChannelID string
+	Settings  ChatSettings
+}
+
+func (s *publisherRPCServer) SetChatSettings(args ChatSettingsArgs, _ *struct{}) error {
+	return s.impl.SetChatSettings(args.ChannelID, args.Settings)
+}
+
+func (s *publisherRPCServer) Publish(req PublishRequest, resp *PublishResult) error {
+	out, err := s.impl.Publish(req)
+	if err != nil {
+		return err
+	}
+	*resp = *out
+	return nil
+}
+
+type publisherRPCClient struct {
+	client *rpc.Client
+}
+
+func (c *publisherRPCClient) Name() (string, error) {
+	var name string
+	err := c.client.Call("Plugin.Name", struct{}{}, &name)
+	return name, err
+}
+
+func (c *publisherRPCClient) SetChatSettings(channelID string, settings ChatSettings) error {
+	return c.client.Call("Plugin.SetChatSettings", ChatSettingsArgs{ChannelID: channelID, Settings: settings}, &struct{}{})
+}
+
+func (c *publisherRPCClient) Publish(req PublishRequest) (*PublishResult, error) {
+	var out PublishResult
+	if err := c.client.Call("Plugin.Publish", req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
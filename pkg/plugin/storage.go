@@ -0,0 +1,137 @@
+package plugin
+
+import (
+	"net/rpc"
+	"time"
+
+	goplugin "github.com/hashicorp/go-plugin"
+)
+
+// ObjectInfo describes one stored object.
+type ObjectInfo struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// StorageService is implemented by plugins that provide shared file
+// storage (S3, MinIO, local disk, ...). Paths are object keys relative
+// to the backend's configured bucket/root. Upload and Download use local
+// filesystem paths, which works because plugins run as subprocesses next
+// to their caller.
+type StorageService interface {
+	Name() (string, error)
+	Upload(localPath, key string) error
+	Download(key, localPath string) error
+	List(prefix string) ([]ObjectInfo, error)
+	Delete(key string) error
+	// PresignGet returns a URL from which the object can be fetched
+	// without credentials for the given lifetime.
+	PresignGet(key string, expiry time.Duration) (string, error)
+}
+
+// ServeStorage runs a storage plugin. It never returns.
+func ServeStorage(impl StorageService) {
+	goplugin.Serve(&goplugin.ServeConfig{
+		HandshakeConfig: Handshake,
+		Plugins: map[string]goplugin.Plugin{
+			"storage": &StoragePlugin{Impl: impl},
+			"health":  &HealthPlugin{Impl: healthImpl(impl)},
+			"config":  &ConfigPlugin{Impl: configImpl(impl)},
+			"info":    &InfoPlugin{Impl: infoImpl(impl, "storage")},
+		},
+	})
+}
+
+// StoragePlugin wires StorageService into go-plugin.
+type StoragePlugin struct {
+	Impl StorageService
+}
+
+// Server implements goplugin.Plugin.
+func (p *StoragePlugin) Server(*goplugin.MuxBroker) (interface{}, error) {
+	return &storageRPCServer{impl: p.Impl}, nil
+}
+
+// Client implements goplugin.Plugin.
+func (p *StoragePlugin) Client(_ *goplugin.MuxBroker, c *rpc.Client) (interface{}, error) {
+	return &storageRPCClient{client: c}, nil
+}
+
+type storageRPCServer struct {
+	impl StorageService
+}
+
+type storageTransferArgs struct {
+	LocalPath string
+	Key       string
+}
+
+type storagePresignArgs struct {
+	Key    string
+	Expiry time.Duration
+}
+
+func (s *storageRPCServer) Name(_ struct{}, resp *string) error {
+	name, err := s.impl.Name()
+	*resp = name
+	return err
+}
+
+func (s *storageRPCServer) Upload(args storageTransferArgs, _ *struct{}) error {
+	return s.impl.Upload(args.LocalPath, args.Key)
+}
+
+func (s *storageRPCServer) Download(args storageTransferArgs, _ *struct{}) error {
+	return s.impl.Download(args.Key, args.LocalPath)
+}
+
+func (s *storageRPCServer) List(prefix string, resp *[]ObjectInfo) error {
+	out, err := s.impl.List(prefix)
+	*resp = out
+	return err
+}
+
+func (s *storageRPCServer) Delete(key string, _ *struct{}) error {
+	return s.impl.Delete(key)
+}
+
+func (s *storageRPCServer) PresignGet(args storagePresignArgs, resp *string) error {
+	url, err := s.impl.PresignGet(args.Key, args.Expiry)
+	*resp = url
+	return err
+}
+
+type storageRPCClient struct {
+	client *rpc.Client
+}
+
+func (c *storageRPCClient) Name() (string, error) {
+	var name string
+	err := c.client.Call("Plugin.Name", struct{}{}, &name)
+	return name, err
+}
+
+func (c *storageRPCClient) Upload(localPath, key string) error {
+	return c.client.Call("Plugin.Upload", storageTransferArgs{LocalPath: localPath, Key: key}, &struct{}{})
+}
+
+func (c *storageRPCClient) Download(key, localPath string) error {
+	return c.client.Call("Plugin.Download", storageTransferArgs{LocalPath: localPath, Key: key}, &struct{}{})
+}
+
+func (c *storageRPCClient) List(prefix string) ([]ObjectInfo, error) {
+	var out []ObjectInfo
+	err := c.client.Call("Plugin.List", prefix, &out)
+	return out, err
+}
+
+func (c *storageRPCClient) Delete(key string) error {
+	return c.client.Call("Plugin.Delete", key, &struct{}{})
+}
+
+func (c *storageRPCClient) PresignGet(key string, expiry time.Duration) (string, error) {
+	var url string
+	err := c.client.Call("Plugin.PresignGet", storagePresignArgs{Key: key, Expiry: expiry}, &url)
+	return url, err
+}
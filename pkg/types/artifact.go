@@ -0,0 +1,65 @@
+package types
+
+import "time"
+
+// ArtifactKind categorizes one file an encode produced.
+type ArtifactKind string
+
+const (
+	// ArtifactRendition is an encoded media file.
+	ArtifactRendition ArtifactKind = "rendition"
+	// ArtifactManifest is a streaming playlist (HLS/DASH).
+	ArtifactManifest ArtifactKind = "manifest"
+	// ArtifactSegment is one media segment referenced by a manifest.
+	ArtifactSegment ArtifactKind = "segment"
+	// ArtifactThumbnail is a still image extracted from the output.
+	ArtifactThumbnail ArtifactKind = "thumbnail"
+	// ArtifactSubtitle is a subtitle or caption track.
+	ArtifactSubtitle ArtifactKind = "subtitle"
+	// ArtifactOther is anything the worker could not classify.
+	ArtifactOther ArtifactKind = "other"
+)
+
+// Artifact describes one output file of a completed task: where it
+// lives and, for renditions, what was encoded into it. Workers report
+// artifacts on task completion; the kernel indexes them per job.
+type Artifact struct {
+	Kind ArtifactKind `json:"kind"`
+	// Key is the storage object key, or a worker-local path when no
+	// storage plugin is configured.
+	Key string `json:"key"`
+	// URL is a presigned download link filled in at read time; it is
+	// never stored.
+	URL       string `json:"url,omitempty"`
+	SizeBytes int64  `json:"size_bytes,omitempty"`
+	// SHA256 is the hex digest of the stored object, computed by the
+	// worker and verified after the upload, so downstream consumers can
+	// validate what they fetch.
+	SHA256 string `json:"sha256,omitempty"`
+
+	// Rendition details, copied from the producing profile.
+	ProfileID   string `json:"profile_id,omitempty"`
+	ProfileName string `json:"profile_name,omitempty"`
+	VideoCodec  string `json:"video_codec,omitempty"`
+	AudioCodec  string `json:"audio_codec,omitempty"`
+	Width       int    `json:"width,omitempty"`
+	Height      int    `json:"height,omitempty"`
+	BitrateKbps int    `json:"bitrate_kbps,omitempty"`
+
+	// Loudness carries the loudnorm measurement when the producing
+	// profile normalized the audio.
+	Loudness *LoudnessReport `json:"loudness,omitempty"`
+
+	TaskID    string    `json:"task_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LoudnessReport is the EBU R128 measurement of a normalized
+// rendition's source audio, reported by the worker after the loudnorm
+// measurement pass.
+type LoudnessReport struct {
+	TargetLUFS      float64 `json:"target_lufs"`
+	InputLUFS       float64 `json:"input_lufs"`
+	InputTruePeakDB float64 `json:"input_true_peak_db"`
+	InputLRA        float64 `json:"input_lra"`
+}
@@ -0,0 +1,124 @@
+package types
+
+import "time"
+
+// JobStatus is the lifecycle state of an encoding job.
+type JobStatus string
+
+const (
+	JobStatusQueued    JobStatus = "queued"
+	JobStatusRunning   JobStatus = "running"
+	JobStatusCompleted JobStatus = "completed"
+	JobStatusFailed    JobStatus = "failed"
+	JobStatusCancelled JobStatus = "cancelled"
+)
+
+// Job is one encoding request: a source rendered through one or more
+// profiles to an output location.
+type Job struct {
+	ID     string `json:"id"`
+	UserID string `json:"user_id"`
+	Title  string `json:"title,omitempty"`
+	// SourceURL locates the input (s3://, http(s)://, storage key).
+	SourceURL string `json:"source_url"`
+	// OutputPrefix is where rendered outputs are written.
+	OutputPrefix string `json:"output_prefix,omitempty"`
+	// StorageBackend selects which configured storage backend outputs
+	// go to, by alias (e.g. "hot", "archive"); empty uses the default.
+	StorageBackend string `json:"storage_backend,omitempty"`
+	// Selector restricts the job's tasks to workers whose labels match
+	// every pair (e.g. {"region": "eu-west"}); empty runs anywhere.
+	Selector   map[string]string `json:"selector,omitempty"`
+	ProfileIDs []string          `json:"profile_ids"`
+	Status     JobStatus         `json:"status"`
+	Error      string            `json:"error,omitempty"`
+	// TrimStartSec/TrimDurationSec cut the source before encoding; zero
+	// values encode the whole input. Used by teaser-clip fan-out jobs.
+	TrimStartSec    float64 `json:"trim_start_sec,omitempty"`
+	TrimDurationSec float64 `json:"trim_duration_sec,omitempty"`
+	// Ingest fetches the source with yt-dlp before transcoding;
+	// SourceURL may then be any page URL the tool understands.
+	Ingest *IngestSpec `json:"ingest,omitempty"`
+	// Edit concatenates bumper clips around the source and/or cuts it
+	// down to an EDL of keep-ranges before transcoding.
+	Edit *EditSpec `json:"edit,omitempty"`
+	// ExtractAudio adds a task that stream-copies the audio track out of
+	// the source without transcoding, alongside any profile tasks —
+	// audio workflows skip the video encode entirely.
+	ExtractAudio bool `json:"extract_audio,omitempty"`
+	// FanOut declares derivative jobs to create once this job completes.
+	FanOut []FanOutSpec `json:"fan_out,omitempty"`
+	// Publish, when set, pushes the finished primary output to a
+	// platform.
+	Publish *PublishSpec `json:"publish,omitempty"`
+	// Deadline, when set, asks deadline-aware scheduling to finish the
+	// job by this time; it is advisory, not a guarantee.
+	Deadline time.Time `json:"deadline,omitempty"`
+	// Metadata carries free-form context (e.g. resolved stream markers
+	// for archive jobs) that travels with the job into its outputs.
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+	UpdatedAt time.Time              `json:"updated_at"`
+}
+
+// IngestSpec asks the pipeline to fetch the job's source with yt-dlp
+// into storage before the probe/transcode tasks run.
+type IngestSpec struct {
+	// Format is a yt-dlp format selector; empty takes the best
+	// video+audio combination.
+	Format string `json:"format,omitempty"`
+	// RateLimit caps the download rate (yt-dlp syntax, e.g. "4M").
+	RateLimit string `json:"rate_limit,omitempty"`
+}
+
+// EditRange is one keep-range of a simple edit decision list.
+type EditRange struct {
+	StartSec float64 `json:"start_sec"`
+	EndSec   float64 `json:"end_sec"`
+}
+
+// EditSpec describes pre-processing applied to the source before the
+// profile encode: bumper clips concatenated around it and an optional
+// EDL of keep-ranges cut out of it. The worker assembles the pieces
+// with the concat demuxer (stream copy), so all inputs must share codec
+// and resolution — checked before the concat runs.
+type EditSpec struct {
+	// PreRollURLs and PostRollURLs are bumper clips played before and
+	// after the (possibly cut) source.
+	PreRollURLs  []string `json:"pre_roll_urls,omitempty"`
+	PostRollURLs []string `json:"post_roll_urls,omitempty"`
+	// Ranges keeps only the listed source ranges, in list order; empty
+	// keeps the whole source.
+	Ranges []EditRange `json:"ranges,omitempty"`
+}
+
+// FanOutSpec declares one derivative job spawned from a completed job's
+// output — e.g. a vertical social cut or a 60s teaser. The aspect
+// conversion itself lives in the referenced profiles.
+type FanOutSpec struct {
+	// Name labels the derivative (e.g. "vertical", "teaser").
+	Name       string   `json:"name"`
+	ProfileIDs []string `json:"profile_ids"`
+	// SourceProfileID picks which primary output feeds the derivative;
+	// empty uses the job's first profile.
+	SourceProfileID string `json:"source_profile_id,omitempty"`
+	// StartSec/DurationSec trim the derivative (teaser clips); zero
+	// values keep the full length.
+	StartSec    float64 `json:"start_sec,omitempty"`
+	DurationSec float64 `json:"duration_sec,omitempty"`
+	// Publish pushes the derivative's output to a platform when it
+	// completes.
+	Publish *PublishSpec `json:"publish,omitempty"`
+}
+
+// PublishSpec is the declarative form of a publish request attached to
+// jobs; the orchestrator resolves it against the finished output.
+type PublishSpec struct {
+	// Plugin is the publisher plugin name, e.g. "youtube".
+	Plugin      string   `json:"plugin"`
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	// Visibility defaults to the platform's private setting.
+	Visibility string `json:"visibility,omitempty"`
+}
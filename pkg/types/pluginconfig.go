@@ -0,0 +1,19 @@
+package types
+
+import "time"
+
+// PluginConfigChange is broadcast on the bus when one kernel replica
+// changes or reloads a plugin, so the other replicas apply the same
+// change immediately instead of serving stale state until an operator
+// hits each one.
+type PluginConfigChange struct {
+	Plugin string `json:"plugin"`
+	// Values is the submitted config; empty for a bare reload.
+	Values map[string]string `json:"values,omitempty"`
+	// Reload asks replicas to restart the plugin subprocess.
+	Reload bool `json:"reload,omitempty"`
+	// Origin identifies the replica that made the change; it skips its
+	// own broadcast.
+	Origin string    `json:"origin"`
+	At     time.Time `json:"at"`
+}
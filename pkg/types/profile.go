@@ -0,0 +1,177 @@
+package types
+
+// ContainerOptions controls how the output container is written, beyond
+// codec settings. Defaults produce a plain progressive MP4.
+type ContainerOptions struct {
+	// Format is the ffmpeg output format/muxer ("mp4", "mov", "mkv",
+	// "hls", "segment"). Empty lets ffmpeg infer it from the extension.
+	Format string `json:"format,omitempty"`
+	// Faststart relocates the moov atom to the front of MP4/MOV outputs
+	// so playback can begin before the whole file is downloaded.
+	Faststart bool `json:"faststart,omitempty"`
+	// Fragmented writes fragmented MP4 (fMP4), for CMAF-style delivery
+	// and outputs readable while still being written.
+	Fragmented bool `json:"fragmented,omitempty"`
+	// FragmentDurationMs is the target fragment duration for fragmented
+	// output. Zero cuts fragments on keyframes only.
+	FragmentDurationMs int `json:"fragment_duration_ms,omitempty"`
+	// SegmentPattern names segment files for segmented formats, e.g.
+	// "seg_%05d.ts" or "chunk_%03d.m4s". Only valid for hls/segment.
+	SegmentPattern string `json:"segment_pattern,omitempty"`
+	// SegmentDurationSec is the target segment length for segmented
+	// formats. Zero uses the muxer default.
+	SegmentDurationSec int `json:"segment_duration_sec,omitempty"`
+	// Encryption encrypts HLS segments; only valid for format "hls".
+	Encryption *EncryptionOptions `json:"encryption,omitempty"`
+}
+
+// EncryptionOptions controls HLS segment encryption. The kernel issues
+// the key material and serves it from its key-delivery endpoint; the
+// manifest's #EXT-X-KEY lines point players there.
+type EncryptionOptions struct {
+	// Method is "aes-128" (default). "sample-aes" is reserved for
+	// external DRM packagers (Widevine/FairPlay) behind the key
+	// provider interface; the built-in ffmpeg path rejects it.
+	Method string `json:"method,omitempty"`
+	// KeyRotationSegments switches to a fresh key every N segments;
+	// zero keeps one key for the whole rendition.
+	KeyRotationSegments int `json:"key_rotation_segments,omitempty"`
+}
+
+// AspectMode controls how a source is reframed when its aspect ratio
+// differs from the profile's Width/Height — the heart of delivering the
+// same master as 16:9, 1:1 and 9:16 renditions.
+type AspectMode string
+
+const (
+	// AspectModeStretch is the zero value: plain scale, no reframing.
+	AspectModeStretch AspectMode = ""
+	// AspectModePad letterboxes/pillarboxes the source to fit.
+	AspectModePad AspectMode = "pad"
+	// AspectModeCrop center-crops the source to fill the frame.
+	AspectModeCrop AspectMode = "crop"
+	// AspectModeSmart crops to the active picture area found by a
+	// cropdetect pre-pass, then center-crops to fill.
+	AspectModeSmart AspectMode = "smart"
+)
+
+// WatermarkOptions overlays an image on the picture.
+type WatermarkOptions struct {
+	// Image is the path or URL of the overlay image.
+	Image string `json:"image"`
+	// Position anchors the overlay: "top-left", "top-right",
+	// "bottom-left", "bottom-right" (default) or "center".
+	Position string `json:"position,omitempty"`
+	// Opacity is the overlay alpha, 0..1. Zero means opaque.
+	Opacity float64 `json:"opacity,omitempty"`
+}
+
+// FilterOptions declares the video filter stages a profile applies.
+// They are mapped into an ffmpeg filtergraph in a fixed order:
+// deinterlace, crop, pad, rotate, denoise, fades, watermark.
+type FilterOptions struct {
+	// Deinterlace picks the deinterlacer: "yadif" or "bwdif".
+	Deinterlace string `json:"deinterlace,omitempty"`
+	// Crop is an ffmpeg crop window "w:h:x:y".
+	Crop string `json:"crop,omitempty"`
+	// Pad is an ffmpeg pad spec "w:h:x:y" with an optional ":color".
+	Pad string `json:"pad,omitempty"`
+	// Rotate turns the picture clockwise: 90, 180 or 270 degrees.
+	Rotate int `json:"rotate,omitempty"`
+	// Denoise strength: "light", "medium" or "heavy" (hqdn3d).
+	Denoise string `json:"denoise,omitempty"`
+	// FadeInSec fades in from black over the first N seconds.
+	FadeInSec float64 `json:"fade_in_sec,omitempty"`
+	// FadeOutStartSec and FadeOutSec fade to black over FadeOutSec
+	// seconds starting at FadeOutStartSec.
+	FadeOutStartSec float64 `json:"fade_out_start_sec,omitempty"`
+	FadeOutSec      float64 `json:"fade_out_sec,omitempty"`
+	// Watermark overlays an image on the picture.
+	Watermark *WatermarkOptions `json:"watermark,omitempty"`
+}
+
+// HDRMode controls how a profile treats high-dynamic-range sources.
+type HDRMode string
+
+const (
+	// HDRModeNone leaves dynamic range handling to the encoder defaults.
+	HDRModeNone HDRMode = ""
+	// HDRModePassthrough keeps a 10-bit pipeline and re-signals the
+	// HDR10/HLG transfer so metadata survives into the output.
+	HDRModePassthrough HDRMode = "passthrough"
+	// HDRModeTonemap converts HDR sources to SDR BT.709 renditions.
+	HDRModeTonemap HDRMode = "tonemap"
+)
+
+// AV1Options carries SVT-AV1 specific encoder parameters. It is only
+// valid with video codec "libsvtav1"; the profile's Preset field maps
+// onto SVT-AV1's numeric presets (0 slowest .. 13 fastest).
+type AV1Options struct {
+	// FilmGrain enables film-grain synthesis at the given strength,
+	// 1..50. Grain is modelled at encode time and re-synthesized by the
+	// decoder, keeping grainy sources cheap to compress.
+	FilmGrain int `json:"film_grain,omitempty"`
+	// FilmGrainDenoise also denoises the source with the modelled grain
+	// removed, instead of encoding the noisy picture as-is.
+	FilmGrainDenoise bool `json:"film_grain_denoise,omitempty"`
+	// TileRows and TileColumns are log2 tile counts (0..6), trading
+	// compression for parallel decode.
+	TileRows    int `json:"tile_rows,omitempty"`
+	TileColumns int `json:"tile_columns,omitempty"`
+}
+
+// Profile is a named set of encoding parameters a job output is rendered
+// with.
+type Profile struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// OwnerID scopes the profile to one user; empty means shared
+	// (admin-created profiles everyone can use).
+	OwnerID string `json:"owner_id,omitempty"`
+
+	// Video settings. Width/Height of zero keep the source dimension.
+	VideoCodec string `json:"video_codec"`
+	Width      int    `json:"width,omitempty"`
+	Height     int    `json:"height,omitempty"`
+	// AspectMode reframes sources whose aspect ratio differs from
+	// Width/Height; requires both dimensions when set.
+	AspectMode AspectMode `json:"aspect_mode,omitempty"`
+	// HDRMode controls high-dynamic-range handling; see the HDRMode
+	// constants.
+	HDRMode HDRMode `json:"hdr_mode,omitempty"`
+	// HDRTransfer names the source transfer function for passthrough
+	// signaling: "pq" (HDR10) or "hlg". Defaults to "pq".
+	HDRTransfer      string `json:"hdr_transfer,omitempty"`
+	VideoBitrateKbps int    `json:"video_bitrate_kbps,omitempty"`
+	// CRF is used instead of a target bitrate when non-zero.
+	CRF       int     `json:"crf,omitempty"`
+	Preset    string  `json:"preset,omitempty"`
+	Framerate float64 `json:"framerate,omitempty"`
+	// AV1 adds SVT-AV1 specific parameters; requires video codec
+	// "libsvtav1".
+	AV1 *AV1Options `json:"av1,omitempty"`
+
+	// Audio settings.
+	AudioCodec       string `json:"audio_codec"`
+	AudioBitrateKbps int    `json:"audio_bitrate_kbps,omitempty"`
+	// LoudnessTargetLUFS enables two-pass EBU R128 loudness
+	// normalization toward the given integrated target (-23 for
+	// broadcast, -14 for streaming). Zero disables it.
+	LoudnessTargetLUFS float64 `json:"loudness_target_lufs,omitempty"`
+	// AudioOnly drops the video stream (-vn) for audio-first outputs
+	// such as MP3/AAC/Opus/FLAC podcast renditions; requires AudioCodec
+	// and ignores the video settings above.
+	AudioOnly bool `json:"audio_only,omitempty"`
+
+	// Filters adds video filter stages (deinterlace, crop, watermark,
+	// fades, ...) ahead of the encode.
+	Filters FilterOptions `json:"filters,omitempty"`
+
+	Container ContainerOptions `json:"container,omitempty"`
+
+	// SandboxCPUs/SandboxMemoryMB cap the container this profile's
+	// encodes run in when the worker sandboxes ffmpeg, overriding the
+	// worker-wide defaults. Zero keeps the defaults.
+	SandboxCPUs     float64 `json:"sandbox_cpus,omitempty"`
+	SandboxMemoryMB int     `json:"sandbox_memory_mb,omitempty"`
+}
@@ -0,0 +1,30 @@
+package types
+
+import "time"
+
+// StreamStatus is the lifecycle state of a live stream.
+type StreamStatus string
+
+const (
+	StreamStatusIdle  StreamStatus = "idle"
+	StreamStatusLive  StreamStatus = "live"
+	StreamStatusEnded StreamStatus = "ended"
+)
+
+// Stream is a live ingest session managed by the kernel.
+type Stream struct {
+	ID     string `json:"id"`
+	UserID string `json:"user_id"`
+	Title  string `json:"title"`
+	// SourceURL is where the incoming stream can be read from (RTMP/HLS
+	// ingest endpoint), used by relays and recording.
+	SourceURL string       `json:"source_url"`
+	Status    StreamStatus `json:"status"`
+	// Archive records the stream while live and submits a VOD transcode
+	// job when it ends.
+	Archive bool `json:"archive,omitempty"`
+	// ArchiveJobID links the stream to the VOD job produced from its
+	// recording.
+	ArchiveJobID string    `json:"archive_job_id,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -0,0 +1,160 @@
+package types
+
+import "time"
+
+// TaskType is the kind of work a task asks a worker to do.
+type TaskType string
+
+const (
+	TaskTypeProbe     TaskType = "probe"
+	TaskTypeTranscode TaskType = "transcode"
+	// TaskTypeBenchmark runs standardized encode benchmarks on one
+	// worker and reports per-encoder scores.
+	TaskTypeBenchmark TaskType = "benchmark"
+	// TaskTypeIngest fetches an external source with yt-dlp into
+	// storage; the job's transcode tasks launch when it completes.
+	TaskTypeIngest TaskType = "ingest"
+)
+
+// HLSKey is one issued segment-encryption key: the raw AES-128 bytes
+// for the worker and the delivery URI written into #EXT-X-KEY.
+type HLSKey struct {
+	ID  string `json:"id"`
+	URI string `json:"uri"`
+	Key []byte `json:"key"`
+}
+
+// TaskStatus is the lifecycle state of a task.
+type TaskStatus string
+
+const (
+	TaskStatusPending   TaskStatus = "pending"
+	TaskStatusRunning   TaskStatus = "running"
+	TaskStatusCompleted TaskStatus = "completed"
+	TaskStatusFailed    TaskStatus = "failed"
+)
+
+// ErrorClass coarsely categorizes task failures. Node-local classes
+// indicate the worker, not the task, is the problem.
+type ErrorClass string
+
+const (
+	ErrorClassNone     ErrorClass = ""
+	ErrorClassDiskFull ErrorClass = "disk_full"
+	ErrorClassGPU      ErrorClass = "gpu_error"
+	ErrorClassIO       ErrorClass = "io_error"
+	ErrorClassEncode   ErrorClass = "encode_error"
+	ErrorClassSource   ErrorClass = "source_unreachable"
+)
+
+// NodeLocal reports whether the error class points at the worker node
+// rather than the task itself.
+func (c ErrorClass) NodeLocal() bool {
+	switch c {
+	case ErrorClassDiskFull, ErrorClassGPU, ErrorClassIO:
+		return true
+	}
+	return false
+}
+
+// Task is one unit of work dispatched to a worker. The profile is
+// embedded so workers need no kernel round trip to execute.
+type Task struct {
+	ID    string   `json:"id"`
+	JobID string   `json:"job_id"`
+	Type  TaskType `json:"type"`
+	// UserID is the owning job's submitter; scheduler policies use it
+	// for fair-share accounting.
+	UserID string `json:"user_id,omitempty"`
+	// Deadline, when set, lets deadline-aware scheduling prioritize this
+	// task.
+	Deadline time.Time `json:"deadline,omitempty"`
+
+	SourceURL    string   `json:"source_url"`
+	OutputPrefix string   `json:"output_prefix,omitempty"`
+	Profile      *Profile `json:"profile,omitempty"`
+	// StorageBackend is the storage alias outputs upload to; empty uses
+	// the worker's default.
+	StorageBackend string `json:"storage_backend,omitempty"`
+	// SeekSec/DurationSec trim the input before encoding; zero values
+	// encode the whole source.
+	SeekSec     float64 `json:"seek_sec,omitempty"`
+	DurationSec float64 `json:"duration_sec,omitempty"`
+	// Ingest carries the yt-dlp fetch options for ingest tasks.
+	Ingest *IngestSpec `json:"ingest,omitempty"`
+	// Edit carries the job's pre-processing spec (bumpers, EDL cuts);
+	// the worker builds the concat input before transcoding.
+	Edit *EditSpec `json:"edit,omitempty"`
+	// TraceParent is the W3C trace context of the dispatching span, so
+	// worker-side spans join the same trace.
+	TraceParent string `json:"trace_parent,omitempty"`
+	// HLSKeys carries issued segment-encryption keys for encrypted HLS
+	// outputs: the first is active, the rest feed rotation. The raw key
+	// material travels only on the internal bus, never in API responses.
+	HLSKeys []HLSKey `json:"hls_keys,omitempty"`
+
+	Status   TaskStatus `json:"status"`
+	Attempts int        `json:"attempts"`
+	WorkerID string     `json:"worker_id,omitempty"`
+	Error    string     `json:"error,omitempty"`
+	// TargetWorker, when set, pins the task to one worker; others
+	// requeue it. Used by dead-letter replays.
+	TargetWorker string `json:"target_worker,omitempty"`
+	// ExcludedWorkers are worker IDs that must not pick this task up,
+	// filled in after repeated node-local failures.
+	ExcludedWorkers []string `json:"excluded_workers,omitempty"`
+	// Selector restricts the task to workers whose labels match every
+	// pair. A preferred selector only biases placement: non-matching
+	// workers step aside a few times (counted in SelectorMisses)
+	// before one takes the task anyway, so storage co-location is
+	// favored without stranding work when no matching worker is up.
+	Selector          map[string]string `json:"selector,omitempty"`
+	SelectorPreferred bool              `json:"selector_preferred,omitempty"`
+	SelectorMisses    int               `json:"selector_misses,omitempty"`
+	CreatedAt         time.Time         `json:"created_at"`
+	UpdatedAt         time.Time         `json:"updated_at"`
+}
+
+// Excluded reports whether workerID is on the task's exclusion list.
+func (t *Task) Excluded(workerID string) bool {
+	for _, id := range t.ExcludedWorkers {
+		if id == workerID {
+			return true
+		}
+	}
+	return false
+}
+
+// TaskLog is one log line a worker emits while running a task —
+// ffmpeg stderr, mostly. The kernel folds these into the event log for
+// live tailing.
+type TaskLog struct {
+	TaskID   string `json:"task_id"`
+	JobID    string `json:"job_id"`
+	WorkerID string `json:"worker_id"`
+	// Token authenticates the sender against its enrollment.
+	Token string `json:"token,omitempty"`
+	// Level is "info" or "error".
+	Level string    `json:"level"`
+	Line  string    `json:"line"`
+	At    time.Time `json:"at"`
+}
+
+// TaskEvent is published by workers as a task moves through its
+// lifecycle.
+type TaskEvent struct {
+	TaskID   string `json:"task_id"`
+	JobID    string `json:"job_id"`
+	WorkerID string `json:"worker_id"`
+	// Token authenticates the sender against its enrollment.
+	Token      string     `json:"token,omitempty"`
+	Status     TaskStatus `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	ErrorClass ErrorClass `json:"error_class,omitempty"`
+	// Artifacts lists the files the task produced; set on completion.
+	Artifacts []Artifact `json:"artifacts,omitempty"`
+	// Benchmark carries per-encoder scores (encoded frames per second)
+	// when a benchmark task completes.
+	Benchmark map[string]float64 `json:"benchmark,omitempty"`
+	At        time.Time          `json:"at"`
+}
@@ -0,0 +1,36 @@
+package types
+
+import "time"
+
+// TemplateWebhook is the notification endpoint a template registers at
+// creation time.
+type TemplateWebhook struct {
+	URL string `json:"url"`
+	// Secret signs payloads; empty generates one.
+	Secret string `json:"secret,omitempty"`
+	// Events are event-type prefixes to notify on; empty means all.
+	Events []string `json:"events,omitempty"`
+}
+
+// JobTemplate bundles everything needed to launch a complete workflow —
+// profiles, output location pattern, fan-out, publish destination, and
+// notifications — behind a single ID. Launching takes the template plus
+// a source URL.
+type JobTemplate struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// OwnerID scopes the template to one user; empty means shared.
+	OwnerID    string   `json:"owner_id,omitempty"`
+	ProfileIDs []string `json:"profile_ids"`
+	// OutputPattern expands into the job's output prefix. Supported
+	// placeholders: {job_id}, {title}, {date} (YYYY-MM-DD),
+	// {template}.
+	OutputPattern string       `json:"output_pattern,omitempty"`
+	FanOut        []FanOutSpec `json:"fan_out,omitempty"`
+	Publish       *PublishSpec `json:"publish,omitempty"`
+	// Webhook registers a notification endpoint when the template is
+	// created; WebhookID records the registration.
+	Webhook   *TemplateWebhook `json:"webhook,omitempty"`
+	WebhookID string           `json:"webhook_id,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+}
@@ -0,0 +1,24 @@
+package types
+
+// Role is the coarse access level assigned to a user by an auth plugin.
+type Role string
+
+const (
+	RoleAdmin Role = "admin"
+	RoleUser  Role = "user"
+)
+
+// User is the authenticated identity attached to every API request.
+// Auth plugins are responsible for producing it; the kernel only consumes it.
+type User struct {
+	ID       string            `json:"id"`
+	Username string            `json:"username"`
+	Email    string            `json:"email,omitempty"`
+	Role     Role              `json:"role"`
+	Labels   map[string]string `json:"labels,omitempty"`
+}
+
+// IsAdmin reports whether the user holds the admin role.
+func (u *User) IsAdmin() bool {
+	return u != nil && u.Role == RoleAdmin
+}
@@ -0,0 +1,71 @@
+package types
+
+import "time"
+
+// WorkerStatus is the kernel's health view of a worker node.
+type WorkerStatus string
+
+const (
+	WorkerStatusHealthy   WorkerStatus = "healthy"
+	WorkerStatusUnhealthy WorkerStatus = "unhealthy"
+	WorkerStatusOffline   WorkerStatus = "offline"
+)
+
+// Heartbeat is the periodic message a worker publishes on the bus.
+type Heartbeat struct {
+	WorkerID string `json:"worker_id"`
+	// Token authenticates the sender against its enrollment.
+	Token    string            `json:"token,omitempty"`
+	Hostname string            `json:"hostname"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	// RunningTasks are the task IDs currently executing on the worker.
+	RunningTasks []string `json:"running_tasks,omitempty"`
+	// Shutdown marks the final heartbeat of a draining worker; the
+	// kernel takes the node offline immediately instead of waiting for
+	// the heartbeat timeout.
+	Shutdown bool `json:"shutdown,omitempty"`
+	// Draining marks a worker that stopped accepting tasks and is
+	// finishing its in-flight work before termination.
+	Draining bool `json:"draining,omitempty"`
+	// DiskFreeBytes and DiskTotalBytes describe the workdir filesystem;
+	// zero means the platform could not report usage.
+	DiskFreeBytes  uint64    `json:"disk_free_bytes,omitempty"`
+	DiskTotalBytes uint64    `json:"disk_total_bytes,omitempty"`
+	At             time.Time `json:"at"`
+}
+
+// WorkerDrain asks one worker to stop accepting new tasks and finish
+// its in-flight work, so scale-down can wait for the node to empty
+// before terminating it.
+type WorkerDrain struct {
+	WorkerID string    `json:"worker_id"`
+	At       time.Time `json:"at"`
+}
+
+// Throttle tells workers to pause batch encodes while live output is
+// degraded, and to resume once it recovers.
+type Throttle struct {
+	Active bool      `json:"active"`
+	Reason string    `json:"reason,omitempty"`
+	At     time.Time `json:"at"`
+}
+
+// Worker is the kernel-side record of a worker node.
+type Worker struct {
+	ID       string            `json:"id"`
+	Hostname string            `json:"hostname"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Status   WorkerStatus      `json:"status"`
+	// Draining mirrors the worker's heartbeat flag: the node is healthy
+	// but no longer accepts tasks, pending termination.
+	Draining      bool      `json:"draining,omitempty"`
+	LastHeartbeat time.Time `json:"last_heartbeat"`
+	FirstSeen     time.Time `json:"first_seen"`
+	// DiskFreeBytes and DiskTotalBytes are from the last heartbeat.
+	DiskFreeBytes  uint64 `json:"disk_free_bytes,omitempty"`
+	DiskTotalBytes uint64 `json:"disk_total_bytes,omitempty"`
+	// Benchmark holds the node's last benchmark scores per encoder
+	// (encoded frames per second); empty until a benchmark ran.
+	Benchmark   map[string]float64 `json:"benchmark,omitempty"`
+	BenchmarkAt time.Time          `json:"benchmark_at,omitempty"`
+}
@@ -0,0 +1,174 @@
+// Command auth-cfaccess authenticates requests already vetted by
+// Cloudflare Access. The bearer token is the CF_Authorization JWT;
+// the plugin resolves it through the team's identity endpoint and maps
+// Access groups, identity-provider claims, and email domains to
+// WebEncode roles — so admins grant rights in Access, not in code.
+//
+// Configuration:
+//
+//	WEBENCODE_CF_TEAM_DOMAIN          team domain, e.g. "acme" or
+//	                                  "acme.cloudflareaccess.com"
+//	WEBENCODE_CF_ADMIN_GROUPS         comma-separated Access group
+//	                                  names granting the admin role
+//	WEBENCODE_CF_ADMIN_CLAIMS         comma-separated claim=value pairs
+//	                                  granting admin, matched against
+//	                                  the identity document
+//	WEBENCODE_CF_ADMIN_EMAIL_DOMAINS  comma-separated email domains
+//	                                  granting admin
+//	WEBENCODE_CF_ALLOWED_EMAIL_DOMAINS comma-separated email domains
+//	                                  admitted at all; empty admits any
+//	                                  identity Access lets through
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rennerdo30/webencode/pkg/plugin"
+)
+
+type cfAccessAuth struct {
+	teamDomain     string
+	adminGroups    []string
+	adminClaims    map[string]string
+	adminDomains   []string
+	allowedDomains []string
+	client         *http.Client
+}
+
+// identity is the subset of the Access get-identity document the
+// plugin maps from.
+type identity struct {
+	UserUUID string `json:"user_uuid"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Groups   []struct {
+		Name string `json:"name"`
+	} `json:"groups"`
+	// Extra catches identity-provider claims surfaced at the top level
+	// of the document for claim=value matching.
+	Extra map[string]interface{} `json:"-"`
+}
+
+func (a *cfAccessAuth) Name() (string, error) { return "cfaccess", nil }
+
+func (a *cfAccessAuth) Authenticate(token string) (*plugin.Identity, error) {
+	req, err := http.NewRequest(http.MethodGet, "https://"+a.teamDomain+"/cdn-cgi/access/get-identity", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.AddCookie(&http.Cookie{Name: "CF_Authorization", Value: token})
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return nil, plugin.ErrInvalidToken
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("get-identity returned %s", resp.Status)
+	}
+	var id identity
+	var raw map[string]interface{}
+	dec := json.NewDecoder(resp.Body)
+	if err := dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+	redecoded, _ := json.Marshal(raw)
+	if err := json.Unmarshal(redecoded, &id); err != nil {
+		return nil, err
+	}
+	id.Extra = raw
+	if id.Email == "" {
+		return nil, plugin.ErrInvalidToken
+	}
+	if len(a.allowedDomains) > 0 && !matchesDomain(id.Email, a.allowedDomains) {
+		return nil, fmt.Errorf("%w: email domain not allowed", plugin.ErrInvalidToken)
+	}
+	return &plugin.Identity{
+		ID:       "cfaccess:" + id.UserUUID,
+		Username: id.Email,
+		Email:    id.Email,
+		Role:     a.role(id),
+		Labels:   map[string]string{"idp": "cfaccess"},
+	}, nil
+}
+
+// role applies the configured mapping rules, most specific first:
+// groups, then claims, then email domains. No match means plain user.
+func (a *cfAccessAuth) role(id identity) string {
+	for _, g := range id.Groups {
+		for _, admin := range a.adminGroups {
+			if strings.EqualFold(g.Name, admin) {
+				return "admin"
+			}
+		}
+	}
+	for claim, want := range a.adminClaims {
+		if got, ok := id.Extra[claim]; ok && fmt.Sprintf("%v", got) == want {
+			return "admin"
+		}
+	}
+	if matchesDomain(id.Email, a.adminDomains) {
+		return "admin"
+	}
+	return "user"
+}
+
+// matchesDomain reports whether the email's domain is in the list.
+func matchesDomain(email string, domains []string) bool {
+	_, domain, found := strings.Cut(email, "@")
+	if !found {
+		return false
+	}
+	for _, d := range domains {
+		if strings.EqualFold(domain, d) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitList parses a comma-separated env value into trimmed non-empty
+// entries.
+func splitList(value string) []string {
+	var out []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func main() {
+	teamDomain := os.Getenv("WEBENCODE_CF_TEAM_DOMAIN")
+	if teamDomain == "" {
+		log.Fatal("auth-cfaccess: WEBENCODE_CF_TEAM_DOMAIN is required")
+	}
+	if !strings.Contains(teamDomain, ".") {
+		teamDomain += ".cloudflareaccess.com"
+	}
+	adminClaims := make(map[string]string)
+	for _, pair := range splitList(os.Getenv("WEBENCODE_CF_ADMIN_CLAIMS")) {
+		claim, value, found := strings.Cut(pair, "=")
+		if !found {
+			log.Fatalf("auth-cfaccess: malformed admin claim %q, want claim=value", pair)
+		}
+		adminClaims[claim] = value
+	}
+	plugin.ServeAuth(&cfAccessAuth{
+		teamDomain:     teamDomain,
+		adminGroups:    splitList(os.Getenv("WEBENCODE_CF_ADMIN_GROUPS")),
+		adminClaims:    adminClaims,
+		adminDomains:   splitList(os.Getenv("WEBENCODE_CF_ADMIN_EMAIL_DOMAINS")),
+		allowedDomains: splitList(os.Getenv("WEBENCODE_CF_ALLOWED_EMAIL_DOMAINS")),
+		client:         &http.Client{Timeout: 15 * time.Second},
+	})
+}
@@ -0,0 +1,203 @@
+// Command auth-forge validates GitHub or GitLab OAuth tokens and maps
+// org/team (group) membership to WebEncode roles, for studios that
+// already manage access through their code forge.
+//
+// Configuration:
+//
+//	WEBENCODE_FORGE             "github" or "gitlab"
+//	WEBENCODE_FORGE_URL         API base override for self-hosted
+//	                            installs (GitHub Enterprise, GitLab CE)
+//	WEBENCODE_FORGE_ORG         org (GitHub) or group path (GitLab) a
+//	                            user must belong to; empty admits any
+//	                            authenticated forge user
+//	WEBENCODE_FORGE_ADMIN_TEAMS comma-separated team slugs (GitHub) or
+//	                            subgroup paths (GitLab) whose members
+//	                            get the admin role
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rennerdo30/webencode/pkg/plugin"
+)
+
+type forgeAuth struct {
+	forge      string
+	apiBase    string
+	org        string
+	adminTeams []string
+	client     *http.Client
+}
+
+func (a *forgeAuth) Name() (string, error) { return "forge-" + a.forge, nil }
+
+func (a *forgeAuth) Authenticate(token string) (*plugin.Identity, error) {
+	switch a.forge {
+	case "github":
+		return a.authenticateGitHub(token)
+	case "gitlab":
+		return a.authenticateGitLab(token)
+	default:
+		return nil, fmt.Errorf("unsupported forge %q", a.forge)
+	}
+}
+
+// get issues an authenticated GET and decodes the JSON body into out.
+// A 401/403 from the forge means the token is bad, not the plugin.
+func (a *forgeAuth) get(token, path string, out interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, a.apiBase+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if a.forge == "github" {
+		req.Header.Set("Accept", "application/vnd.github+json")
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return plugin.ErrInvalidToken
+	case resp.StatusCode == http.StatusNotFound:
+		return errNotFound
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return fmt.Errorf("%s returned %s", path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+var errNotFound = fmt.Errorf("not found")
+
+func (a *forgeAuth) authenticateGitHub(token string) (*plugin.Identity, error) {
+	var user struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Email string `json:"email"`
+	}
+	if err := a.get(token, "/user", &user); err != nil {
+		return nil, err
+	}
+	if a.org != "" {
+		var membership struct {
+			State string `json:"state"`
+		}
+		err := a.get(token, "/user/memberships/orgs/"+a.org, &membership)
+		if err == errNotFound || (err == nil && membership.State != "active") {
+			return nil, fmt.Errorf("%w: not a member of org %s", plugin.ErrInvalidToken, a.org)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	role := "user"
+	for _, team := range a.adminTeams {
+		var membership struct {
+			State string `json:"state"`
+		}
+		err := a.get(token, "/orgs/"+a.org+"/teams/"+team+"/memberships/"+user.Login, &membership)
+		if err == errNotFound {
+			continue
+		}
+		if err != nil && !plugin.IsInvalidToken(err) {
+			return nil, err
+		}
+		if err == nil && membership.State == "active" {
+			role = "admin"
+			break
+		}
+	}
+	return &plugin.Identity{
+		ID:       "github:" + strconv.FormatInt(user.ID, 10),
+		Username: user.Login,
+		Email:    user.Email,
+		Role:     role,
+		Labels:   map[string]string{"forge": "github", "org": a.org},
+	}, nil
+}
+
+func (a *forgeAuth) authenticateGitLab(token string) (*plugin.Identity, error) {
+	var user struct {
+		ID       int64  `json:"id"`
+		Username string `json:"username"`
+		Email    string `json:"email"`
+	}
+	if err := a.get(token, "/user", &user); err != nil {
+		return nil, err
+	}
+	// GitLab has no per-group membership probe usable with a user
+	// token; list the user's groups and match paths instead.
+	var groups []struct {
+		FullPath string `json:"full_path"`
+	}
+	if err := a.get(token, "/groups?min_access_level=10&per_page=100", &groups); err != nil {
+		return nil, err
+	}
+	inGroup := func(path string) bool {
+		for _, g := range groups {
+			if g.FullPath == path {
+				return true
+			}
+		}
+		return false
+	}
+	if a.org != "" && !inGroup(a.org) {
+		return nil, fmt.Errorf("%w: not a member of group %s", plugin.ErrInvalidToken, a.org)
+	}
+	role := "user"
+	for _, team := range a.adminTeams {
+		// Admin subgroups are addressed relative to the base group.
+		path := team
+		if a.org != "" && !strings.Contains(team, "/") {
+			path = a.org + "/" + team
+		}
+		if inGroup(path) {
+			role = "admin"
+			break
+		}
+	}
+	return &plugin.Identity{
+		ID:       "gitlab:" + strconv.FormatInt(user.ID, 10),
+		Username: user.Username,
+		Email:    user.Email,
+		Role:     role,
+		Labels:   map[string]string{"forge": "gitlab", "org": a.org},
+	}, nil
+}
+
+func main() {
+	forge := os.Getenv("WEBENCODE_FORGE")
+	if forge != "github" && forge != "gitlab" {
+		log.Fatalf("auth-forge: WEBENCODE_FORGE must be github or gitlab, got %q", forge)
+	}
+	apiBase := os.Getenv("WEBENCODE_FORGE_URL")
+	if apiBase == "" {
+		if forge == "github" {
+			apiBase = "https://api.github.com"
+		} else {
+			apiBase = "https://gitlab.com/api/v4"
+		}
+	}
+	var adminTeams []string
+	for _, t := range strings.Split(os.Getenv("WEBENCODE_FORGE_ADMIN_TEAMS"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			adminTeams = append(adminTeams, t)
+		}
+	}
+	plugin.ServeAuth(&forgeAuth{
+		forge:      forge,
+		apiBase:    strings.TrimSuffix(apiBase, "/"),
+		org:        os.Getenv("WEBENCODE_FORGE_ORG"),
+		adminTeams: adminTeams,
+		client:     &http.Client{Timeout: 15 * time.Second},
+	})
+}
@@ -0,0 +1,260 @@
+// Command auth-ldap authenticates users against an LDAP directory. The
+// bearer token is base64("username:password") — HTTP Basic semantics
+// carried through the kernel's bearer slot. Group membership maps to
+// WebEncode roles with full nested-group resolution: Active Directory
+// servers are asked with the matching-rule-in-chain filter, everything
+// else falls back to a recursive memberOf walk.
+//
+// Configuration:
+//
+//	WEBENCODE_LDAP_URL            ldap:// or ldaps:// server URL
+//	WEBENCODE_LDAP_STARTTLS       "true" upgrades ldap:// with StartTLS
+//	WEBENCODE_LDAP_CA_CERT        PEM file with the server's CA; empty
+//	                              uses the system pool
+//	WEBENCODE_LDAP_BIND_DN        service account for user/group lookups
+//	WEBENCODE_LDAP_BIND_PASSWORD  service account password
+//	WEBENCODE_LDAP_BASE_DN        search base
+//	WEBENCODE_LDAP_USER_FILTER    user search filter, %s is the
+//	                              username (default "(uid=%s)")
+//	WEBENCODE_LDAP_ROLE_MAP       semicolon-separated groupDN=role
+//	                              pairs, e.g.
+//	                              "cn=ops,ou=groups,dc=acme,dc=io=admin"
+//	WEBENCODE_LDAP_NESTED         "chain" (AD matching rule),
+//	                              "recursive", or "off"
+//	                              (default "chain" with recursive
+//	                              fallback)
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/rennerdo30/webencode/pkg/plugin"
+)
+
+// matchingRuleInChain is Active Directory's transitive-membership
+// matching rule OID.
+const matchingRuleInChain = "1.2.840.113556.1.4.1941"
+
+type ldapAuth struct {
+	url          string
+	startTLS     bool
+	tlsConfig    *tls.Config
+	bindDN       string
+	bindPassword string
+	baseDN       string
+	userFilter   string
+	// roleMap maps lowercase group DNs to roles; admin wins over user
+	// when a user is in several mapped groups.
+	roleMap map[string]string
+	nested  string
+}
+
+func (a *ldapAuth) Name() (string, error) { return "ldap", nil }
+
+func (a *ldapAuth) Authenticate(token string) (*plugin.Identity, error) {
+	decoded, err := base64.StdEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: token is not base64 basic credentials", plugin.ErrInvalidToken)
+	}
+	username, password, found := strings.Cut(string(decoded), ":")
+	if !found || username == "" || password == "" {
+		return nil, fmt.Errorf("%w: token is not username:password", plugin.ErrInvalidToken)
+	}
+
+	conn, err := a.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if a.bindDN != "" {
+		if err := conn.Bind(a.bindDN, a.bindPassword); err != nil {
+			return nil, fmt.Errorf("service bind: %w", err)
+		}
+	}
+	entry, err := a.findUser(conn, username)
+	if err != nil {
+		return nil, err
+	}
+	// Verify the password by binding as the user, then rebind as the
+	// service account for group resolution.
+	if err := conn.Bind(entry.DN, password); err != nil {
+		return nil, fmt.Errorf("%w: bad credentials", plugin.ErrInvalidToken)
+	}
+	if a.bindDN != "" {
+		if err := conn.Bind(a.bindDN, a.bindPassword); err != nil {
+			return nil, fmt.Errorf("service rebind: %w", err)
+		}
+	}
+
+	groups, err := a.resolveGroups(conn, entry)
+	if err != nil {
+		return nil, err
+	}
+	role := "user"
+	for _, g := range groups {
+		if a.roleMap[strings.ToLower(g)] == "admin" {
+			role = "admin"
+			break
+		}
+	}
+	email := entry.GetAttributeValue("mail")
+	return &plugin.Identity{
+		ID:       "ldap:" + entry.DN,
+		Username: username,
+		Email:    email,
+		Role:     role,
+		Labels:   map[string]string{"idp": "ldap"},
+	}, nil
+}
+
+// connect dials the directory, upgrading with StartTLS when configured.
+func (a *ldapAuth) connect() (*ldap.Conn, error) {
+	conn, err := ldap.DialURL(a.url, ldap.DialWithTLSConfig(a.tlsConfig))
+	if err != nil {
+		return nil, err
+	}
+	if a.startTLS {
+		if err := conn.StartTLS(a.tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("starttls: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// findUser locates the user's entry under the base DN.
+func (a *ldapAuth) findUser(conn *ldap.Conn, username string) (*ldap.Entry, error) {
+	filter := fmt.Sprintf(a.userFilter, ldap.EscapeFilter(username))
+	res, err := conn.Search(ldap.NewSearchRequest(
+		a.baseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, 0, false,
+		filter, []string{"dn", "mail", "memberOf"}, nil,
+	))
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Entries) != 1 {
+		return nil, fmt.Errorf("%w: unknown user", plugin.ErrInvalidToken)
+	}
+	return res.Entries[0], nil
+}
+
+// resolveGroups returns every group DN the user belongs to, including
+// nested memberships.
+func (a *ldapAuth) resolveGroups(conn *ldap.Conn, entry *ldap.Entry) ([]string, error) {
+	direct := entry.GetAttributeValues("memberOf")
+	if a.nested == "off" {
+		return direct, nil
+	}
+	if a.nested != "recursive" {
+		// Ask the server to do the transitive closure; directories
+		// without the matching rule just return nothing, so fall
+		// through to the recursive walk.
+		res, err := conn.Search(ldap.NewSearchRequest(
+			a.baseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+			fmt.Sprintf("(member:%s:=%s)", matchingRuleInChain, ldap.EscapeFilter(entry.DN)),
+			[]string{"dn"}, nil,
+		))
+		if err == nil && len(res.Entries) > 0 {
+			groups := make([]string, 0, len(res.Entries))
+			for _, e := range res.Entries {
+				groups = append(groups, e.DN)
+			}
+			return groups, nil
+		}
+	}
+	return a.walkGroups(conn, direct)
+}
+
+// walkGroups recursively expands memberOf until no new groups appear.
+func (a *ldapAuth) walkGroups(conn *ldap.Conn, start []string) ([]string, error) {
+	seen := make(map[string]bool)
+	queue := append([]string(nil), start...)
+	var out []string
+	for len(queue) > 0 {
+		dn := queue[0]
+		queue = queue[1:]
+		if seen[strings.ToLower(dn)] {
+			continue
+		}
+		seen[strings.ToLower(dn)] = true
+		out = append(out, dn)
+		res, err := conn.Search(ldap.NewSearchRequest(
+			dn, ldap.ScopeBaseObject, ldap.NeverDerefAliases, 1, 0, false,
+			"(objectClass=*)", []string{"memberOf"}, nil,
+		))
+		if err != nil {
+			// A group outside the searchable tree still counts as a
+			// membership; skip expanding it.
+			continue
+		}
+		for _, e := range res.Entries {
+			queue = append(queue, e.GetAttributeValues("memberOf")...)
+		}
+	}
+	return out, nil
+}
+
+func main() {
+	serverURL := os.Getenv("WEBENCODE_LDAP_URL")
+	if serverURL == "" {
+		log.Fatal("auth-ldap: WEBENCODE_LDAP_URL is required")
+	}
+	parsed, err := url.Parse(serverURL)
+	if err != nil {
+		log.Fatalf("auth-ldap: bad WEBENCODE_LDAP_URL: %v", err)
+	}
+	tlsConfig := &tls.Config{ServerName: parsed.Hostname()}
+	if caPath := os.Getenv("WEBENCODE_LDAP_CA_CERT"); caPath != "" {
+		pem, err := os.ReadFile(caPath)
+		if err != nil {
+			log.Fatalf("auth-ldap: read CA cert: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			log.Fatalf("auth-ldap: no certificates in %s", caPath)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	userFilter := os.Getenv("WEBENCODE_LDAP_USER_FILTER")
+	if userFilter == "" {
+		userFilter = "(uid=%s)"
+	}
+	roleMap := make(map[string]string)
+	for _, pair := range strings.Split(os.Getenv("WEBENCODE_LDAP_ROLE_MAP"), ";") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		idx := strings.LastIndex(pair, "=")
+		if idx <= 0 {
+			log.Fatalf("auth-ldap: malformed role mapping %q, want groupDN=role", pair)
+		}
+		roleMap[strings.ToLower(pair[:idx])] = pair[idx+1:]
+	}
+	nested := os.Getenv("WEBENCODE_LDAP_NESTED")
+	switch nested {
+	case "", "chain", "recursive", "off":
+	default:
+		log.Fatalf("auth-ldap: WEBENCODE_LDAP_NESTED must be chain, recursive, or off, got %q", nested)
+	}
+	plugin.ServeAuth(&ldapAuth{
+		url:          serverURL,
+		startTLS:     os.Getenv("WEBENCODE_LDAP_STARTTLS") == "true",
+		tlsConfig:    tlsConfig,
+		bindDN:       os.Getenv("WEBENCODE_LDAP_BIND_DN"),
+		bindPassword: os.Getenv("WEBENCODE_LDAP_BIND_PASSWORD"),
+		baseDN:       os.Getenv("WEBENCODE_LDAP_BASE_DN"),
+		userFilter:   userFilter,
+		roleMap:      roleMap,
+		nested:       nested,
+	})
+}
@@ -0,0 +1,215 @@
+// Command encoder-gstreamer runs transcodes through gst-launch-1.0
+// pipelines as an alternative to ffmpeg, useful where hardware elements
+// (v4l2, NVMM on Jetson) are better supported than local ffmpeg builds.
+// Encoder elements default to x264enc/avenc_aac and can be overridden
+// with GST_H264_ENCODER, GST_H265_ENCODER and GST_AAC_ENCODER (e.g.
+// nvv4l2h264enc on Jetson). GST_LAUNCH overrides the binary path.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/rennerdo30/webencode/pkg/plugin"
+)
+
+// encodeJob tracks one running gst-launch process.
+type encodeJob struct {
+	cmd    *exec.Cmd
+	output string
+
+	mu       sync.Mutex
+	state    string
+	err      string
+	canceled bool
+}
+
+type gstEncoder struct {
+	launch string
+
+	mu   sync.Mutex
+	jobs map[string]*encodeJob
+}
+
+func (e *gstEncoder) Name() (string, error) { return "gstreamer", nil }
+
+// HealthCheck verifies the gst-launch binary is runnable.
+func (e *gstEncoder) HealthCheck() error {
+	return exec.Command(e.launch, "--version").Run()
+}
+
+func (e *gstEncoder) Submit(spec plugin.EncodeSpec) (string, error) {
+	output, err := outputPath(spec)
+	if err != nil {
+		return "", err
+	}
+	args, err := pipeline(spec, output)
+	if err != nil {
+		return "", err
+	}
+	cmd := exec.Command(e.launch, args...)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("start %s: %w", e.launch, err)
+	}
+
+	id := uuid.NewString()
+	job := &encodeJob{cmd: cmd, output: output, state: "progressing"}
+	e.mu.Lock()
+	e.jobs[id] = job
+	e.mu.Unlock()
+
+	go func() {
+		err := cmd.Wait()
+		job.mu.Lock()
+		defer job.mu.Unlock()
+		switch {
+		case job.canceled:
+			job.state = "canceled"
+		case err != nil:
+			job.state = "error"
+			job.err = err.Error()
+		default:
+			job.state = "complete"
+		}
+	}()
+	return id, nil
+}
+
+func (e *gstEncoder) Status(id string) (*plugin.EncodeStatus, error) {
+	e.mu.Lock()
+	job, ok := e.jobs[id]
+	e.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown encode %q", id)
+	}
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	st := &plugin.EncodeStatus{ID: id, State: job.state, Error: job.err}
+	if job.state == "complete" {
+		st.ProgressPct = 100
+		st.OutputURLs = []string{job.output}
+	}
+	return st, nil
+}
+
+func (e *gstEncoder) Cancel(id string) error {
+	e.mu.Lock()
+	job, ok := e.jobs[id]
+	e.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown encode %q", id)
+	}
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	if job.state != "progressing" {
+		return nil
+	}
+	job.canceled = true
+	return job.cmd.Process.Kill()
+}
+
+// outputPath derives the rendition filename under the output prefix.
+// GStreamer writes to the filesystem, so the prefix must be a local
+// path (uploads to object storage stay the worker's job).
+func outputPath(spec plugin.EncodeSpec) (string, error) {
+	if strings.Contains(spec.OutputPrefix, "://") {
+		return "", errors.New("gstreamer needs a local output prefix")
+	}
+	container := spec.Container
+	if container == "" {
+		container = "mp4"
+	}
+	if err := os.MkdirAll(spec.OutputPrefix, 0o755); err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%dx%d.%s", spec.Width, spec.Height, container)
+	return filepath.Join(spec.OutputPrefix, name), nil
+}
+
+// pipeline builds the gst-launch-1.0 argument list for the spec.
+func pipeline(spec plugin.EncodeSpec, output string) ([]string, error) {
+	source := spec.SourceURL
+	if !strings.Contains(source, "://") {
+		source = "file://" + source
+	}
+
+	var videoEnc, parse string
+	switch spec.VideoCodec {
+	case "", "libx264", "h264":
+		videoEnc = envOr("GST_H264_ENCODER", "x264enc")
+		parse = "h264parse"
+	case "libx265", "hevc":
+		videoEnc = envOr("GST_H265_ENCODER", "x265enc")
+		parse = "h265parse"
+	default:
+		return nil, fmt.Errorf("unsupported video codec %q", spec.VideoCodec)
+	}
+	if spec.VideoBitrateKbps > 0 {
+		// Both x264enc/x265enc and the v4l2/NVMM elements take kbps.
+		videoEnc += fmt.Sprintf(" bitrate=%d", spec.VideoBitrateKbps)
+	}
+
+	var mux string
+	switch spec.Container {
+	case "", "mp4":
+		mux = "mp4mux"
+	case "mkv", "webm":
+		mux = "matroskamux"
+	case "mpegts":
+		mux = "mpegtsmux"
+	default:
+		return nil, fmt.Errorf("unsupported container %q", spec.Container)
+	}
+
+	videoChain := []string{"d.", "queue", "videoconvert"}
+	if spec.Width > 0 && spec.Height > 0 {
+		videoChain = append(videoChain, "videoscale",
+			fmt.Sprintf("video/x-raw,width=%d,height=%d", spec.Width, spec.Height))
+	}
+	if spec.Framerate > 0 {
+		videoChain = append(videoChain, "videorate",
+			fmt.Sprintf("video/x-raw,framerate=%d/1", int(spec.Framerate)))
+	}
+	videoChain = append(videoChain, videoEnc, parse, "mux.")
+
+	audioEnc := envOr("GST_AAC_ENCODER", "avenc_aac")
+	if spec.AudioBitrateKbps > 0 {
+		audioEnc += fmt.Sprintf(" bitrate=%d", spec.AudioBitrateKbps*1000)
+	}
+	audioChain := []string{"d.", "queue", "audioconvert", "audioresample", audioEnc, "mux."}
+
+	args := []string{"-e", "uridecodebin", "uri=" + source, "name=d",
+		mux, "name=mux", "!", "filesink", "location=" + output}
+	for _, chain := range [][]string{videoChain, audioChain} {
+		for i, elem := range chain {
+			if i > 0 {
+				args = append(args, "!")
+			}
+			args = append(args, strings.Fields(elem)...)
+		}
+	}
+	return args, nil
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func main() {
+	enc := &gstEncoder{
+		launch: envOr("GST_LAUNCH", "gst-launch-1.0"),
+		jobs:   make(map[string]*encodeJob),
+	}
+	plugin.ServeEncoder(enc)
+}
@@ -0,0 +1,295 @@
+// Command encoder-mediaconvert offloads transcodes to AWS Elemental
+// MediaConvert so installs without local encode capacity keep the same
+// pipeline. Requires AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY,
+// AWS_REGION, the account-specific MEDIACONVERT_ENDPOINT and the
+// MEDIACONVERT_ROLE_ARN MediaConvert assumes to read/write S3.
+// Source and output prefix must both be s3:// URLs.
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rennerdo30/webencode/pkg/plugin"
+)
+
+const apiVersion = "/2017-08-29"
+
+type mediaConvertEncoder struct {
+	endpoint  string
+	region    string
+	accessKey string
+	secretKey string
+	roleARN   string
+	queue     string
+	client    *http.Client
+}
+
+func (e *mediaConvertEncoder) Name() (string, error) { return "mediaconvert", nil }
+
+// HealthCheck lists zero jobs to prove the endpoint and credentials
+// work.
+func (e *mediaConvertEncoder) HealthCheck() error {
+	var resp json.RawMessage
+	return e.call(http.MethodGet, apiVersion+"/jobs?maxResults=1", nil, &resp)
+}
+
+func (e *mediaConvertEncoder) Submit(spec plugin.EncodeSpec) (string, error) {
+	if !strings.HasPrefix(spec.SourceURL, "s3://") {
+		return "", errors.New("mediaconvert needs an s3:// source URL")
+	}
+	if !strings.HasPrefix(spec.OutputPrefix, "s3://") {
+		return "", errors.New("mediaconvert needs an s3:// output prefix")
+	}
+	job := map[string]interface{}{
+		"Role": e.roleARN,
+		"Settings": map[string]interface{}{
+			"Inputs": []interface{}{map[string]interface{}{
+				"FileInput":      spec.SourceURL,
+				"AudioSelectors": map[string]interface{}{"Audio Selector 1": map[string]interface{}{"DefaultSelection": "DEFAULT"}},
+				"VideoSelector":  map[string]interface{}{},
+			}},
+			"OutputGroups": []interface{}{map[string]interface{}{
+				"OutputGroupSettings": map[string]interface{}{
+					"Type":              "FILE_GROUP_SETTINGS",
+					"FileGroupSettings": map[string]interface{}{"Destination": strings.TrimSuffix(spec.OutputPrefix, "/") + "/"},
+				},
+				"Outputs": []interface{}{e.output(spec)},
+			}},
+		},
+	}
+	if e.queue != "" {
+		job["Queue"] = e.queue
+	}
+	var created struct {
+		Job struct {
+			ID string `json:"id"`
+		} `json:"job"`
+	}
+	if err := e.call(http.MethodPost, apiVersion+"/jobs", job, &created); err != nil {
+		return "", err
+	}
+	return created.Job.ID, nil
+}
+
+// output maps the flattened spec onto a MediaConvert output block.
+// MediaConvert accepts far fewer codecs than ffmpeg; unsupported ones
+// fall back to H.264/AAC.
+func (e *mediaConvertEncoder) output(spec plugin.EncodeSpec) map[string]interface{} {
+	videoBitrate := spec.VideoBitrateKbps * 1000
+	if videoBitrate <= 0 {
+		videoBitrate = 5_000_000
+	}
+	audioBitrate := spec.AudioBitrateKbps * 1000
+	if audioBitrate <= 0 {
+		audioBitrate = 128_000
+	}
+
+	videoCodec := "H_264"
+	codecSettings := map[string]interface{}{
+		"Codec": videoCodec,
+		"H264Settings": map[string]interface{}{
+			"Bitrate":         videoBitrate,
+			"RateControlMode": "CBR",
+		},
+	}
+	if spec.VideoCodec == "libx265" || spec.VideoCodec == "hevc" {
+		codecSettings = map[string]interface{}{
+			"Codec": "H_265",
+			"H265Settings": map[string]interface{}{
+				"Bitrate":         videoBitrate,
+				"RateControlMode": "CBR",
+			},
+		}
+	}
+
+	videoDescription := map[string]interface{}{"CodecSettings": codecSettings}
+	if spec.Width > 0 {
+		videoDescription["Width"] = spec.Width
+	}
+	if spec.Height > 0 {
+		videoDescription["Height"] = spec.Height
+	}
+
+	container := "MP4"
+	if spec.Container == "mkv" || spec.Container == "webm" {
+		container = "MKV"
+	}
+	return map[string]interface{}{
+		"ContainerSettings": map[string]interface{}{"Container": container},
+		"VideoDescription":  videoDescription,
+		"AudioDescriptions": []interface{}{map[string]interface{}{
+			"AudioSourceName": "Audio Selector 1",
+			"CodecSettings": map[string]interface{}{
+				"Codec":       "AAC",
+				"AacSettings": map[string]interface{}{"Bitrate": audioBitrate, "CodingMode": "CODING_MODE_2_0", "SampleRate": 48000},
+			},
+		}},
+	}
+}
+
+func (e *mediaConvertEncoder) Status(id string) (*plugin.EncodeStatus, error) {
+	var resp struct {
+		Job struct {
+			ID              string `json:"id"`
+			Status          string `json:"status"`
+			PercentComplete int    `json:"jobPercentComplete"`
+			ErrorMessage    string `json:"errorMessage"`
+		} `json:"job"`
+	}
+	if err := e.call(http.MethodGet, apiVersion+"/jobs/"+id, nil, &resp); err != nil {
+		return nil, err
+	}
+	st := &plugin.EncodeStatus{ID: id, ProgressPct: resp.Job.PercentComplete, Error: resp.Job.ErrorMessage}
+	switch resp.Job.Status {
+	case "SUBMITTED":
+		st.State = "submitted"
+	case "PROGRESSING":
+		st.State = "progressing"
+	case "COMPLETE":
+		st.State = "complete"
+	case "CANCELED":
+		st.State = "canceled"
+	default:
+		st.State = "error"
+	}
+	return st, nil
+}
+
+func (e *mediaConvertEncoder) Cancel(id string) error {
+	var resp json.RawMessage
+	return e.call(http.MethodDelete, apiVersion+"/jobs/"+id, nil, &resp)
+}
+
+// call performs one SigV4-signed MediaConvert API request.
+func (e *mediaConvertEncoder) call(method, path string, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, e.endpoint+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	e.sign(req, payload)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(data, &apiErr)
+		if apiErr.Message != "" {
+			return fmt.Errorf("mediaconvert: %s: %s", resp.Status, apiErr.Message)
+		}
+		return fmt.Errorf("mediaconvert: %s", resp.Status)
+	}
+	return json.Unmarshal(data, out)
+}
+
+// sign applies AWS Signature Version 4 for the mediaconvert service,
+// hand-rolled so the plugin does not pull in the AWS SDK.
+func (e *mediaConvertEncoder) sign(req *http.Request, payload []byte) {
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+	payloadHash := sha256.Sum256(payload)
+
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payloadHash[:]))
+
+	signedHeaders := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	var canonicalHeaders strings.Builder
+	for _, h := range signedHeaders {
+		canonicalHeaders.WriteString(h + ":" + strings.TrimSpace(req.Header.Get(h)) + "\n")
+	}
+
+	query := req.URL.Query()
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var canonicalQuery strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			canonicalQuery.WriteString("&")
+		}
+		canonicalQuery.WriteString(k + "=" + query.Get(k))
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery.String(),
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := dateStamp + "/" + e.region + "/mediaconvert/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+e.secretKey), dateStamp)
+	signingKey = hmacSHA256(signingKey, e.region)
+	signingKey = hmacSHA256(signingKey, "mediaconvert")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		e.accessKey, scope, strings.Join(signedHeaders, ";"), signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func main() {
+	enc := &mediaConvertEncoder{
+		endpoint:  strings.TrimSuffix(os.Getenv("MEDIACONVERT_ENDPOINT"), "/"),
+		region:    os.Getenv("AWS_REGION"),
+		accessKey: os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		roleARN:   os.Getenv("MEDIACONVERT_ROLE_ARN"),
+		queue:     os.Getenv("MEDIACONVERT_QUEUE"),
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}
+	if enc.endpoint == "" || enc.region == "" || enc.accessKey == "" || enc.secretKey == "" || enc.roleARN == "" {
+		fmt.Fprintln(os.Stderr, "encoder-mediaconvert: MEDIACONVERT_ENDPOINT, MEDIACONVERT_ROLE_ARN, AWS_REGION and AWS credentials are required")
+		os.Exit(1)
+	}
+	plugin.ServeEncoder(enc)
+}
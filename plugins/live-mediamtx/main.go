@@ -0,0 +1,179 @@
+// Command live-mediamtx manages live ingest through a MediaMTX server
+// using its v3 control API. Configuration:
+//
+//	MEDIAMTX_API_URL   control API base, default http://localhost:9997
+//	MEDIAMTX_HOST      public hostname broadcasters connect to
+//	MEDIAMTX_RTMP_PORT default 1935
+//	MEDIAMTX_SRT_PORT  default 8890
+//	MEDIAMTX_HLS_PORT  default 8888
+//	MEDIAMTX_WEBRTC_PORT default 8889
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/rennerdo30/webencode/pkg/plugin"
+)
+
+type mediamtx struct {
+	apiURL     string
+	host       string
+	rtmpPort   string
+	srtPort    string
+	hlsPort    string
+	webrtcPort string
+	client     *http.Client
+
+	// passphrases remembers the SRT passphrase per path so Telemetry
+	// and StopIngest can find the connection again.
+	passphrases map[string]string
+}
+
+func (m *mediamtx) Name() (string, error) { return "mediamtx", nil }
+
+// pathName maps a WebEncode stream ID to a MediaMTX path.
+func pathName(streamID string) string { return "live/" + streamID }
+
+// StartIngest adds a MediaMTX path for the stream and returns RTMP and
+// SRT endpoints. The SRT link is encrypted with a generated passphrase.
+func (m *mediamtx) StartIngest(streamID string) (*plugin.IngestEndpoints, error) {
+	passphrase, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	path := pathName(streamID)
+	conf := map[string]interface{}{
+		"source":               "publisher",
+		"srtPublishPassphrase": passphrase,
+		"srtReadPassphrase":    passphrase,
+	}
+	if err := m.call(http.MethodPost, "/v3/config/paths/add/"+url.PathEscape(path), conf, nil); err != nil {
+		return nil, err
+	}
+	m.passphrases[path] = passphrase
+
+	srtQuery := url.Values{}
+	srtQuery.Set("streamid", "publish:"+path)
+	srtQuery.Set("passphrase", passphrase)
+	return &plugin.IngestEndpoints{
+		RTMPURL:       fmt.Sprintf("rtmp://%s:%s/%s", m.host, m.rtmpPort, path),
+		SRTURL:        fmt.Sprintf("srt://%s:%s?%s", m.host, m.srtPort, srtQuery.Encode()),
+		SRTPassphrase: passphrase,
+		WHIPURL:       fmt.Sprintf("http://%s:%s/%s/whip", m.host, m.webrtcPort, path),
+		PlaybackURL:   fmt.Sprintf("http://%s:%s/%s/index.m3u8", m.host, m.hlsPort, path),
+		WHEPURL:       fmt.Sprintf("http://%s:%s/%s/whep", m.host, m.webrtcPort, path),
+	}, nil
+}
+
+// StopIngest removes the path, disconnecting any publisher.
+func (m *mediamtx) StopIngest(streamID string) error {
+	path := pathName(streamID)
+	delete(m.passphrases, path)
+	return m.call(http.MethodDelete, "/v3/config/paths/delete/"+url.PathEscape(path), nil, nil)
+}
+
+// Telemetry reads path state and, for SRT publishers, link stats from
+// the srtconns list.
+func (m *mediamtx) Telemetry(streamID string) (*plugin.LiveTelemetry, error) {
+	path := pathName(streamID)
+	var pathInfo struct {
+		Ready  bool `json:"ready"`
+		Source *struct {
+			Type string `json:"type"`
+			ID   string `json:"id"`
+		} `json:"source"`
+		BytesReceived int64 `json:"bytesReceived"`
+	}
+	if err := m.call(http.MethodGet, "/v3/paths/get/"+url.PathEscape(path), nil, &pathInfo); err != nil {
+		return nil, err
+	}
+	out := &plugin.LiveTelemetry{Connected: pathInfo.Ready}
+	if pathInfo.Source == nil || pathInfo.Source.Type != "srtConn" {
+		return out, nil
+	}
+
+	var conn struct {
+		MsRTT                float64 `json:"msRTT"`
+		PacketsRetransmitted int64   `json:"packetsSentRetransmitted"`
+		PacketsReceivedLost  int64   `json:"packetsReceivedLost"`
+		MbpsLinkCapacity     float64 `json:"mbpsLinkCapacity"`
+		MbpsReceiveRate      float64 `json:"mbpsReceiveRate"`
+	}
+	if err := m.call(http.MethodGet, "/v3/srtconns/get/"+url.PathEscape(pathInfo.Source.ID), nil, &conn); err != nil {
+		return out, nil // path info alone is still useful
+	}
+	out.BitrateKbps = conn.MbpsReceiveRate * 1000
+	out.SRT = &plugin.SRTStats{
+		RTTMs:             conn.MsRTT,
+		PacketsRetrans:    conn.PacketsRetransmitted,
+		PacketsLost:       conn.PacketsReceivedLost,
+		LinkBandwidthMbps: conn.MbpsLinkCapacity,
+	}
+	return out, nil
+}
+
+func (m *mediamtx) call(method, path string, body, out interface{}) error {
+	var reader *bytes.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(raw)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequest(method, m.apiURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("mediamtx %s %s returned %s", method, path, resp.Status)
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func main() {
+	plugin.ServeLive(&mediamtx{
+		apiURL:      envOr("MEDIAMTX_API_URL", "http://localhost:9997"),
+		host:        envOr("MEDIAMTX_HOST", "localhost"),
+		rtmpPort:    envOr("MEDIAMTX_RTMP_PORT", "1935"),
+		srtPort:     envOr("MEDIAMTX_SRT_PORT", "8890"),
+		hlsPort:     envOr("MEDIAMTX_HLS_PORT", "8888"),
+		webrtcPort:  envOr("MEDIAMTX_WEBRTC_PORT", "8889"),
+		client:      &http.Client{Timeout: 10 * time.Second},
+		passphrases: make(map[string]string),
+	})
+}
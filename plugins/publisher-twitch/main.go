@@ -0,0 +1,133 @@
+// Command publisher-twitch publishes finished outputs as Twitch videos
+// via the Helix API. Twitch has no playlist or scheduled-publish
+// concept, so those request fields are ignored; Category maps to a game
+// ID. Requires TWITCH_ACCESS_TOKEN and TWITCH_CLIENT_ID.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rennerdo30/webencode/pkg/plugin"
+)
+
+const apiBase = "https://api.twitch.tv/helix"
+
+type twitchPublisher struct {
+	token    string
+	clientID string
+	client   *http.Client
+}
+
+func (p *twitchPublisher) Name() (string, error) { return "twitch", nil }
+
+func (p *twitchPublisher) Publish(req plugin.PublishRequest) (*plugin.PublishResult, error) {
+	q := url.Values{}
+	q.Set("title", req.Title)
+	if req.Description != "" {
+		q.Set("description", req.Description)
+	}
+	if len(req.Tags) > 0 {
+		q.Set("tag_list", strings.Join(req.Tags, ","))
+	}
+	if req.Category != "" {
+		q.Set("game_id", req.Category)
+	}
+	// Twitch videos are either public or private; treat unlisted as
+	// private, and keep private as the default.
+	if req.Visibility == plugin.VisibilityPublic {
+		q.Set("viewable", "public")
+	} else {
+		q.Set("viewable", "private")
+	}
+	q.Set("video_url", req.FileURL)
+	if req.ThumbnailURL != "" {
+		q.Set("thumbnail_url", req.ThumbnailURL)
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, apiBase+"/videos?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+p.token)
+	httpReq.Header.Set("Client-Id", p.clientID)
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("videos create returned %s", resp.Status)
+	}
+	var out struct {
+		Data []struct {
+			ID  string `json:"id"`
+			URL string `json:"url"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	if len(out.Data) == 0 {
+		return nil, fmt.Errorf("videos create returned no video")
+	}
+	return &plugin.PublishResult{VideoID: out.Data[0].ID, URL: out.Data[0].URL}, nil
+}
+
+// SetChatSettings maps the controls onto Helix PATCH /chat/settings.
+// The broadcaster doubles as the moderator, which the API allows for
+// tokens with the broadcaster's own authorization.
+func (p *twitchPublisher) SetChatSettings(channelID string, settings plugin.ChatSettings) error {
+	body := map[string]interface{}{}
+	if settings.SlowModeSec != nil {
+		body["slow_mode"] = *settings.SlowModeSec > 0
+		if *settings.SlowModeSec > 0 {
+			body["slow_mode_wait_time"] = *settings.SlowModeSec
+		}
+	}
+	if settings.SubscribersOnly != nil {
+		body["subscriber_mode"] = *settings.SubscribersOnly
+	}
+	if settings.EmoteOnly != nil {
+		body["emote_mode"] = *settings.EmoteOnly
+	}
+	if len(body) == 0 {
+		return nil
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	q := url.Values{}
+	q.Set("broadcaster_id", channelID)
+	q.Set("moderator_id", channelID)
+	httpReq, err := http.NewRequest(http.MethodPatch, apiBase+"/chat/settings?"+q.Encode(), strings.NewReader(string(payload)))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+p.token)
+	httpReq.Header.Set("Client-Id", p.clientID)
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("chat settings update returned %s", resp.Status)
+	}
+	return nil
+}
+
+func main() {
+	plugin.ServePublisher(&twitchPublisher{
+		token:    os.Getenv("TWITCH_ACCESS_TOKEN"),
+		clientID: os.Getenv("TWITCH_CLIENT_ID"),
+		client:   &http.Client{Timeout: 5 * time.Minute},
+	})
+}
@@ -0,0 +1,169 @@
+// Command publisher-vimeo publishes finished outputs to Vimeo using the
+// pull-upload API, so Vimeo fetches the file straight from storage.
+// Requires a token with upload scope in VIMEO_ACCESS_TOKEN.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rennerdo30/webencode/pkg/plugin"
+)
+
+const apiBase = "https://api.vimeo.com"
+
+type vimeoPublisher struct {
+	token  string
+	client *http.Client
+}
+
+func (p *vimeoPublisher) Name() (string, error) { return "vimeo", nil }
+
+func (p *vimeoPublisher) Publish(req plugin.PublishRequest) (*plugin.PublishResult, error) {
+	visibility := req.Visibility
+	if visibility == "" {
+		visibility = plugin.VisibilityPrivate
+	}
+	body := map[string]interface{}{
+		"upload": map[string]string{
+			"approach": "pull",
+			"link":     req.FileURL,
+		},
+		"name":        req.Title,
+		"description": req.Description,
+		"privacy": map[string]string{
+			"view": vimeoPrivacy(visibility),
+		},
+	}
+	if len(req.Tags) > 0 {
+		// Vimeo caps tags at 20; trim rather than fail the publish.
+		tags := req.Tags
+		if len(tags) > 20 {
+			tags = tags[:20]
+		}
+		body["tags"] = tags
+	}
+
+	var created struct {
+		URI  string `json:"uri"`
+		Link string `json:"link"`
+	}
+	if err := p.call(http.MethodPost, "/me/videos", body, &created); err != nil {
+		return nil, err
+	}
+	videoID := strings.TrimPrefix(created.URI, "/videos/")
+
+	if req.ThumbnailURL != "" {
+		if err := p.setThumbnail(created.URI, req.ThumbnailURL); err != nil {
+			return nil, fmt.Errorf("video %s created but thumbnail failed: %w", videoID, err)
+		}
+	}
+	for _, album := range req.Playlists {
+		if err := p.call(http.MethodPut, "/me/albums/"+album+created.URI, nil, nil); err != nil {
+			return nil, fmt.Errorf("video %s created but album %s failed: %w", videoID, album, err)
+		}
+	}
+	if req.PublishAt != nil {
+		// Vimeo models scheduling as a publish-to-social time on the
+		// video resource.
+		patch := map[string]interface{}{
+			"publish": map[string]string{"time": req.PublishAt.UTC().Format(time.RFC3339)},
+		}
+		if err := p.call(http.MethodPatch, created.URI, patch, nil); err != nil {
+			return nil, fmt.Errorf("video %s created but scheduling failed: %w", videoID, err)
+		}
+	}
+	return &plugin.PublishResult{VideoID: videoID, URL: created.Link}, nil
+}
+
+// setThumbnail uploads a picture and activates it as the video poster.
+func (p *vimeoPublisher) setThumbnail(videoURI, thumbURL string) error {
+	var pic struct {
+		URI  string `json:"uri"`
+		Link string `json:"link"`
+	}
+	if err := p.call(http.MethodPost, videoURI+"/pictures", map[string]bool{"active": true}, &pic); err != nil {
+		return err
+	}
+	src, err := p.client.Get(thumbURL)
+	if err != nil {
+		return err
+	}
+	defer src.Body.Close()
+	if src.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetch thumbnail: %s", src.Status)
+	}
+	put, err := http.NewRequest(http.MethodPut, pic.Link, src.Body)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(put)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("thumbnail upload returned %s", resp.Status)
+	}
+	return p.call(http.MethodPatch, pic.URI, map[string]bool{"active": true}, nil)
+}
+
+func (p *vimeoPublisher) call(method, path string, body, out interface{}) error {
+	var reader *bytes.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(raw)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequest(method, apiBase+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/vnd.vimeo.*+json;version=3.4")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s %s returned %s", method, path, resp.Status)
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
+
+func vimeoPrivacy(v plugin.Visibility) string {
+	switch v {
+	case plugin.VisibilityPublic:
+		return "anybody"
+	case plugin.VisibilityUnlisted:
+		return "unlisted"
+	default:
+		return "nobody"
+	}
+}
+
+// SetChatSettings is unsupported: Vimeo has no viewer chat.
+func (p *vimeoPublisher) SetChatSettings(string, plugin.ChatSettings) error {
+	return plugin.ErrChatControlsUnsupported
+}
+
+func main() {
+	plugin.ServePublisher(&vimeoPublisher{
+		token:  os.Getenv("VIMEO_ACCESS_TOKEN"),
+		client: &http.Client{Timeout: 5 * time.Minute},
+	})
+}
@@ -0,0 +1,208 @@
+// Command publisher-youtube publishes finished outputs to YouTube via
+// the Data API v3. It expects an OAuth access token with the
+// youtube.upload scope in YOUTUBE_ACCESS_TOKEN.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/rennerdo30/webencode/pkg/plugin"
+)
+
+const apiBase = "https://www.googleapis.com/youtube/v3"
+
+type youtubePublisher struct {
+	token  string
+	client *http.Client
+}
+
+func (p *youtubePublisher) Name() (string, error) { return "youtube", nil }
+
+// snippet and status follow the videos.insert resource shape.
+type videoResource struct {
+	ID      string `json:"id,omitempty"`
+	Snippet struct {
+		Title       string   `json:"title"`
+		Description string   `json:"description"`
+		Tags        []string `json:"tags,omitempty"`
+		CategoryID  string   `json:"categoryId,omitempty"`
+	} `json:"snippet"`
+	Status struct {
+		PrivacyStatus string `json:"privacyStatus"`
+		PublishAt     string `json:"publishAt,omitempty"`
+	} `json:"status"`
+}
+
+func (p *youtubePublisher) Publish(req plugin.PublishRequest) (*plugin.PublishResult, error) {
+	var res videoResource
+	res.Snippet.Title = req.Title
+	res.Snippet.Description = req.Description
+	res.Snippet.Tags = req.Tags
+	res.Snippet.CategoryID = req.Category
+	res.Status.PrivacyStatus = string(req.Visibility)
+	if res.Status.PrivacyStatus == "" {
+		res.Status.PrivacyStatus = string(plugin.VisibilityPrivate)
+	}
+	if req.PublishAt != nil {
+		// Scheduled publishing requires the video to stay private until
+		// the publish time; YouTube rejects other combinations.
+		res.Status.PrivacyStatus = string(plugin.VisibilityPrivate)
+		res.Status.PublishAt = req.PublishAt.UTC().Format(time.RFC3339)
+	}
+
+	videoID, err := p.upload(req.FileURL, res)
+	if err != nil {
+		return nil, err
+	}
+	if req.ThumbnailURL != "" {
+		if err := p.setThumbnail(videoID, req.ThumbnailURL); err != nil {
+			return nil, fmt.Errorf("video %s uploaded but thumbnail failed: %w", videoID, err)
+		}
+	}
+	for _, playlistID := range req.Playlists {
+		if err := p.addToPlaylist(videoID, playlistID); err != nil {
+			return nil, fmt.Errorf("video %s uploaded but playlist %s failed: %w", videoID, playlistID, err)
+		}
+	}
+	return &plugin.PublishResult{
+		VideoID: videoID,
+		URL:     "https://www.youtube.com/watch?v=" + videoID,
+	}, nil
+}
+
+// upload starts a resumable upload session with the metadata and streams
+// the source file into it.
+func (p *youtubePublisher) upload(fileURL string, res videoResource) (string, error) {
+	meta, err := json.Marshal(res)
+	if err != nil {
+		return "", err
+	}
+	initReq, err := http.NewRequest(http.MethodPost,
+		"https://www.googleapis.com/upload/youtube/v3/videos?uploadType=resumable&part=snippet,status",
+		bytes.NewReader(meta))
+	if err != nil {
+		return "", err
+	}
+	p.authorize(initReq)
+	initReq.Header.Set("Content-Type", "application/json")
+	initResp, err := p.client.Do(initReq)
+	if err != nil {
+		return "", err
+	}
+	defer initResp.Body.Close()
+	if initResp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("resumable init returned %s", initResp.Status)
+	}
+	session := initResp.Header.Get("Location")
+	if session == "" {
+		return "", fmt.Errorf("resumable init returned no session URL")
+	}
+
+	src, err := p.client.Get(fileURL)
+	if err != nil {
+		return "", fmt.Errorf("fetch source: %w", err)
+	}
+	defer src.Body.Close()
+	if src.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetch source: %s", src.Status)
+	}
+
+	putReq, err := http.NewRequest(http.MethodPut, session, src.Body)
+	if err != nil {
+		return "", err
+	}
+	putReq.ContentLength = src.ContentLength
+	p.authorize(putReq)
+	putResp, err := p.client.Do(putReq)
+	if err != nil {
+		return "", err
+	}
+	defer putResp.Body.Close()
+	if putResp.StatusCode != http.StatusOK && putResp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("upload returned %s", putResp.Status)
+	}
+	var uploaded videoResource
+	if err := json.NewDecoder(putResp.Body).Decode(&uploaded); err != nil {
+		return "", err
+	}
+	return uploaded.ID, nil
+}
+
+func (p *youtubePublisher) setThumbnail(videoID, thumbURL string) error {
+	src, err := p.client.Get(thumbURL)
+	if err != nil {
+		return err
+	}
+	defer src.Body.Close()
+	if src.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetch thumbnail: %s", src.Status)
+	}
+	req, err := http.NewRequest(http.MethodPost,
+		"https://www.googleapis.com/upload/youtube/v3/thumbnails/set?videoId="+videoID, src.Body)
+	if err != nil {
+		return err
+	}
+	p.authorize(req)
+	req.Header.Set("Content-Type", src.Header.Get("Content-Type"))
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("thumbnails.set returned %s", resp.Status)
+	}
+	return nil
+}
+
+func (p *youtubePublisher) addToPlaylist(videoID, playlistID string) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"snippet": map[string]interface{}{
+			"playlistId": playlistID,
+			"resourceId": map[string]string{
+				"kind":    "youtube#video",
+				"videoId": videoID,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, apiBase+"/playlistItems?part=snippet", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	p.authorize(req)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("playlistItems.insert returned %s", resp.Status)
+	}
+	return nil
+}
+
+// SetChatSettings is unsupported: the Data API only exposes live chat
+// moderation per message, not channel-wide chat modes.
+func (p *youtubePublisher) SetChatSettings(string, plugin.ChatSettings) error {
+	return plugin.ErrChatControlsUnsupported
+}
+
+func (p *youtubePublisher) authorize(req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+p.token)
+}
+
+func main() {
+	plugin.ServePublisher(&youtubePublisher{
+		token:  os.Getenv("YOUTUBE_ACCESS_TOKEN"),
+		client: &http.Client{Timeout: 30 * time.Minute},
+	})
+}